@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 )
@@ -19,7 +20,7 @@ func getApplicationAuthenticationDaoWithTenant(c echo.Context) (dao.ApplicationA
 		return nil, err
 	}
 
-	return dao.GetApplicationAuthenticationDao(&tenantId), nil
+	return dao.GetApplicationAuthenticationDao(&tenantId).WithContext(c.Request().Context()), nil
 }
 
 func ApplicationAuthenticationList(c echo.Context) error {
@@ -81,12 +82,12 @@ func ApplicationAuthenticationListAuthentications(c echo.Context) error {
 
 	id, err := strconv.ParseInt(c.Param("application_authentication_id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	auths, count, err := authDao.ListForApplicationAuthentication(id, 100, 0, nil)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, util.ErrorDoc(err.Error(), "404"))
+		return c.JSON(http.StatusNotFound, util.ErrorDoc(c.Request().Context(), err.Error(), "404"))
 	}
 
 	out := make([]interface{}, count)
@@ -96,3 +97,79 @@ func ApplicationAuthenticationListAuthentications(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, util.CollectionResponse(out, c.Request(), int(count), 100, 0))
 }
+
+// ApplicationAuthenticationPause pauses a given application authentication by setting its "paused_at" column to "now()". When a "resume_at" is given, the
+// application authentication gets resumed on its own at that time.
+func ApplicationAuthenticationPause(c echo.Context) error {
+	appAuthId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	pauseRequest, err := getPauseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	appAuthDao, err := getApplicationAuthenticationDao(c)
+	if err != nil {
+		return err
+	}
+
+	err = appAuthDao.Pause(appAuthId)
+	if err != nil {
+		return err
+	}
+
+	err = scheduleResume(c, service.PausedApplicationAuthentication, appAuthId, pauseRequest.ResumeAt)
+	if err != nil {
+		return err
+	}
+
+	appAuth, err := appAuthDao.GetById(&appAuthId)
+	if err != nil {
+		return err
+	}
+
+	err = service.RaiseEvent("ApplicationAuthentication.Pause", appAuth, service.ForwadableHeaders(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// ApplicationAuthenticationResume resumes a given application authentication by setting its "paused_at" column to "NULL".
+func ApplicationAuthenticationResume(c echo.Context) error {
+	appAuthId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	appAuthDao, err := getApplicationAuthenticationDao(c)
+	if err != nil {
+		return err
+	}
+
+	err = appAuthDao.Resume(appAuthId)
+	if err != nil {
+		return err
+	}
+
+	err = scheduleResume(c, service.PausedApplicationAuthentication, appAuthId, nil)
+	if err != nil {
+		return err
+	}
+
+	appAuth, err := appAuthDao.GetById(&appAuthId)
+	if err != nil {
+		return err
+	}
+
+	err = service.RaiseEvent("ApplicationAuthentication.Unpause", appAuth, service.ForwadableHeaders(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusNoContent, nil)
+}
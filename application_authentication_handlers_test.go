@@ -1,14 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/RedHatInsights/sources-api-go/dao"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
 	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/util"
 )
 
@@ -179,3 +183,148 @@ func TestApplicationAuthenticationGetBadRequest(t *testing.T) {
 
 	testutils.BadRequestTest(t, rec)
 }
+
+// TestApplicationAuthenticationPause tests that an application authentication gets paused, and that its resumption gets scheduled.
+func TestApplicationAuthenticationPause(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+
+	resumeAt := time.Now().Add(time.Hour)
+	body, err := json.Marshal(m.PauseRequest{ResumeAt: &resumeAt})
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/application_authentications/1/pause",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	err = ApplicationAuthenticationPause(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf(`want status "%d", got "%d"`, http.StatusNoContent, rec.Code)
+	}
+
+	due, err := dao.GetScheduledResumeDao(nil).ListDue(resumeAt, 100)
+	if err != nil {
+		t.Error(err)
+	}
+
+	scheduled := false
+	for _, entry := range due {
+		scheduled = scheduled || (entry.ResourceType == service.PausedApplicationAuthentication && entry.ResourceID == 1)
+	}
+
+	if !scheduled {
+		t.Errorf("want the resumption scheduled, got %+v", due)
+	}
+}
+
+// TestApplicationAuthenticationResume tests that an application authentication gets resumed, and that its scheduled resumption gets cancelled.
+func TestApplicationAuthenticationResume(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/application_authentications/1/unpause",
+		nil,
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	err := ApplicationAuthenticationResume(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf(`want status "%d", got "%d"`, http.StatusNoContent, rec.Code)
+	}
+
+	due, err := dao.GetScheduledResumeDao(nil).ListDue(time.Now().Add(24*time.Hour), 100)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, entry := range due {
+		if entry.ResourceType == service.PausedApplicationAuthentication && entry.ResourceID == 1 {
+			t.Errorf("want the scheduled resumption cancelled, got %+v", entry)
+		}
+	}
+}
+
+// TestApplicationAuthenticationPauseNotFound tests that a not found error is returned when the application authentication doesn't exist, and that no
+// resumption gets scheduled for it.
+func TestApplicationAuthenticationPauseNotFound(t *testing.T) {
+	resumeAt := time.Now().Add(time.Hour)
+	body, err := json.Marshal(m.PauseRequest{ResumeAt: &resumeAt})
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/application_authentications/12345/pause",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	c.SetParamNames("id")
+	c.SetParamValues("12345")
+
+	notFoundApplicationAuthenticationPause := ErrorHandlingContext(ApplicationAuthenticationPause)
+	err = notFoundApplicationAuthenticationPause(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.NotFoundTest(t, rec)
+}
+
+// TestApplicationAuthenticationPauseBadRequest tests that a bad request is returned when the given "resume_at" isn't in the future.
+func TestApplicationAuthenticationPauseBadRequest(t *testing.T) {
+	resumeAt := time.Now().Add(-time.Hour)
+	body, err := json.Marshal(m.PauseRequest{ResumeAt: &resumeAt})
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/application_authentications/1/pause",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	badRequestApplicationAuthenticationPause := ErrorHandlingContext(ApplicationAuthenticationPause)
+	err = badRequestApplicationAuthenticationPause(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.BadRequestTest(t, rec)
+}
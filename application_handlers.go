@@ -22,7 +22,7 @@ func getApplicationDaoWithTenant(c echo.Context) (dao.ApplicationDao, error) {
 		return nil, err
 	}
 
-	return dao.GetApplicationDao(&tenantId), nil
+	return dao.GetApplicationDao(&tenantId).WithContext(c.Request().Context()), nil
 }
 
 func ApplicationList(c echo.Context) error {
@@ -99,6 +99,11 @@ func ApplicationCreate(c echo.Context) error {
 		return util.NewErrBadRequest(fmt.Sprintf("Validation failed: %s", err.Error()))
 	}
 
+	err = checkNotPaused(c, service.PausedSource, input.SourceID)
+	if err != nil {
+		return err
+	}
+
 	application := &m.Application{
 		Extra:             input.Extra,
 		ApplicationTypeID: input.ApplicationTypeID,
@@ -135,12 +140,21 @@ func ApplicationEdit(c echo.Context) error {
 		return err
 	}
 
+	err = checkNotPaused(c, service.PausedApplication, app.ID)
+	if err != nil {
+		return err
+	}
+
 	app.UpdateFromRequest(input)
 	err = applicationDB.Update(app)
 	if err != nil {
 		return err
 	}
 
+	if input.AvailabilityStatus != nil {
+		service.RecordAvailabilityStatus(c.Request().Context(), *applicationDB.Tenant(), "Application", strconv.FormatInt(app.ID, 10), app.AvailabilityStatus.AvailabilityStatus, app.AvailabilityStatusError, m.AvailabilityStatusOriginApi)
+	}
+
 	setEventStreamResource(c, app)
 	return c.JSON(http.StatusOK, app.ToResponse())
 }
@@ -232,13 +246,19 @@ func SourceListApplications(c echo.Context) error {
 	return c.JSON(http.StatusOK, util.CollectionResponse(out, c.Request(), int(count), limit, offset))
 }
 
-// ApplicationPause pauses a given application by setting its "paused_at" column to "now()".
+// ApplicationPause pauses a given application by setting its "paused_at" column to "now()". When a "resume_at" is
+// given, the application gets resumed on its own at that time.
 func ApplicationPause(c echo.Context) error {
 	applicationId, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return util.NewErrBadRequest(err)
 	}
 
+	pauseRequest, err := getPauseRequest(c)
+	if err != nil {
+		return err
+	}
+
 	applicationDao, err := getApplicationDao(c)
 	if err != nil {
 		return err
@@ -249,6 +269,11 @@ func ApplicationPause(c echo.Context) error {
 		return util.NewErrBadRequest(err)
 	}
 
+	err = scheduleResume(c, service.PausedApplication, applicationId, pauseRequest.ResumeAt)
+	if err != nil {
+		return err
+	}
+
 	application, err := applicationDao.GetById(&applicationId)
 	if err != nil {
 		return err
@@ -283,6 +308,11 @@ func ApplicationResume(c echo.Context) error {
 		return util.NewErrBadRequest(err)
 	}
 
+	err = scheduleResume(c, service.PausedApplication, applicationId, nil)
+	if err != nil {
+		return err
+	}
+
 	application, err := applicationDao.GetById(&applicationId)
 	if err != nil {
 		return err
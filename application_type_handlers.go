@@ -21,9 +21,9 @@ func getApplicationTypeDaoWithTenant(c echo.Context) (dao.ApplicationTypeDao, er
 	}
 
 	if tenantId == 0 && err == nil {
-		return dao.GetApplicationTypeDao(nil), nil
+		return dao.GetApplicationTypeDao(nil).WithContext(c.Request().Context()), nil
 	} else {
-		return dao.GetApplicationTypeDao(&tenantId), nil
+		return dao.GetApplicationTypeDao(&tenantId).WithContext(c.Request().Context()), nil
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/RedHatInsights/sources-api-go/dao"
 	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 )
@@ -19,7 +20,7 @@ func getAuthenticationDaoWithTenant(c echo.Context) (dao.AuthenticationDao, erro
 		return nil, err
 	}
 
-	return dao.GetAuthenticationDao(&tenantId), nil
+	return dao.GetAuthenticationDao(&tenantId).WithContext(c.Request().Context()), nil
 }
 
 func AuthenticationList(c echo.Context) error {
@@ -59,7 +60,7 @@ func AuthenticationGet(c echo.Context) error {
 
 	auth, err := authDao.GetById(c.Param("uid"))
 	if err != nil {
-		return c.JSON(http.StatusNotFound, util.ErrorDoc(err.Error(), "404"))
+		return c.JSON(http.StatusNotFound, util.ErrorDoc(c.Request().Context(), err.Error(), "404"))
 	}
 	return c.JSON(http.StatusOK, auth.ToResponse())
 }
@@ -76,6 +77,11 @@ func AuthenticationCreate(c echo.Context) error {
 		return err
 	}
 
+	err = checkNotPaused(c, createRequest.ResourceType, createRequest.ResourceID)
+	if err != nil {
+		return err
+	}
+
 	auth := &m.Authentication{
 		Name:         createRequest.Name,
 		AuthType:     createRequest.AuthType,
@@ -87,7 +93,7 @@ func AuthenticationCreate(c echo.Context) error {
 	}
 	err = authDao.Create(auth)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	// TODO: once ToEvent() is added for authentication un-comment this.
@@ -109,13 +115,22 @@ func AuthenticationUpdate(c echo.Context) error {
 
 	auth, err := authDao.GetById(c.Param("uid"))
 	if err != nil {
-		return c.JSON(http.StatusNotFound, util.ErrorDoc(fmt.Sprintf("Authentication %v not found (%s)", c.Param("uid"), err.Error()), "404"))
+		return c.JSON(http.StatusNotFound, util.ErrorDoc(c.Request().Context(), fmt.Sprintf("Authentication %v not found (%s)", c.Param("uid"), err.Error()), "404"))
+	}
+
+	err = checkNotPaused(c, auth.ResourceType, auth.ResourceID)
+	if err != nil {
+		return err
 	}
 
 	auth.UpdateFromRequest(updateRequest)
 	err = authDao.Update(auth)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
+	}
+
+	if updateRequest.AvailabilityStatus != nil {
+		service.RecordAvailabilityStatus(c.Request().Context(), *authDao.Tenant(), "Authentication", auth.ID, auth.AvailabilityStatus.AvailabilityStatus, auth.AvailabilityStatusError, m.AvailabilityStatusOriginApi)
 	}
 
 	// TODO: once ToEvent() is added for authentication un-comment this.
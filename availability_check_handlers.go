@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+)
+
+// function that defines how we get the dao - default implementation below.
+var getAvailabilityCheckDao func(c echo.Context) (dao.AvailabilityCheckDao, error)
+
+func getAvailabilityCheckDaoWithTenant(c echo.Context) (dao.AvailabilityCheckDao, error) {
+	tenantId, err := getTenantFromEchoContext(c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.GetAvailabilityCheckDao(&tenantId).WithContext(c.Request().Context()), nil
+}
+
+// SourceAvailabilityCheckGet returns an availability check of the source, along with the state of its targets.
+func SourceAvailabilityCheckGet(c echo.Context) error {
+	availabilityCheckDao, err := getAvailabilityCheckDao(c)
+	if err != nil {
+		return err
+	}
+
+	sourceID, err := strconv.ParseInt(c.Param("source_id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	checkID, err := strconv.ParseInt(c.Param("check_id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	check, err := availabilityCheckDao.GetById(sourceID, checkID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, check.ToResponse())
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+func TestSourceAvailabilityCheckGet(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/sources/1/availability_checks/1",
+		nil,
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("source_id", "check_id")
+	c.SetParamValues("1", "1")
+
+	err := SourceAvailabilityCheckGet(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Did not return 200. Body: %s", rec.Body.String())
+	}
+
+	var out m.AvailabilityCheckResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Error("Failed unmarshaling output")
+	}
+
+	if out.ID != "1" || out.SourceID != "1" {
+		t.Errorf("Wrong availability check returned, got id %s for source %s", out.ID, out.SourceID)
+	}
+
+	if len(out.Targets) != 2 {
+		t.Errorf("Expected 2 targets, got %d", len(out.Targets))
+	}
+}
+
+func TestSourceAvailabilityCheckGetNotFound(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/sources/1/availability_checks/12345",
+		nil,
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("source_id", "check_id")
+	c.SetParamValues("1", "12345")
+
+	notFoundSourceAvailabilityCheckGet := ErrorHandlingContext(SourceAvailabilityCheckGet)
+	err := notFoundSourceAvailabilityCheckGet(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.NotFoundTest(t, rec)
+}
+
+func TestSourceAvailabilityCheckGetBadRequest(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/sources/1/availability_checks/xxx",
+		nil,
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("source_id", "check_id")
+	c.SetParamValues("1", "xxx")
+
+	badRequestSourceAvailabilityCheckGet := ErrorHandlingContext(SourceAvailabilityCheckGet)
+	err := badRequestSourceAvailabilityCheckGet(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.BadRequestTest(t, rec)
+}
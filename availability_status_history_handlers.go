@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+)
+
+// function that defines how we get the dao - default implementation below.
+var getAvailabilityStatusHistoryDao func(c echo.Context) (dao.AvailabilityStatusHistoryDao, error)
+
+func getAvailabilityStatusHistoryDaoWithTenant(c echo.Context) (dao.AvailabilityStatusHistoryDao, error) {
+	tenantId, err := getTenantFromEchoContext(c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.GetAvailabilityStatusHistoryDao(&tenantId).WithContext(c.Request().Context()), nil
+}
+
+// SourceAvailabilityHistory lists the availability status transitions of the source. The time range can be narrowed
+// down with the "created_at" filters.
+func SourceAvailabilityHistory(c echo.Context) error {
+	sourcesDB, err := getSourceDao(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("source_id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	_, err = sourcesDB.GetById(&id)
+	if err != nil {
+		return err
+	}
+
+	return listAvailabilityHistory(c, "Source", id)
+}
+
+// ApplicationAvailabilityHistory lists the availability status transitions of the application. The time range can be
+// narrowed down with the "created_at" filters.
+func ApplicationAvailabilityHistory(c echo.Context) error {
+	applicationDB, err := getApplicationDao(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("application_id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	_, err = applicationDB.GetById(&id)
+	if err != nil {
+		return err
+	}
+
+	return listAvailabilityHistory(c, "Application", id)
+}
+
+// EndpointAvailabilityHistory lists the availability status transitions of the endpoint. The time range can be
+// narrowed down with the "created_at" filters.
+func EndpointAvailabilityHistory(c echo.Context) error {
+	endpointDB, err := getEndpointDao(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("endpoint_id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	_, err = endpointDB.GetById(&id)
+	if err != nil {
+		return err
+	}
+
+	return listAvailabilityHistory(c, "Endpoint", id)
+}
+
+// listAvailabilityHistory returns the paginated availability status transitions of the given resource.
+func listAvailabilityHistory(c echo.Context, resourceType string, id int64) error {
+	historyDB, err := getAvailabilityStatusHistoryDao(c)
+	if err != nil {
+		return err
+	}
+
+	filters, err := getFilters(c)
+	if err != nil {
+		return err
+	}
+
+	limit, offset, err := getLimitAndOffset(c)
+	if err != nil {
+		return err
+	}
+
+	history, count, err := historyDB.ListForResource(resourceType, strconv.FormatInt(id, 10), limit, offset, filters)
+	if err != nil {
+		return err
+	}
+
+	out := make([]interface{}, len(history))
+	for i := 0; i < len(history); i++ {
+		out[i] = history[i].ToResponse()
+	}
+
+	return c.JSON(http.StatusOK, util.CollectionResponse(out, c.Request(), int(count), limit, offset))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+func TestSourceAvailabilityHistory(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/sources/1/availability_history",
+		nil,
+		map[string]interface{}{
+			"limit":    100,
+			"offset":   0,
+			"filters":  []util.Filter{},
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("source_id")
+	c.SetParamValues("1")
+
+	err := SourceAvailabilityHistory(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Did not return 200. Body: %s", rec.Body.String())
+	}
+
+	var out util.Collection
+	err = json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Error("Failed unmarshaling output")
+	}
+
+	if len(out.Data) != 2 {
+		t.Fatalf("Expected 2 transitions, got %d", len(out.Data))
+	}
+
+	latest, ok := out.Data[0].(map[string]interface{})
+	if !ok {
+		t.Fatal("model did not deserialize as a transition")
+	}
+
+	if latest["status"] != m.Unavailable || latest["previous_status"] != m.Available || latest["status_error"] != "the ARN is not valid" {
+		t.Errorf("Expected the latest transition first, got %v", latest)
+	}
+
+	AssertLinks(t, c.Request().RequestURI, out.Links, 100, 0)
+}
+
+func TestApplicationAvailabilityHistory(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/applications/1/availability_history",
+		nil,
+		map[string]interface{}{
+			"limit":    100,
+			"offset":   0,
+			"filters":  []util.Filter{},
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("application_id")
+	c.SetParamValues("1")
+
+	err := ApplicationAvailabilityHistory(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Did not return 200. Body: %s", rec.Body.String())
+	}
+
+	var out util.Collection
+	err = json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Error("Failed unmarshaling output")
+	}
+
+	// the application edit tests might have recorded newer transitions, so the fixture is the oldest one.
+	if len(out.Data) == 0 {
+		t.Fatal("Expected the application's transitions, got none")
+	}
+
+	oldest, ok := out.Data[len(out.Data)-1].(map[string]interface{})
+	if !ok {
+		t.Fatal("model did not deserialize as a transition")
+	}
+
+	if oldest["resource_type"] != "Application" || oldest["origin"] != m.AvailabilityStatusOriginStatusMessage {
+		t.Errorf("Expected the application's transition, got %v", oldest)
+	}
+}
+
+func TestEndpointAvailabilityHistoryEmpty(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/endpoints/1/availability_history",
+		nil,
+		map[string]interface{}{
+			"limit":    100,
+			"offset":   0,
+			"filters":  []util.Filter{},
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("endpoint_id")
+	c.SetParamValues("1")
+
+	err := EndpointAvailabilityHistory(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Did not return 200. Body: %s", rec.Body.String())
+	}
+
+	var out util.Collection
+	err = json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Error("Failed unmarshaling output")
+	}
+
+	if len(out.Data) != 0 {
+		t.Errorf("Expected no transitions, got %d", len(out.Data))
+	}
+}
+
+func TestSourceAvailabilityHistoryNotFound(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/sources/12345/availability_history",
+		nil,
+		map[string]interface{}{
+			"limit":    100,
+			"offset":   0,
+			"filters":  []util.Filter{},
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("source_id")
+	c.SetParamValues("12345")
+
+	notFoundSourceAvailabilityHistory := ErrorHandlingContext(SourceAvailabilityHistory)
+	err := notFoundSourceAvailabilityHistory(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.NotFoundTest(t, rec)
+}
+
+func TestSourceAvailabilityHistoryBadRequest(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/sources/xxx/availability_history",
+		nil,
+		map[string]interface{}{
+			"limit":    100,
+			"offset":   0,
+			"filters":  []util.Filter{},
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("source_id")
+	c.SetParamValues("xxx")
+
+	badRequestSourceAvailabilityHistory := ErrorHandlingContext(SourceAvailabilityHistory)
+	err := badRequestSourceAvailabilityHistory(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.BadRequestTest(t, rec)
+}
@@ -11,6 +11,9 @@ import (
 
 var parsedConfig *SourcesApiConfig
 
+// EventStreamEncodings are the accepted values of EVENT_STREAM_ENCODING.
+var EventStreamEncodings = []string{"legacy", "cloudevents-binary", "cloudevents-structured"}
+
 // SourcesApiConfig is the struct for storing runtime configuration
 type SourcesApiConfig struct {
 	AppName                   string
@@ -37,8 +40,34 @@ type SourcesApiConfig struct {
 	CachePort                 int
 	CachePassword             string
 	SlowSQLThreshold          int
+	ShutdownTimeout           int
 	Psks                      []string
+	PsksNamed                 bool
 	BypassRbac                bool
+	EventStreamEncoding       string
+	EventStreamValidation     bool
+	ReplayEventsPerSecond     int
+	AvailabilityCheckInterval int
+	AvailabilityCheckJitter   float64
+	AvailabilityHistoryDays   int
+	AvailabilityDispatchers   string
+	SourceAvailabilityRules   string
+	SystemPolicies            string
+	AvailabilityVerifiers     bool
+	VerifierTimeout           int
+	VerifierAwsStsUrl         string
+	VerifierAzureLoginUrl     string
+	VerifierGcpTokenUrl       string
+	CertificateExpiryDays     int
+	RbacCacheTtl              int
+	RbacCacheNegativeTtl      int
+	TenantTranslatorUrl       string
+	TenantCacheSize           int
+	TenantCacheTtl            int
+	PskFile                   string
+	PskReloadInterval         int
+	AuditLogTopic             string
+	RateLimits                string
 }
 
 // Get - returns the config parsed from runtime vars
@@ -101,7 +130,84 @@ func Get() *SourcesApiConfig {
 	options.SetDefault("LogLevelForSqlLogs", "DEBUG")
 	options.SetDefault("MarketplaceHost", os.Getenv("MARKETPLACE_HOST"))
 	options.SetDefault("SlowSQLThreshold", 2) //seconds
+	options.SetDefault("ShutdownTimeout", 20) //seconds
 	options.SetDefault("BypassRbac", os.Getenv("BYPASS_RBAC") == "true")
+	options.SetDefault("EventStreamValidation", os.Getenv("EVENT_STREAM_VALIDATION") == "true")
+	// one of "legacy", "cloudevents-binary" or "cloudevents-structured".
+	options.SetDefault("EventStreamEncoding", "legacy")
+	if os.Getenv("EVENT_STREAM_ENCODING") != "" {
+		options.SetDefault("EventStreamEncoding", os.Getenv("EVENT_STREAM_ENCODING"))
+	}
+	if !isEventStreamEncoding(options.GetString("EventStreamEncoding")) {
+		panic(fmt.Sprintf("invalid EVENT_STREAM_ENCODING %q, must be one of %q", options.GetString("EventStreamEncoding"), EventStreamEncodings))
+	}
+	// maximum number of events per second sent when replaying a tenant's state.
+	options.SetDefault("ReplayEventsPerSecond", 50)
+	if os.Getenv("REPLAY_EVENTS_PER_SECOND") != "" {
+		options.SetDefault("ReplayEventsPerSecond", os.Getenv("REPLAY_EVENTS_PER_SECOND"))
+	}
+
+	// how often, in seconds, the scheduler requests the availability checks of every source.
+	options.SetDefault("AvailabilityCheckInterval", 3600)
+	if os.Getenv("AVAILABILITY_CHECK_INTERVAL") != "" {
+		options.SetDefault("AvailabilityCheckInterval", os.Getenv("AVAILABILITY_CHECK_INTERVAL"))
+	}
+	// fraction of the interval used to randomly spread the checks, so that the replicas don't all fire at once.
+	options.SetDefault("AvailabilityCheckJitter", 0.1)
+	if os.Getenv("AVAILABILITY_CHECK_JITTER") != "" {
+		options.SetDefault("AvailabilityCheckJitter", os.Getenv("AVAILABILITY_CHECK_JITTER"))
+	}
+	// number of days the availability status transitions are kept for. Zero keeps them forever.
+	options.SetDefault("AvailabilityHistoryDays", 90)
+	if os.Getenv("AVAILABILITY_HISTORY_DAYS") != "" {
+		options.SetDefault("AvailabilityHistoryDays", os.Getenv("AVAILABILITY_HISTORY_DAYS"))
+	}
+	// JSON document overriding the availability check dispatchers declared in the seeds, e.g.
+	// {"application_types": {"/insights/platform/catalog": {"strategy": "http", "url_env": "CATALOG_URL"}}}
+	options.SetDefault("AvailabilityDispatchers", os.Getenv("AVAILABILITY_DISPATCHERS"))
+	// YAML file overriding, per source type, the built-in rules used to derive the sources' availability statuses.
+	options.SetDefault("SourceAvailabilityRules", os.Getenv("SOURCE_AVAILABILITY_RULES_FILE"))
+	// JSON array overriding the policies of the system identities, e.g.
+	// [{"key": "cn", "source_types": ["satellite"], "permissions": ["source:read", "source:write"]}]
+	options.SetDefault("SystemPolicies", os.Getenv("SYSTEM_POLICIES"))
+	// whether the built-in verifiers check the sources' credentials and endpoints along with the availability checks.
+	options.SetDefault("AvailabilityVerifiers", os.Getenv("AVAILABILITY_VERIFIERS") == "true")
+	// timeout, in seconds, of the built-in verifiers' requests.
+	options.SetDefault("VerifierTimeout", 10)
+	if os.Getenv("VERIFIER_TIMEOUT") != "" {
+		options.SetDefault("VerifierTimeout", os.Getenv("VERIFIER_TIMEOUT"))
+	}
+	// the providers' endpoints the verifiers talk to. Empty uses the public ones.
+	options.SetDefault("VerifierAwsStsUrl", os.Getenv("VERIFIER_AWS_STS_URL"))
+	options.SetDefault("VerifierAzureLoginUrl", os.Getenv("VERIFIER_AZURE_LOGIN_URL"))
+	options.SetDefault("VerifierGcpTokenUrl", os.Getenv("VERIFIER_GCP_TOKEN_URL"))
+	// days before their expiry the endpoints' certificates get reported in their availability status error.
+	options.SetDefault("CertificateExpiryDays", 30)
+	if os.Getenv("CERTIFICATE_EXPIRY_DAYS") != "" {
+		options.SetDefault("CertificateExpiryDays", os.Getenv("CERTIFICATE_EXPIRY_DAYS"))
+	}
+	// seconds the RBAC access lists are cached for. Zero disables the cache.
+	options.SetDefault("RbacCacheTtl", 30)
+	if os.Getenv("RBAC_CACHE_TTL") != "" {
+		options.SetDefault("RbacCacheTtl", os.Getenv("RBAC_CACHE_TTL"))
+	}
+	// seconds the access lists which don't grant any permission are cached for. Zero doesn't cache them.
+	options.SetDefault("RbacCacheNegativeTtl", 10)
+	if os.Getenv("RBAC_CACHE_NEGATIVE_TTL") != "" {
+		options.SetDefault("RbacCacheNegativeTtl", os.Getenv("RBAC_CACHE_NEGATIVE_TTL"))
+	}
+	// the tenant translator service which maps the account numbers to their org ids when backfilling them.
+	options.SetDefault("TenantTranslatorUrl", os.Getenv("TENANT_TRANSLATOR_URL"))
+	// number of tenant identifiers the tenant cache holds. Zero disables the cache.
+	options.SetDefault("TenantCacheSize", 10000)
+	if os.Getenv("TENANT_CACHE_SIZE") != "" {
+		options.SetDefault("TenantCacheSize", os.Getenv("TENANT_CACHE_SIZE"))
+	}
+	// seconds the tenants are cached for. Zero disables the cache.
+	options.SetDefault("TenantCacheTtl", 300)
+	if os.Getenv("TENANT_CACHE_TTL") != "" {
+		options.SetDefault("TenantCacheTtl", os.Getenv("TENANT_CACHE_TTL"))
+	}
 
 	var (
 		err      error
@@ -116,6 +222,21 @@ func Get() *SourcesApiConfig {
 	options.SetDefault("AppName", "source-api-go")
 
 	options.SetDefault("psks", strings.Split(os.Getenv("SOURCES_PSKS"), ","))
+	// whether the SOURCES_PSKS entries are in the "name:secret" format instead of being just the secrets.
+	options.SetDefault("PsksNamed", os.Getenv("SOURCES_PSKS_NAMED") == "true")
+	// the JSON file with the named PSKs and their scopes, which gets reloaded when it changes.
+	options.SetDefault("PskFile", os.Getenv("SOURCES_PSK_FILE"))
+	// seconds between the checks for changes of the PSK file.
+	options.SetDefault("PskReloadInterval", 30)
+	if os.Getenv("SOURCES_PSK_RELOAD_INTERVAL") != "" {
+		options.SetDefault("PskReloadInterval", os.Getenv("SOURCES_PSK_RELOAD_INTERVAL"))
+	}
+	// the topic the audit log gets mirrored to. Empty doesn't mirror it.
+	options.SetDefault("AuditLogTopic", os.Getenv("AUDIT_LOG_TOPIC"))
+	// JSON object with the requests per minute each tenant, and each identity or PSK, can make on each route group,
+	// e.g. {"internal": {"tenant": {"read": 6000, "write": 1200, "secret_read": 600}}}. The groups and the budgets
+	// which are left out keep the default ones, and zero doesn't limit the requests.
+	options.SetDefault("RateLimits", os.Getenv("RATE_LIMITS"))
 
 	options.AutomaticEnv()
 	parsedConfig = &SourcesApiConfig{
@@ -141,13 +262,50 @@ func Get() *SourcesApiConfig {
 		CacheHost:                 options.GetString("CacheHost"),
 		CachePort:                 options.GetInt("CachePort"),
 		CachePassword:             options.GetString("CachePassword"),
+		ShutdownTimeout:           options.GetInt("ShutdownTimeout"),
 		Psks:                      options.GetStringSlice("psks"),
+		PsksNamed:                 options.GetBool("PsksNamed"),
 		BypassRbac:                options.GetBool("BypassRbac"),
+		EventStreamEncoding:       options.GetString("EventStreamEncoding"),
+		EventStreamValidation:     options.GetBool("EventStreamValidation"),
+		ReplayEventsPerSecond:     options.GetInt("ReplayEventsPerSecond"),
+		AvailabilityCheckInterval: options.GetInt("AvailabilityCheckInterval"),
+		AvailabilityCheckJitter:   options.GetFloat64("AvailabilityCheckJitter"),
+		AvailabilityHistoryDays:   options.GetInt("AvailabilityHistoryDays"),
+		AvailabilityDispatchers:   options.GetString("AvailabilityDispatchers"),
+		SourceAvailabilityRules:   options.GetString("SourceAvailabilityRules"),
+		SystemPolicies:            options.GetString("SystemPolicies"),
+		AvailabilityVerifiers:     options.GetBool("AvailabilityVerifiers"),
+		VerifierTimeout:           options.GetInt("VerifierTimeout"),
+		VerifierAwsStsUrl:         options.GetString("VerifierAwsStsUrl"),
+		VerifierAzureLoginUrl:     options.GetString("VerifierAzureLoginUrl"),
+		VerifierGcpTokenUrl:       options.GetString("VerifierGcpTokenUrl"),
+		CertificateExpiryDays:     options.GetInt("CertificateExpiryDays"),
+		RbacCacheTtl:              options.GetInt("RbacCacheTtl"),
+		RbacCacheNegativeTtl:      options.GetInt("RbacCacheNegativeTtl"),
+		TenantTranslatorUrl:       options.GetString("TenantTranslatorUrl"),
+		TenantCacheSize:           options.GetInt("TenantCacheSize"),
+		TenantCacheTtl:            options.GetInt("TenantCacheTtl"),
+		PskFile:                   options.GetString("PskFile"),
+		PskReloadInterval:         options.GetInt("PskReloadInterval"),
+		AuditLogTopic:             options.GetString("AuditLogTopic"),
+		RateLimits:                options.GetString("RateLimits"),
 	}
 
 	return parsedConfig
 }
 
+// isEventStreamEncoding tells whether the encoding is one of the supported ones.
+func isEventStreamEncoding(encoding string) bool {
+	for _, supported := range EventStreamEncodings {
+		if encoding == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (sourceConfig *SourcesApiConfig) KafkaTopic(requestedTopic string) string {
 	topic, found := sourceConfig.KafkaTopics[requestedTopic]
 	if !found {
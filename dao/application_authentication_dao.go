@@ -1,7 +1,10 @@
 package dao
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	m "github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
@@ -25,6 +28,14 @@ func init() {
 
 type applicationAuthenticationDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (a *applicationAuthenticationDaoImpl) WithContext(ctx context.Context) ApplicationAuthenticationDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 func (a *applicationAuthenticationDaoImpl) ApplicationAuthenticationsByApplications(applications []m.Application) ([]m.ApplicationAuthentication, error) {
@@ -35,7 +46,7 @@ func (a *applicationAuthenticationDaoImpl) ApplicationAuthenticationsByApplicati
 		applicationIDs = append(applicationIDs, value.ID)
 	}
 
-	err := DB.Preload("Tenant").Where("application_id IN ?", applicationIDs).Find(&applicationAuthentications).Error
+	err := a.db().Preload("Tenant").Where("application_id IN ?", applicationIDs).Find(&applicationAuthentications).Error
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +62,7 @@ func (a *applicationAuthenticationDaoImpl) ApplicationAuthenticationsByAuthentic
 		authenticationUIDs = append(authenticationUIDs, value.ID)
 	}
 
-	result := DB.Preload("Tenant").Where("authentication_uid IN ?", authenticationUIDs).Find(&applicationAuthentications)
+	result := a.db().Preload("Tenant").Where("authentication_uid IN ?", authenticationUIDs).Find(&applicationAuthentications)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -69,7 +80,7 @@ func (a *applicationAuthenticationDaoImpl) ApplicationAuthenticationsByResource(
 
 func (a *applicationAuthenticationDaoImpl) List(limit int, offset int, filters []util.Filter) ([]m.ApplicationAuthentication, int64, error) {
 	appAuths := make([]m.ApplicationAuthentication, 0, limit)
-	query := DB.Debug().Model(&m.ApplicationAuthentication{}).
+	query := a.db().Debug().Model(&m.ApplicationAuthentication{}).
 		Offset(offset).
 		Where("tenant_id = ?", a.TenantID)
 
@@ -90,7 +101,7 @@ func (a *applicationAuthenticationDaoImpl) List(limit int, offset int, filters [
 
 func (a *applicationAuthenticationDaoImpl) GetById(id *int64) (*m.ApplicationAuthentication, error) {
 	appAuth := &m.ApplicationAuthentication{ID: *id}
-	result := DB.First(&appAuth)
+	result := a.db().First(&appAuth)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("application authentication")
 	}
@@ -98,18 +109,18 @@ func (a *applicationAuthenticationDaoImpl) GetById(id *int64) (*m.ApplicationAut
 }
 
 func (a *applicationAuthenticationDaoImpl) Create(appAuth *m.ApplicationAuthentication) error {
-	result := DB.Create(appAuth)
+	result := a.db().Create(appAuth)
 	return result.Error
 }
 
 func (a *applicationAuthenticationDaoImpl) Update(appAuth *m.ApplicationAuthentication) error {
-	result := DB.Updates(appAuth)
+	result := a.db().Updates(appAuth)
 	return result.Error
 }
 
 func (a *applicationAuthenticationDaoImpl) Delete(id *int64) error {
 	appAuth := &m.ApplicationAuthentication{ID: *id}
-	if result := DB.Delete(appAuth); result.RowsAffected == 0 {
+	if result := a.db().Delete(appAuth); result.RowsAffected == 0 {
 		return fmt.Errorf("failed to delete application id %v", *id)
 	}
 
@@ -119,3 +130,106 @@ func (a *applicationAuthenticationDaoImpl) Delete(id *int64) error {
 func (a *applicationAuthenticationDaoImpl) Tenant() *int64 {
 	return a.TenantID
 }
+
+// findWithTenant fetches the application authentication, along with its tenant, making sure it belongs to the
+// resource's tenant.
+func (a *applicationAuthenticationDaoImpl) findWithTenant(resource util.Resource) (*m.ApplicationAuthentication, error) {
+	var appAuth m.ApplicationAuthentication
+
+	err := a.db().
+		Preload("Tenant").
+		Where("id = ?", resource.ResourceID).
+		Where("tenant_id = ?", resource.TenantID).
+		First(&appAuth).
+		Error
+
+	if err != nil {
+		return nil, util.NewErrNotFound("application authentication")
+	}
+
+	return &appAuth, nil
+}
+
+func (a *applicationAuthenticationDaoImpl) BulkMessage(resource util.Resource) (map[string]interface{}, error) {
+	appAuth, err := a.findWithTenant(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	application := &m.Application{ID: appAuth.ApplicationID}
+	result := a.db().Preload("Source").Find(&application)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	authentication := &m.Authentication{ResourceID: application.ID,
+		ResourceType:               "Application",
+		ApplicationAuthentications: []m.ApplicationAuthentication{}}
+
+	return BulkMessageFromSource(&application.Source, authentication)
+}
+
+// FetchAndUpdateBy updates the application authentication's columns. The application authentications don't have an
+// availability status of their own, so the status messages about them get rejected before reaching this point.
+func (a *applicationAuthenticationDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error {
+	result := a.db().
+		Model(&m.ApplicationAuthentication{}).
+		Where("id = ?", resource.ResourceID).
+		Where("tenant_id = ?", resource.TenantID).
+		Updates(updateAttributes)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("application authentication not found %v", resource)
+	}
+
+	return nil
+}
+
+func (a *applicationAuthenticationDaoImpl) ToEventJSON(resource util.Resource) ([]byte, error) {
+	appAuth, err := a.findWithTenant(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(appAuth.ToEvent())
+}
+
+func (a *applicationAuthenticationDaoImpl) Pause(id int64) error {
+	result := a.db().Debug().
+		Model(&m.ApplicationAuthentication{}).
+		Where("id = ?", id).
+		Where("tenant_id = ?", a.TenantID).
+		Update("paused_at", time.Now())
+
+	if result.Error != nil {
+		return util.NewErrBadRequest(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return util.NewErrNotFound("application authentication")
+	}
+
+	return nil
+}
+
+func (a *applicationAuthenticationDaoImpl) Resume(id int64) error {
+	result := a.db().Debug().
+		Model(&m.ApplicationAuthentication{}).
+		Where("id = ?", id).
+		Where("tenant_id = ?", a.TenantID).
+		Update("paused_at", nil)
+
+	if result.Error != nil {
+		return util.NewErrBadRequest(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return util.NewErrNotFound("application authentication")
+	}
+
+	return nil
+}
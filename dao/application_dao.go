@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -27,16 +28,24 @@ func init() {
 
 type applicationDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (a *applicationDaoImpl) WithContext(ctx context.Context) ApplicationDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 func (a *applicationDaoImpl) SubCollectionList(primaryCollection interface{}, limit int, offset int, filters []util.Filter) ([]m.Application, int64, error) {
 	applications := make([]m.Application, 0, limit)
-	sourceType, err := m.NewRelationObject(primaryCollection, *a.TenantID, DB.Debug())
+	sourceType, err := m.NewRelationObject(primaryCollection, *a.TenantID, a.db().Debug())
 	if err != nil {
 		return nil, 0, util.NewErrNotFound("source")
 	}
 
-	query := sourceType.HasMany(&m.Application{}, DB.Debug())
+	query := sourceType.HasMany(&m.Application{}, a.db().Debug())
 
 	query, err = applyFilters(query, filters)
 	if err != nil {
@@ -55,7 +64,7 @@ func (a *applicationDaoImpl) SubCollectionList(primaryCollection interface{}, li
 
 func (a *applicationDaoImpl) List(limit int, offset int, filters []util.Filter) ([]m.Application, int64, error) {
 	applications := make([]m.Application, 0, limit)
-	query := DB.Debug().Model(&m.Application{}).
+	query := a.db().Debug().Model(&m.Application{}).
 		Offset(offset).
 		Where("tenant_id = ?", a.TenantID)
 
@@ -76,7 +85,7 @@ func (a *applicationDaoImpl) List(limit int, offset int, filters []util.Filter)
 
 func (a *applicationDaoImpl) GetById(id *int64) (*m.Application, error) {
 	app := &m.Application{ID: *id}
-	result := DB.First(&app)
+	result := a.db().First(&app)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("application")
 	}
@@ -86,24 +95,24 @@ func (a *applicationDaoImpl) GetById(id *int64) (*m.Application, error) {
 
 func (a *applicationDaoImpl) Create(app *m.Application) error {
 	app.TenantID = *a.TenantID
-	result := DB.Create(app)
+	result := a.db().Create(app)
 
 	return result.Error
 }
 
 func (a *applicationDaoImpl) Update(app *m.Application) error {
-	result := DB.Updates(app)
+	result := a.db().Updates(app)
 	return result.Error
 }
 
 func (a *applicationDaoImpl) Delete(id *int64) (*m.Application, error) {
 	app := &m.Application{ID: *id}
-	result := DB.Where("tenant_id = ?", a.TenantID).First(app)
+	result := a.db().Where("tenant_id = ?", a.TenantID).First(app)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("application")
 	}
 
-	if result := DB.Delete(app); result.Error != nil {
+	if result := a.db().Delete(app); result.Error != nil {
 		return nil, fmt.Errorf("failed to delete application id %v", *id)
 	}
 
@@ -116,7 +125,7 @@ func (a *applicationDaoImpl) Tenant() *int64 {
 
 func (a *applicationDaoImpl) BulkMessage(resource util.Resource) (map[string]interface{}, error) {
 	application := &m.Application{ID: resource.ResourceID}
-	result := DB.Preload("Source").Find(&application)
+	result := a.db().Preload("Source").Find(&application)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -130,7 +139,7 @@ func (a *applicationDaoImpl) BulkMessage(resource util.Resource) (map[string]int
 }
 
 func (a *applicationDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error {
-	result := DB.Model(&m.Application{ID: resource.ResourceID}).Updates(updateAttributes)
+	result := a.db().Model(&m.Application{ID: resource.ResourceID}).Updates(updateAttributes)
 	if result.RowsAffected == 0 {
 		return fmt.Errorf("application not found %v", resource)
 	}
@@ -140,7 +149,7 @@ func (a *applicationDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttr
 
 func (a *applicationDaoImpl) FindWithTenant(id *int64) (*m.Application, error) {
 	app := &m.Application{ID: *id}
-	result := DB.Preload("Tenant").Find(&app)
+	result := a.db().Preload("Tenant").Find(&app)
 
 	return app, result.Error
 }
@@ -153,7 +162,7 @@ func (a *applicationDaoImpl) ToEventJSON(resource util.Resource) ([]byte, error)
 }
 
 func (a *applicationDaoImpl) Pause(id int64) error {
-	err := DB.Debug().
+	err := a.db().Debug().
 		Model(&m.Application{}).
 		Where("id = ?", id).
 		Where("tenant_id = ?", a.TenantID).
@@ -164,7 +173,7 @@ func (a *applicationDaoImpl) Pause(id int64) error {
 }
 
 func (a *applicationDaoImpl) Resume(id int64) error {
-	err := DB.Debug().
+	err := a.db().Debug().
 		Model(&m.Application{}).
 		Where("id = ?", id).
 		Where("tenant_id = ?", a.TenantID).
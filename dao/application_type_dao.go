@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"fmt"
 
 	m "github.com/RedHatInsights/sources-api-go/model"
@@ -26,6 +27,14 @@ func init() {
 
 type applicationTypeDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (a *applicationTypeDaoImpl) WithContext(ctx context.Context) ApplicationTypeDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 func (a *applicationTypeDaoImpl) SubCollectionList(primaryCollection interface{}, limit, offset int, filters []util.Filter) ([]m.ApplicationType, int64, error) {
@@ -33,12 +42,12 @@ func (a *applicationTypeDaoImpl) SubCollectionList(primaryCollection interface{}
 	// 0, size of limit (since we will not be returning more than that)
 	applicationTypes := make([]m.ApplicationType, 0, limit)
 
-	applicationType, err := m.NewRelationObject(primaryCollection, *a.TenantID, DB.Debug())
+	applicationType, err := m.NewRelationObject(primaryCollection, *a.TenantID, a.db().Debug())
 	if err != nil {
 		return nil, 0, util.NewErrNotFound("source")
 	}
 
-	query := applicationType.HasMany(&m.ApplicationType{}, DB.Debug())
+	query := applicationType.HasMany(&m.ApplicationType{}, a.db().Debug())
 
 	// getting the total count (filters included) for pagination
 	count := int64(0)
@@ -57,7 +66,7 @@ func (a *applicationTypeDaoImpl) List(limit, offset int, filters []util.Filter)
 	// allocating a slice of application types, initial length of
 	// 0, size of limit (since we will not be returning more than that)
 	appTypes := make([]m.ApplicationType, 0, limit)
-	query := DB.Model(&m.ApplicationType{}).Debug()
+	query := a.db().Model(&m.ApplicationType{}).Debug()
 
 	query, err := applyFilters(query, filters)
 	if err != nil {
@@ -79,7 +88,7 @@ func (a *applicationTypeDaoImpl) List(limit, offset int, filters []util.Filter)
 
 func (a *applicationTypeDaoImpl) GetById(id *int64) (*m.ApplicationType, error) {
 	appType := &m.ApplicationType{Id: *id}
-	result := DB.Debug().First(appType)
+	result := a.db().Debug().First(appType)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("application type")
 	}
@@ -101,14 +110,14 @@ func (a *applicationTypeDaoImpl) Delete(_ *int64) error {
 
 func (at *applicationTypeDaoImpl) ApplicationTypeCompatibleWithSource(typeId, sourceId int64) error {
 	source := m.Source{ID: sourceId}
-	result := DB.Preload("SourceType").Find(&source)
+	result := at.db().Preload("SourceType").Find(&source)
 	if result.Error != nil {
 		return fmt.Errorf("source not found")
 	}
 
 	// searching for the application type that has the source type's name in its
 	// supported source types column.
-	result = DB.First(
+	result = at.db().First(
 		&m.ApplicationType{Id: typeId},
 		datatypes.JSONQuery("supported_source_types").HasKey(source.SourceType.Name),
 	)
@@ -0,0 +1,45 @@
+package dao
+
+import (
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// GetAuditLogDao is a function definition that can be replaced in runtime in case some other DAO provider is needed.
+var GetAuditLogDao func() AuditLogDao
+
+// getDefaultAuditLogDao gets the default DAO implementation. The audit logs span every tenant, so it isn't tied to
+// any.
+func getDefaultAuditLogDao() AuditLogDao {
+	return &auditLogDaoImpl{}
+}
+
+// init sets the default DAO implementation so that other packages can request it easily.
+func init() {
+	GetAuditLogDao = getDefaultAuditLogDao
+}
+
+type auditLogDaoImpl struct{}
+
+func (a *auditLogDaoImpl) Record(entry *m.AuditLog) error {
+	return DB.Create(entry).Error
+}
+
+func (a *auditLogDaoImpl) List(limit, offset int, filters []util.Filter) ([]m.AuditLog, int64, error) {
+	logs := make([]m.AuditLog, 0, limit)
+
+	query, err := applyFilters(DB.Model(&m.AuditLog{}), filters)
+	if err != nil {
+		return nil, 0, util.NewErrBadRequest(err)
+	}
+
+	count := int64(0)
+	query.Count(&count)
+
+	result := query.Order("created_at DESC").Order("id DESC").Limit(limit).Offset(offset).Find(&logs)
+	if result.Error != nil {
+		return nil, 0, util.NewErrBadRequest(result.Error)
+	}
+
+	return logs, count, nil
+}
@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// TestAuditLogRecordAndList tests that the entries get listed newest first, and that they can be filtered.
+func TestAuditLogRecordAndList(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("audit_log")
+
+	auditLogDao := GetAuditLogDao()
+
+	entries := []m.AuditLog{
+		{TenantID: &fixtures.TestTenantData[0].Id, ActorType: m.AuditActorPsk, Actor: "cost", Method: "POST", Route: "/sources", ResourceType: "source", ResourceID: "1", Fields: "name", Status: 201, Outcome: m.AuditOutcomeSuccess},
+		{ActorType: m.AuditActorUser, Actor: "jdoe", Method: "DELETE", Route: "/sources/:id", ResourceType: "source", ResourceID: "1", Status: 401, Outcome: m.AuditOutcomeDenied},
+	}
+
+	for i := range entries {
+		err := auditLogDao.Record(&entries[i])
+		if err != nil {
+			t.Fatalf(`want nil error, got "%s"`, err)
+		}
+	}
+
+	logs, count, err := auditLogDao.List(10, 0, []util.Filter{})
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if count != 2 || len(logs) != 2 || logs[0].ID != entries[1].ID {
+		t.Errorf(`want the two entries newest first, got "%+v"`, logs)
+	}
+
+	logs, count, err = auditLogDao.List(10, 0, []util.Filter{{Name: "actor", Value: []string{"cost"}}})
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if count != 1 || len(logs) != 1 || logs[0].ID != entries[0].ID || *logs[0].TenantID != fixtures.TestTenantData[0].Id {
+		t.Errorf(`want the entry of the PSK, got "%+v"`, logs)
+	}
+
+	DoneWithFixtures("audit_log")
+}
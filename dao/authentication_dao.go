@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -36,6 +37,14 @@ func init() {
 
 type authenticationDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (a *authenticationDaoImpl) WithContext(ctx context.Context) AuthenticationDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 // marketplaceTokenCacher is a variable that holds the "GetMarketplaceTokenCacher" function, or any function that is
@@ -129,7 +138,7 @@ func (a *authenticationDaoImpl) ListForSource(sourceID int64, _, _ int, _ []util
 
 func (a *authenticationDaoImpl) ListForApplication(applicationID int64, _, _ int, _ []util.Filter) ([]m.Authentication, int64, error) {
 	app := m.Application{ID: applicationID}
-	result := DB.
+	result := a.db().
 		Where("tenant_id = ?", *a.TenantID).
 		Preload("ApplicationAuthentications").
 		First(&app)
@@ -148,7 +157,7 @@ func (a *authenticationDaoImpl) ListForApplication(applicationID int64, _, _ int
 
 func (a *authenticationDaoImpl) ListForApplicationAuthentication(appauthID int64, _, _ int, _ []util.Filter) ([]m.Authentication, int64, error) {
 	appauth := m.ApplicationAuthentication{ID: appauthID}
-	result := DB.
+	result := a.db().
 		Where("tenant_id = ?", *a.TenantID).
 		First(&appauth)
 
@@ -231,7 +240,7 @@ func (a *authenticationDaoImpl) GetById(uid string) (*m.Authentication, error) {
 }
 
 func (a *authenticationDaoImpl) Create(auth *m.Authentication) error {
-	query := DB.Select("source_id").Where("tenant_id = ?", *a.TenantID)
+	query := a.db().Select("source_id").Where("tenant_id = ?", *a.TenantID)
 
 	switch auth.ResourceType {
 	case "Application":
@@ -537,7 +546,7 @@ func (a *authenticationDaoImpl) ToEventJSON(resource util.Resource) ([]byte, err
 	}
 
 	auth.TenantID = resource.TenantID
-	auth.Tenant = m.Tenant{ExternalTenant: resource.AccountNumber}
+	auth.Tenant = m.Tenant{ExternalTenant: resource.AccountNumber, OrgID: resource.OrgID}
 	authEvent := auth.ToEvent()
 	data, err := json.Marshal(authEvent)
 	if err != nil {
@@ -0,0 +1,135 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"gorm.io/gorm"
+)
+
+// GetAvailabilityCheckDao is a function definition that can be replaced in runtime in case some other DAO provider is
+// needed.
+var GetAvailabilityCheckDao func(*int64) AvailabilityCheckDao
+
+// getDefaultAvailabilityCheckDao gets the default DAO implementation which will have the given tenant ID.
+func getDefaultAvailabilityCheckDao(tenantId *int64) AvailabilityCheckDao {
+	return &availabilityCheckDaoImpl{
+		TenantID: tenantId,
+	}
+}
+
+// init sets the default DAO implementation so that other packages can request it easily.
+func init() {
+	GetAvailabilityCheckDao = getDefaultAvailabilityCheckDao
+}
+
+type availabilityCheckDaoImpl struct {
+	TenantID *int64
+	requestContext
+}
+
+func (a *availabilityCheckDaoImpl) WithContext(ctx context.Context) AvailabilityCheckDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
+}
+
+func (a *availabilityCheckDaoImpl) Create(check *m.AvailabilityCheck) error {
+	check.TenantID = *a.TenantID
+	for i := range check.Targets {
+		check.Targets[i].TenantID = *a.TenantID
+	}
+
+	return a.db().Debug().Create(check).Error
+}
+
+func (a *availabilityCheckDaoImpl) GetById(sourceId, id int64) (*m.AvailabilityCheck, error) {
+	var check m.AvailabilityCheck
+
+	err := a.db().Debug().
+		Preload("Targets", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("id = ?", id).
+		Where("source_id = ?", sourceId).
+		Where("tenant_id = ?", a.TenantID).
+		First(&check).
+		Error
+
+	if err != nil {
+		return nil, util.NewErrNotFound("availability check")
+	}
+
+	return &check, nil
+}
+
+func (a *availabilityCheckDaoImpl) UpdateTarget(target *m.AvailabilityCheckTarget) error {
+	return a.db().Debug().
+		Model(target).
+		Where("tenant_id = ?", a.TenantID).
+		Select("dispatch_status", "dispatch_error", "response_code", "dispatched_at", "status", "status_error", "responded_at").
+		Updates(target).
+		Error
+}
+
+func (a *availabilityCheckDaoImpl) RecordStatus(checkId int64, resourceType, resourceId, status, statusError string) (*m.AvailabilityCheckTarget, error) {
+	var target m.AvailabilityCheckTarget
+
+	query := a.db().Debug().
+		Where("tenant_id = ?", a.TenantID).
+		Where("resource_type = ?", resourceType).
+		Where("resource_id = ?", resourceId).
+		Where("dispatch_status = ?", m.DispatchSent).
+		Where("responded_at IS NULL")
+
+	// Without the check's id, the status is assumed to answer the latest check that is still awaiting it.
+	if checkId != 0 {
+		query = query.Where("availability_check_id = ?", checkId)
+	}
+
+	err := query.Order("id DESC").First(&target).Error
+	if err != nil {
+		return nil, util.NewErrNotFound("availability check target")
+	}
+
+	now := time.Now()
+	target.Status = status
+	target.StatusError = statusError
+	target.RespondedAt = &now
+
+	err = a.UpdateTarget(&target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &target, a.CompleteIfAnswered(target.AvailabilityCheckID)
+}
+
+func (a *availabilityCheckDaoImpl) CompleteIfAnswered(id int64) error {
+	var targets []m.AvailabilityCheckTarget
+
+	err := a.db().Debug().
+		Where("availability_check_id = ?", id).
+		Where("tenant_id = ?", a.TenantID).
+		Find(&targets).
+		Error
+
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if !target.Answered() {
+			return nil
+		}
+	}
+
+	return a.db().Debug().
+		Model(&m.AvailabilityCheck{}).
+		Where("id = ?", id).
+		Where("tenant_id = ?", a.TenantID).
+		Where("completed_at IS NULL").
+		Update("completed_at", time.Now()).
+		Error
+}
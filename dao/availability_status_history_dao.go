@@ -0,0 +1,126 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"gorm.io/gorm"
+)
+
+// historyLockKeyFormat is the key of the advisory lock that serializes the records of a resource's history.
+const historyLockKeyFormat = "availability_status_history:%d:%s:%s"
+
+// GetAvailabilityStatusHistoryDao is a function definition that can be replaced in runtime in case some other DAO
+// provider is needed.
+var GetAvailabilityStatusHistoryDao func(*int64) AvailabilityStatusHistoryDao
+
+// getDefaultAvailabilityStatusHistoryDao gets the default DAO implementation which will have the given tenant ID.
+func getDefaultAvailabilityStatusHistoryDao(tenantId *int64) AvailabilityStatusHistoryDao {
+	return &availabilityStatusHistoryDaoImpl{
+		TenantID: tenantId,
+	}
+}
+
+// init sets the default DAO implementation so that other packages can request it easily.
+func init() {
+	GetAvailabilityStatusHistoryDao = getDefaultAvailabilityStatusHistoryDao
+}
+
+type availabilityStatusHistoryDaoImpl struct {
+	TenantID *int64
+	requestContext
+}
+
+func (a *availabilityStatusHistoryDaoImpl) WithContext(ctx context.Context) AvailabilityStatusHistoryDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
+}
+
+func (a *availabilityStatusHistoryDaoImpl) Record(entry *m.AvailabilityStatusHistory) (bool, error) {
+	recorded := false
+
+	err := a.db().Debug().Transaction(func(tx *gorm.DB) error {
+		// the status messages of the same resource may be processed concurrently, so the resource's history is locked
+		// until the transaction ends. Locking the latest row wouldn't be enough, since there is none for the first
+		// entry, and the waiting transaction would still read the row it was waiting for instead of the new one.
+		err := tx.
+			Exec("SELECT pg_advisory_xact_lock(hashtext(?))", fmt.Sprintf(historyLockKeyFormat, *a.TenantID, entry.ResourceType, entry.ResourceID)).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var latest m.AvailabilityStatusHistory
+
+		result := tx.
+			Where("tenant_id = ?", a.TenantID).
+			Where("resource_type = ?", entry.ResourceType).
+			Where("resource_id = ?", entry.ResourceID).
+			Order("id DESC").
+			Limit(1).
+			Find(&latest)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != 0 {
+			if latest.Status == entry.Status && latest.StatusError == entry.StatusError {
+				return nil
+			}
+
+			entry.PreviousStatus = latest.Status
+		}
+
+		entry.TenantID = *a.TenantID
+
+		err = tx.Create(entry).Error
+		if err != nil {
+			return err
+		}
+
+		recorded = true
+
+		return nil
+	})
+
+	return recorded, err
+}
+
+func (a *availabilityStatusHistoryDaoImpl) ListForResource(resourceType, resourceId string, limit, offset int, filters []util.Filter) ([]m.AvailabilityStatusHistory, int64, error) {
+	history := make([]m.AvailabilityStatusHistory, 0, limit)
+
+	query := a.db().Debug().
+		Model(&m.AvailabilityStatusHistory{}).
+		Where("tenant_id = ?", a.TenantID).
+		Where("resource_type = ?", resourceType).
+		Where("resource_id = ?", resourceId)
+
+	query, err := applyFilters(query, filters)
+	if err != nil {
+		return nil, 0, util.NewErrBadRequest(err)
+	}
+
+	count := int64(0)
+	query.Count(&count)
+
+	result := query.Order("created_at DESC").Order("id DESC").Limit(limit).Offset(offset).Find(&history)
+	if result.Error != nil {
+		return nil, 0, util.NewErrBadRequest(result.Error)
+	}
+
+	return history, count, nil
+}
+
+func (a *availabilityStatusHistoryDaoImpl) Prune(before time.Time) (int64, error) {
+	result := a.db().Debug().
+		Where("created_at < ?", before).
+		Delete(&m.AvailabilityStatusHistory{})
+
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,132 @@
+package dao
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// TestAvailabilityStatusHistoryRecord tests that only the transitions get appended, along with their previous status.
+func TestAvailabilityStatusHistoryRecord(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("availability_history")
+
+	historyDao := GetAvailabilityStatusHistoryDao(&fixtures.TestTenantData[0].Id)
+
+	// the fixtures leave the source 1 as unavailable with the same error.
+	recorded, err := historyDao.Record(&m.AvailabilityStatusHistory{ResourceType: "Source", ResourceID: "1", Status: m.Unavailable, StatusError: "the ARN is not valid"})
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if recorded {
+		t.Error("want the repeated status skipped, but it was recorded")
+	}
+
+	entry := &m.AvailabilityStatusHistory{ResourceType: "Source", ResourceID: "1", Status: m.Available, Origin: m.AvailabilityStatusOriginApi}
+	recorded, err = historyDao.Record(entry)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if !recorded || entry.PreviousStatus != m.Unavailable {
+		t.Errorf(`want the transition from "%s" recorded, got recorded "%t" from "%s"`, m.Unavailable, recorded, entry.PreviousStatus)
+	}
+
+	history, count, err := historyDao.ListForResource("Source", "1", 100, 0, []util.Filter{})
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if count != 3 || history[0].ID != entry.ID {
+		t.Errorf(`want 3 transitions with the new one first, got "%d" transitions`, count)
+	}
+
+	DoneWithFixtures("availability_history")
+}
+
+// TestAvailabilityStatusHistoryRecordConcurrently tests that the same status, recorded concurrently for a resource
+// without history, only gets appended once.
+func TestAvailabilityStatusHistoryRecordConcurrently(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("availability_history")
+
+	historyDao := GetAvailabilityStatusHistoryDao(&fixtures.TestTenantData[0].Id)
+
+	var wg sync.WaitGroup
+	var recordedCount int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			recorded, err := historyDao.Record(&m.AvailabilityStatusHistory{ResourceType: "Endpoint", ResourceID: "12345", Status: m.Available})
+			if err != nil {
+				t.Errorf(`want nil error, got "%s"`, err)
+			}
+
+			if recorded {
+				atomic.AddInt32(&recordedCount, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if recordedCount != 1 {
+		t.Errorf(`want the status recorded once, got "%d" times`, recordedCount)
+	}
+
+	_, count, err := historyDao.ListForResource("Endpoint", "12345", 100, 0, []util.Filter{})
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if count != 1 {
+		t.Errorf(`want 1 transition, got "%d" transitions`, count)
+	}
+
+	DoneWithFixtures("availability_history")
+}
+
+// TestAvailabilityStatusHistoryTimeRange tests that the transitions can be filtered by time range.
+func TestAvailabilityStatusHistoryTimeRange(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("availability_history")
+
+	historyDao := GetAvailabilityStatusHistoryDao(&fixtures.TestTenantData[0].Id)
+
+	filters := []util.Filter{{Name: "created_at", Operation: "[lt]", Value: []string{"2022-01-21T00:00:00Z"}}}
+	history, count, err := historyDao.ListForResource("Source", "1", 100, 0, filters)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if count != 1 || history[0].ID != 1 {
+		t.Errorf(`want only the transition "1", got "%d" transitions`, count)
+	}
+
+	DoneWithFixtures("availability_history")
+}
+
+// TestAvailabilityStatusHistoryPrune tests that only the transitions older than the given time get deleted.
+func TestAvailabilityStatusHistoryPrune(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("availability_history")
+
+	pruned, err := GetAvailabilityStatusHistoryDao(nil).Prune(time.Date(2022, 1, 21, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if pruned != 1 {
+		t.Errorf(`want "1" transition pruned, got "%d"`, pruned)
+	}
+
+	DoneWithFixtures("availability_history")
+}
@@ -22,6 +22,10 @@ func GetFromResourceType(resourceType string) (*m.EventModelDao, error) {
 		resource = GetApplicationDao(nil)
 	case "Authentication":
 		resource = GetAuthenticationDao(nil)
+	case "ApplicationAuthentication":
+		resource = GetApplicationAuthenticationDao(nil)
+	case "RhcConnection":
+		resource = GetRhcConnectionDao(nil)
 	default:
 		return nil, fmt.Errorf("invalid resource_type (%s) to get DAO instance", resourceType)
 	}
@@ -0,0 +1,24 @@
+package dao
+
+import "testing"
+
+// TestGetFromResourceType tests that every resource type which can receive status messages has a DAO.
+func TestGetFromResourceType(t *testing.T) {
+	resourceTypes := []string{"Source", "Endpoint", "Application", "Authentication", "ApplicationAuthentication", "RhcConnection"}
+
+	for _, resourceType := range resourceTypes {
+		eventDao, err := GetFromResourceType(resourceType)
+		if err != nil {
+			t.Errorf(`want nil error for "%s", got "%s"`, resourceType, err)
+		}
+
+		if eventDao == nil {
+			t.Errorf(`want a DAO for "%s", got nil`, resourceType)
+		}
+	}
+
+	_, err := GetFromResourceType("Unknown")
+	if err == nil {
+		t.Error("want error for an unknown resource type, got nil")
+	}
+}
@@ -15,6 +15,7 @@ import (
 var (
 	DB *gorm.DB
 
+	vaultConfig *vault.Config
 	vaultClient *vault.Client
 	Vault       VaultClient
 
@@ -51,6 +52,7 @@ func Init() {
 		panic(fmt.Sprintf("Failed to read Vault Environment: %v", err))
 	}
 
+	vaultConfig = cfg
 	vaultClient, err = vault.NewClient(cfg)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to Create Vault Client: %v", err))
@@ -64,6 +66,25 @@ func Init() {
 	}
 }
 
+// Close closes the database's connection pool and releases the Vault client's token and idle connections.
+func Close() error {
+	if vaultClient != nil {
+		vaultClient.ClearToken()
+		vaultConfig.HttpClient.CloseIdleConnections()
+	}
+
+	if DB == nil {
+		return nil
+	}
+
+	rawDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return rawDB.Close()
+}
+
 func dbString() string {
 	return fmt.Sprintf(
 		"user=%s password=%s dbname=%s host=%s port=%d sslmode=disable",
@@ -1,11 +1,14 @@
 package dao
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	m "github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
+	"gorm.io/gorm/clause"
 )
 
 // GetEndpointDao is a function definition that can be replaced in runtime in case some other DAO provider is
@@ -26,16 +29,24 @@ func init() {
 
 type endpointDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (a *endpointDaoImpl) WithContext(ctx context.Context) EndpointDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 func (a *endpointDaoImpl) SubCollectionList(primaryCollection interface{}, limit int, offset int, filters []util.Filter) ([]m.Endpoint, int64, error) {
 	endpoints := make([]m.Endpoint, 0, limit)
-	sourceType, err := m.NewRelationObject(primaryCollection, *a.TenantID, DB.Debug())
+	sourceType, err := m.NewRelationObject(primaryCollection, *a.TenantID, a.db().Debug())
 	if err != nil {
 		return nil, 0, util.NewErrNotFound("source")
 	}
 
-	query := sourceType.HasMany(&m.Endpoint{}, DB.Debug())
+	query := sourceType.HasMany(&m.Endpoint{}, a.db().Debug())
 	query = query.Where("endpoints.tenant_id = ?", a.TenantID)
 
 	query, err = applyFilters(query, filters)
@@ -46,7 +57,7 @@ func (a *endpointDaoImpl) SubCollectionList(primaryCollection interface{}, limit
 	count := int64(0)
 	query.Model(&m.Endpoint{}).Count(&count)
 
-	result := query.Limit(limit).Offset(offset).Find(&endpoints)
+	result := query.Preload("Certificate").Limit(limit).Offset(offset).Find(&endpoints)
 	if result.Error != nil {
 		return nil, 0, util.NewErrBadRequest(result.Error)
 	}
@@ -56,7 +67,7 @@ func (a *endpointDaoImpl) SubCollectionList(primaryCollection interface{}, limit
 
 func (a *endpointDaoImpl) List(limit int, offset int, filters []util.Filter) ([]m.Endpoint, int64, error) {
 	endpoints := make([]m.Endpoint, 0, limit)
-	query := DB.Debug().Model(&m.Endpoint{}).
+	query := a.db().Debug().Model(&m.Endpoint{}).
 		Offset(offset).
 		Where("tenant_id = ?", a.TenantID)
 
@@ -68,7 +79,7 @@ func (a *endpointDaoImpl) List(limit int, offset int, filters []util.Filter) ([]
 	count := int64(0)
 	query.Count(&count)
 
-	result := query.Limit(limit).Find(&endpoints)
+	result := query.Preload("Certificate").Limit(limit).Find(&endpoints)
 	if result.Error != nil {
 		return nil, 0, util.NewErrBadRequest(result.Error)
 	}
@@ -78,7 +89,7 @@ func (a *endpointDaoImpl) List(limit int, offset int, filters []util.Filter) ([]
 
 func (a *endpointDaoImpl) GetById(id *int64) (*m.Endpoint, error) {
 	app := &m.Endpoint{ID: *id}
-	result := DB.First(&app)
+	result := a.db().Preload("Certificate").First(&app)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("endpoint")
 	}
@@ -89,26 +100,32 @@ func (a *endpointDaoImpl) GetById(id *int64) (*m.Endpoint, error) {
 func (a *endpointDaoImpl) Create(app *m.Endpoint) error {
 	app.TenantID = *a.TenantID
 
-	result := DB.Create(app)
+	result := a.db().Create(app)
 	return result.Error
 }
 
 func (a *endpointDaoImpl) Update(app *m.Endpoint) error {
-	result := DB.Updates(app)
+	// the certificate is only written by the probes.
+	result := a.db().Omit("Certificate").Updates(app)
 	return result.Error
 }
 
 func (a *endpointDaoImpl) Delete(id *int64) (*m.Endpoint, error) {
 	endpt := &m.Endpoint{ID: *id}
-	result := DB.Where("tenant_id = ?", a.TenantID).First(&endpt)
+	result := a.db().Where("tenant_id = ?", a.TenantID).First(&endpt)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("endpoint")
 	}
 
-	if result := DB.Delete(endpt); result.Error != nil {
+	if result := a.db().Delete(endpt); result.Error != nil {
 		return nil, fmt.Errorf("failed to delete endpoint id %v", *id)
 	}
 
+	// the certificates' table isn't part of the Rails schema, so there is no foreign key to cascade the deletion.
+	if result := a.db().Where("endpoint_id = ?", endpt.ID).Delete(&m.EndpointCertificate{}); result.Error != nil {
+		return nil, fmt.Errorf("failed to delete the certificate of endpoint id %v", *id)
+	}
+
 	return endpt, nil
 }
 
@@ -120,13 +137,13 @@ func (a *endpointDaoImpl) CanEndpointBeSetAsDefaultForSource(sourceId int64) boo
 	endpoint := &m.Endpoint{}
 
 	// add double quotes to the "default" column to avoid any clashes with postgres' "default" keyword
-	result := DB.Where(`"default" = true AND source_id = ?`, sourceId).First(&endpoint)
+	result := a.db().Where(`"default" = true AND source_id = ?`, sourceId).First(&endpoint)
 	return result.Error != nil
 }
 
 func (a *endpointDaoImpl) IsRoleUniqueForSource(role string, sourceId int64) bool {
 	endpoint := &m.Endpoint{}
-	result := DB.Where("role = ? AND source_id = ?", role, sourceId).First(&endpoint)
+	result := a.db().Where("role = ? AND source_id = ?", role, sourceId).First(&endpoint)
 
 	// If the record doesn't exist "result.Error" will have a "record not found" error
 	return result.Error != nil
@@ -135,14 +152,14 @@ func (a *endpointDaoImpl) IsRoleUniqueForSource(role string, sourceId int64) boo
 func (a *endpointDaoImpl) SourceHasEndpoints(sourceId int64) bool {
 	endpoint := &m.Endpoint{}
 
-	result := DB.Where("source_id = ?", sourceId).First(&endpoint)
+	result := a.db().Where("source_id = ?", sourceId).First(&endpoint)
 
 	return result.Error == nil
 }
 
 func (a *endpointDaoImpl) BulkMessage(resource util.Resource) (map[string]interface{}, error) {
 	endpoint := &m.Endpoint{ID: resource.ResourceID}
-	result := DB.Preload("Source").Find(&endpoint)
+	result := a.db().Preload("Source").Find(&endpoint)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -153,7 +170,7 @@ func (a *endpointDaoImpl) BulkMessage(resource util.Resource) (map[string]interf
 }
 
 func (a *endpointDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error {
-	result := DB.Model(&m.Endpoint{ID: resource.ResourceID}).Updates(updateAttributes)
+	result := a.db().Model(&m.Endpoint{ID: resource.ResourceID}).Updates(updateAttributes)
 	if result.RowsAffected == 0 {
 		return fmt.Errorf("endpoint not found %v", resource)
 	}
@@ -163,14 +180,62 @@ func (a *endpointDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttribu
 
 func (a *endpointDaoImpl) FindWithTenant(id *int64) (*m.Endpoint, error) {
 	endpoint := &m.Endpoint{ID: *id}
-	result := DB.Preload("Tenant").Find(&endpoint)
+	result := a.db().Preload("Tenant").Find(&endpoint)
 
 	return endpoint, result.Error
 }
 
+func (a *endpointDaoImpl) SaveCertificate(certificate *m.EndpointCertificate) error {
+	certificate.TenantID = *a.TenantID
+
+	return a.db().Debug().
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "endpoint_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"subject", "issuer", "not_after", "updated_at"}),
+		}).
+		Create(certificate).
+		Error
+}
+
 func (a *endpointDaoImpl) ToEventJSON(resource util.Resource) ([]byte, error) {
 	endpoint, err := a.FindWithTenant(&resource.ResourceID)
 	data, _ := json.Marshal(endpoint.ToEvent())
 
 	return data, err
 }
+
+func (a *endpointDaoImpl) Pause(id int64) error {
+	result := a.db().Debug().
+		Model(&m.Endpoint{}).
+		Where("id = ?", id).
+		Where("tenant_id = ?", a.TenantID).
+		Update("paused_at", time.Now())
+
+	if result.Error != nil {
+		return util.NewErrBadRequest(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return util.NewErrNotFound("endpoint")
+	}
+
+	return nil
+}
+
+func (a *endpointDaoImpl) Resume(id int64) error {
+	result := a.db().Debug().
+		Model(&m.Endpoint{}).
+		Where("id = ?", id).
+		Where("tenant_id = ?", a.TenantID).
+		Update("paused_at", nil)
+
+	if result.Error != nil {
+		return util.NewErrBadRequest(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return util.NewErrNotFound("endpoint")
+	}
+
+	return nil
+}
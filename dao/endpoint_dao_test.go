@@ -0,0 +1,47 @@
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// TestEndpointSaveCertificate tests that the probed certificate gets replaced on every probe, and is loaded along with
+// the endpoint.
+func TestEndpointSaveCertificate(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("endpoint_certificate")
+
+	endpointDao := GetEndpointDao(&fixtures.TestTenantData[0].Id)
+	endpointId := fixtures.TestEndpointData[0].ID
+	notAfter := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	err := endpointDao.SaveCertificate(&m.EndpointCertificate{EndpointID: endpointId, Subject: "CN=old", Issuer: "CN=CA", NotAfter: notAfter})
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	err = endpointDao.SaveCertificate(&m.EndpointCertificate{EndpointID: endpointId, Subject: "CN=new", Issuer: "CN=CA", NotAfter: notAfter})
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	endpoint, err := endpointDao.GetById(&endpointId)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if endpoint.Certificate == nil || endpoint.Certificate.Subject != "CN=new" || !endpoint.Certificate.NotAfter.Equal(notAfter) {
+		t.Errorf(`want the latest certificate, got "%+v"`, endpoint.Certificate)
+	}
+
+	response := endpoint.ToResponse()
+	if response.CertificateSubject == nil || *response.CertificateSubject != "CN=new" || response.CertificateNotAfter == nil {
+		t.Errorf(`want the certificate in the response, got "%+v"`, response)
+	}
+
+	DoneWithFixtures("endpoint_certificate")
+}
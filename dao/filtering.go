@@ -58,6 +58,13 @@ func applyFilters(query *gorm.DB, filters []util.Filter) (*gorm.DB, error) {
 			query = query.Where(fmt.Sprintf("%v ILIKE ?", filterName), fmt.Sprintf("%%%s", filter.Value[0]))
 		case "sort_by":
 			query = query.Order(filter.Value[0])
+		case util.SourceScopeOperation:
+			scope := util.SourceScopeFrom(filter)
+			query = query.Where(
+				fmt.Sprintf("%v IN (SELECT id FROM sources WHERE id IN ? OR source_type_id IN (SELECT id FROM source_types WHERE name IN ?))", filterName),
+				scope.SourceIds,
+				scope.SourceTypes,
+			)
 		default:
 			return nil, fmt.Errorf("unsupported operation %v", filter.Operation)
 		}
@@ -1,6 +1,9 @@
 package dao
 
 import (
+	"context"
+	"time"
+
 	m "github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/hashicorp/vault/api"
@@ -9,6 +12,8 @@ import (
 type SourceDao interface {
 	// List lists all the sources from a given tenant, which should be specified in the request.
 	List(limit, offset int, filters []util.Filter) ([]m.Source, int64, error)
+	// ListOwned lists the sources of the tenant which the given system owns.
+	ListOwned(owner *m.SourceOwner, limit, offset int, filters []util.Filter) ([]m.Source, int64, error)
 	// ListInternal lists all the existing sources.
 	ListInternal(limit, offset int, filters []util.Filter) ([]m.Source, int64, error)
 	SubCollectionList(primaryCollection interface{}, limit, offset int, filters []util.Filter) ([]m.Source, int64, error)
@@ -28,6 +33,14 @@ type SourceDao interface {
 	Pause(id int64) error
 	// Resume resumes the given source and all its dependant applications.
 	Resume(id int64) error
+	// ListForAvailabilityChecks lists the unpaused sources of the given source type, from every tenant, along with
+	// the unpaused applications and the endpoints that are needed to request their availability checks.
+	ListForAvailabilityChecks(sourceTypeId int64, limit, offset int) ([]m.Source, error)
+	// ListForReplay lists, ordered by id, the tenant's sources with an id greater than the given one, so that the
+	// replays can page through them without skipping or repeating any of them.
+	ListForReplay(afterId int64, limit int) ([]m.Source, error)
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) SourceDao
 }
 
 type ApplicationDao interface {
@@ -45,6 +58,8 @@ type ApplicationDao interface {
 	Pause(id int64) error
 	// Resume resumes the application.
 	Resume(id int64) error
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) ApplicationDao
 }
 
 type AuthenticationDao interface {
@@ -62,6 +77,8 @@ type AuthenticationDao interface {
 	BulkMessage(resource util.Resource) (map[string]interface{}, error)
 	FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error
 	ToEventJSON(resource util.Resource) ([]byte, error)
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) AuthenticationDao
 }
 
 type ApplicationAuthenticationDao interface {
@@ -72,6 +89,15 @@ type ApplicationAuthenticationDao interface {
 	Delete(id *int64) error
 	Tenant() *int64
 	ApplicationAuthenticationsByResource(resourceType string, applications []m.Application, authentications []m.Authentication) ([]m.ApplicationAuthentication, error)
+	BulkMessage(resource util.Resource) (map[string]interface{}, error)
+	FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error
+	ToEventJSON(resource util.Resource) ([]byte, error)
+	// Pause pauses the application authentication.
+	Pause(id int64) error
+	// Resume resumes the application authentication.
+	Resume(id int64) error
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) ApplicationAuthenticationDao
 }
 
 type ApplicationTypeDao interface {
@@ -82,6 +108,8 @@ type ApplicationTypeDao interface {
 	Update(src *m.ApplicationType) error
 	Delete(id *int64) error
 	ApplicationTypeCompatibleWithSource(typeId, sourceId int64) error
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) ApplicationTypeDao
 }
 
 type EndpointDao interface {
@@ -102,12 +130,22 @@ type EndpointDao interface {
 	BulkMessage(resource util.Resource) (map[string]interface{}, error)
 	FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error
 	ToEventJSON(resource util.Resource) ([]byte, error)
+	// SaveCertificate creates or replaces the certificate the endpoint presented when it was probed.
+	SaveCertificate(certificate *m.EndpointCertificate) error
+	// Pause pauses the endpoint.
+	Pause(id int64) error
+	// Resume resumes the endpoint.
+	Resume(id int64) error
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) EndpointDao
 }
 
 type MetaDataDao interface {
 	List(limit, offset int, filters []util.Filter) ([]m.MetaData, int64, error)
 	SubCollectionList(primaryCollection interface{}, limit, offset int, filters []util.Filter) ([]m.MetaData, int64, error)
 	GetById(id *int64) (*m.MetaData, error)
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) MetaDataDao
 }
 
 type SourceTypeDao interface {
@@ -133,9 +171,68 @@ type RhcConnectionDao interface {
 	Delete(id *int64) (*m.RhcConnection, error)
 	// ListForSource gets all the related connections to the given source id.
 	ListForSource(sourceId *int64, limit, offset int, filters []util.Filter) ([]m.RhcConnection, int64, error)
+	BulkMessage(resource util.Resource) (map[string]interface{}, error)
+	FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error
+	ToEventJSON(resource util.Resource) ([]byte, error)
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) RhcConnectionDao
+}
+
+type AvailabilityCheckDao interface {
+	// Create persists the availability check along with its targets.
+	Create(check *m.AvailabilityCheck) error
+	// GetById fetches the source's availability check along with its targets.
+	GetById(sourceId, id int64) (*m.AvailabilityCheck, error)
+	// UpdateTarget persists the dispatch and status results of the target.
+	UpdateTarget(target *m.AvailabilityCheckTarget) error
+	// RecordStatus stores the received status in the target that is awaiting it, and completes the availability check
+	// if it was the last one pending. A zero check id picks the latest availability check awaiting the resource.
+	RecordStatus(checkId int64, resourceType, resourceId, status, statusError string) (*m.AvailabilityCheckTarget, error)
+	// CompleteIfAnswered marks the availability check as completed if none of its targets are awaiting an answer.
+	CompleteIfAnswered(id int64) error
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) AvailabilityCheckDao
+}
+
+type AvailabilityStatusHistoryDao interface {
+	// Record appends the availability status transition to the resource's history, unless the status and the error
+	// are the same as the latest recorded ones. It returns whether the transition was recorded.
+	Record(entry *m.AvailabilityStatusHistory) (bool, error)
+	// ListForResource lists the availability status transitions of the given resource, newest first.
+	ListForResource(resourceType, resourceId string, limit, offset int, filters []util.Filter) ([]m.AvailabilityStatusHistory, int64, error)
+	// Prune deletes the transitions, from every tenant, that were recorded before the given time.
+	Prune(before time.Time) (int64, error)
+	// WithContext returns a copy of the DAO which runs its queries with the given context.
+	WithContext(ctx context.Context) AvailabilityStatusHistoryDao
+}
+
+type AuditLogDao interface {
+	// Record appends the entry to the audit log.
+	Record(entry *m.AuditLog) error
+	// List lists the audit log entries of every tenant, newest first.
+	List(limit, offset int, filters []util.Filter) ([]m.AuditLog, int64, error)
 }
 
 type TenantDao interface {
-	GetOrCreateTenantID(accountNumber string) (*int64, error)
+	// GetOrCreateTenantID returns the id of the tenant with the given organization id or, when there is none, with the
+	// given account number. The tenant gets created when it doesn't exist.
+	GetOrCreateTenantID(accountNumber, orgId string) (*int64, error)
 	TenantByAccountNumber(accountNumber string) (*m.Tenant, error)
+	// TenantByIdentifiers finds the tenant by its organization id first, and by its account number otherwise.
+	TenantByIdentifiers(accountNumber, orgId string) (*m.Tenant, error)
+	// ListWithoutOrgId lists, ordered by id, the tenants with an account number but no organization id.
+	ListWithoutOrgId(afterId int64, limit int) ([]m.Tenant, error)
+	// SetOrgId sets the organization id of the tenant.
+	SetOrgId(id int64, orgId string) error
+}
+
+type ScheduledResumeDao interface {
+	// Schedule sets when the paused resource gets resumed, replacing the previous schedule if there was one.
+	Schedule(resourceType string, resourceId int64, resumeAt time.Time) error
+	// Cancel removes the resource's schedule, if it has one.
+	Cancel(resourceType string, resourceId int64) error
+	// ListDue lists the schedules of every tenant which are due before the given time, along with their tenants.
+	ListDue(before time.Time, limit int) ([]m.ScheduledResume, error)
+	// Done removes the schedule once its resource is resumed, unless it was rescheduled in the meantime.
+	Done(entry *m.ScheduledResume) error
 }
@@ -72,6 +72,9 @@ func CreateFixtures(schema string) {
 
 	MigrateSchema()
 
+	// the tenants cached while using the previous schema don't exist in this one.
+	cachedTenants = newTenantCache(config.Get().TenantCacheSize, time.Duration(config.Get().TenantCacheTtl)*time.Second)
+
 	DB.Create(&fixtures.TestTenantData)
 
 	DB.Create(&fixtures.TestSourceTypeData)
@@ -112,6 +115,7 @@ func DropSchema(dbSchema string) {
 // MigrateSchema migrates all the models.
 func MigrateSchema() {
 	err := DB.AutoMigrate(
+		&m.Tenant{},
 		&m.SourceType{},
 		&m.ApplicationType{},
 		&m.MetaData{},
@@ -120,6 +124,8 @@ func MigrateSchema() {
 		&m.RhcConnection{},
 		&m.SourceRhcConnection{},
 		&m.Application{},
+
+		&m.AuditLog{},
 	)
 
 	if err != nil {
@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	m "github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
 )
@@ -23,16 +24,24 @@ func init() {
 
 type metaDataDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (a *metaDataDaoImpl) WithContext(ctx context.Context) MetaDataDao {
+	scoped := *a
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 func (a *metaDataDaoImpl) SubCollectionList(primaryCollection interface{}, limit int, offset int, filters []util.Filter) ([]m.MetaData, int64, error) {
 	metadatas := make([]m.MetaData, 0, limit)
-	collection, err := m.NewRelationObject(primaryCollection, -1, DB.Debug())
+	collection, err := m.NewRelationObject(primaryCollection, -1, a.db().Debug())
 	if err != nil {
 		return nil, 0, util.NewErrNotFound("application type")
 	}
 
-	query := collection.HasMany(&m.MetaData{}, DB.Debug())
+	query := collection.HasMany(&m.MetaData{}, a.db().Debug())
 	query = query.Where("meta_data.type = 'AppMetaData'")
 
 	query, err = applyFilters(query, filters)
@@ -52,7 +61,7 @@ func (a *metaDataDaoImpl) SubCollectionList(primaryCollection interface{}, limit
 
 func (a *metaDataDaoImpl) List(limit int, offset int, filters []util.Filter) ([]m.MetaData, int64, error) {
 	metaData := make([]m.MetaData, 0, limit)
-	query := DB.Debug().Model(&m.MetaData{}).Where("type = 'AppMetaData'")
+	query := a.db().Debug().Model(&m.MetaData{}).Where("type = 'AppMetaData'")
 
 	query, err := applyFilters(query, filters)
 	if err != nil {
@@ -71,7 +80,7 @@ func (a *metaDataDaoImpl) List(limit int, offset int, filters []util.Filter) ([]
 
 func (a *metaDataDaoImpl) GetById(id *int64) (*m.MetaData, error) {
 	metaData := &m.MetaData{ID: *id}
-	result := DB.First(&metaData)
+	result := a.db().First(&metaData)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("metadata")
 	}
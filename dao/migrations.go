@@ -0,0 +1,211 @@
+package dao
+
+import (
+	"fmt"
+	"sync"
+
+	logging "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// goOwnedModels are the models whose tables are only used by this service, and therefore are not part of the schema
+// managed by the Rails application.
+var goOwnedModels = []interface{}{
+	&m.AuditLog{},
+	&m.AvailabilityCheck{},
+	&m.AvailabilityCheckTarget{},
+	&m.AvailabilityStatusHistory{},
+	&m.EndpointCertificate{},
+	&m.ScheduledResume{},
+}
+
+// Migrate applies the schema changes of this service. It runs as a one-shot command, the "-migrate" mode, once for
+// every release, so that the replicas don't change the schema every time they start.
+func Migrate() error {
+	err := migrateGoOwnedTables()
+	if err != nil {
+		return fmt.Errorf("unable to migrate the tables of this service: %w", err)
+	}
+
+	err = migrateTenants()
+	if err != nil {
+		return fmt.Errorf("unable to migrate the tenants table: %w", err)
+	}
+
+	err = migrateSources()
+	if err != nil {
+		return fmt.Errorf("unable to migrate the sources table: %w", err)
+	}
+
+	return nil
+}
+
+// migrateGoOwnedTables creates or updates the tables that only this service uses.
+func migrateGoOwnedTables() error {
+	return DB.AutoMigrate(goOwnedModels...)
+}
+
+// tenantUniqueIndexes are the indexes which keep the concurrent requests from creating the same tenant twice, and
+// which the tenant upserts rely on. The account numbers are only unique among the tenants without org id, since the
+// same account number may show up along with different org ids.
+var tenantUniqueIndexes = []string{
+	tenantExternalTenantIndex,
+	tenantOrgIdIndex,
+}
+
+const (
+	tenantExternalTenantIndex = "index_tenants_on_external_tenant_without_org_id"
+	tenantOrgIdIndex          = "index_tenants_on_org_id_unique"
+)
+
+// tenantIndexes are the indexes declared in the tenant model. Both the migration and the upserts take the indexed
+// columns and predicates from them, so that the upserts' conflict targets always match the created indexes.
+var tenantIndexes = parseTenantIndexes()
+
+func parseTenantIndexes() map[string]schema.Index {
+	tenantSchema, err := schema.Parse(&m.Tenant{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("unable to parse the tenant model: %s", err))
+	}
+
+	return tenantSchema.ParseIndexes()
+}
+
+// tenantUniqueIndex returns the column and the predicate of the given unique index of the tenants.
+func tenantUniqueIndex(name string) (string, string) {
+	index := tenantIndexes[name]
+
+	return index.Fields[0].DBName, index.Where
+}
+
+// migrateTenants adds the "org_id" column, which the tenants are looked up by, and the unique indexes of the tenants'
+// identifiers to the tenants table of the Rails application. The tenants which would break an index are merged before
+// creating it, in the same transaction.
+func migrateTenants() error {
+	migrator := DB.Migrator()
+
+	if !migrator.HasColumn(&m.Tenant{}, "OrgID") {
+		err := migrator.AddColumn(&m.Tenant{}, "OrgID")
+		if err != nil {
+			return err
+		}
+	}
+
+	// the plain index on the org ids is superseded by the unique one.
+	if migrator.HasIndex(&m.Tenant{}, "idx_tenants_org_id") {
+		err := migrator.DropIndex(&m.Tenant{}, "idx_tenants_org_id")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, index := range tenantUniqueIndexes {
+		if migrator.HasIndex(&m.Tenant{}, index) {
+			continue
+		}
+
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			err := mergeDuplicatedTenants(tx, index)
+			if err != nil {
+				return err
+			}
+
+			return tx.Migrator().CreateIndex(&m.Tenant{}, index)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create the unique index %q: %w", index, err)
+		}
+	}
+
+	return nil
+}
+
+// mergeDuplicatedTenants merges the tenants which share the identifier of the given unique index into the oldest one
+// of them: the rows which refer to the duplicated tenants are moved over to the oldest tenant, and the duplicated
+// tenants get deleted.
+func mergeDuplicatedTenants(tx *gorm.DB, index string) error {
+	column, predicate := tenantUniqueIndex(index)
+	tenantsTable := tx.NamingStrategy.TableName("Tenant")
+
+	var duplicates []struct {
+		Id     int64
+		KeptId int64
+	}
+
+	err := tx.
+		Raw(fmt.Sprintf(`SELECT "id", "kept_id" FROM (SELECT "id", MIN("id") OVER (PARTITION BY %q) AS "kept_id" FROM ? WHERE %s) AS "t" WHERE "id" <> "kept_id"`, column, predicate), clause.Table{Name: tenantsTable}).
+		Scan(&duplicates).
+		Error
+	if err != nil {
+		return err
+	}
+
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	tables, err := tablesReferringTo(tx, tenantsTable)
+	if err != nil {
+		return err
+	}
+
+	for _, duplicate := range duplicates {
+		logging.Log.Warnf(`Merging the tenant "%d" into the tenant "%d", since they share the same %s`, duplicate.Id, duplicate.KeptId, column)
+
+		for _, table := range tables {
+			err := tx.
+				Exec(`UPDATE ? SET "tenant_id" = ? WHERE "tenant_id" = ?`, gorm.Expr(table), duplicate.KeptId, duplicate.Id).
+				Error
+			if err != nil {
+				return fmt.Errorf("unable to move the rows of the %s table to the tenant %d: %w", table, duplicate.KeptId, err)
+			}
+		}
+
+		err := tx.
+			Delete(&m.Tenant{}, duplicate.Id).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tablesReferringTo returns the quoted names of the tables, in the same schema as the given tenants table, which have
+// a "tenant_id" column.
+func tablesReferringTo(tx *gorm.DB, tenantsTable string) ([]string, error) {
+	var tables []string
+
+	err := tx.
+		Raw(`SELECT "c"."oid"::regclass::text FROM "pg_class" AS "c" JOIN "pg_attribute" AS "a" ON "a"."attrelid" = "c"."oid" WHERE "c"."relkind" = 'r' AND "c"."relnamespace" = (SELECT "relnamespace" FROM "pg_class" WHERE "oid" = ?::regclass) AND "a"."attname" = 'tenant_id' AND NOT "a"."attisdropped"`, tenantsTable).
+		Scan(&tables).
+		Error
+
+	return tables, err
+}
+
+// migrateSources adds the "owner" column, which tracks the system identities that created the sources, to the sources
+// table of the Rails application.
+func migrateSources() error {
+	migrator := DB.Migrator()
+
+	if !migrator.HasColumn(&m.Source{}, "Owner") {
+		err := migrator.AddColumn(&m.Source{}, "Owner")
+		if err != nil {
+			return err
+		}
+	}
+
+	if !migrator.HasIndex(&m.Source{}, "Owner") {
+		err := migrator.CreateIndex(&m.Source{}, "Owner")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
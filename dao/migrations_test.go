@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"gorm.io/gorm/clause"
+)
+
+// TestTenantUpsertMatchesIndexes tests that the upserts' conflict targets are the unique indexes of the tenant model.
+func TestTenantUpsertMatchesIndexes(t *testing.T) {
+	testCases := []struct {
+		orgId     string
+		column    string
+		predicate string
+	}{
+		{orgId: "", column: "external_tenant", predicate: "external_tenant <> '' AND (org_id IS NULL OR org_id = '')"},
+		{orgId: "org", column: "org_id", predicate: "org_id <> ''"},
+	}
+
+	for _, tc := range testCases {
+		upsert := tenantUpsert(tc.orgId)
+
+		if len(upsert.Columns) != 1 || upsert.Columns[0].Name != tc.column {
+			t.Errorf(`want conflict target "%s", got "%v"`, tc.column, upsert.Columns)
+		}
+
+		if len(upsert.TargetWhere.Exprs) != 1 || upsert.TargetWhere.Exprs[0].(clause.Expr).SQL != tc.predicate {
+			t.Errorf(`want predicate "%s", got "%v"`, tc.predicate, upsert.TargetWhere.Exprs)
+		}
+	}
+}
+
+// TestMigrateTenantsMergesDuplicates tests that the tenants which share an org id get merged into the oldest one
+// before the unique index gets created.
+func TestMigrateTenantsMergesDuplicates(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("tenant_merge")
+
+	err := DB.Migrator().DropIndex(&m.Tenant{}, tenantOrgIdIndex)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	kept := fixtures.TestTenantData[0]
+	DB.Model(&m.Tenant{}).Where("id = ?", kept.Id).Update("org_id", "duplicated org")
+
+	duplicate := m.Tenant{ExternalTenant: "another account", OrgID: "duplicated org"}
+	DB.Create(&duplicate)
+
+	source := fixtures.TestSourceData[0]
+	DB.Model(&m.Source{}).Where("id = ?", source.ID).Update("tenant_id", duplicate.Id)
+
+	err = migrateTenants()
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if !DB.Migrator().HasIndex(&m.Tenant{}, tenantOrgIdIndex) {
+		t.Errorf(`want the index "%s" to be created`, tenantOrgIdIndex)
+	}
+
+	var count int64
+	DB.Model(&m.Tenant{}).Where("id = ?", duplicate.Id).Count(&count)
+	if count != 0 {
+		t.Errorf(`want the duplicated tenant "%d" to be deleted`, duplicate.Id)
+	}
+
+	var tenantId int64
+	DB.Model(&m.Source{}).Select("tenant_id").Where("id = ?", source.ID).Scan(&tenantId)
+	if tenantId != kept.Id {
+		t.Errorf(`want the source to belong to tenant "%d", got "%d"`, kept.Id, tenantId)
+	}
+
+	DoneWithFixtures("tenant_merge")
+}
@@ -1,8 +1,10 @@
 package dao
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	m "github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
@@ -73,6 +75,17 @@ func (src *MockSourceDao) List(limit, offset int, filters []util.Filter) ([]m.So
 	return src.Sources, count, nil
 }
 
+func (src *MockSourceDao) ListOwned(owner *m.SourceOwner, limit, offset int, filters []util.Filter) ([]m.Source, int64, error) {
+	sources := make([]m.Source, 0)
+	for i := range src.Sources {
+		if owner.Owns(&src.Sources[i]) {
+			sources = append(sources, src.Sources[i])
+		}
+	}
+
+	return sources, int64(len(sources)), nil
+}
+
 func (src *MockSourceDao) ListInternal(limit, offset int, filters []util.Filter) ([]m.Source, int64, error) {
 	count := int64(len(src.Sources))
 	return src.Sources, count, nil
@@ -132,6 +145,41 @@ func (m *MockSourceDao) ListForRhcConnection(id *int64, limit, offset int, filte
 	return m.RelatedSources, count, nil
 }
 
+func (src *MockSourceDao) ListForAvailabilityChecks(sourceTypeId int64, limit, offset int) ([]m.Source, error) {
+	sources := make([]m.Source, 0)
+	for _, src := range src.Sources {
+		if src.SourceTypeID == sourceTypeId && src.PausedAt.IsZero() {
+			sources = append(sources, src)
+		}
+	}
+
+	if offset >= len(sources) {
+		return []m.Source{}, nil
+	}
+
+	sources = sources[offset:]
+	if len(sources) > limit {
+		sources = sources[:limit]
+	}
+
+	return sources, nil
+}
+
+func (src *MockSourceDao) ListForReplay(afterId int64, limit int) ([]m.Source, error) {
+	sources := make([]m.Source, 0)
+	for _, src := range src.Sources {
+		if src.ID > afterId {
+			sources = append(sources, src)
+		}
+	}
+
+	if len(sources) > limit {
+		sources = sources[:limit]
+	}
+
+	return sources, nil
+}
+
 func (m *MockSourceDao) BulkMessage(_ util.Resource) (map[string]interface{}, error) {
 	return nil, nil
 }
@@ -402,7 +450,7 @@ func (a *MockEndpointDao) Create(src *m.Endpoint) error {
 }
 
 func (a *MockEndpointDao) Update(src *m.Endpoint) error {
-	panic("not implemented") // TODO: Implement
+	return nil
 }
 
 func (a *MockEndpointDao) Delete(id *int64) (*m.Endpoint, error) {
@@ -438,6 +486,30 @@ func (m *MockEndpointDao) ToEventJSON(_ util.Resource) ([]byte, error) {
 	return nil, nil
 }
 
+func (a *MockEndpointDao) SaveCertificate(certificate *m.EndpointCertificate) error {
+	return nil
+}
+
+func (a *MockEndpointDao) Pause(id int64) error {
+	for _, resource := range a.Endpoints {
+		if resource.ID == id {
+			return nil
+		}
+	}
+
+	return util.NewErrNotFound("endpoint")
+}
+
+func (a *MockEndpointDao) Resume(id int64) error {
+	for _, resource := range a.Endpoints {
+		if resource.ID == id {
+			return nil
+		}
+	}
+
+	return util.NewErrNotFound("endpoint")
+}
+
 func (m *MockRhcConnectionDao) List(limit, offset int, filters []util.Filter) ([]m.RhcConnection, int64, error) {
 	count := int64(len(m.RhcConnections))
 	return m.RhcConnections, count, nil
@@ -499,6 +571,18 @@ func (m *MockRhcConnectionDao) ListForSource(sourceId *int64, limit, offset int,
 	return m.RelatedRhcConnections, count, nil
 }
 
+func (m *MockRhcConnectionDao) BulkMessage(_ util.Resource) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRhcConnectionDao) FetchAndUpdateBy(_ util.Resource, _ map[string]interface{}) error {
+	return nil
+}
+
+func (m *MockRhcConnectionDao) ToEventJSON(_ util.Resource) ([]byte, error) {
+	return nil, nil
+}
+
 func (m MockApplicationAuthenticationDao) List(limit, offset int, filters []util.Filter) ([]m.ApplicationAuthentication, int64, error) {
 	count := int64(len(m.ApplicationAuthentications))
 	return m.ApplicationAuthentications, count, nil
@@ -534,3 +618,293 @@ func (m MockApplicationAuthenticationDao) Tenant() *int64 {
 func (m MockApplicationAuthenticationDao) ApplicationAuthenticationsByResource(_ string, _ []m.Application, _ []m.Authentication) ([]m.ApplicationAuthentication, error) {
 	return m.ApplicationAuthentications, nil
 }
+
+func (m MockApplicationAuthenticationDao) BulkMessage(_ util.Resource) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m MockApplicationAuthenticationDao) FetchAndUpdateBy(_ util.Resource, _ map[string]interface{}) error {
+	return nil
+}
+
+func (m MockApplicationAuthenticationDao) ToEventJSON(_ util.Resource) ([]byte, error) {
+	return nil, nil
+}
+
+func (m MockApplicationAuthenticationDao) Pause(id int64) error {
+	for _, resource := range m.ApplicationAuthentications {
+		if resource.ID == id {
+			return nil
+		}
+	}
+
+	return util.NewErrNotFound("application authentication")
+}
+
+func (m MockApplicationAuthenticationDao) Resume(id int64) error {
+	for _, resource := range m.ApplicationAuthentications {
+		if resource.ID == id {
+			return nil
+		}
+	}
+
+	return util.NewErrNotFound("application authentication")
+}
+
+type MockAvailabilityCheckDao struct {
+	AvailabilityChecks []m.AvailabilityCheck
+}
+
+func (m *MockAvailabilityCheckDao) Create(check *m.AvailabilityCheck) error {
+	check.ID = int64(len(m.AvailabilityChecks) + 1)
+	m.AvailabilityChecks = append(m.AvailabilityChecks, *check)
+
+	return nil
+}
+
+func (m *MockAvailabilityCheckDao) GetById(sourceId, id int64) (*m.AvailabilityCheck, error) {
+	for _, check := range m.AvailabilityChecks {
+		if check.ID == id && check.SourceID == sourceId {
+			return &check, nil
+		}
+	}
+
+	return nil, util.NewErrNotFound("availability check")
+}
+
+func (m *MockAvailabilityCheckDao) UpdateTarget(_ *m.AvailabilityCheckTarget) error {
+	return nil
+}
+
+func (m *MockAvailabilityCheckDao) RecordStatus(_ int64, _, _, _, _ string) (*m.AvailabilityCheckTarget, error) {
+	return nil, util.NewErrNotFound("availability check target")
+}
+
+func (m *MockAvailabilityCheckDao) CompleteIfAnswered(_ int64) error {
+	return nil
+}
+
+type MockAvailabilityStatusHistoryDao struct {
+	History []m.AvailabilityStatusHistory
+}
+
+func (a *MockAvailabilityStatusHistoryDao) Record(entry *m.AvailabilityStatusHistory) (bool, error) {
+	for i := len(a.History) - 1; i >= 0; i-- {
+		latest := a.History[i]
+		if latest.ResourceType != entry.ResourceType || latest.ResourceID != entry.ResourceID {
+			continue
+		}
+
+		if latest.Status == entry.Status && latest.StatusError == entry.StatusError {
+			return false, nil
+		}
+
+		entry.PreviousStatus = latest.Status
+		break
+	}
+
+	entry.ID = int64(len(a.History) + 1)
+	a.History = append(a.History, *entry)
+
+	return true, nil
+}
+
+func (a *MockAvailabilityStatusHistoryDao) ListForResource(resourceType, resourceId string, _, _ int, _ []util.Filter) ([]m.AvailabilityStatusHistory, int64, error) {
+	out := make([]m.AvailabilityStatusHistory, 0)
+
+	// newest first, like the real implementation.
+	for i := len(a.History) - 1; i >= 0; i-- {
+		if a.History[i].ResourceType == resourceType && a.History[i].ResourceID == resourceId {
+			out = append(out, a.History[i])
+		}
+	}
+
+	return out, int64(len(out)), nil
+}
+
+func (a *MockAvailabilityStatusHistoryDao) Prune(before time.Time) (int64, error) {
+	kept := make([]m.AvailabilityStatusHistory, 0, len(a.History))
+	for _, entry := range a.History {
+		if !entry.CreatedAt.Before(before) {
+			kept = append(kept, entry)
+		}
+	}
+
+	pruned := int64(len(a.History) - len(kept))
+	a.History = kept
+
+	return pruned, nil
+}
+
+type MockAuditLogDao struct {
+	Logs []m.AuditLog
+}
+
+func (a *MockAuditLogDao) Record(entry *m.AuditLog) error {
+	entry.ID = int64(len(a.Logs) + 1)
+	a.Logs = append(a.Logs, *entry)
+
+	return nil
+}
+
+func (a *MockAuditLogDao) List(_, _ int, _ []util.Filter) ([]m.AuditLog, int64, error) {
+	out := make([]m.AuditLog, 0, len(a.Logs))
+
+	// newest first, like the real implementation.
+	for i := len(a.Logs) - 1; i >= 0; i-- {
+		out = append(out, a.Logs[i])
+	}
+
+	return out, int64(len(out)), nil
+}
+
+type MockScheduledResumeDao struct {
+	Scheduled []m.ScheduledResume
+}
+
+func (a *MockScheduledResumeDao) Schedule(resourceType string, resourceId int64, resumeAt time.Time) error {
+	for i := range a.Scheduled {
+		if a.Scheduled[i].ResourceType == resourceType && a.Scheduled[i].ResourceID == resourceId {
+			a.Scheduled[i].ResumeAt = resumeAt
+			return nil
+		}
+	}
+
+	a.Scheduled = append(a.Scheduled, m.ScheduledResume{ID: int64(len(a.Scheduled) + 1), ResourceType: resourceType, ResourceID: resourceId, ResumeAt: resumeAt})
+
+	return nil
+}
+
+func (a *MockScheduledResumeDao) Cancel(resourceType string, resourceId int64) error {
+	kept := make([]m.ScheduledResume, 0, len(a.Scheduled))
+	for _, entry := range a.Scheduled {
+		if entry.ResourceType != resourceType || entry.ResourceID != resourceId {
+			kept = append(kept, entry)
+		}
+	}
+
+	a.Scheduled = kept
+
+	return nil
+}
+
+func (a *MockScheduledResumeDao) ListDue(before time.Time, limit int) ([]m.ScheduledResume, error) {
+	out := make([]m.ScheduledResume, 0)
+	for _, entry := range a.Scheduled {
+		if !entry.ResumeAt.After(before) && len(out) < limit {
+			out = append(out, entry)
+		}
+	}
+
+	return out, nil
+}
+
+func (a *MockScheduledResumeDao) Done(entry *m.ScheduledResume) error {
+	kept := make([]m.ScheduledResume, 0, len(a.Scheduled))
+	for _, scheduled := range a.Scheduled {
+		if scheduled.ID != entry.ID || !scheduled.ResumeAt.Equal(entry.ResumeAt) {
+			kept = append(kept, scheduled)
+		}
+	}
+
+	a.Scheduled = kept
+
+	return nil
+}
+
+func (src *MockSourceDao) WithContext(_ context.Context) SourceDao {
+	return src
+}
+
+func (a *MockApplicationDao) WithContext(_ context.Context) ApplicationDao {
+	return a
+}
+
+func (m MockApplicationAuthenticationDao) WithContext(_ context.Context) ApplicationAuthenticationDao {
+	return m
+}
+
+func (a *MockApplicationTypeDao) WithContext(_ context.Context) ApplicationTypeDao {
+	return a
+}
+
+func (m *MockAvailabilityCheckDao) WithContext(_ context.Context) AvailabilityCheckDao {
+	return m
+}
+
+func (a *MockAvailabilityStatusHistoryDao) WithContext(_ context.Context) AvailabilityStatusHistoryDao {
+	return a
+}
+
+func (a *MockEndpointDao) WithContext(_ context.Context) EndpointDao {
+	return a
+}
+
+func (a *MockMetaDataDao) WithContext(_ context.Context) MetaDataDao {
+	return a
+}
+
+func (m *MockRhcConnectionDao) WithContext(_ context.Context) RhcConnectionDao {
+	return m
+}
+
+type MockTenantDao struct {
+	Tenants []m.Tenant
+}
+
+func (t *MockTenantDao) GetOrCreateTenantID(accountNumber, orgId string) (*int64, error) {
+	tenant, err := t.TenantByIdentifiers(accountNumber, orgId)
+	if err == nil {
+		return &tenant.Id, nil
+	}
+
+	t.Tenants = append(t.Tenants, m.Tenant{Id: int64(len(t.Tenants) + 1), ExternalTenant: accountNumber, OrgID: orgId})
+
+	return &t.Tenants[len(t.Tenants)-1].Id, nil
+}
+
+func (t *MockTenantDao) TenantByAccountNumber(accountNumber string) (*m.Tenant, error) {
+	for i := range t.Tenants {
+		if t.Tenants[i].ExternalTenant == accountNumber {
+			return &t.Tenants[i], nil
+		}
+	}
+
+	return nil, util.NewErrNotFound("tenant")
+}
+
+func (t *MockTenantDao) TenantByIdentifiers(accountNumber, orgId string) (*m.Tenant, error) {
+	for i := range t.Tenants {
+		if orgId != "" && t.Tenants[i].OrgID == orgId {
+			return &t.Tenants[i], nil
+		}
+	}
+
+	if accountNumber == "" {
+		return nil, util.NewErrNotFound("tenant")
+	}
+
+	return t.TenantByAccountNumber(accountNumber)
+}
+
+func (t *MockTenantDao) ListWithoutOrgId(afterId int64, limit int) ([]m.Tenant, error) {
+	tenants := make([]m.Tenant, 0)
+	for _, tenant := range t.Tenants {
+		if tenant.Id > afterId && tenant.OrgID == "" && tenant.ExternalTenant != "" && len(tenants) < limit {
+			tenants = append(tenants, tenant)
+		}
+	}
+
+	return tenants, nil
+}
+
+func (t *MockTenantDao) SetOrgId(id int64, orgId string) error {
+	for i := range t.Tenants {
+		if t.Tenants[i].Id == id {
+			t.Tenants[i].OrgID = orgId
+			return nil
+		}
+	}
+
+	return util.NewErrNotFound("tenant")
+}
@@ -0,0 +1,22 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// requestContext is embedded in the DAOs which run queries on behalf of the requests, so that the queries run with
+// the request's context and their SQL logs carry the request's logger.
+type requestContext struct {
+	ctx context.Context
+}
+
+// db returns the database handle to run the DAO's queries with.
+func (rc requestContext) db() *gorm.DB {
+	if rc.ctx == nil {
+		return DB
+	}
+
+	return DB.WithContext(rc.ctx)
+}
@@ -1,6 +1,8 @@
 package dao
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -29,10 +31,18 @@ func init() {
 
 type rhcConnectionDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (s *rhcConnectionDaoImpl) WithContext(ctx context.Context) RhcConnectionDao {
+	scoped := *s
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 func (s *rhcConnectionDaoImpl) List(limit, offset int, filters []util.Filter) ([]m.RhcConnection, int64, error) {
-	query := DB.
+	query := s.db().
 		Debug().
 		Model(&m.RhcConnection{}).
 		Select(`"rhc_connections".*, STRING_AGG(CAST ("jt"."source_id" AS TEXT), ',') AS "source_ids"`).
@@ -62,7 +72,7 @@ func (s *rhcConnectionDaoImpl) List(limit, offset int, filters []util.Filter) ([
 
 	// Loop through the rows to map both the connection and its related sources.
 	var rows []map[string]interface{}
-	err = DB.ScanRows(result, &rows)
+	err = s.db().ScanRows(result, &rows)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -90,7 +100,7 @@ func (s *rhcConnectionDaoImpl) List(limit, offset int, filters []util.Filter) ([
 }
 
 func (s *rhcConnectionDaoImpl) GetById(id *int64) (*m.RhcConnection, error) {
-	query := DB.
+	query := s.db().
 		Debug().
 		Model(&m.RhcConnection{}).
 		Select(`"rhc_connections".*, STRING_AGG(CAST ("jt"."source_id" AS TEXT), ',') AS "source_ids"`).
@@ -114,7 +124,7 @@ func (s *rhcConnectionDaoImpl) GetById(id *int64) (*m.RhcConnection, error) {
 
 	// Loop through the rows to map both the connection and its related sources.
 	var rows []map[string]interface{}
-	err = DB.ScanRows(result, &rows)
+	err = s.db().ScanRows(result, &rows)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +150,7 @@ func (s *rhcConnectionDaoImpl) Create(rhcConnection *m.RhcConnection) (*m.RhcCon
 	// If the source doesn't exist we cannot create the RhcConnection, since it needs to be linked to at least one
 	// source.
 	var sourceExists bool
-	err := DB.Debug().
+	err := s.db().Debug().
 		Model(&m.Source{}).
 		Select(`1`).
 		Where(`id = ?`, rhcConnection.Sources[0].ID).
@@ -157,7 +167,7 @@ func (s *rhcConnectionDaoImpl) Create(rhcConnection *m.RhcConnection) (*m.RhcCon
 		return nil, util.NewErrNotFound("source")
 	}
 
-	err = DB.Transaction(func(tx *gorm.DB) error {
+	err = s.db().Transaction(func(tx *gorm.DB) error {
 		var err error
 
 		err = tx.Debug().
@@ -192,37 +202,32 @@ func (s *rhcConnectionDaoImpl) Create(rhcConnection *m.RhcConnection) (*m.RhcCon
 }
 
 func (s *rhcConnectionDaoImpl) Update(rhcConnection *m.RhcConnection) error {
-	err := DB.Debug().
+	err := s.db().Debug().
 		Updates(rhcConnection).
 		Error
 	return err
 }
 
 func (s *rhcConnectionDaoImpl) Delete(id *int64) (*m.RhcConnection, error) {
-	var rhcConnection m.RhcConnection
-
-	err := DB.Debug().
-		Where("id = ?", id).
-		First(&rhcConnection).
-		Error
-
+	// Fetch the connection along with its related sources, so that they can be sent in the "destroy" event.
+	rhcConnection, err := s.GetById(id)
 	if err != nil {
-		return nil, util.NewErrNotFound("rhcConnection")
+		return nil, err
 	}
 
 	// The foreign key in the join table takes care of deleting the associated row.
-	err = DB.Debug().
+	err = s.db().Debug().
 		Where(`id = ?`, *id).
 		Delete(&m.RhcConnection{}).
 		Error
 
-	return &rhcConnection, err
+	return rhcConnection, err
 }
 
 func (s *rhcConnectionDaoImpl) ListForSource(sourceId *int64, limit, offset int, filters []util.Filter) ([]m.RhcConnection, int64, error) {
 	rhcConnections := make([]m.RhcConnection, 0)
 
-	query := DB.Debug().
+	query := s.db().Debug().
 		Model(&m.RhcConnection{}).
 		Joins(`INNER JOIN "source_rhc_connections" "sr" ON "rhc_connections"."id" = "sr"."rhc_connection_id"`).
 		Where(`"sr"."source_id" = ?`, sourceId).
@@ -245,3 +250,54 @@ func (s *rhcConnectionDaoImpl) ListForSource(sourceId *int64, limit, offset int,
 	return rhcConnections, count, err
 
 }
+
+// BulkMessage builds the bulk message from the first source the connection is related to, and adds the connection
+// itself to it.
+func (s *rhcConnectionDaoImpl) BulkMessage(resource util.Resource) (map[string]interface{}, error) {
+	s.TenantID = &resource.TenantID
+	rhcConnection, err := s.GetById(&resource.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rhcConnection.Sources) == 0 {
+		return nil, fmt.Errorf("rhcConnection %d is not related to any source", rhcConnection.ID)
+	}
+
+	source := m.Source{ID: rhcConnection.Sources[0].ID}
+	authentication := &m.Authentication{ResourceID: source.ID, ResourceType: "Source"}
+
+	bulkMessage, err := BulkMessageFromSource(&source, authentication)
+	if err != nil {
+		return nil, err
+	}
+
+	bulkMessage["rhc_connections"] = []interface{}{rhcConnection.ToEvent()}
+
+	return bulkMessage, nil
+}
+
+func (s *rhcConnectionDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error {
+	// The connections are shared between tenants, so make sure the tenant is related to the connection before
+	// updating it.
+	s.TenantID = &resource.TenantID
+	_, err := s.GetById(&resource.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	return s.db().Debug().
+		Model(&m.RhcConnection{ID: resource.ResourceID}).
+		Updates(updateAttributes).
+		Error
+}
+
+func (s *rhcConnectionDaoImpl) ToEventJSON(resource util.Resource) ([]byte, error) {
+	s.TenantID = &resource.TenantID
+	rhcConnection, err := s.GetById(&resource.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rhcConnection.ToEvent())
+}
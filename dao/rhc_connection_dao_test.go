@@ -2,6 +2,7 @@ package dao
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"reflect"
 	"strings"
@@ -369,3 +370,80 @@ func TestRhcConnectionRowsClosed(t *testing.T) {
 
 	DoneWithFixtures(RHC_CONNECTION_SCHEMA)
 }
+
+// TestRhcConnectionFetchAndUpdateBy tests that the availability status of a connection can be updated through the
+// "EventModelDao" interface.
+func TestRhcConnectionFetchAndUpdateBy(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures(RHC_CONNECTION_SCHEMA)
+
+	resource := util.Resource{
+		ResourceType: "RhcConnection",
+		ResourceID:   fixtures.TestRhcConnectionData[0].ID,
+		TenantID:     tenantId,
+	}
+
+	eventDao, err := GetFromResourceType(resource.ResourceType)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	err = (*eventDao).FetchAndUpdateBy(resource, map[string]interface{}{"availability_status": "unavailable"})
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	var rhcConnection model.RhcConnection
+	err = DB.Debug().
+		Where(`id = ?`, resource.ResourceID).
+		First(&rhcConnection).
+		Error
+
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if rhcConnection.AvailabilityStatus.AvailabilityStatus != "unavailable" {
+		t.Errorf(`want "unavailable" availability status, got "%s"`, rhcConnection.AvailabilityStatus.AvailabilityStatus)
+	}
+
+	// The event should include the sources the connection is related to.
+	data, err := (*eventDao).ToEventJSON(resource)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	var event model.RhcConnectionEvent
+	err = json.Unmarshal(data, &event)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	// The first connection is related to the first two sources.
+	if len(event.SourceIds) != 2 {
+		t.Errorf(`want 2 related source ids in the event, got "%v"`, event.SourceIds)
+	}
+
+	DoneWithFixtures(RHC_CONNECTION_SCHEMA)
+}
+
+// TestRhcConnectionFetchAndUpdateByDifferentTenant tests that a connection cannot be updated for a tenant which isn't
+// related to it.
+func TestRhcConnectionFetchAndUpdateByDifferentTenant(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures(RHC_CONNECTION_SCHEMA)
+
+	otherTenantDao := rhcConnectionDaoImpl{}
+	resource := util.Resource{
+		ResourceType: "RhcConnection",
+		ResourceID:   fixtures.TestRhcConnectionData[0].ID,
+		TenantID:     12345,
+	}
+
+	err := otherTenantDao.FetchAndUpdateBy(resource, map[string]interface{}{"availability_status": "unavailable"})
+	if !errors.Is(err, util.ErrNotFoundEmpty) {
+		t.Errorf(`want "%s" type, got "%s"`, reflect.TypeOf(util.ErrNotFoundEmpty), reflect.TypeOf(err))
+	}
+
+	DoneWithFixtures(RHC_CONNECTION_SCHEMA)
+}
@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"gorm.io/gorm/clause"
+)
+
+// GetScheduledResumeDao is a function definition that can be replaced in runtime in case some other DAO provider is
+// needed.
+var GetScheduledResumeDao func(*int64) ScheduledResumeDao
+
+// getDefaultScheduledResumeDao gets the default DAO implementation which will have the given tenant ID.
+func getDefaultScheduledResumeDao(tenantId *int64) ScheduledResumeDao {
+	return &scheduledResumeDaoImpl{
+		TenantID: tenantId,
+	}
+}
+
+// init sets the default DAO implementation so that other packages can request it easily.
+func init() {
+	GetScheduledResumeDao = getDefaultScheduledResumeDao
+}
+
+type scheduledResumeDaoImpl struct {
+	TenantID *int64
+}
+
+func (s *scheduledResumeDaoImpl) Schedule(resourceType string, resourceId int64, resumeAt time.Time) error {
+	entry := &m.ScheduledResume{
+		TenantID:     *s.TenantID,
+		ResourceType: resourceType,
+		ResourceID:   resourceId,
+		ResumeAt:     resumeAt,
+	}
+
+	return DB.Debug().
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "resource_type"}, {Name: "resource_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"resume_at", "updated_at"}),
+		}).
+		Create(entry).
+		Error
+}
+
+func (s *scheduledResumeDaoImpl) Cancel(resourceType string, resourceId int64) error {
+	return DB.Debug().
+		Where("tenant_id = ?", s.TenantID).
+		Where("resource_type = ?", resourceType).
+		Where("resource_id = ?", resourceId).
+		Delete(&m.ScheduledResume{}).
+		Error
+}
+
+func (s *scheduledResumeDaoImpl) ListDue(before time.Time, limit int) ([]m.ScheduledResume, error) {
+	entries := make([]m.ScheduledResume, 0, limit)
+
+	err := DB.Debug().
+		Preload("Tenant").
+		Where("resume_at <= ?", before).
+		Order("resume_at").
+		Limit(limit).
+		Find(&entries).
+		Error
+
+	return entries, err
+}
+
+func (s *scheduledResumeDaoImpl) Done(entry *m.ScheduledResume) error {
+	// the resource might have been paused again with another "resume_at" in the meantime.
+	return DB.Debug().
+		Where("id = ?", entry.ID).
+		Where("resume_at = ?", entry.ResumeAt).
+		Delete(&m.ScheduledResume{}).
+		Error
+}
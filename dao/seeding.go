@@ -362,3 +362,48 @@ func loadApplicationTypeSeeds() map[string]*m.ApplicationType {
 
 	return hash
 }
+
+// AvailabilityCheckDispatchSeeds returns the availability check dispatch configurations declared in the application
+// type and source type seeds, keyed by their names.
+func AvailabilityCheckDispatchSeeds() (*m.AvailabilityCheckDispatchers, error) {
+	dispatchers := &m.AvailabilityCheckDispatchers{
+		ApplicationTypes: make(map[string]m.AvailabilityCheckDispatch),
+		SourceTypes:      make(map[string]m.AvailabilityCheckDispatch),
+	}
+
+	appTypeSeeds := make(applicationTypeSeedMap)
+	data, err := seedsFS.ReadFile("seeds/application_types.yml")
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, &appTypeSeeds)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range appTypeSeeds {
+		if values.AvailabilityCheck != nil {
+			dispatchers.ApplicationTypes[name] = *values.AvailabilityCheck
+		}
+	}
+
+	sourceTypeSeeds := make(sourceTypeSeedMap)
+	data, err = seedsFS.ReadFile("seeds/source_types.yml")
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, &sourceTypeSeeds)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range sourceTypeSeeds {
+		if values.AvailabilityCheck != nil {
+			dispatchers.SourceTypes[name] = *values.AvailabilityCheck
+		}
+	}
+
+	return dispatchers, nil
+}
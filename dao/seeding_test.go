@@ -158,3 +158,25 @@ func TestSeedingApplicationMetadata(t *testing.T) {
 		t.Errorf("Seeding did not match values, got %v expected %v", len(appmdata), count)
 	}
 }
+
+// TestAvailabilityCheckDispatchSeeds tests that the availability check dispatchers get picked up from the seeds.
+func TestAvailabilityCheckDispatchSeeds(t *testing.T) {
+	dispatchers, err := AvailabilityCheckDispatchSeeds()
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	costManagement, ok := dispatchers.ApplicationTypes["/insights/platform/cost-management"]
+	if !ok || costManagement.Strategy != m.DispatchStrategyHttp || costManagement.URLEnv != "COST_MANAGEMENT_AVAILABILITY_CHECK_URL" {
+		t.Errorf(`want the cost management HTTP dispatcher, got "%+v"`, costManagement)
+	}
+
+	satellite, ok := dispatchers.SourceTypes["satellite"]
+	if !ok || satellite.Strategy != m.DispatchStrategyKafka || satellite.Topic != "platform.topological-inventory.operations-satellite" {
+		t.Errorf(`want the satellite Kafka dispatcher, got "%+v"`, satellite)
+	}
+
+	if _, ok := dispatchers.SourceTypes["amazon"]; ok {
+		t.Error("want no dispatcher for amazon")
+	}
+}
@@ -1,5 +1,7 @@
 package dao
 
+import m "github.com/RedHatInsights/sources-api-go/model"
+
 // type aliases to make reading easier
 type (
 	sourceTypeSeedMap       map[string]sourceTypeSeed
@@ -13,6 +15,8 @@ type sourceTypeSeed struct {
 	Schema      interface{} `json:"schema"`
 	Vendor      string      `json:"vendor"`
 	IconURL     string      `json:"icon_url"`
+	// AvailabilityCheck isn't stored in the database, it configures how the endpoints' availability checks are sent.
+	AvailabilityCheck *m.AvailabilityCheckDispatch `json:"availability_check"`
 }
 
 type applicationTypeSeed struct {
@@ -20,6 +24,9 @@ type applicationTypeSeed struct {
 	DependentApplications        interface{} `json:"dependent_applications"`
 	SupportedSourceTypes         interface{} `json:"supported_source_types"`
 	SupportedAuthenticationTypes interface{} `json:"supported_authentication_types"`
+	// AvailabilityCheck isn't stored in the database, it configures how the applications' availability checks are
+	// sent.
+	AvailabilityCheck *m.AvailabilityCheckDispatch `json:"availability_check"`
 }
 
 const SuperKeyMetaData = "SuperKeyMetaData"
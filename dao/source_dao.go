@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -28,6 +29,14 @@ func init() {
 
 type sourceDaoImpl struct {
 	TenantID *int64
+	requestContext
+}
+
+func (s *sourceDaoImpl) WithContext(ctx context.Context) SourceDao {
+	scoped := *s
+	scoped.ctx = ctx
+
+	return &scoped
 }
 
 func (s *sourceDaoImpl) SubCollectionList(primaryCollection interface{}, limit, offset int, filters []util.Filter) ([]m.Source, int64, error) {
@@ -35,11 +44,11 @@ func (s *sourceDaoImpl) SubCollectionList(primaryCollection interface{}, limit,
 	// 0, size of limit (since we will not be returning more than that)
 	sources := make([]m.Source, 0, limit)
 
-	sourceType, err := m.NewRelationObject(primaryCollection, *s.TenantID, DB.Debug())
+	sourceType, err := m.NewRelationObject(primaryCollection, *s.TenantID, s.db().Debug())
 	if err != nil {
 		return nil, 0, util.NewErrNotFound(sourceType.StringBaseObject())
 	}
-	query := sourceType.HasMany(&m.Source{}, DB.Debug())
+	query := sourceType.HasMany(&m.Source{}, s.db().Debug())
 
 	query = query.Where("sources.tenant_id = ?", s.TenantID)
 
@@ -63,7 +72,7 @@ func (s *sourceDaoImpl) SubCollectionList(primaryCollection interface{}, limit,
 
 func (s *sourceDaoImpl) List(limit, offset int, filters []util.Filter) ([]m.Source, int64, error) {
 	sources := make([]m.Source, 0, limit)
-	query := DB.Debug().Model(&m.Source{}).
+	query := s.db().Debug().Model(&m.Source{}).
 		Offset(offset).
 		Where("tenant_id = ?", s.TenantID)
 
@@ -85,8 +94,34 @@ func (s *sourceDaoImpl) List(limit, offset int, filters []util.Filter) ([]m.Sour
 	return sources, count, nil
 }
 
+func (s *sourceDaoImpl) ListOwned(owner *m.SourceOwner, limit, offset int, filters []util.Filter) ([]m.Source, int64, error) {
+	sources := make([]m.Source, 0, limit)
+	query := s.db().Debug().Model(&m.Source{}).
+		Offset(offset).
+		Where("tenant_id = ?", s.TenantID).
+		Where("source_type_id IN ?", s.db().Model(&m.SourceType{}).Select("id").Where("name IN ?", owner.SourceTypes)).
+		Where("owner = ? OR (owner IS NULL AND source_ref = ?)", owner.String(), owner.ID)
+
+	query, err := applyFilters(query, filters)
+	if err != nil {
+		return nil, 0, util.NewErrBadRequest(err)
+	}
+
+	// getting the total count (filters included) for pagination
+	count := int64(0)
+	query.Count(&count)
+
+	// limiting + running the actual query.
+	result := query.Limit(limit).Find(&sources)
+	if result.Error != nil {
+		return nil, 0, util.NewErrBadRequest(result.Error)
+	}
+
+	return sources, count, nil
+}
+
 func (s *sourceDaoImpl) ListInternal(limit, offset int, filters []util.Filter) ([]m.Source, int64, error) {
-	query := DB.Debug().
+	query := s.db().Debug().
 		Model(&m.Source{}).
 		Joins("Tenant").
 		Select(`sources.id, sources.availability_status, "Tenant".external_tenant`)
@@ -111,7 +146,7 @@ func (s *sourceDaoImpl) ListInternal(limit, offset int, filters []util.Filter) (
 
 func (s *sourceDaoImpl) GetById(id *int64) (*m.Source, error) {
 	src := &m.Source{ID: *id}
-	result := DB.First(src)
+	result := s.db().First(src)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("source")
 	}
@@ -122,7 +157,7 @@ func (s *sourceDaoImpl) GetById(id *int64) (*m.Source, error) {
 // Function that searches for a source and preloads any specified relations
 func (s *sourceDaoImpl) GetByIdWithPreload(id *int64, preloads ...string) (*m.Source, error) {
 	src := &m.Source{ID: *id}
-	q := DB.Where("tenant_id = ?", s.TenantID)
+	q := s.db().Where("tenant_id = ?", s.TenantID)
 
 	for _, preload := range preloads {
 		q = q.Preload(preload)
@@ -137,23 +172,23 @@ func (s *sourceDaoImpl) GetByIdWithPreload(id *int64, preloads ...string) (*m.So
 
 func (s *sourceDaoImpl) Create(src *m.Source) error {
 	src.TenantID = *s.TenantID // the TenantID gets injected in the middleware
-	result := DB.Create(src)
+	result := s.db().Create(src)
 	return result.Error
 }
 
 func (s *sourceDaoImpl) Update(src *m.Source) error {
-	result := DB.Updates(src)
+	result := s.db().Updates(src)
 	return result.Error
 }
 
 func (s *sourceDaoImpl) Delete(id *int64) (*m.Source, error) {
 	src := &m.Source{ID: *id}
-	result := DB.Where("tenant_id = ?", s.TenantID).First(src)
+	result := s.db().Where("tenant_id = ?", s.TenantID).First(src)
 	if result.Error != nil {
 		return nil, util.NewErrNotFound("source")
 	}
 
-	if result := DB.Delete(src); result.Error != nil {
+	if result := s.db().Delete(src); result.Error != nil {
 		return nil, fmt.Errorf("failed to delete source id %v", *id)
 	}
 
@@ -166,7 +201,7 @@ func (s *sourceDaoImpl) Tenant() *int64 {
 
 func (s *sourceDaoImpl) NameExistsInCurrentTenant(name string) bool {
 	src := &m.Source{Name: name}
-	result := DB.Where("name = ? AND tenant_id = ?", name, s.TenantID).First(src)
+	result := s.db().Where("name = ? AND tenant_id = ?", name, s.TenantID).First(src)
 
 	// If the name is found, GORM returns one row and no errors.
 	return result.Error == nil
@@ -174,7 +209,7 @@ func (s *sourceDaoImpl) NameExistsInCurrentTenant(name string) bool {
 
 func (s *sourceDaoImpl) BulkMessage(resource util.Resource) (map[string]interface{}, error) {
 	src := m.Source{ID: resource.ResourceID}
-	result := DB.Find(&src)
+	result := s.db().Find(&src)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -184,7 +219,7 @@ func (s *sourceDaoImpl) BulkMessage(resource util.Resource) (map[string]interfac
 }
 
 func (s *sourceDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttributes map[string]interface{}) error {
-	result := DB.Model(&m.Source{ID: resource.ResourceID}).Updates(updateAttributes)
+	result := s.db().Model(&m.Source{ID: resource.ResourceID}).Updates(updateAttributes)
 	if result.RowsAffected == 0 {
 		return fmt.Errorf("source not found %v", resource)
 	}
@@ -194,7 +229,7 @@ func (s *sourceDaoImpl) FetchAndUpdateBy(resource util.Resource, updateAttribute
 
 func (s *sourceDaoImpl) FindWithTenant(id *int64) (*m.Source, error) {
 	src := &m.Source{ID: *id}
-	result := DB.Preload("Tenant").Find(&src)
+	result := s.db().Preload("Tenant").Find(&src)
 
 	return src, result.Error
 }
@@ -216,7 +251,7 @@ func (s *sourceDaoImpl) ToEventJSON(resource util.Resource) ([]byte, error) {
 func (s *sourceDaoImpl) ListForRhcConnection(rhcConnectionId *int64, limit, offset int, filters []util.Filter) ([]m.Source, int64, error) {
 	sources := make([]m.Source, 0)
 
-	query := DB.Debug().
+	query := s.db().Debug().
 		Model(&m.Source{}).
 		Joins(`INNER JOIN "source_rhc_connections" "sr" ON "sources"."id" = "sr"."source_id"`).
 		Where(`"sr"."rhc_connection_id" = ?`, rhcConnectionId).
@@ -240,7 +275,7 @@ func (s *sourceDaoImpl) ListForRhcConnection(rhcConnectionId *int64, limit, offs
 }
 
 func (s *sourceDaoImpl) Pause(id int64) error {
-	err := DB.Debug().Transaction(func(tx *gorm.DB) error {
+	err := s.db().Debug().Transaction(func(tx *gorm.DB) error {
 		err := tx.Debug().
 			Model(&m.Source{}).
 			Where("id = ?", id).
@@ -266,7 +301,7 @@ func (s *sourceDaoImpl) Pause(id int64) error {
 }
 
 func (s *sourceDaoImpl) Resume(id int64) error {
-	err := DB.Debug().Transaction(func(tx *gorm.DB) error {
+	err := s.db().Debug().Transaction(func(tx *gorm.DB) error {
 		err := tx.Debug().
 			Model(&m.Source{}).
 			Where("id = ?", id).
@@ -290,3 +325,40 @@ func (s *sourceDaoImpl) Resume(id int64) error {
 
 	return err
 }
+
+func (s *sourceDaoImpl) ListForAvailabilityChecks(sourceTypeId int64, limit, offset int) ([]m.Source, error) {
+	sources := make([]m.Source, 0, limit)
+
+	err := s.db().Debug().
+		Model(&m.Source{}).
+		Preload("SourceType").
+		Preload("Tenant").
+		Preload("Applications", "paused_at IS NULL").
+		Preload("Applications.ApplicationType").
+		Preload("Endpoints", "paused_at IS NULL").
+		Preload("Endpoints.Tenant").
+		Where("source_type_id = ?", sourceTypeId).
+		Where("paused_at IS NULL").
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&sources).
+		Error
+
+	return sources, err
+}
+
+func (s *sourceDaoImpl) ListForReplay(afterId int64, limit int) ([]m.Source, error) {
+	sources := make([]m.Source, 0, limit)
+
+	err := s.db().Debug().
+		Model(&m.Source{}).
+		Where("tenant_id = ?", s.TenantID).
+		Where("id > ?", afterId).
+		Order("id").
+		Limit(limit).
+		Find(&sources).
+		Error
+
+	return sources, err
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/RedHatInsights/sources-api-go/internal/testutils"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
 )
 
 var sourceDao = sourceDaoImpl{
@@ -119,3 +121,145 @@ func TestResumingSource(t *testing.T) {
 
 	DoneWithFixtures("pause_unpause")
 }
+
+// TestListForAvailabilityChecks checks that the paused sources don't get listed for the availability checks.
+func TestListForAvailabilityChecks(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("availability_checks")
+
+	sourceDao := GetSourceDao(&testSource.TenantID)
+	err := sourceDao.Pause(testSource.ID)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	sources, err := GetSourceDao(nil).ListForAvailabilityChecks(testSource.SourceTypeID, 100, 0)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	want := 0
+	for _, src := range fixtures.TestSourceData {
+		if src.SourceTypeID == testSource.SourceTypeID && src.ID != testSource.ID {
+			want++
+		}
+	}
+
+	if len(sources) != want {
+		t.Errorf(`want "%d" sources to check, got "%d"`, want, len(sources))
+	}
+
+	for _, src := range sources {
+		if src.ID == testSource.ID {
+			t.Errorf(`want the paused source "%d" skipped, but it was listed`, testSource.ID)
+		}
+
+		if src.Tenant.ExternalTenant == "" {
+			t.Errorf(`want the tenant of the source "%d" preloaded, got an empty one`, src.ID)
+		}
+	}
+
+	DoneWithFixtures("availability_checks")
+}
+
+// TestListOwned checks that the systems only list the sources of their source types which they created, or which
+// point to them through their source ref when they have no owner.
+func TestListOwned(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("list_owned")
+
+	owner := &m.SourceOwner{Key: "cluster_id", ID: "cluster", SourceTypes: []string{fixtures.TestSourceTypeData[0].Name}}
+	ownerString, anotherOwner, sourceRef := owner.String(), "cluster_id:another", owner.ID
+
+	sources := []m.Source{
+		{Name: "created", Owner: &ownerString},
+		{Name: "referenced", SourceRef: &sourceRef},
+		{Name: "created by another system", Owner: &anotherOwner},
+		{Name: "referenced but created by another system", Owner: &anotherOwner, SourceRef: &sourceRef},
+	}
+
+	sourceDao := GetSourceDao(&testSource.TenantID)
+	for i := range sources {
+		sources[i].SourceTypeID = fixtures.TestSourceTypeData[0].Id
+		err := sourceDao.Create(&sources[i])
+		if err != nil {
+			t.Fatalf(`want nil error, got "%s"`, err)
+		}
+	}
+
+	listed, count, err := sourceDao.ListOwned(owner, 100, 0, nil)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if count != 2 || len(listed) != 2 {
+		t.Fatalf(`want the two owned sources, got "%v"`, listed)
+	}
+
+	for _, src := range listed {
+		if src.ID != sources[0].ID && src.ID != sources[1].ID {
+			t.Errorf(`want the owned sources "%d" and "%d", got "%d"`, sources[0].ID, sources[1].ID, src.ID)
+		}
+	}
+
+	// the sources of the source types outside the system's policy aren't listed.
+	owner.SourceTypes = []string{"openshift"}
+	listed, _, err = sourceDao.ListOwned(owner, 100, 0, nil)
+	if err != nil {
+		t.Errorf(`want nil error, got "%s"`, err)
+	}
+
+	if len(listed) != 0 {
+		t.Errorf(`want no sources, got "%v"`, listed)
+	}
+
+	DoneWithFixtures("list_owned")
+}
+
+// TestListSourceScope tests that the source scope filters only list the sources, or the resources of the sources,
+// with the given ids or source types.
+func TestListSourceScope(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("source_scope")
+
+	sourceDao := GetSourceDao(&fixtures.TestTenantData[0].Id)
+	applicationDao := GetApplicationDao(&fixtures.TestTenantData[0].Id)
+
+	tests := []struct {
+		name  string
+		scope util.SourceScope
+		want  int
+	}{
+		{"source id", util.SourceScope{SourceIds: []int64{fixtures.TestSourceData[0].ID}}, 1},
+		{"source type", util.SourceScope{SourceTypes: []string{fixtures.TestSourceTypeData[0].Name}}, len(fixtures.TestSourceData)},
+		{"nothing granted", util.SourceScope{}, 0},
+	}
+
+	for _, test := range tests {
+		sources, count, err := sourceDao.List(100, 0, []util.Filter{test.scope.Filter("id")})
+		if err != nil {
+			t.Errorf(`want nil error, got "%s"`, err)
+		}
+
+		if count != int64(test.want) || len(sources) != test.want {
+			t.Errorf(`%s: want %d sources, got "%d"`, test.name, test.want, count)
+		}
+
+		applications, _, err := applicationDao.List(100, 0, []util.Filter{test.scope.Filter("source_id")})
+		if err != nil {
+			t.Errorf(`want nil error, got "%s"`, err)
+		}
+
+		for _, app := range applications {
+			if test.want == 1 && app.SourceID != fixtures.TestSourceData[0].ID {
+				t.Errorf(`%s: want only the applications of source "%d", got the source "%d"`, test.name, fixtures.TestSourceData[0].ID, app.SourceID)
+			}
+		}
+
+		if test.want == 0 && len(applications) != 0 {
+			t.Errorf(`%s: want no applications, got "%d"`, test.name, len(applications))
+		}
+	}
+
+	DoneWithFixtures("source_scope")
+}
@@ -0,0 +1,154 @@
+package dao
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/config"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	tenantCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sources_api_tenant_cache_hits_total",
+		Help: "The number of tenant lookups answered by the tenant cache.",
+	})
+	tenantCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sources_api_tenant_cache_misses_total",
+		Help: "The number of tenant lookups which had to hit the database.",
+	})
+)
+
+// cachedTenants caches the tenants looked up by the tenancy middleware and the status listener, so that they don't hit the
+// database on every request and message.
+var cachedTenants = newTenantCache(config.Get().TenantCacheSize, time.Duration(config.Get().TenantCacheTtl)*time.Second)
+
+// tenantCache is a least recently used cache of the tenants, keyed by their organization id and by their account
+// number. The tenants expire after the TTL, so that the changes made by the Rails application show up eventually.
+type tenantCache struct {
+	size int
+	ttl  time.Duration
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	// order holds the entries from the most to the least recently used.
+	order *list.List
+}
+
+// tenantCacheEntry is a tenant held by the cache under one of its keys.
+type tenantCacheEntry struct {
+	key     string
+	tenant  m.Tenant
+	expires time.Time
+}
+
+// newTenantCache returns a cache which holds up to the given number of keys. A zero size or TTL disables it.
+func newTenantCache(size int, ttl time.Duration) *tenantCache {
+	return &tenantCache{size: size, ttl: ttl, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// enabled tells whether the cache holds anything at all.
+func (tc *tenantCache) enabled() bool {
+	return tc.size > 0 && tc.ttl > 0
+}
+
+// get returns the tenant with the given identifiers. The organization id takes precedence, just like it does when
+// the tenants are looked up in the database, so a tenant cached by its account number alone isn't returned when an
+// organization id is given.
+func (tc *tenantCache) get(accountNumber, orgId string) (m.Tenant, bool) {
+	if !tc.enabled() {
+		return m.Tenant{}, false
+	}
+
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	key := tenantLookupKey(accountNumber, orgId)
+	element, ok := tc.entries[key]
+	if !ok {
+		tenantCacheMisses.Inc()
+		return m.Tenant{}, false
+	}
+
+	entry := element.Value.(*tenantCacheEntry)
+	if time.Now().After(entry.expires) {
+		tc.removeElement(element)
+		tenantCacheMisses.Inc()
+		return m.Tenant{}, false
+	}
+
+	tc.order.MoveToFront(element)
+	tenantCacheHits.Inc()
+
+	return entry.tenant, true
+}
+
+// add caches the tenant under each of its identifiers, evicting the least recently used tenants when the cache is
+// full.
+func (tc *tenantCache) add(tenant m.Tenant) {
+	if !tc.enabled() {
+		return
+	}
+
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	expires := time.Now().Add(tc.ttl)
+	for _, key := range tenantKeys(tenant) {
+		if element, ok := tc.entries[key]; ok {
+			element.Value = &tenantCacheEntry{key: key, tenant: tenant, expires: expires}
+			tc.order.MoveToFront(element)
+			continue
+		}
+
+		tc.entries[key] = tc.order.PushFront(&tenantCacheEntry{key: key, tenant: tenant, expires: expires})
+	}
+
+	for tc.order.Len() > tc.size {
+		tc.removeElement(tc.order.Back())
+	}
+}
+
+// remove drops the tenant with the given id from the cache.
+func (tc *tenantCache) remove(id int64) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	for _, element := range tc.entries {
+		if element.Value.(*tenantCacheEntry).tenant.Id == id {
+			tc.removeElement(element)
+		}
+	}
+}
+
+// removeElement drops the entry from both the map and the list. The mutex must be held.
+func (tc *tenantCache) removeElement(element *list.Element) {
+	tc.order.Remove(element)
+	delete(tc.entries, element.Value.(*tenantCacheEntry).key)
+}
+
+// tenantLookupKey returns the key the tenant with the given identifiers is looked up by.
+func tenantLookupKey(accountNumber, orgId string) string {
+	if orgId != "" {
+		return "org:" + orgId
+	}
+
+	return "account:" + accountNumber
+}
+
+// tenantKeys returns the keys the tenant is cached under, which are the ones of the lookups that find it.
+func tenantKeys(tenant m.Tenant) []string {
+	keys := make([]string, 0, 2)
+	if tenant.OrgID != "" {
+		keys = append(keys, tenantLookupKey("", tenant.OrgID))
+	}
+
+	if tenant.ExternalTenant != "" {
+		keys = append(keys, tenantLookupKey(tenant.ExternalTenant, ""))
+	}
+
+	return keys
+}
@@ -0,0 +1,111 @@
+package dao
+
+import (
+	"testing"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestTenantCacheLookups tests that the tenants are found by the same identifiers they are found by in the database,
+// and that the hits and misses are counted.
+func TestTenantCacheLookups(t *testing.T) {
+	cache := newTenantCache(10, time.Minute)
+	cache.add(m.Tenant{Id: 1, ExternalTenant: "12345", OrgID: "org-1"})
+	cache.add(m.Tenant{Id: 2, ExternalTenant: "67890"})
+
+	hits := testutil.ToFloat64(tenantCacheHits)
+	misses := testutil.ToFloat64(tenantCacheMisses)
+
+	testCases := []struct {
+		accountNumber string
+		orgId         string
+		want          int64
+	}{
+		{accountNumber: "", orgId: "org-1", want: 1},
+		{accountNumber: "another account", orgId: "org-1", want: 1},
+		{accountNumber: "12345", orgId: "", want: 1},
+		{accountNumber: "67890", orgId: "", want: 2},
+		{accountNumber: "67890", orgId: "org-2", want: 0},
+		{accountNumber: "", orgId: "org-3", want: 0},
+	}
+
+	for _, tc := range testCases {
+		tenant, ok := cache.get(tc.accountNumber, tc.orgId)
+		if ok != (tc.want != 0) || tenant.Id != tc.want {
+			t.Errorf(`account number "%s" and org id "%s": want tenant "%d", got "%d"`, tc.accountNumber, tc.orgId, tc.want, tenant.Id)
+		}
+	}
+
+	if got := testutil.ToFloat64(tenantCacheHits) - hits; got != 4 {
+		t.Errorf(`want 4 hits, got "%v"`, got)
+	}
+
+	if got := testutil.ToFloat64(tenantCacheMisses) - misses; got != 2 {
+		t.Errorf(`want 2 misses, got "%v"`, got)
+	}
+}
+
+// TestTenantCacheEviction tests that the least recently used tenants get evicted when the cache is full.
+func TestTenantCacheEviction(t *testing.T) {
+	cache := newTenantCache(2, time.Minute)
+	cache.add(m.Tenant{Id: 1, ExternalTenant: "1"})
+	cache.add(m.Tenant{Id: 2, ExternalTenant: "2"})
+
+	// using the first tenant makes the second one the least recently used.
+	if _, ok := cache.get("1", ""); !ok {
+		t.Fatalf(`want tenant "1" to be cached`)
+	}
+
+	cache.add(m.Tenant{Id: 3, ExternalTenant: "3"})
+
+	if _, ok := cache.get("2", ""); ok {
+		t.Errorf(`want tenant "2" to be evicted`)
+	}
+
+	for _, accountNumber := range []string{"1", "3"} {
+		if _, ok := cache.get(accountNumber, ""); !ok {
+			t.Errorf(`want tenant "%s" to be cached`, accountNumber)
+		}
+	}
+}
+
+// TestTenantCacheExpiry tests that the tenants expire after the TTL, and that they can be removed.
+func TestTenantCacheExpiry(t *testing.T) {
+	cache := newTenantCache(10, time.Millisecond)
+	cache.add(m.Tenant{Id: 1, ExternalTenant: "12345"})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.get("12345", ""); ok {
+		t.Errorf(`want the tenant to be expired`)
+	}
+
+	if len(cache.entries) != 0 || cache.order.Len() != 0 {
+		t.Errorf(`want the expired tenant to be dropped, got "%d" entries`, len(cache.entries))
+	}
+
+	cache = newTenantCache(10, time.Minute)
+	cache.add(m.Tenant{Id: 1, ExternalTenant: "12345", OrgID: "org-1"})
+	cache.remove(1)
+
+	if _, ok := cache.get("", "org-1"); ok {
+		t.Errorf(`want the tenant to be removed`)
+	}
+
+	if len(cache.entries) != 0 || cache.order.Len() != 0 {
+		t.Errorf(`want both keys of the tenant to be removed, got "%d" entries`, len(cache.entries))
+	}
+}
+
+// TestTenantCacheDisabled tests that a cache without size or TTL doesn't hold anything.
+func TestTenantCacheDisabled(t *testing.T) {
+	for _, cache := range []*tenantCache{newTenantCache(0, time.Minute), newTenantCache(10, 0)} {
+		cache.add(m.Tenant{Id: 1, ExternalTenant: "12345"})
+
+		if _, ok := cache.get("12345", ""); ok {
+			t.Errorf(`want a disabled cache to not hold the tenant`)
+		}
+	}
+}
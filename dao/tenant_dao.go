@@ -1,7 +1,12 @@
 package dao
 
 import (
+	"errors"
+
 	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetTenantDao is a function definition that can be replaced in runtime in case some other DAO provider is
@@ -20,20 +25,53 @@ func init() {
 
 type tenantDaoImpl struct{}
 
-func (t *tenantDaoImpl) GetOrCreateTenantID(accountNumber string) (*int64, error) {
-	tenant := m.Tenant{ExternalTenant: accountNumber}
+func (t *tenantDaoImpl) GetOrCreateTenantID(accountNumber, orgId string) (*int64, error) {
+	tenant, err := t.TenantByIdentifiers(accountNumber, orgId)
 
-	// Find the tenant, scanning into the struct above
-	result := DB.
-		Where("external_tenant = ?", accountNumber).
-		First(&tenant)
+	// The tenants found by their account number get the organization id they were missing.
+	if err == nil {
+		if orgId != "" && tenant.OrgID == "" {
+			err = DB.
+				Model(tenant).
+				Update("org_id", orgId).
+				Error
+
+			cachedTenants.remove(tenant.Id)
+		}
 
-	// Looks like we didn't find it, create it and return the ID.
-	if result.Error != nil {
-		result = DB.Create(&tenant)
+		return &tenant.Id, err
 	}
 
-	return &tenant.Id, result.Error
+	if !errors.Is(err, util.ErrNotFoundEmpty) {
+		return nil, err
+	}
+
+	// Looks like we didn't find it, create it and return the ID. A concurrent request might have created the same
+	// tenant in the meantime, in which case the upsert returns the ID of that one instead.
+	tenant = &m.Tenant{ExternalTenant: accountNumber, OrgID: orgId}
+	err = DB.
+		Clauses(tenantUpsert(orgId)).
+		Create(tenant).
+		Error
+
+	return &tenant.Id, err
+}
+
+// tenantUpsert returns the conflict clause for the unique index of the identifier the tenant gets looked up by. The
+// no-op update makes the insert return the ID of the existing tenant.
+func tenantUpsert(orgId string) clause.OnConflict {
+	index := tenantExternalTenantIndex
+	if orgId != "" {
+		index = tenantOrgIdIndex
+	}
+
+	column, predicate := tenantUniqueIndex(index)
+
+	return clause.OnConflict{
+		Columns:     []clause.Column{{Name: column}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: predicate}}},
+		DoUpdates:   clause.Assignments(map[string]interface{}{column: gorm.Expr("excluded." + column)}),
+	}
 }
 
 func (t *tenantDaoImpl) TenantByAccountNumber(accountNumber string) (*m.Tenant, error) {
@@ -45,3 +83,85 @@ func (t *tenantDaoImpl) TenantByAccountNumber(accountNumber string) (*m.Tenant,
 
 	return &tenant, result.Error
 }
+
+func (t *tenantDaoImpl) TenantByIdentifiers(accountNumber, orgId string) (*m.Tenant, error) {
+	if tenant, ok := cachedTenants.get(accountNumber, orgId); ok {
+		return &tenant, nil
+	}
+
+	tenant, err := tenantByIdentifiers(accountNumber, orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedTenants.add(*tenant)
+
+	return tenant, nil
+}
+
+// tenantByIdentifiers looks the tenant up in the database, bypassing the cache.
+func tenantByIdentifiers(accountNumber, orgId string) (*m.Tenant, error) {
+	var tenant m.Tenant
+
+	if orgId != "" {
+		err := DB.
+			Where("org_id = ?", orgId).
+			First(&tenant).
+			Error
+
+		if err == nil {
+			return &tenant, nil
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if accountNumber != "" {
+		query := DB.Where("external_tenant = ?", accountNumber)
+
+		// a tenant which already belongs to another organization isn't the one we are looking for.
+		if orgId != "" {
+			query = query.Where("org_id IS NULL OR org_id = ''")
+		}
+
+		err := query.First(&tenant).Error
+		if err == nil {
+			return &tenant, nil
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, util.NewErrNotFound("tenant")
+}
+
+func (t *tenantDaoImpl) ListWithoutOrgId(afterId int64, limit int) ([]m.Tenant, error) {
+	tenants := make([]m.Tenant, 0)
+
+	err := DB.
+		Where("id > ?", afterId).
+		Where("org_id IS NULL OR org_id = ''").
+		Where("external_tenant IS NOT NULL AND external_tenant != ''").
+		Order("id").
+		Limit(limit).
+		Find(&tenants).
+		Error
+
+	return tenants, err
+}
+
+func (t *tenantDaoImpl) SetOrgId(id int64, orgId string) error {
+	err := DB.
+		Model(&m.Tenant{}).
+		Where("id = ?", id).
+		Update("org_id", orgId).
+		Error
+
+	cachedTenants.remove(id)
+
+	return err
+}
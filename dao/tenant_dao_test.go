@@ -0,0 +1,167 @@
+package dao
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// TestGetOrCreateTenantID tests that the tenants are looked up by their org id first, then by their account number,
+// and that they get created when they don't exist.
+func TestGetOrCreateTenantID(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("tenant_org_id")
+
+	tenantDao := GetTenantDao()
+	fixture := fixtures.TestTenantData[0]
+
+	// the tenant found by its account number gets the org id.
+	id, err := tenantDao.GetOrCreateTenantID(fixture.ExternalTenant, "org-1")
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if *id != fixture.Id {
+		t.Errorf(`want tenant "%d", got "%d"`, fixture.Id, *id)
+	}
+
+	tenant, err := tenantDao.TenantByIdentifiers("", "org-1")
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if tenant.Id != fixture.Id || tenant.ExternalTenant != fixture.ExternalTenant {
+		t.Errorf(`want tenant "%d", got "%d"`, fixture.Id, tenant.Id)
+	}
+
+	// the org id takes precedence over the account number.
+	id, err = tenantDao.GetOrCreateTenantID("another account", "org-1")
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if *id != fixture.Id {
+		t.Errorf(`want tenant "%d", got "%d"`, fixture.Id, *id)
+	}
+
+	// an account number that belongs to another organization isn't a match, so a new tenant gets created.
+	id, err = tenantDao.GetOrCreateTenantID(fixture.ExternalTenant, "org-2")
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if *id == fixture.Id {
+		t.Errorf(`want a new tenant, got "%d"`, *id)
+	}
+
+	// the tenants without account number can be created.
+	id, err = tenantDao.GetOrCreateTenantID("", "org-3")
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	tenant, err = tenantDao.TenantByIdentifiers("", "org-3")
+	if err != nil || tenant.Id != *id {
+		t.Errorf(`want tenant "%d", got "%v" with error "%v"`, *id, tenant, err)
+	}
+
+	_, err = tenantDao.TenantByIdentifiers("", "org-4")
+	if !errors.Is(err, util.ErrNotFoundEmpty) {
+		t.Errorf(`want a not found error, got "%v"`, err)
+	}
+
+	DoneWithFixtures("tenant_org_id")
+}
+
+// TestGetOrCreateTenantIDConcurrently tests that the concurrent requests of a new tenant all end up with the same one.
+func TestGetOrCreateTenantIDConcurrently(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("tenant_upsert")
+
+	testCases := []struct {
+		accountNumber string
+		orgId         string
+	}{
+		{accountNumber: "new account", orgId: ""},
+		{accountNumber: "", orgId: "new org"},
+	}
+
+	for _, tc := range testCases {
+		ids := make(chan int64, 10)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(ids); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				id, err := GetTenantDao().GetOrCreateTenantID(tc.accountNumber, tc.orgId)
+				if err != nil {
+					t.Errorf(`want nil error, got "%s"`, err)
+					return
+				}
+
+				ids <- *id
+			}()
+		}
+
+		wg.Wait()
+		close(ids)
+
+		want := <-ids
+		for id := range ids {
+			if id != want {
+				t.Errorf(`want every request to get tenant "%d", got "%d"`, want, id)
+			}
+		}
+
+		var count int64
+		DB.Model(&m.Tenant{}).Where("external_tenant = ? AND org_id = ?", tc.accountNumber, tc.orgId).Count(&count)
+		if count != 1 {
+			t.Errorf(`want a single tenant, got "%d"`, count)
+		}
+	}
+
+	DoneWithFixtures("tenant_upsert")
+}
+
+// TestListWithoutOrgId tests that only the tenants with an account number and no org id are listed for the backfill.
+func TestListWithoutOrgId(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	CreateFixtures("tenant_backfill")
+
+	tenants := []m.Tenant{
+		{ExternalTenant: "without org id"},
+		{ExternalTenant: "with org id", OrgID: "org"},
+		{OrgID: "without account number"},
+	}
+	DB.Create(&tenants)
+
+	tenantDao := GetTenantDao()
+
+	listed, err := tenantDao.ListWithoutOrgId(0, 100)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	// the fixture's tenant has no org id either.
+	if len(listed) != 2 || listed[0].Id != fixtures.TestTenantData[0].Id || listed[1].Id != tenants[0].Id {
+		t.Errorf(`want the fixture's tenant and "%d", got "%v"`, tenants[0].Id, listed)
+	}
+
+	err = tenantDao.SetOrgId(tenants[0].Id, "backfilled")
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	listed, _ = tenantDao.ListWithoutOrgId(fixtures.TestTenantData[0].Id, 100)
+	if len(listed) != 0 {
+		t.Errorf(`want no tenants left, got "%v"`, listed)
+	}
+
+	DoneWithFixtures("tenant_backfill")
+}
@@ -22,7 +22,7 @@ func getEndpointDaoWithTenant(c echo.Context) (dao.EndpointDao, error) {
 		return nil, err
 	}
 
-	return dao.GetEndpointDao(&tenantId), nil
+	return dao.GetEndpointDao(&tenantId).WithContext(c.Request().Context()), nil
 }
 
 func SourceListEndpoint(c echo.Context) error {
@@ -142,6 +142,11 @@ func EndpointCreate(c echo.Context) error {
 		return util.NewErrBadRequest(fmt.Sprintf("Validation failed: %s", err))
 	}
 
+	err = checkNotPaused(c, service.PausedSource, input.SourceID)
+	if err != nil {
+		return err
+	}
+
 	endpoint := &m.Endpoint{
 		Default:              &input.Default,
 		ReceptorNode:         input.ReceptorNode,
@@ -165,6 +170,56 @@ func EndpointCreate(c echo.Context) error {
 	return c.JSON(http.StatusCreated, endpoint.ToResponse())
 }
 
+func EndpointEdit(c echo.Context) error {
+	endpointDao, err := getEndpointDao(c)
+	if err != nil {
+		return err
+	}
+
+	input := &m.EndpointEditRequest{}
+	if err := c.Bind(input); err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	endpoint, err := endpointDao.GetById(&id)
+	if err != nil {
+		return err
+	}
+
+	err = checkNotPaused(c, service.PausedEndpoint, endpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	err = service.ValidateEndpointEditRequest(endpointDao, endpoint, input)
+	if err != nil {
+		return util.NewErrBadRequest(fmt.Sprintf("Validation failed: %s", err))
+	}
+
+	endpoint.UpdateFromRequest(input)
+	err = endpointDao.Update(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if input.AvailabilityStatus != nil {
+		var statusError string
+		if endpoint.AvailabilityStatusError != nil {
+			statusError = *endpoint.AvailabilityStatusError
+		}
+
+		service.RecordAvailabilityStatus(c.Request().Context(), *endpointDao.Tenant(), "Endpoint", strconv.FormatInt(endpoint.ID, 10), endpoint.AvailabilityStatus.AvailabilityStatus, statusError, m.AvailabilityStatusOriginApi)
+	}
+
+	setEventStreamResource(c, endpoint)
+	return c.JSON(http.StatusOK, endpoint.ToResponse())
+}
+
 func EndpointDelete(c echo.Context) error {
 	endpointDao, err := getEndpointDao(c)
 	if err != nil {
@@ -211,7 +266,7 @@ func EndpointListAuthentications(c echo.Context) error {
 
 	auths, count, err := authDB.ListForEndpoint(id, limit, offset, filters)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, util.ErrorDoc(err.Error(), "404"))
+		return c.JSON(http.StatusNotFound, util.ErrorDoc(c.Request().Context(), err.Error(), "404"))
 	}
 
 	out := make([]interface{}, len(auths))
@@ -221,3 +276,112 @@ func EndpointListAuthentications(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, util.CollectionResponse(out, c.Request(), int(count), limit, offset))
 }
+
+// EndpointProbe checks the endpoint's connectivity right away, recording the certificate it presents and its
+// availability status, and returns the updated endpoint.
+func EndpointProbe(c echo.Context) error {
+	endpointId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	err = checkNotPaused(c, service.PausedEndpoint, endpointId)
+	if err != nil {
+		return err
+	}
+
+	endpointDao, err := getEndpointDao(c)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := endpointDao.GetById(&endpointId)
+	if err != nil {
+		return err
+	}
+
+	service.ProbeEndpoint(c.Request().Context(), endpoint, service.ForwadableHeaders(c))
+
+	endpoint, err = endpointDao.GetById(&endpointId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, endpoint.ToResponse())
+}
+
+// EndpointPause pauses a given endpoint by setting its "paused_at" column to "now()". When a "resume_at" is given, the
+// endpoint gets resumed on its own at that time.
+func EndpointPause(c echo.Context) error {
+	endpointId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	pauseRequest, err := getPauseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	endpointDao, err := getEndpointDao(c)
+	if err != nil {
+		return err
+	}
+
+	err = endpointDao.Pause(endpointId)
+	if err != nil {
+		return err
+	}
+
+	err = scheduleResume(c, service.PausedEndpoint, endpointId, pauseRequest.ResumeAt)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := endpointDao.GetById(&endpointId)
+	if err != nil {
+		return err
+	}
+
+	err = service.RaiseEvent("Endpoint.Pause", endpoint, service.ForwadableHeaders(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusNoContent, nil)
+}
+
+// EndpointResume resumes a given endpoint by setting its "paused_at" column to "NULL".
+func EndpointResume(c echo.Context) error {
+	endpointId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	endpointDao, err := getEndpointDao(c)
+	if err != nil {
+		return err
+	}
+
+	err = endpointDao.Resume(endpointId)
+	if err != nil {
+		return err
+	}
+
+	err = scheduleResume(c, service.PausedEndpoint, endpointId, nil)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := endpointDao.GetById(&endpointId)
+	if err != nil {
+		return err
+	}
+
+	err = service.RaiseEvent("Endpoint.Unpause", endpoint, service.ForwadableHeaders(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusNoContent, nil)
+}
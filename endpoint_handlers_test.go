@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/RedHatInsights/sources-api-go/dao"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
 	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/util"
 )
 
@@ -326,7 +330,7 @@ func TestEndpointCreate(t *testing.T) {
 	scheme := "scheme"
 	port := 443
 	verifySsl := true
-	certificateAuthority := "Let's Encrypt"
+	certificateAuthority := fixtures.TestCertificateAuthority
 
 	requestBody := m.EndpointCreateRequest{
 		Default:              false,
@@ -418,3 +422,281 @@ func TestEndpointDeleteBadRequest(t *testing.T) {
 
 	testutils.BadRequestTest(t, rec)
 }
+
+func TestEndpointEdit(t *testing.T) {
+	req := m.EndpointEditRequest{
+		Host:                 request.PointerToString("edited.example.com"),
+		CertificateAuthority: request.PointerToString(fixtures.TestCertificateAuthority),
+		AvailabilityStatus:   request.PointerToString(m.Available),
+	}
+
+	body, _ := json.Marshal(req)
+
+	c, rec := request.CreateTestContext(
+		http.MethodPatch,
+		"/api/sources/v3.1/endpoints/1",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	err := EndpointEdit(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("want %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var endpoint m.EndpointResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &endpoint)
+	if err != nil {
+		t.Errorf("unable to unmarshal the endpoint from the response: %s", err)
+	}
+
+	if *endpoint.Host != "edited.example.com" {
+		t.Errorf("want host %q, got %q", "edited.example.com", *endpoint.Host)
+	}
+
+	if *endpoint.AvailabilityStatus != m.Available {
+		t.Errorf("want availability status %q, got %q", m.Available, *endpoint.AvailabilityStatus)
+	}
+}
+
+// TestEndpointEditBadRequest tests that an invalid certificate authority is rejected.
+func TestEndpointEditBadRequest(t *testing.T) {
+	req := m.EndpointEditRequest{
+		CertificateAuthority: request.PointerToString("Let's Encrypt"),
+	}
+
+	body, _ := json.Marshal(req)
+
+	c, rec := request.CreateTestContext(
+		http.MethodPatch,
+		"/api/sources/v3.1/endpoints/1",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	badRequestEndpointEdit := ErrorHandlingContext(EndpointEdit)
+	err := badRequestEndpointEdit(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.BadRequestTest(t, rec)
+}
+
+func TestEndpointEditNotFound(t *testing.T) {
+	body, _ := json.Marshal(m.EndpointEditRequest{})
+
+	c, rec := request.CreateTestContext(
+		http.MethodPatch,
+		"/api/sources/v3.1/endpoints/9764567834",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("id")
+	c.SetParamValues("9764567834")
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	notFoundEndpointEdit := ErrorHandlingContext(EndpointEdit)
+	err := notFoundEndpointEdit(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.NotFoundTest(t, rec)
+}
+
+// TestEndpointPause tests that an endpoint gets paused, and that its resumption gets scheduled.
+func TestEndpointPause(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+
+	resumeAt := time.Now().Add(time.Hour)
+	body, err := json.Marshal(m.PauseRequest{ResumeAt: &resumeAt})
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/endpoints/1/pause",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	err = EndpointPause(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf(`want status "%d", got "%d"`, http.StatusNoContent, rec.Code)
+	}
+
+	due, err := dao.GetScheduledResumeDao(nil).ListDue(resumeAt, 100)
+	if err != nil {
+		t.Error(err)
+	}
+
+	scheduled := false
+	for _, entry := range due {
+		scheduled = scheduled || (entry.ResourceType == service.PausedEndpoint && entry.ResourceID == 1)
+	}
+
+	if !scheduled {
+		t.Errorf("want the resumption scheduled, got %+v", due)
+	}
+}
+
+// TestEndpointResume tests that an endpoint gets resumed, and that its scheduled resumption gets cancelled.
+func TestEndpointResume(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/endpoints/1/unpause",
+		nil,
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	err := EndpointResume(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf(`want status "%d", got "%d"`, http.StatusNoContent, rec.Code)
+	}
+
+	due, err := dao.GetScheduledResumeDao(nil).ListDue(time.Now().Add(24*time.Hour), 100)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, entry := range due {
+		if entry.ResourceType == service.PausedEndpoint && entry.ResourceID == 1 {
+			t.Errorf("want the scheduled resumption cancelled, got %+v", entry)
+		}
+	}
+}
+
+// TestEndpointPauseNotFound tests that a not found error is returned when the endpoint doesn't exist, and that no
+// resumption gets scheduled for it.
+func TestEndpointPauseNotFound(t *testing.T) {
+	resumeAt := time.Now().Add(time.Hour)
+	body, err := json.Marshal(m.PauseRequest{ResumeAt: &resumeAt})
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/endpoints/12345/pause",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	c.SetParamNames("id")
+	c.SetParamValues("12345")
+
+	notFoundEndpointPause := ErrorHandlingContext(EndpointPause)
+	err = notFoundEndpointPause(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.NotFoundTest(t, rec)
+}
+
+// TestEndpointPauseBadRequest tests that a bad request is returned when the given "resume_at" isn't in the future.
+func TestEndpointPauseBadRequest(t *testing.T) {
+	resumeAt := time.Now().Add(-time.Hour)
+	body, err := json.Marshal(m.PauseRequest{ResumeAt: &resumeAt})
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/endpoints/1/pause",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	badRequestEndpointPause := ErrorHandlingContext(EndpointPause)
+	err = badRequestEndpointPause(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.BadRequestTest(t, rec)
+}
+
+// TestEndpointEditPausedSource tests that the endpoints of a paused source can't be edited.
+func TestEndpointEditPausedSource(t *testing.T) {
+	usePausedSource(t, fixtures.TestSourceData[0])
+
+	body, _ := json.Marshal(m.EndpointEditRequest{Host: request.PointerToString("example.com")})
+
+	c, rec := request.CreateTestContext(
+		http.MethodPatch,
+		"/api/sources/v3.1/endpoints/1",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	conflictEndpointEdit := ErrorHandlingContext(EndpointEdit)
+	err := conflictEndpointEdit(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.ConflictTest(t, rec)
+
+	if !strings.Contains(rec.Body.String(), "source 1 is paused") {
+		t.Errorf("want the error to point at the paused source, got %s", rec.Body.String())
+	}
+}
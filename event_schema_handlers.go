@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/RedHatInsights/sources-api-go/internal/eventschemas"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+)
+
+// EventSchemaList lists all the published event schemas, for every version.
+func EventSchemaList(c echo.Context) error {
+	versions, err := eventschemas.Versions()
+	if err != nil {
+		return err
+	}
+
+	out := make([]interface{}, 0)
+	for _, version := range versions {
+		for _, name := range eventschemas.Names() {
+			// older versions might not have every schema.
+			if _, err := eventschemas.Read(version, name); err != nil {
+				continue
+			}
+
+			out = append(out, map[string]interface{}{
+				"name":    name,
+				"version": version,
+				"link":    fmt.Sprintf("%s/%s/%s", c.Request().URL.Path, version, name),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, util.CollectionResponse(out, c.Request(), len(out), len(out), 0))
+}
+
+// EventSchemaGet returns the JSON Schema of the given event payload and version.
+func EventSchemaGet(c echo.Context) error {
+	schema, err := eventschemas.Read(c.Param("version"), c.Param("name"))
+	if err != nil {
+		return util.NewErrNotFound("event schema")
+	}
+
+	return c.JSONBlob(http.StatusOK, schema)
+}
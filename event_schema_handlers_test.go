@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/eventschemas"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+func TestEventSchemaList(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/event_schemas",
+		nil,
+		map[string]interface{}{},
+	)
+
+	err := EventSchemaList(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("want %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var out util.Collection
+	err = json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Error("Failed unmarshaling output")
+	}
+
+	versions, err := eventschemas.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every version keeps being published, so that the consumers can upgrade at their own pace.
+	if len(out.Data) != len(versions)*len(eventschemas.Names()) {
+		t.Errorf("want %d schemas, got %d", len(versions)*len(eventschemas.Names()), len(out.Data))
+	}
+
+	current := 0
+	for _, schema := range out.Data {
+		s, ok := schema.(map[string]interface{})
+		if !ok {
+			t.Error("model did not deserialize as an event schema")
+		}
+
+		if s["version"] == eventschemas.Version {
+			current++
+		}
+
+		want := "/api/sources/v3.1/event_schemas/" + s["version"].(string) + "/" + s["name"].(string)
+		if s["link"] != want {
+			t.Errorf("want link %q, got %q", want, s["link"])
+		}
+	}
+
+	if current != len(eventschemas.Names()) {
+		t.Errorf("want %d schemas of version %q, got %d", len(eventschemas.Names()), eventschemas.Version, current)
+	}
+}
+
+func TestEventSchemaGet(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/event_schemas/v1/Source",
+		nil,
+		map[string]interface{}{},
+	)
+
+	c.SetParamNames("version", "name")
+	c.SetParamValues("v1", "Source")
+
+	err := EventSchemaGet(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("want %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	want, err := eventschemas.Read("v1", "Source")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !bytes.Equal(want, rec.Body.Bytes()) {
+		t.Error("the returned schema doesn't match the published one")
+	}
+}
+
+func TestEventSchemaGetNotFound(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/api/sources/v3.1/event_schemas/v1/Unknown",
+		nil,
+		map[string]interface{}{},
+	)
+
+	c.SetParamNames("version", "name")
+	c.SetParamValues("v1", "Unknown")
+
+	notFoundEventSchemaGet := ErrorHandlingContext(EventSchemaGet)
+	err := notFoundEventSchemaGet(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.NotFoundTest(t, rec)
+}
@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 )
@@ -87,3 +89,39 @@ func getTenantFromEchoContext(c echo.Context) (int64, error) {
 		return 0, errors.New("the tenant was provided in an invalid format")
 	}
 }
+
+// getPauseRequest binds the optional payload of the pause calls, and checks that the resource isn't asked to be resumed
+// in the past.
+func getPauseRequest(c echo.Context) (*m.PauseRequest, error) {
+	pauseRequest := &m.PauseRequest{}
+	if err := c.Bind(pauseRequest); err != nil {
+		return nil, util.NewErrBadRequest(err)
+	}
+
+	if err := service.ValidatePauseRequest(pauseRequest); err != nil {
+		return nil, util.NewErrBadRequest(err)
+	}
+
+	return pauseRequest, nil
+}
+
+// scheduleResume schedules the resumption of the given paused resource, or cancels it when no time is given.
+func scheduleResume(c echo.Context, resourceType string, resourceId int64, resumeAt *time.Time) error {
+	tenantId, err := getTenantFromEchoContext(c)
+	if err != nil {
+		return err
+	}
+
+	return service.ScheduleResume(tenantId, resourceType, resourceId, resumeAt)
+}
+
+// checkNotPaused rejects the changes to the given resource when it is paused, either by itself or through the
+// resource it belongs to.
+func checkNotPaused(c echo.Context, resourceType string, resourceId int64) error {
+	tenantId, err := getTenantFromEchoContext(c)
+	if err != nil {
+		return err
+	}
+
+	return service.CheckNotPaused(tenantId, resourceType, resourceId)
+}
@@ -0,0 +1,163 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/google/uuid"
+)
+
+// Encodings supported for the messages sent to the event stream.
+const (
+	// LegacyEncoding sends the raw event JSON along with the "event_type" and "encoding" headers.
+	LegacyEncoding = "legacy"
+	// CloudEventsBinaryEncoding sends the raw event JSON as the value, and the CloudEvents attributes as "ce_" headers.
+	CloudEventsBinaryEncoding = "cloudevents-binary"
+	// CloudEventsStructuredEncoding sends the whole CloudEvent, attributes and data, as a JSON document.
+	CloudEventsStructuredEncoding = "cloudevents-structured"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	// cloudEventsSource identifies this service as the context in which the events happened.
+	cloudEventsSource = "urn:redhat:source:sources-api"
+	// cloudEventsTypePrefix gets prepended to the event type, which results in types such as
+	// "com.redhat.sources.Source.create".
+	cloudEventsTypePrefix = "com.redhat.sources."
+	// cloudEventsStructuredContentType is the content type that Kafka messages in structured mode must have.
+	cloudEventsStructuredContentType = "application/cloudevents+json"
+	jsonContentType                  = "application/json"
+)
+
+// CloudEvent represents a CloudEvents 1.0 event, as it gets sent when using the structured mode. The "rhaccount" and
+// "rhorgid" attributes are extension attributes which hold the tenant the event belongs to.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	RhAccount       string          `json:"rhaccount,omitempty"`
+	RhOrgId         string          `json:"rhorgid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewCloudEvent builds a CloudEvent out of the event type, the payload that is going to be sent and the headers that
+// are being forwarded, which contain the tenant.
+func NewCloudEvent(eventType string, payload []byte, headers []kafka.Header) *CloudEvent {
+	// a missing tenant shouldn't prevent the event from being sent, as the tenant is just an extension.
+	accountNumber, orgId, _ := util.TenantFromHeaders(headers)
+
+	return &CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              uuid.New().String(),
+		Source:          cloudEventsSource,
+		Type:            cloudEventsTypePrefix + eventType,
+		Subject:         subjectFromPayload(payload),
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: jsonContentType,
+		RhAccount:       accountNumber,
+		RhOrgId:         orgId,
+		Data:            payload,
+	}
+}
+
+// BinaryHeaders returns the CloudEvent's attributes as Kafka headers, following the binary content mode of the Kafka
+// protocol binding.
+func (ce *CloudEvent) BinaryHeaders() []kafka.Header {
+	headers := []kafka.Header{
+		{Key: "ce_specversion", Value: []byte(ce.SpecVersion)},
+		{Key: "ce_id", Value: []byte(ce.ID)},
+		{Key: "ce_source", Value: []byte(ce.Source)},
+		{Key: "ce_type", Value: []byte(ce.Type)},
+		{Key: "ce_time", Value: []byte(ce.Time)},
+		{Key: "content-type", Value: []byte(ce.DataContentType)},
+	}
+
+	if ce.Subject != "" {
+		headers = append(headers, kafka.Header{Key: "ce_subject", Value: []byte(ce.Subject)})
+	}
+
+	if ce.RhAccount != "" {
+		headers = append(headers, kafka.Header{Key: "ce_rhaccount", Value: []byte(ce.RhAccount)})
+	}
+
+	if ce.RhOrgId != "" {
+		headers = append(headers, kafka.Header{Key: "ce_rhorgid", Value: []byte(ce.RhOrgId)})
+	}
+
+	return headers
+}
+
+// subjectFromPayload extracts the ID of the resource the event is about. For the "Records.*" bulk messages, that is
+// the ID of the source the records belong to.
+func subjectFromPayload(payload []byte) string {
+	var event map[string]interface{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return ""
+	}
+
+	if source, ok := event["source"].(map[string]interface{}); ok {
+		event = source
+	}
+
+	switch id := event["id"].(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatInt(int64(id), 10)
+	default:
+		return ""
+	}
+}
+
+// encodeMessage builds the Kafka message for the given event according to the configured encoding. The unknown
+// encodings are rejected, even though the configuration doesn't load with one.
+func encodeMessage(encoding string, eventType string, payload []byte, headers []kafka.Header) (*kafka.Message, error) {
+	m := &kafka.Message{}
+
+	switch encoding {
+	case CloudEventsBinaryEncoding:
+		ce := NewCloudEvent(eventType, payload, headers)
+
+		m.AddHeaders(append(legacyHeaders(eventType, headers), ce.BinaryHeaders()...))
+		m.AddValue(payload)
+	case CloudEventsStructuredEncoding:
+		ce := NewCloudEvent(eventType, payload, headers)
+
+		headers = legacyHeaders(eventType, headers)
+
+		m.AddHeaders(append(headers, kafka.Header{Key: "content-type", Value: []byte(cloudEventsStructuredContentType)}))
+		err := m.AddValueAsJSON(ce)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal the cloud event: %w", err)
+		}
+	case LegacyEncoding:
+		headers = legacyHeaders(eventType, headers)
+
+		m.AddHeaders(append(headers, kafka.Header{Key: "encoding", Value: []byte("json")}))
+		m.AddValue(payload)
+	default:
+		return nil, fmt.Errorf("unknown event stream encoding %q", encoding)
+	}
+
+	return m, nil
+}
+
+// legacyHeaders overrides the "event_type" header with the given event type, if present.
+func legacyHeaders(eventType string, headers []kafka.Header) []kafka.Header {
+	for index, header := range headers {
+		if header.Key == "event_type" {
+			headers[index] = kafka.Header{Key: "event_type", Value: []byte(eventType)}
+			break
+		}
+	}
+
+	return headers
+}
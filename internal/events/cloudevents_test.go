@@ -0,0 +1,134 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/kafka"
+)
+
+var testPayload = []byte(`{"id":12,"name":"my source","tenant":"12345"}`)
+
+func testHeaders() []kafka.Header {
+	return []kafka.Header{
+		{Key: "x-rh-sources-account-number", Value: []byte("12345")},
+		{Key: "x-rh-sources-org-id", Value: []byte("67890")},
+		{Key: "event_type", Value: []byte("Source.create")},
+	}
+}
+
+// TestLegacyEncoding tests that the legacy encoding keeps sending the raw payload with the "encoding" header.
+func TestLegacyEncoding(t *testing.T) {
+	m, err := encodeMessage(LegacyEncoding, "Source.create", testPayload, testHeaders())
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if string(m.Value) != string(testPayload) {
+		t.Errorf("want %s, got %s", testPayload, m.Value)
+	}
+
+	if m.GetHeader("encoding") != "json" {
+		t.Errorf(`want "json" encoding header, got %q`, m.GetHeader("encoding"))
+	}
+
+	if m.GetHeader("ce_type") != "" {
+		t.Errorf("want no cloud events headers, got %q", m.GetHeader("ce_type"))
+	}
+}
+
+// TestUnknownEncodingIsRejected tests that an empty or unknown encoding doesn't silently fall back to the legacy one.
+func TestUnknownEncodingIsRejected(t *testing.T) {
+	for _, encoding := range []string{"", "cloudevents"} {
+		_, err := encodeMessage(encoding, "Source.create", testPayload, testHeaders())
+		if err == nil {
+			t.Errorf("want error for the %q encoding, got none", encoding)
+		}
+	}
+}
+
+// TestCloudEventsBinaryEncoding tests that the binary mode sends the attributes as "ce_" headers and leaves the
+// payload untouched.
+func TestCloudEventsBinaryEncoding(t *testing.T) {
+	m, err := encodeMessage(CloudEventsBinaryEncoding, "Source.update", testPayload, testHeaders())
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if string(m.Value) != string(testPayload) {
+		t.Errorf("want %s, got %s", testPayload, m.Value)
+	}
+
+	want := map[string]string{
+		"ce_specversion": "1.0",
+		"ce_source":      cloudEventsSource,
+		"ce_type":        "com.redhat.sources.Source.update",
+		"ce_subject":     "12",
+		"ce_rhaccount":   "12345",
+		"ce_rhorgid":     "67890",
+		"content-type":   "application/json",
+		"event_type":     "Source.update",
+	}
+
+	for header, value := range want {
+		if m.GetHeader(header) != value {
+			t.Errorf("want header %q to be %q, got %q", header, value, m.GetHeader(header))
+		}
+	}
+
+	if m.GetHeader("ce_id") == "" {
+		t.Error("want a ce_id header, got none")
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, m.GetHeader("ce_time")); err != nil {
+		t.Errorf("want a RFC3339 ce_time header, got %q: %s", m.GetHeader("ce_time"), err)
+	}
+}
+
+// TestCloudEventsStructuredEncoding tests that the structured mode sends the whole event as the message's value.
+func TestCloudEventsStructuredEncoding(t *testing.T) {
+	m, err := encodeMessage(CloudEventsStructuredEncoding, "Source.destroy", testPayload, testHeaders())
+	if err != nil {
+		t.Fatalf("want no error, got %s", err)
+	}
+
+	if m.GetHeader("content-type") != "application/cloudevents+json" {
+		t.Errorf(`want "application/cloudevents+json" content type, got %q`, m.GetHeader("content-type"))
+	}
+
+	var ce CloudEvent
+	if err := json.Unmarshal(m.Value, &ce); err != nil {
+		t.Fatalf("unable to unmarshal the cloud event: %s", err)
+	}
+
+	if ce.SpecVersion != "1.0" || ce.Type != "com.redhat.sources.Source.destroy" || ce.Subject != "12" || ce.RhAccount != "12345" || ce.RhOrgId != "67890" {
+		t.Errorf("unexpected cloud event attributes: %+v", ce)
+	}
+
+	if ce.ID == "" || ce.Time == "" || ce.Source != cloudEventsSource {
+		t.Errorf("missing required cloud event attributes: %+v", ce)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(ce.Data, &data); err != nil {
+		t.Fatalf("unable to unmarshal the cloud event's data: %s", err)
+	}
+
+	if data["name"] != "my source" {
+		t.Errorf(`want the original payload as data, got %s`, ce.Data)
+	}
+}
+
+// TestSubjectFromBulkMessage tests that the subject of the "Records.*" messages is the source's ID.
+func TestSubjectFromBulkMessage(t *testing.T) {
+	payload := []byte(`{"source":{"id":7},"applications":[{"id":1}]}`)
+
+	if subject := subjectFromPayload(payload); subject != "7" {
+		t.Errorf(`want subject "7", got %q`, subject)
+	}
+
+	if subject := subjectFromPayload([]byte(`{"id":"uuid-like-id"}`)); subject != "uuid-like-id" {
+		t.Errorf(`want subject "uuid-like-id", got %q`, subject)
+	}
+}
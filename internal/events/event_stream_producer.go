@@ -3,6 +3,7 @@ package events
 import (
 	c "github.com/RedHatInsights/sources-api-go/config"
 	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/internal/eventschemas"
 	"github.com/RedHatInsights/sources-api-go/kafka"
 	logging "github.com/RedHatInsights/sources-api-go/logger"
 	m "github.com/RedHatInsights/sources-api-go/model"
@@ -25,26 +26,32 @@ type EventStreamSender struct {
 }
 
 func (esp *EventStreamSender) RaiseEvent(eventType string, payload []byte, headers []kafka.Header) error {
+	// refuse to send payloads which break the published contract, when asked to.
+	if config.EventStreamValidation {
+		err := eventschemas.Validate(eventType, payload)
+		if err != nil {
+			return err
+		}
+	}
+
 	logging.Log.Debugf("publishing message to topic %q...", EventStreamTopic)
 
 	producerConfig := kafka.ProducerConfig{Topic: config.KafkaTopic(EventStreamTopic)}
 	kafkaConfig := kafka.Config{KafkaBrokers: config.KafkaBrokers, ProducerConfig: producerConfig}
 	kf := &kafka.Manager{Config: kafkaConfig}
-
-	m := &kafka.Message{}
-
-	for index, header := range headers {
-		if header.Key == "event_type" {
-			headers[index] = kafka.Header{Key: "event_type", Value: []byte(eventType)}
-			break
+	// closing the manager flushes the producer, so that no messages are lost when the process gets shut down.
+	defer func() {
+		if err := kf.Close(); err != nil {
+			logging.Log.Warnf("failed to close the producer for topic %q: %s", EventStreamTopic, err)
 		}
-	}
-	headers = append(headers, kafka.Header{Key: "encoding", Value: []byte("json")})
+	}()
 
-	m.AddHeaders(headers)
-	m.AddValue(payload)
+	m, err := encodeMessage(config.EventStreamEncoding, eventType, payload, headers)
+	if err != nil {
+		return err
+	}
 
-	err := kf.Produce(m)
+	err = kf.Produce(m)
 	if err != nil {
 		return err
 	}
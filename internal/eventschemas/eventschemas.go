@@ -0,0 +1,299 @@
+package eventschemas
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+//go:generate go run generate.go
+
+// Version is the current version of the event schemas. It must be bumped —and the schemas regenerated under a new
+// directory— whenever a backwards incompatible change is made to any of the event payloads.
+const Version = "v2"
+
+// RecordsSchemaName is the name of the schema of the "Records.*" bulk messages.
+const RecordsSchemaName = "Records"
+
+const (
+	draft    = "http://json-schema.org/draft-07/schema#"
+	idFormat = "https://console.redhat.com/api/sources/v3.1/event_schemas/%s/%s"
+)
+
+//go:embed schemas
+var schemasFS embed.FS
+
+// Schema represents a JSON Schema document.
+type Schema map[string]interface{}
+
+// eventPayloads holds the structs that get marshalled into the payloads of the "<Resource>.<action>" events, keyed by
+// the resource name.
+var eventPayloads = map[string]interface{}{
+	"Application":               m.ApplicationEvent{},
+	"ApplicationAuthentication": m.ApplicationAuthenticationEvent{},
+	"Authentication":            m.AuthenticationEvent{},
+	"Endpoint":                  m.EndpointEvent{},
+	"RhcConnection":             m.RhcConnectionEvent{},
+	"Source":                    m.SourceEvent{},
+}
+
+// recordsDefinitions are the resources that a bulk message is made of.
+var recordsDefinitions = []string{"Application", "ApplicationAuthentication", "Authentication", "Endpoint", "RhcConnection", "Source"}
+
+// Names returns the sorted names of all the schemas.
+func Names() []string {
+	names := []string{RecordsSchemaName}
+	for name := range eventPayloads {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NameForEventType returns the name of the schema the payload of the given event type must comply with. For example,
+// both "Source.create" and "Source.Pause" map to "Source", and "Records.update" maps to "Records".
+func NameForEventType(eventType string) string {
+	return strings.SplitN(eventType, ".", 2)[0]
+}
+
+// Read returns the embedded schema with the given version and name.
+func Read(version, name string) ([]byte, error) {
+	return schemasFS.ReadFile(fmt.Sprintf("schemas/%s/%s.json", version, name))
+}
+
+// Versions returns the sorted versions of the embedded schemas.
+func Versions() ([]string, error) {
+	entries, err := schemasFS.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	sort.Strings(versions)
+
+	return versions, nil
+}
+
+// Validate validates the payload of the given event type against the embedded schema of the current version.
+func Validate(eventType string, payload []byte) error {
+	schema, err := Read(Version, NameForEventType(eventType))
+	if err != nil {
+		return fmt.Errorf("no schema found for event type %q: %w", eventType, err)
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to validate the payload of event type %q: %w", eventType, err)
+	}
+
+	if !result.Valid() {
+		violations := make([]string, len(result.Errors()))
+		for i, violation := range result.Errors() {
+			violations[i] = violation.String()
+		}
+
+		return fmt.Errorf("invalid payload for event type %q: %s", eventType, strings.Join(violations, "; "))
+	}
+
+	return nil
+}
+
+// Generate builds the schema with the given name out of the event payload structs.
+func Generate(name string) (Schema, error) {
+	var schema Schema
+
+	if name == RecordsSchemaName {
+		schema = recordsSchema()
+	} else {
+		payload, ok := eventPayloads[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown event payload %q", name)
+		}
+
+		schema = structSchema(reflect.TypeOf(payload))
+	}
+
+	schema["$schema"] = draft
+	schema["$id"] = fmt.Sprintf(idFormat, Version, name)
+	schema["title"] = name
+
+	return schema, nil
+}
+
+// recordsSchema builds the schema of the bulk messages, which contain the source and all its related resources. The
+// "updated" property is only present in the "Records.update" messages.
+func recordsSchema() Schema {
+	definitions := Schema{}
+	for _, name := range recordsDefinitions {
+		definitions[name] = structSchema(reflect.TypeOf(eventPayloads[name]))
+	}
+
+	arrayOf := func(name string) Schema {
+		return Schema{"type": "array", "items": Schema{"$ref": "#/definitions/" + name}}
+	}
+
+	return Schema{
+		"type": "object",
+		"properties": Schema{
+			"source":                      Schema{"$ref": "#/definitions/Source"},
+			"endpoints":                   arrayOf("Endpoint"),
+			"applications":                arrayOf("Application"),
+			"authentications":             arrayOf("Authentication"),
+			"application_authentications": arrayOf("ApplicationAuthentication"),
+			"rhc_connections":             arrayOf("RhcConnection"),
+			// {"<ResourceType>": {"<id>": ["<updated attribute>", ...]}}
+			"updated": Schema{
+				"type": "object",
+				"additionalProperties": Schema{
+					"type": "object",
+					"additionalProperties": Schema{
+						"type":  "array",
+						"items": Schema{"type": "string"},
+					},
+				},
+			},
+		},
+		"required":             []string{"source", "endpoints", "applications", "authentications", "application_authentications"},
+		"additionalProperties": false,
+		"definitions":          definitions,
+	}
+}
+
+// structSchema builds an object schema out of the struct's exported fields, using their JSON names. The fields of the
+// embedded structs are flattened, just like "encoding/json" does.
+func structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := make([]string, 0)
+
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				collect(field.Type)
+				continue
+			}
+
+			name, omitEmpty := jsonName(field)
+			if name == "" {
+				continue
+			}
+
+			properties[name] = typeSchema(field.Type)
+			if !omitEmpty {
+				required = append(required, name)
+			}
+		}
+	}
+	collect(t)
+
+	sort.Strings(required)
+
+	return Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonName returns the name the field gets marshalled with, or an empty string if the field is skipped.
+func jsonName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	parts := strings.Split(tag, ",")
+
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	omitEmpty := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// typeSchema returns the schema of a single field. Pointers, maps and slices are nullable since "encoding/json"
+// marshals their nil values as "null".
+func typeSchema(t reflect.Type) Schema {
+	nullable := false
+	if t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	var (
+		typeName string
+		schema   = Schema{}
+	)
+
+	switch {
+	case t == timeType:
+		typeName = "string"
+		schema["format"] = "date-time"
+	case t.Implements(jsonMarshalerType):
+		// raw JSON documents such as "datatypes.JSON" can hold any value.
+		return schema
+	case t.Kind() == reflect.String:
+		typeName = "string"
+	case t.Kind() == reflect.Bool:
+		typeName = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		typeName = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		typeName = "number"
+	case t.Kind() == reflect.Map:
+		nullable = true
+		typeName = "object"
+	case t.Kind() == reflect.Slice:
+		nullable = true
+		typeName = "array"
+		schema["items"] = typeSchema(t.Elem())
+	case t.Kind() == reflect.Struct:
+		schema = structSchema(t)
+		typeName = "object"
+	default:
+		return schema
+	}
+
+	if nullable {
+		schema["type"] = []string{typeName, "null"}
+	} else {
+		schema["type"] = typeName
+	}
+
+	return schema
+}
@@ -0,0 +1,139 @@
+package eventschemas
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// TestSchemasAreUpToDate tests that the embedded schemas match the event payload structs, so that any change to the
+// payloads gets reflected in the published contract. Run "go generate ./internal/eventschemas/" to fix it.
+func TestSchemasAreUpToDate(t *testing.T) {
+	for _, name := range Names() {
+		generated, err := Generate(name)
+		if err != nil {
+			t.Fatalf("unable to generate the schema for %q: %s", name, err)
+		}
+
+		embedded, err := Read(Version, name)
+		if err != nil {
+			t.Fatalf("missing embedded schema for %q: %s", name, err)
+		}
+
+		// round trip the generated schema so that both of them have the same types.
+		raw, err := json.Marshal(generated)
+		if err != nil {
+			t.Fatalf("unable to marshal the schema for %q: %s", name, err)
+		}
+
+		var want, got interface{}
+		if err := json.Unmarshal(raw, &want); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := json.Unmarshal(embedded, &got); err != nil {
+			t.Fatalf("invalid embedded schema for %q: %s", name, err)
+		}
+
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf(`the embedded schema for %q is out of date, run "go generate ./internal/eventschemas/"`, name)
+		}
+	}
+}
+
+// TestEventPayloadsMatchSchemas tests that the payloads generated by the models comply with their schemas.
+func TestEventPayloadsMatchSchemas(t *testing.T) {
+	authentication := fixtures.TestAuthenticationData[0]
+	applicationAuthentication := fixtures.TestApplicationAuthenticationData[0]
+
+	events := map[string]m.Event{
+		"Source.create":        &fixtures.TestSourceData[0],
+		"Application.update":   &fixtures.TestApplicationData[0],
+		"Endpoint.destroy":     &fixtures.TestEndpointData[0],
+		"RhcConnection.create": &fixtures.TestRhcConnectionData[0],
+	}
+
+	payloads := map[string]interface{}{
+		"Authentication.create":            authentication.ToEvent(),
+		"ApplicationAuthentication.create": applicationAuthentication.ToEvent(),
+	}
+
+	for eventType, event := range events {
+		payloads[eventType] = event.ToEvent()
+	}
+
+	for eventType, payload := range payloads {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("unable to marshal the %q payload: %s", eventType, err)
+		}
+
+		if err := Validate(eventType, raw); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// TestBulkMessagesMatchSchema tests that the bulk messages the status listener tests expect comply with the
+// "Records" schema.
+func TestBulkMessagesMatchSchema(t *testing.T) {
+	files, err := filepath.Glob("../../statuslistener/test_data/bulk_message_*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("no bulk messages found to validate")
+	}
+
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Validate("Records.update", raw); err != nil {
+			t.Errorf("%s: %s", file, err)
+		}
+	}
+}
+
+// TestValidateRejectsInvalidPayloads tests that payloads with unknown properties or wrong types are rejected.
+func TestValidateRejectsInvalidPayloads(t *testing.T) {
+	invalidPayloads := []string{
+		`{"id": "not an integer"}`,
+		`{"unknown_attribute": true}`,
+		`"not even an object"`,
+	}
+
+	for _, payload := range invalidPayloads {
+		if err := Validate("Source.update", []byte(payload)); err == nil {
+			t.Errorf("want error for payload %s, got none", payload)
+		}
+	}
+
+	if err := Validate("Unknown.create", []byte(`{}`)); err == nil {
+		t.Error("want error for an unknown event type, got none")
+	}
+}
+
+// TestNameForEventType tests that the event types get mapped to the right schema.
+func TestNameForEventType(t *testing.T) {
+	want := map[string]string{
+		"Source.create":  "Source",
+		"Source.Pause":   "Source",
+		"Records.update": "Records",
+		"Application":    "Application",
+	}
+
+	for eventType, name := range want {
+		if got := NameForEventType(eventType); got != name {
+			t.Errorf("want %q for event type %q, got %q", name, eventType, got)
+		}
+	}
+}
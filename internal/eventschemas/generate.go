@@ -0,0 +1,42 @@
+//go:build ignore
+// +build ignore
+
+// This program regenerates the event schemas of the current version out of the event payload structs. Run it with
+// "go generate ./internal/eventschemas/".
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/RedHatInsights/sources-api-go/internal/eventschemas"
+)
+
+func main() {
+	directory := filepath.Join("schemas", eventschemas.Version)
+
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		log.Fatalf("unable to create the schemas directory: %s", err)
+	}
+
+	for _, name := range eventschemas.Names() {
+		schema, err := eventschemas.Generate(name)
+		if err != nil {
+			log.Fatalf("unable to generate the schema for %q: %s", name, err)
+		}
+
+		out, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			log.Fatalf("unable to marshal the schema for %q: %s", name, err)
+		}
+
+		err = os.WriteFile(filepath.Join(directory, fmt.Sprintf("%s.json", name)), append(out, '\n'), 0644)
+		if err != nil {
+			log.Fatalf("unable to write the schema for %q: %s", name, err)
+		}
+	}
+}
@@ -44,6 +44,8 @@ func ConnectToTestDB(dbSchema string) {
 // - Application
 // - Endpoint
 // - MetaData
+// - AvailabilityCheck
+// - AvailabilityStatusHistory
 func CreateFixtures() {
 	dao.DB.Create(&fixtures.TestTenantData)
 
@@ -61,6 +63,9 @@ func CreateFixtures() {
 
 	dao.DB.Create(&fixtures.TestMetaDataData)
 
+	dao.DB.Create(&fixtures.TestAvailabilityCheckData)
+	dao.DB.Create(&fixtures.TestAvailabilityStatusHistoryData)
+
 	UpdateTablesSequences()
 }
 
@@ -106,6 +111,13 @@ func MigrateSchema() {
 
 		&m.Endpoint{},
 		&m.MetaData{},
+
+		&m.AvailabilityCheck{},
+		&m.AvailabilityCheckTarget{},
+		&m.AvailabilityStatusHistory{},
+		&m.EndpointCertificate{},
+		&m.ScheduledResume{},
+		&m.AuditLog{},
 	)
 
 	if err != nil {
@@ -123,6 +135,9 @@ func UpdateTablesSequences() {
 		"meta_data",
 		"applications",
 		"application_authentications",
+		"availability_checks",
+		"availability_check_targets",
+		"availability_status_history",
 		"application_types",
 		"rhc_connections",
 		"sources",
@@ -0,0 +1,38 @@
+package fixtures
+
+import (
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+var availabilityCheckDispatchedAt = time.Date(2022, 1, 19, 11, 57, 23, 0, time.UTC)
+
+var TestAvailabilityCheckData = []m.AvailabilityCheck{
+	{
+		ID:       1,
+		SourceID: 1,
+		TenantID: 1,
+		Targets: []m.AvailabilityCheckTarget{
+			{
+				ID:                  1,
+				AvailabilityCheckID: 1,
+				TenantID:            1,
+				ResourceType:        "Application",
+				ResourceID:          "1",
+				DispatchStatus:      m.DispatchSent,
+				ResponseCode:        202,
+				DispatchedAt:        &availabilityCheckDispatchedAt,
+			},
+			{
+				ID:                  2,
+				AvailabilityCheckID: 1,
+				TenantID:            1,
+				ResourceType:        "Endpoint",
+				ResourceID:          "1",
+				DispatchStatus:      m.DispatchSkipped,
+				DispatchedAt:        &availabilityCheckDispatchedAt,
+			},
+		},
+	},
+}
@@ -0,0 +1,40 @@
+package fixtures
+
+import (
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+var TestAvailabilityStatusHistoryData = []m.AvailabilityStatusHistory{
+	{
+		ID:           1,
+		CreatedAt:    time.Date(2022, 1, 20, 10, 0, 0, 0, time.UTC),
+		TenantID:     1,
+		ResourceType: "Source",
+		ResourceID:   "1",
+		Status:       m.Available,
+		Origin:       m.AvailabilityStatusOriginDerived,
+	},
+	{
+		ID:             2,
+		CreatedAt:      time.Date(2022, 1, 21, 10, 0, 0, 0, time.UTC),
+		TenantID:       1,
+		ResourceType:   "Source",
+		ResourceID:     "1",
+		Status:         m.Unavailable,
+		PreviousStatus: m.Available,
+		StatusError:    "the ARN is not valid",
+		Origin:         m.AvailabilityStatusOriginDerived,
+	},
+	{
+		ID:           3,
+		CreatedAt:    time.Date(2022, 1, 21, 10, 0, 0, 0, time.UTC),
+		TenantID:     1,
+		ResourceType: "Application",
+		ResourceID:   "1",
+		Status:       m.Unavailable,
+		StatusError:  "the ARN is not valid",
+		Origin:       m.AvailabilityStatusOriginStatusMessage,
+	},
+}
@@ -0,0 +1,14 @@
+package fixtures
+
+// TestCertificateAuthority is a self-signed certificate authority, valid for a hundred years, for the endpoints.
+const TestCertificateAuthority = `-----BEGIN CERTIFICATE-----
+MIIBjDCCATGgAwIBAgIUJ/geM69M2u0xvkIcYiPNrBSA7LowCgYIKoZIzj0EAwIw
+GjEYMBYGA1UEAwwPU291cmNlcyBUZXN0IENBMCAXDTI2MTAxODIzNDQzOFoYDzIx
+MjYwOTI0MjM0NDM4WjAaMRgwFgYDVQQDDA9Tb3VyY2VzIFRlc3QgQ0EwWTATBgcq
+hkjOPQIBBggqhkjOPQMBBwNCAASaRqQr23yyXy+5k8JhJdjZKl3ZVSzqYOr7Wh2O
+jn97oO07sR3x4jA8WlDPDlSA9cgaL2OEK6iSBIkF5nStDuLNo1MwUTAdBgNVHQ4E
+FgQUn0FJ+R5RWGKudz5fiOOOjX5y2d0wHwYDVR0jBBgwFoAUn0FJ+R5RWGKudz5f
+iOOOjX5y2d0wDwYDVR0TAQH/BAUwAwEB/zAKBggqhkjOPQQDAgNJADBGAiEAzvSd
+k/jL3oGzhiLtsvww1G36ZluTbP6JkDBqetNYdKwCIQCAlhUVTRp7zVVY3g6TxRpS
+ND63QuAgXJOl3YDNT140xA==
+-----END CERTIFICATE-----`
@@ -68,3 +68,28 @@ func BadRequestTest(t *testing.T, rec *httptest.ResponseRecorder) {
 		}
 	}
 }
+
+func ConflictTest(t *testing.T, rec *httptest.ResponseRecorder) {
+	if rec.Code != 409 {
+		t.Error(fmt.Sprintf("Wrong return code: expected 409, got %d", rec.Code))
+	}
+
+	var out util.ErrorDocument
+	err := json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Error("Failed unmarshaling output")
+	}
+
+	if len(out.Errors) == 0 {
+		t.Error("Error message is empty")
+	}
+
+	for _, src := range out.Errors {
+		if !strings.HasPrefix(src.Detail, "conflict") {
+			t.Error(fmt.Sprintf("Wrong error message: expected prefix 'conflict' in '%s'", src.Detail))
+		}
+		if src.Status != "409" {
+			t.Error(fmt.Sprintf("Wrong error status: expected 409, got %s", src.Status))
+		}
+	}
+}
@@ -5,4 +5,6 @@ type StatusMessage struct {
 	ResourceID   string `json:"resource_id"`
 	Status       string `json:"status"`
 	Error        string `json:"error"`
+	// AvailabilityCheckID is the id of the availability check that requested the status, when the sender knows it.
+	AvailabilityCheckID string `json:"availability_check_id,omitempty"`
 }
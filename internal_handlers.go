@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 )
@@ -17,7 +18,7 @@ func InternalAuthenticationGet(c echo.Context) error {
 
 	auth, err := authDao.GetById(c.Param("uuid"))
 	if err != nil {
-		return c.JSON(http.StatusNotFound, util.ErrorDoc(err.Error(), "404"))
+		return c.JSON(http.StatusNotFound, util.ErrorDoc(c.Request().Context(), err.Error(), "404"))
 	}
 
 	exposeEncryptedAttribute := c.QueryParam("expose_encrypted_attribute[]")
@@ -42,7 +43,7 @@ func InternalSourceList(c echo.Context) error {
 	}
 
 	// The DAO doesn't need a tenant set, since the queries won't be filtered by that tenant
-	sourcesDB := dao.GetSourceDao(nil)
+	sourcesDB := dao.GetSourceDao(nil).WithContext(c.Request().Context())
 	sources, count, err := sourcesDB.ListInternal(limit, offset, filters)
 
 	if err != nil {
@@ -56,3 +57,57 @@ func InternalSourceList(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, util.CollectionResponse(out, c.Request(), int(count), limit, offset))
 }
+
+// InternalTenantReplay re-emits the current state of the requesting tenant to the event stream, so that the
+// downstream applications which lost their state can rebuild it. The replay can be limited to the sources of a given
+// application type with the "application_type" query parameter, which accepts either the type's id or its name. The
+// replay runs in the background, and its result gets logged once it finishes.
+func InternalTenantReplay(c echo.Context) error {
+	accountNumber, orgId, err := util.TenantFromHeaders(service.ForwadableHeaders(c))
+	if err != nil {
+		return util.NewErrBadRequest(err)
+	}
+
+	tenant, err := dao.GetTenantDao().TenantByIdentifiers(accountNumber, orgId)
+	if err != nil {
+		return util.NewErrNotFound("tenant")
+	}
+
+	var applicationTypeId int64
+	if c.QueryParam("application_type") != "" {
+		applicationTypeId, err = service.ApplicationTypeIdFrom(c.QueryParam("application_type"))
+		if err != nil {
+			return err
+		}
+	}
+
+	service.ReplayTenantInBackground(tenant, service.ReplayOptionsFromConfig(applicationTypeId))
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// InternalAuditLogList lists the audit log entries of every tenant, newest first. The entries can be narrowed down with
+// the usual filters, such as "filter[actor]", "filter[resource_type]" or "filter[created_at][gte]".
+func InternalAuditLogList(c echo.Context) error {
+	filters, err := getFilters(c)
+	if err != nil {
+		return err
+	}
+
+	limit, offset, err := getLimitAndOffset(c)
+	if err != nil {
+		return err
+	}
+
+	logs, count, err := dao.GetAuditLogDao().List(limit, offset, filters)
+	if err != nil {
+		return err
+	}
+
+	out := make([]interface{}, len(logs))
+	for i := 0; i < len(logs); i++ {
+		out[i] = logs[i].ToResponse()
+	}
+
+	return c.JSON(http.StatusOK, util.CollectionResponse(out, c.Request(), int(count), limit, offset))
+}
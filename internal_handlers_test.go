@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/RedHatInsights/sources-api-go/dao"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	m "github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
 )
 
@@ -107,3 +109,55 @@ func TestSourceListInternalBadRequestInvalidFilter(t *testing.T) {
 
 	testutils.BadRequestTest(t, rec)
 }
+
+// TestAuditLogListInternal tests that the audit log entries get listed newest first.
+func TestAuditLogListInternal(t *testing.T) {
+	tenantId := int64(1)
+	auditLog := &dao.MockAuditLogDao{Logs: []m.AuditLog{
+		{ID: 1, TenantID: &tenantId, ActorType: m.AuditActorPsk, Actor: "cost", Method: http.MethodPost, Route: "/sources", ResourceType: "source", ResourceID: "1", Fields: "name,source_type_id", Status: http.StatusCreated, Outcome: m.AuditOutcomeSuccess},
+		{ID: 2, ActorType: m.AuditActorUser, Actor: "jdoe", Method: http.MethodDelete, Route: "/sources/:id", ResourceType: "source", ResourceID: "1", Status: http.StatusUnauthorized, Outcome: m.AuditOutcomeDenied},
+	}}
+
+	original := dao.GetAuditLogDao
+	dao.GetAuditLogDao = func() dao.AuditLogDao { return auditLog }
+	t.Cleanup(func() { dao.GetAuditLogDao = original })
+
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/internal/v2.0/audit_logs",
+		nil,
+		map[string]interface{}{
+			"limit":   100,
+			"offset":  0,
+			"filters": []util.Filter{},
+		})
+
+	err := InternalAuditLogList(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf(`want status "%d", got "%d"`, http.StatusOK, rec.Code)
+	}
+
+	var out struct {
+		Data []m.AuditLogResponse `json:"data"`
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Fatalf("Failed unmarshalling output: %s", err)
+	}
+
+	if len(out.Data) != 2 || out.Data[0].ID != "2" || out.Data[1].ID != "1" {
+		t.Fatalf(`want the entries newest first, got "%+v"`, out.Data)
+	}
+
+	if out.Data[0].TenantID != "" || len(out.Data[0].Fields) != 0 {
+		t.Errorf(`want no tenant nor fields, got "%+v"`, out.Data[0])
+	}
+
+	if out.Data[1].TenantID != "1" || len(out.Data[1].Fields) != 2 || out.Data[1].Fields[0] != "name" {
+		t.Errorf(`want the tenant and the fields, got "%+v"`, out.Data[1])
+	}
+}
@@ -0,0 +1,61 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// commitOrder commits the offsets of the messages which get processed concurrently in the order they were fetched.
+// Committing an offset marks every earlier message of the partition as consumed, so a message only gets committed
+// once all the earlier messages of its partition are processed too. Otherwise a crash would skip the messages that
+// were still being processed.
+type commitOrder struct {
+	mutex      sync.Mutex
+	partitions map[int][]*pendingMessage
+}
+
+// pendingMessage is a fetched message whose offset hasn't been committed yet.
+type pendingMessage struct {
+	message   kafka.Message
+	processed bool
+}
+
+func newCommitOrder() *commitOrder {
+	return &commitOrder{partitions: make(map[int][]*pendingMessage)}
+}
+
+// fetched queues the message for its commit. The messages must be queued in the order they get fetched.
+func (co *commitOrder) fetched(message kafka.Message) *pendingMessage {
+	co.mutex.Lock()
+	defer co.mutex.Unlock()
+
+	pending := &pendingMessage{message: message}
+	co.partitions[message.Partition] = append(co.partitions[message.Partition], pending)
+
+	return pending
+}
+
+// processed marks the message as processed, and commits the last message of the partition whose earlier messages are
+// all processed, if any. The commits are serialized, so that the committed offsets never go back.
+func (co *commitOrder) processed(pending *pendingMessage, commit func(kafka.Message) error) error {
+	co.mutex.Lock()
+	defer co.mutex.Unlock()
+
+	pending.processed = true
+
+	queue := co.partitions[pending.message.Partition]
+
+	done := 0
+	for done < len(queue) && queue[done].processed {
+		done++
+	}
+
+	if done == 0 {
+		return nil
+	}
+
+	co.partitions[pending.message.Partition] = queue[done:]
+
+	return commit(queue[done-1].message)
+}
@@ -3,17 +3,20 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"sync"
 
+	l "github.com/RedHatInsights/sources-api-go/logger"
 	"github.com/segmentio/kafka-go"
 )
 
 func (manager *Manager) Produce(message *Message) error {
-	if manager.Producer() == nil {
+	producer := manager.Producer()
+	if producer == nil {
 		return fmt.Errorf("producer is not initialized")
 	}
 
 	if !message.isEmpty() {
-		err := manager.Producer().WriteMessages(context.Background(),
+		err := producer.WriteMessages(context.Background(),
 			kafka.Message{
 				Headers: message.Headers,
 				Value:   message.Value,
@@ -27,6 +30,9 @@ func (manager *Manager) Produce(message *Message) error {
 }
 
 func (manager *Manager) Producer() *kafka.Writer {
+	manager.producerMutex.Lock()
+	defer manager.producerMutex.Unlock()
+
 	if manager.producer != nil {
 		return manager.producer
 	}
@@ -43,19 +49,57 @@ func (manager *Manager) Producer() *kafka.Writer {
 	return manager.producer
 }
 
-func (manager *Manager) Consume(consumerHandler func(Message)) error {
+// Consume fetches the messages from the consumer's topic and hands each one of them to the given handler, until the
+// given context gets cancelled. The handlers run concurrently, and the offset of every message is committed once its
+// handler and the handlers of the earlier messages of its partition have finished. Before returning the function
+// waits for all the in-flight handlers to complete, so that no message is left half processed.
+func (manager *Manager) Consume(ctx context.Context, consumerHandler func(Message)) error {
 	if manager.Consumer() == nil {
 		return fmt.Errorf("consumer is not initialized")
 	}
 
+	var inFlight sync.WaitGroup
+	commits := newCommitOrder()
+
 	for {
-		message, err := manager.Consumer().ReadMessage(context.Background())
+		message, err := manager.Consumer().FetchMessage(ctx)
 		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
 			continue
 		}
 
-		go consumerHandler(Message(message))
+		pending := commits.fetched(message)
+
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+
+			consumerHandler(Message(pending.message))
+
+			// The context might have been cancelled already, but the offset still needs to be committed since the
+			// message got processed.
+			err := commits.processed(pending, func(message kafka.Message) error {
+				err := manager.Consumer().CommitMessages(context.Background(), message)
+				if err != nil {
+					return fmt.Errorf("unable to commit offset %d for topic %q: %w", message.Offset, message.Topic, err)
+				}
+
+				return nil
+			})
+			if err != nil {
+				l.Log.Error(err)
+			}
+		}()
 	}
+
+	l.Log.Infof("Waiting for the in-flight messages of topic %q to be processed...", manager.ConsumerConfig.Topic)
+	inFlight.Wait()
+	l.Log.Infof("Waiting for the in-flight messages of topic %q to be processed...Complete", manager.ConsumerConfig.Topic)
+
+	return nil
 }
 
 func (manager *Manager) Consumer() *kafka.Reader {
@@ -70,3 +114,30 @@ func (manager *Manager) Consumer() *kafka.Reader {
 	})
 	return manager.consumer
 }
+
+// Close closes the consumer and the producer, if they were initialized. Closing the producer flushes any pending
+// messages to the brokers.
+func (manager *Manager) Close() error {
+	if manager.consumer != nil {
+		err := manager.consumer.Close()
+		if err != nil {
+			return fmt.Errorf("unable to close the consumer: %w", err)
+		}
+
+		manager.consumer = nil
+	}
+
+	manager.producerMutex.Lock()
+	defer manager.producerMutex.Unlock()
+
+	if manager.producer != nil {
+		err := manager.producer.Close()
+		if err != nil {
+			return fmt.Errorf("unable to close the producer: %w", err)
+		}
+
+		manager.producer = nil
+	}
+
+	return nil
+}
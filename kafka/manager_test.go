@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// TestConsumeStopsWhenContextIsCancelled tests that the consumer loop returns once the context gets cancelled, even
+// if the broker is unreachable.
+func TestConsumeStopsWhenContextIsCancelled(t *testing.T) {
+	logger.Log = logrus.New()
+
+	manager := &Manager{Config: Config{
+		KafkaBrokers:   []string{"localhost:1"},
+		ConsumerConfig: ConsumerConfig{Topic: "test-topic", GroupID: "test-group"},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Consume(ctx, func(_ Message) {
+			t.Error("no messages should have been consumed")
+		})
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("want no error, got %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the consumer loop did not stop after cancelling the context")
+	}
+
+	err := manager.Close()
+	if err != nil {
+		t.Errorf("want no error when closing the manager, got %s", err)
+	}
+}
+
+// TestCloseUninitializedManager tests that closing a manager which never created a consumer or a producer is a no-op.
+func TestCloseUninitializedManager(t *testing.T) {
+	manager := &Manager{}
+
+	err := manager.Close()
+	if err != nil {
+		t.Errorf("want no error, got %s", err)
+	}
+}
+
+// TestCommitOrder tests that the offsets only get committed once the earlier messages of their partition are
+// processed, regardless of the order the handlers finish in.
+func TestCommitOrder(t *testing.T) {
+	commits := newCommitOrder()
+
+	var committed []string
+	commit := func(message kafka.Message) error {
+		committed = append(committed, fmt.Sprintf("%d:%d", message.Partition, message.Offset))
+		return nil
+	}
+
+	first := commits.fetched(kafka.Message{Partition: 0, Offset: 1})
+	second := commits.fetched(kafka.Message{Partition: 0, Offset: 2})
+	third := commits.fetched(kafka.Message{Partition: 0, Offset: 3})
+	other := commits.fetched(kafka.Message{Partition: 1, Offset: 7})
+
+	steps := []struct {
+		processed *pendingMessage
+		want      []string
+	}{
+		{third, nil},
+		{other, []string{"1:7"}},
+		{first, []string{"1:7", "0:1"}},
+		{second, []string{"1:7", "0:1", "0:3"}},
+	}
+
+	for _, step := range steps {
+		err := commits.processed(step.processed, commit)
+		if err != nil {
+			t.Fatalf("want no error, got %s", err)
+		}
+
+		if !reflect.DeepEqual(committed, step.want) {
+			t.Errorf("after processing offset %d, want the commits %v, got %v", step.processed.message.Offset, step.want, committed)
+		}
+	}
+}
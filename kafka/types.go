@@ -1,6 +1,10 @@
 package kafka
 
-import "github.com/segmentio/kafka-go"
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
 
 type ProducerConfig struct {
 	Topic string
@@ -21,6 +25,8 @@ type Manager struct {
 	Config
 	consumer *kafka.Reader
 	producer *kafka.Writer
+	// producerMutex guards the lazy creation of the producer, since a manager may be shared by several goroutines.
+	producerMutex sync.Mutex
 }
 
 type Header kafka.Header
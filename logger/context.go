@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loggerContextKey is the key of the logger carried by the contexts.
+type loggerContextKey struct{}
+
+// WithRequestId returns the application's logger with the given request id on every line. The work which doesn't
+// come from a request, and therefore has no id, gets the application's logger as is.
+func WithRequestId(id string) logrus.FieldLogger {
+	if id == "" {
+		return Log
+	}
+
+	return Log.WithField("request_id", id)
+}
+
+// ContextWithLogger returns a copy of the context which carries the given logger, so that the code running on behalf
+// of a request logs with the request's logger.
+func ContextWithLogger(ctx context.Context, logger logrus.FieldLogger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, or the application's logger when it carries none.
+func FromContext(ctx context.Context) logrus.FieldLogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(logrus.FieldLogger); ok {
+			return logger
+		}
+	}
+
+	return Log
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestGormLoggerUsesRequestLogger tests that the SQL logs of the queries made for a request carry the request's id,
+// and that the other queries are logged with the application's logger.
+func TestGormLoggerUsesRequestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := &logrus.Logger{Out: out, Level: logrus.InfoLevel, Formatter: &logrus.JSONFormatter{}, Hooks: make(logrus.LevelHooks)}
+	gormLogger := &CustomGORMLogger{Logger: logger}
+
+	ctx := ContextWithLogger(context.Background(), logger.WithField("request_id", "request-id"))
+	gormLogger.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	if !strings.Contains(out.String(), `"request_id":"request-id"`) {
+		t.Errorf(`want the query logged with the request id, got "%s"`, out.String())
+	}
+
+	out.Reset()
+	gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	if out.Len() == 0 || strings.Contains(out.String(), "request_id") {
+		t.Errorf(`want the query logged without a request id, got "%s"`, out.String())
+	}
+}
+
+// TestFromContext tests that the contexts without a logger get the application's logger.
+func TestFromContext(t *testing.T) {
+	original := Log
+	Log = &logrus.Logger{}
+	t.Cleanup(func() { Log = original })
+
+	if FromContext(context.Background()) != Log {
+		t.Errorf(`want the application's logger`)
+	}
+
+	entry := Log.WithField("request_id", "request-id")
+	if FromContext(ContextWithLogger(context.Background(), entry)) != entry {
+		t.Errorf(`want the context's logger`)
+	}
+
+	if WithRequestId("") != Log {
+		t.Errorf(`want the application's logger for an empty request id`)
+	}
+}
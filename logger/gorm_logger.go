@@ -21,27 +21,39 @@ func (l *CustomGORMLogger) LogMode(gormLogger.LogLevel) gormLogger.Interface {
 	return l
 }
 
-func (l *CustomGORMLogger) Info(_ context.Context, logMessage string, data ...interface{}) {
-	l.Logger.Info(logMessage, data)
+// loggerFor returns the logger of the request the query runs for, when its context carries one.
+func (l *CustomGORMLogger) loggerFor(ctx context.Context) logrus.FieldLogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(logrus.FieldLogger); ok {
+			return logger
+		}
+	}
+
+	return l.Logger
+}
+
+func (l *CustomGORMLogger) Info(ctx context.Context, logMessage string, data ...interface{}) {
+	l.loggerFor(ctx).Info(logMessage, data)
 }
 
-func (l *CustomGORMLogger) Warn(_ context.Context, logMessage string, data ...interface{}) {
-	l.Logger.Warn(logMessage, data)
+func (l *CustomGORMLogger) Warn(ctx context.Context, logMessage string, data ...interface{}) {
+	l.loggerFor(ctx).Warn(logMessage, data)
 }
 
-func (l *CustomGORMLogger) Error(_ context.Context, logMessage string, data ...interface{}) {
-	l.Logger.Error(logMessage, data)
+func (l *CustomGORMLogger) Error(ctx context.Context, logMessage string, data ...interface{}) {
+	l.loggerFor(ctx).Error(logMessage, data)
 }
 
-func (l *CustomGORMLogger) Trace(_ context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+func (l *CustomGORMLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
 	var ErrorRecordNotFound = errors.New("record not found")
 
 	elapsed := time.Since(begin)
 	sql, rows := fc()
+	sql = RedactSQL(sql)
 	duration := float64(elapsed.Nanoseconds()) / 1e6
 	fileWithLineNum := utils.FileWithLineNum()
 
-	loggerEntry := l.Logger.WithFields(logrus.Fields{
+	loggerEntry := l.loggerFor(ctx).WithFields(logrus.Fields{
 		"rows":     rows,
 		"duration": duration,
 		"filename": fileWithLineNum,
@@ -178,11 +178,12 @@ func logLevelToEchoLogLevel(configLogLevel string) echoLog.Lvl {
 }
 
 func FormatForMiddleware(config *appconf.SourcesApiConfig) string {
-	// fields of default format from (converted to JSON): labstack/echo/v4@v4.4.0/middleware/logger.go
+	// fields of default format from (converted to JSON): labstack/echo/v4@v4.4.0/middleware/logger.go, along with
+	// the name of the PSK the request was authenticated with.
 	defaultFormat := `{"time":"${time_rfc3339_nano}","id":"${id}","remote_ip":"${remote_ip}",` +
 		`"host":"${host}","method":"${method}","uri":"${uri}","user_agent":"${user_agent}",` +
 		`"status":"${status}","error":"${error}","latency":"${latency}","latency_human":"${latency_human}"` +
-		`,"bytes_in":"${bytes_in}","bytes_out":"${bytes_out}"}` + "\n"
+		`,"bytes_in":"${bytes_in}","bytes_out":"${bytes_out}","psk_name":"${header:x-rh-sources-psk-name}","request_id":"${header:x-rh-insights-request-id}"}` + "\n"
 
 	fieldsDefaultFormat := make(map[string]interface{})
 
@@ -212,6 +213,8 @@ func InitEchoLogger(e *echo.Echo, config *appconf.SourcesApiConfig) {
 	logger.SetOutput(LogOutputFrom(config.LogHandler))
 	logger.SetFormatter(NewCustomLoggerFormatter(config, true))
 
+	// the secrets get redacted before the entries reach the other hooks.
+	logger.Logger.AddHook(&RedactionHook{})
 	AddHooksTo(logger.Logger, config)
 	e.Logger = logger
 	e.Logger.SetLevel(logLevelToEchoLogLevel(config.LogLevel))
@@ -238,5 +241,7 @@ func InitLogger(config *appconf.SourcesApiConfig) {
 		ReportCaller: true,
 	}
 
+	// the secrets get redacted before the entries reach the other hooks.
+	Log.AddHook(&RedactionHook{})
 	AddHooksTo(Log, config)
 }
@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces the secrets in the logs and the error messages.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the names of the fields, headers and columns whose values are secrets: the authentications'
+// passwords and API keys, the tokens of the marketplace and Vault, the PSKs and the identity headers.
+var sensitiveKeys = []string{
+	"password", "password_hash", "passwd",
+	"secret", "client_secret", "secret_key", "secret_access_key", "aws_secret_access_key",
+	"token", "access_token", "refresh_token", "id_token", "client_token", "x-vault-token",
+	"api_key", "apikey", "marketplace",
+	"psk", "x-rh-sources-psk",
+	"x-rh-identity", "authorization",
+}
+
+var (
+	keys = `(?i:` + strings.Join(quoteAll(sensitiveKeys), "|") + `)`
+
+	redactions = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		// JSON fields: "password":"secret".
+		{regexp.MustCompile(`"(` + keys + `)"(\s*:\s*)"(?:[^"\\]|\\.)*"`), `"$1"$2"` + Redacted + `"`},
+		// JSON fields within JSON strings: \"access_token\":\"secret\".
+		{regexp.MustCompile(`\\"(` + keys + `)\\"(\s*:\s*)\\"(?:[^\\]|\\[^"])*\\"`), `\"$1\"$2\"` + Redacted + `\"`},
+		// SQL conditions and assignments: "password" = 'secret'.
+		{regexp.MustCompile(`"(` + keys + `)"(\s*=\s*)'(?:[^']|'')*'`), `"$1"$2'` + Redacted + `'`},
+		// the headers of the Kafka messages, as they get printed: {x-rh-identity eyJ...}.
+		{regexp.MustCompile(`\{(` + keys + `) [^}]*\}`), `{$1 ` + Redacted + `}`},
+		// Go values, headers, query strings and connection strings: password:secret, Token:"secret",
+		// Authorization: Bearer secret, apikey=secret.
+		{regexp.MustCompile(`\b(` + keys + `)(\s*[:=]\s*)(?:\[REDACTED\]|(?i:bearer|basic)\s+[^\s,;&}\])"']+|"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|[^\s,;&{}\[\]()"']+)`), `$1$2` + Redacted},
+		// the bearer tokens anywhere else.
+		{regexp.MustCompile(`\b((?i:bearer))\s+[A-Za-z0-9\-._~+/]+=*`), `$1 ` + Redacted},
+	}
+
+	// insertStatement splits the INSERT statements in their head, their columns and their values.
+	insertStatement = regexp.MustCompile(`(?is)^(\s*INSERT\s+INTO\s+\S+\s*\()([^)]*)(\)\s*VALUES\s*)(.*)$`)
+)
+
+// quoteAll escapes the keys for the patterns.
+func quoteAll(keys []string) []string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+
+	return quoted
+}
+
+// isSensitiveKey tells whether the values of the field, header or column with the given name are secrets.
+func isSensitiveKey(key string) bool {
+	key = strings.Trim(strings.TrimSpace(key), "\"`")
+	for _, sensitive := range sensitiveKeys {
+		if strings.EqualFold(key, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact replaces the secrets in the message. Every log entry goes through it, as well as the error messages that get
+// sent back to the clients.
+func Redact(message string) string {
+	for _, redaction := range redactions {
+		message = redaction.pattern.ReplaceAllString(message, redaction.replacement)
+	}
+
+	return message
+}
+
+// RedactSQL replaces the secrets in the SQL statement, including the values inserted in the sensitive columns, which
+// can only be told apart by their position.
+func RedactSQL(sql string) string {
+	parts := insertStatement.FindStringSubmatch(sql)
+	if parts == nil {
+		return Redact(sql)
+	}
+
+	sensitive := make(map[int]bool)
+	for i, column := range strings.Split(parts[2], ",") {
+		if isSensitiveKey(column) {
+			sensitive[i] = true
+		}
+	}
+
+	if len(sensitive) == 0 {
+		return Redact(sql)
+	}
+
+	return Redact(parts[1] + parts[2] + parts[3] + redactInsertValues(parts[4], sensitive))
+}
+
+// redactInsertValues replaces the values in the given positions of each tuple of the VALUES clause. Whatever follows
+// the tuples, such as an ON CONFLICT or a RETURNING clause, is left as is.
+func redactInsertValues(values string, sensitive map[int]bool) string {
+	var out, value strings.Builder
+	column, depth, quoted, done := 0, 0, false, false
+
+	flush := func() {
+		raw := value.String()
+		trimmed := strings.TrimSpace(raw)
+		if sensitive[column] && !strings.EqualFold(trimmed, "NULL") {
+			out.WriteString(raw[:len(raw)-len(strings.TrimLeft(raw, " \t\n"))])
+			out.WriteString("'" + Redacted + "'")
+		} else {
+			out.WriteString(raw)
+		}
+
+		value.Reset()
+	}
+
+	for _, ch := range values {
+		if done {
+			out.WriteRune(ch)
+			continue
+		}
+
+		if depth == 0 {
+			switch {
+			case ch == '(':
+				depth, column = 1, 0
+			case !strings.ContainsRune(" \t\n,", ch):
+				done = true
+			}
+
+			out.WriteRune(ch)
+			continue
+		}
+
+		switch {
+		case quoted:
+			// the escaped quotes close and open the literal again, which leaves it open.
+			if ch == '\'' {
+				quoted = false
+			}
+		case ch == '\'':
+			quoted = true
+		case ch == '(':
+			depth++
+		case ch == ')' && depth > 1:
+			depth--
+		case ch == ')':
+			flush()
+			depth = 0
+			out.WriteRune(ch)
+			continue
+		case ch == ',' && depth == 1:
+			flush()
+			column++
+			out.WriteRune(ch)
+			continue
+		}
+
+		value.WriteRune(ch)
+	}
+
+	out.WriteString(value.String())
+
+	return out.String()
+}
+
+// RedactionHook redacts the log entries before they get formatted or shipped anywhere else, so it must be the first
+// hook of the loggers.
+type RedactionHook struct{}
+
+func (h *RedactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the entry's message and fields. The fields with sensitive names are replaced entirely.
+func (h *RedactionHook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+
+	for key, value := range entry.Data {
+		if isSensitiveKey(key) {
+			entry.Data[key] = Redacted
+			continue
+		}
+
+		switch value := value.(type) {
+		case string:
+			entry.Data[key] = Redact(value)
+		case []byte:
+			entry.Data[key] = Redact(string(value))
+		case error:
+			entry.Data[key] = Redact(value.Error())
+		case fmt.Stringer:
+			entry.Data[key] = Redact(value.String())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,335 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// the secrets of the corpus, which must never show up in the redacted output.
+const (
+	marketplaceApiKey   = "mkt-api-key-0d9f3b"
+	marketplaceToken    = "eyJhbGciOiJSUzI1NiJ9.mkt-token.c2lnbmF0dXJl"
+	vaultPassword       = "vault-password-7c1e"
+	vaultToken          = "s.vault-token-91ab"
+	pskSecret           = "psk-secret-55d2"
+	identityHeader      = "eyJpZGVudGl0eSI6eyJhY2NvdW50X251bWJlciI6IjEyMzQ1In19"
+	databasePassword    = "db-password-a41c"
+	awsSecretAccessKey  = "aws-secret-8e2f/Kq+Zp"
+	quotedVaultPassword = "it''s a vault-password-7c1e"
+)
+
+var secrets = []string{marketplaceApiKey, marketplaceToken, vaultPassword, vaultToken, pskSecret, identityHeader, databasePassword, awsSecretAccessKey}
+
+// redactionCorpus holds the ways the secrets show up in the logs and the error messages, along with the parts that
+// must be kept since they aren't secrets.
+var redactionCorpus = []struct {
+	name  string
+	input string
+	keep  []string
+}{
+	{
+		name:  "marketplace token response",
+		input: fmt.Sprintf(`{"access_token":"%s","expiration":1625097600}`, marketplaceToken),
+		keep:  []string{`"expiration":1625097600`},
+	},
+	{
+		name:  "marketplace bearer token struct",
+		input: fmt.Sprintf(`BearerToken{Expiration:1625097600, Token:"%s"}`, marketplaceToken),
+		keep:  []string{"Expiration:1625097600"},
+	},
+	{
+		name:  "marketplace token in the authentication's extra",
+		input: fmt.Sprintf(`{"authtype":"marketplace-token","extra":{"marketplace":"{\"expiration\":1,\"access_token\":\"%s\"}"}}`, marketplaceToken),
+		keep:  []string{`"authtype":"marketplace-token"`},
+	},
+	{
+		name:  "marketplace token escaped in a JSON string",
+		input: fmt.Sprintf(`{"message":"{\"access_token\":\"%s\",\"expiration\":1}"}`, marketplaceToken),
+		keep:  []string{`\"expiration\":1`},
+	},
+	{
+		name:  "marketplace token request",
+		input: fmt.Sprintf(`POST https://marketplace.example.com/api-security/om/v1/token grant_type=urn:ibm:params:oauth:grant-type:apikey&apikey=%s`, marketplaceApiKey),
+		keep:  []string{"grant_type=urn:ibm:params:oauth:grant-type:apikey"},
+	},
+	{
+		name:  "marketplace authorization header",
+		input: fmt.Sprintf(`sending the request with the header "Authorization: Bearer %s"`, marketplaceToken),
+		keep:  []string{"sending the request"},
+	},
+	{
+		name:  "bearer token",
+		input: fmt.Sprintf(`unable to use the token Bearer %s`, marketplaceToken),
+		keep:  []string{"unable to use the token"},
+	},
+	{
+		name:  "marketplace API key in the authentication",
+		input: fmt.Sprintf(`{"authtype":"marketplace","username":"user","password":"%s"}`, marketplaceApiKey),
+		keep:  []string{`"username":"user"`},
+	},
+	{
+		name:  "vault secret as a Go map",
+		input: fmt.Sprintf(`map[data:map[authtype:username_password password:%s username:admin] metadata:map[version:1]]`, vaultPassword),
+		keep:  []string{"username:admin", "authtype:username_password", "version:1"},
+	},
+	{
+		name:  "vault secret as JSON",
+		input: fmt.Sprintf(`{"data":{"data":{"password":"%s","username":"admin"}},"request_id":"abc"}`, vaultPassword),
+		keep:  []string{`"username":"admin"`, `"request_id":"abc"`},
+	},
+	{
+		name:  "vault authentication struct",
+		input: fmt.Sprintf(`{ID:5 AuthType:username_password Username:admin Password:%s ResourceType:Source}`, vaultPassword),
+		keep:  []string{"Username:admin", "ResourceType:Source"},
+	},
+	{
+		name:  "vault token header",
+		input: fmt.Sprintf(`X-Vault-Token: %s`, vaultToken),
+		keep:  []string{"X-Vault-Token"},
+	},
+	{
+		name:  "vault client token",
+		input: fmt.Sprintf(`{"auth":{"client_token":"%s","lease_duration":3600}}`, vaultToken),
+		keep:  []string{`"lease_duration":3600`},
+	},
+	{
+		name:  "psk header",
+		input: fmt.Sprintf(`x-rh-sources-psk: %s, x-rh-sources-account-number: 12345`, pskSecret),
+		keep:  []string{"x-rh-sources-account-number: 12345"},
+	},
+	{
+		name:  "psk in the JSON headers",
+		input: fmt.Sprintf(`{"x-rh-sources-psk":"%s","psk_name":"sources-monitor"}`, pskSecret),
+		keep:  []string{`"psk_name":"sources-monitor"`},
+	},
+	{
+		name:  "identity header",
+		input: fmt.Sprintf(`x-rh-identity=%s`, identityHeader),
+		keep:  []string{"x-rh-identity"},
+	},
+	{
+		name:  "identity header in the JSON headers",
+		input: fmt.Sprintf(`{"X-Rh-Identity":"%s","event_type":"availability_status"}`, identityHeader),
+		keep:  []string{`"event_type":"availability_status"`},
+	},
+	{
+		name:  "connection string",
+		input: fmt.Sprintf(`host=localhost port=5432 user=root password=%s dbname=sources_api_development sslmode=disable`, databasePassword),
+		keep:  []string{"user=root", "dbname=sources_api_development"},
+	},
+	{
+		name:  "aws credentials",
+		input: fmt.Sprintf(`{"aws_secret_access_key":"%s","aws_access_key_id":"AKIA"}`, awsSecretAccessKey),
+		keep:  []string{`"aws_access_key_id":"AKIA"`},
+	},
+	{
+		name:  "SQL update",
+		input: fmt.Sprintf(`UPDATE "authentications" SET "password"='%s',"updated_at"='2021-07-01 00:00:00' WHERE "id" = 5`, vaultPassword),
+		keep:  []string{`"updated_at"='2021-07-01 00:00:00'`, `"id" = 5`},
+	},
+	{
+		name:  "SQL condition",
+		input: fmt.Sprintf(`SELECT * FROM "authentications" WHERE "password" = '%s'`, quotedVaultPassword),
+		keep:  []string{`SELECT * FROM "authentications"`},
+	},
+	{
+		name:  "error message",
+		input: fmt.Sprintf(`Validation failed: invalid JSON in the request: {"name":"auth","password":"%s"}`, vaultPassword),
+		keep:  []string{"Validation failed", `"name":"auth"`},
+	},
+}
+
+// assertRedacted checks that none of the secrets are in the output, and that the parts to keep are.
+func assertRedacted(t *testing.T, name, output string, keep []string) {
+	t.Helper()
+
+	for _, secret := range secrets {
+		if strings.Contains(output, secret) {
+			t.Errorf(`%s: want the secret %q redacted, got %q`, name, secret, output)
+		}
+	}
+
+	for _, part := range keep {
+		if !strings.Contains(output, part) {
+			t.Errorf(`%s: want %q kept, got %q`, name, part, output)
+		}
+	}
+
+	if !strings.Contains(output, Redacted) {
+		t.Errorf(`%s: want %q in the output, got %q`, name, Redacted, output)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for _, tc := range redactionCorpus {
+		assertRedacted(t, tc.name, Redact(tc.input), tc.keep)
+	}
+}
+
+// TestRedactIsIdempotent tests that redacting twice doesn't mangle the output any further.
+func TestRedactIsIdempotent(t *testing.T) {
+	for _, tc := range redactionCorpus {
+		once := Redact(tc.input)
+		if twice := Redact(once); twice != once {
+			t.Errorf(`%s: want %q, got %q`, tc.name, once, twice)
+		}
+	}
+}
+
+// TestRedactKeepsHarmlessMessages tests that the messages which only mention the sensitive words are left alone.
+func TestRedactKeepsHarmlessMessages(t *testing.T) {
+	messages := []string{
+		"marketplace token included in authentication",
+		"unable to take a rate limit token from Redis, falling back to the memory",
+		"Loaded 3 PSKs from /etc/psks.json",
+		`Authentication required by either [x-rh-identity] or [x-rh-sources-psk]`,
+		`{"psk_name":"sources-monitor","status":"200"}`,
+		"secret/data/1/Source_5_abc",
+	}
+
+	for _, message := range messages {
+		if got := Redact(message); got != message {
+			t.Errorf(`want %q unchanged, got %q`, message, got)
+		}
+	}
+}
+
+// TestRedactKafkaHeaders tests that the headers of the Kafka messages, printed the way the status listener does, get
+// redacted.
+func TestRedactKafkaHeaders(t *testing.T) {
+	headers := []kafka.Header{
+		{Key: "event_type", Value: []byte("availability_status")},
+		{Key: "x-rh-identity", Value: []byte(identityHeader)},
+		{Key: "x-rh-sources-psk", Value: []byte(pskSecret)},
+		{Key: "x-rh-sources-account-number", Value: []byte("12345")},
+	}
+
+	payload := fmt.Sprintf(`{"resource_type":"Source","resource_id":"1","status":"available","error":"Bearer %s was rejected"}`, marketplaceToken)
+	output := Redact(fmt.Sprintf("Kafka message %s, %s received with payload: %s", headers, []byte("key"), []byte(payload)))
+
+	assertRedacted(t, "kafka message", output, []string{"{event_type availability_status}", "{x-rh-sources-account-number 12345}", `"status":"available"`})
+}
+
+func TestRedactSQLInsert(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "sensitive columns",
+			sql:  fmt.Sprintf(`INSERT INTO "authentications" ("name","password","extra","username") VALUES ('auth, one','%s','{"marketplace":"{\"access_token\":\"%s\"}"}','admin') RETURNING "id"`, quotedVaultPassword, marketplaceToken),
+			want: `INSERT INTO "authentications" ("name","password","extra","username") VALUES ('auth, one','[REDACTED]','{"marketplace":"[REDACTED]"}','admin') RETURNING "id"`,
+		},
+		{
+			name: "several rows",
+			sql:  fmt.Sprintf(`INSERT INTO "authentications" ("password","username") VALUES ('%s','admin'),(NULL,'other'),(lower('%s'), 'third')`, vaultPassword, marketplaceApiKey),
+			want: `INSERT INTO "authentications" ("password","username") VALUES ('[REDACTED]','admin'),(NULL,'other'),('[REDACTED]', 'third')`,
+		},
+		{
+			name: "no sensitive columns",
+			sql:  `INSERT INTO "tenants" ("external_tenant","org_id") VALUES ('12345','org') ON CONFLICT ("org_id") WHERE org_id <> '' DO UPDATE SET "org_id"="excluded"."org_id" RETURNING "id"`,
+			want: `INSERT INTO "tenants" ("external_tenant","org_id") VALUES ('12345','org') ON CONFLICT ("org_id") WHERE org_id <> '' DO UPDATE SET "org_id"="excluded"."org_id" RETURNING "id"`,
+		},
+		{
+			name: "conflict clause after the values",
+			sql:  fmt.Sprintf(`INSERT INTO "authentications" ("password","name") VALUES ('%s','auth') ON CONFLICT ("password") DO NOTHING`, vaultPassword),
+			want: `INSERT INTO "authentications" ("password","name") VALUES ('[REDACTED]','auth') ON CONFLICT ("password") DO NOTHING`,
+		},
+	}
+
+	for _, tc := range tests {
+		if got := RedactSQL(tc.sql); got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+// testLogger returns a logger set up like the application's, which writes its JSON entries to the returned buffer.
+func testLogger() (*logrus.Logger, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	logger := &logrus.Logger{
+		Out:       out,
+		Level:     logrus.DebugLevel,
+		Formatter: &CustomLoggerFormatter{AppName: "sources-api-go", Hostname: "test", InjectedToOtherLogger: true},
+		Hooks:     make(logrus.LevelHooks),
+	}
+	logger.AddHook(&RedactionHook{})
+
+	return logger, out
+}
+
+// TestRedactionHook tests that neither the messages nor the fields of the log entries leak the secrets.
+func TestRedactionHook(t *testing.T) {
+	for _, tc := range redactionCorpus {
+		logger, out := testLogger()
+		logger.Info(tc.input)
+		logger.WithField("detail", tc.input).Warn("failed")
+		logger.WithError(errors.New(tc.input)).Error("failed")
+
+		// the entries are JSON, so the quotes of the parts to keep are escaped.
+		keep := make([]string, len(tc.keep))
+		for i, part := range tc.keep {
+			raw, _ := json.Marshal(part)
+			keep[i] = strings.Trim(string(raw), `"`)
+		}
+
+		assertRedacted(t, tc.name, out.String(), keep)
+	}
+}
+
+// TestRedactionHookSensitiveFields tests that the fields with sensitive names get replaced entirely, whatever their
+// values are.
+func TestRedactionHookSensitiveFields(t *testing.T) {
+	logger, out := testLogger()
+	logger.WithFields(logrus.Fields{
+		"password":      []byte(vaultPassword),
+		"x-rh-identity": identityHeader,
+		"Authorization": "Bearer " + marketplaceToken,
+		"method":        "GET",
+	}).Info("request")
+
+	entry := make(map[string]interface{})
+	err := json.Unmarshal(out.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	for _, field := range []string{"password", "x-rh-identity", "Authorization"} {
+		if entry[field] != Redacted {
+			t.Errorf(`want the field %q redacted, got %q`, field, entry[field])
+		}
+	}
+
+	if entry["method"] != "GET" {
+		t.Errorf(`want the field "method" kept, got %q`, entry["method"])
+	}
+}
+
+// TestGORMLoggerRedactsSQL tests that the SQL logs don't leak the inserted or updated secrets.
+func TestGORMLoggerRedactsSQL(t *testing.T) {
+	logger, out := testLogger()
+	gormLogger := &CustomGORMLogger{Logger: logger, LogLevelForSqlLogs: "DEBUG"}
+
+	statements := []string{
+		fmt.Sprintf(`INSERT INTO "authentications" ("username","password") VALUES ('admin','%s') RETURNING "id"`, vaultPassword),
+		fmt.Sprintf(`UPDATE "authentications" SET "password"='%s' WHERE "id" = 5`, marketplaceApiKey),
+	}
+
+	for _, statement := range statements {
+		gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) { return statement, 1 }, nil)
+	}
+
+	gormLogger.Trace(context.Background(), time.Now(), func() (string, int64) { return statements[0], 0 }, errors.New("duplicate key"))
+
+	assertRedacted(t, "gorm logger", out.String(), []string{`('admin','[REDACTED]')`})
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	echoLog "github.com/labstack/gommon/log"
+	logrusEcho "github.com/neko-neko/echo-logrus/v2/log"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestLogger is the echo logger of a single request, which adds the request's fields, such as its id, to every
+// line. The rest of the logger's behavior is the one of the application's echo logger.
+type RequestLogger struct {
+	*logrusEcho.MyLogger
+	entry *logrus.Entry
+}
+
+// NewRequestLogger returns a logger which adds the given fields to the lines of the given echo logger. The loggers
+// which aren't the application's logrus one, such as the default logger of the tests, are returned as they are.
+func NewRequestLogger(logger echo.Logger, fields logrus.Fields) echo.Logger {
+	switch logger := logger.(type) {
+	case *logrusEcho.MyLogger:
+		return &RequestLogger{MyLogger: logger, entry: logger.Logger.WithFields(fields)}
+	case *RequestLogger:
+		return &RequestLogger{MyLogger: logger.MyLogger, entry: logger.entry.WithFields(fields)}
+	default:
+		return logger
+	}
+}
+
+// jsonMessage turns the JSON logged by the "j" methods into the line's message.
+func jsonMessage(j echoLog.JSON) string {
+	raw, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Sprint(j)
+	}
+
+	return string(raw)
+}
+
+func (rl *RequestLogger) Print(i ...interface{}) {
+	rl.entry.Print(i...)
+}
+
+func (rl *RequestLogger) Printf(format string, args ...interface{}) {
+	rl.entry.Printf(format, args...)
+}
+
+func (rl *RequestLogger) Printj(j echoLog.JSON) {
+	rl.entry.Print(jsonMessage(j))
+}
+
+func (rl *RequestLogger) Debug(i ...interface{}) {
+	rl.entry.Debug(i...)
+}
+
+func (rl *RequestLogger) Debugf(format string, args ...interface{}) {
+	rl.entry.Debugf(format, args...)
+}
+
+func (rl *RequestLogger) Debugj(j echoLog.JSON) {
+	rl.entry.Debug(jsonMessage(j))
+}
+
+func (rl *RequestLogger) Info(i ...interface{}) {
+	rl.entry.Info(i...)
+}
+
+func (rl *RequestLogger) Infof(format string, args ...interface{}) {
+	rl.entry.Infof(format, args...)
+}
+
+func (rl *RequestLogger) Infoj(j echoLog.JSON) {
+	rl.entry.Info(jsonMessage(j))
+}
+
+func (rl *RequestLogger) Warn(i ...interface{}) {
+	rl.entry.Warn(i...)
+}
+
+func (rl *RequestLogger) Warnf(format string, args ...interface{}) {
+	rl.entry.Warnf(format, args...)
+}
+
+func (rl *RequestLogger) Warnj(j echoLog.JSON) {
+	rl.entry.Warn(jsonMessage(j))
+}
+
+func (rl *RequestLogger) Error(i ...interface{}) {
+	rl.entry.Error(i...)
+}
+
+func (rl *RequestLogger) Errorf(format string, args ...interface{}) {
+	rl.entry.Errorf(format, args...)
+}
+
+func (rl *RequestLogger) Errorj(j echoLog.JSON) {
+	rl.entry.Error(jsonMessage(j))
+}
+
+func (rl *RequestLogger) Fatal(i ...interface{}) {
+	rl.entry.Fatal(i...)
+}
+
+func (rl *RequestLogger) Fatalf(format string, args ...interface{}) {
+	rl.entry.Fatalf(format, args...)
+}
+
+func (rl *RequestLogger) Fatalj(j echoLog.JSON) {
+	rl.entry.Fatal(jsonMessage(j))
+}
+
+func (rl *RequestLogger) Panic(i ...interface{}) {
+	rl.entry.Panic(i...)
+}
+
+func (rl *RequestLogger) Panicf(format string, args ...interface{}) {
+	rl.entry.Panicf(format, args...)
+}
+
+func (rl *RequestLogger) Panicj(j echoLog.JSON) {
+	rl.entry.Panic(jsonMessage(j))
+}
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/RedHatInsights/sources-api-go/config"
 	"github.com/RedHatInsights/sources-api-go/dao"
 	logging "github.com/RedHatInsights/sources-api-go/logger"
 	"github.com/RedHatInsights/sources-api-go/marketplace"
+	sourcesMiddleware "github.com/RedHatInsights/sources-api-go/middleware"
 	"github.com/RedHatInsights/sources-api-go/redis"
+	"github.com/RedHatInsights/sources-api-go/scheduler"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/statuslistener"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -22,20 +29,45 @@ func main() {
 	redis.Init()
 
 	availabilityListener := flag.Bool("listener", false, "run availability status listener")
+	availabilityScheduler := flag.Bool("scheduler", false, "run the periodic availability checks scheduler")
+	replayAccount := flag.String("replay", "", "re-emit the current state of the given account number to the event stream and exit")
+	replayOrgId := flag.String("replay-org-id", "", "re-emit the current state of the given org id to the event stream and exit")
+	replayApplicationType := flag.String("replay-application-type", "", "only replay the sources of the given application type, by id or name")
+	backfillOrgIds := flag.Bool("backfill-org-ids", false, "set the org id of the tenants which only have an account number and exit")
+	migrate := flag.Bool("migrate", false, "apply the schema changes of this service and exit")
 	flag.Parse()
 
-	if *availabilityListener {
-		statuslistener.Run()
-	} else {
-		runServer()
+	// the context gets cancelled once we receive a termination signal, which lets the running mode shut down
+	// gracefully.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	switch {
+	case *availabilityListener:
+		go serveMetrics(ctx)
+		statuslistener.Run(ctx)
+	case *availabilityScheduler:
+		scheduler.Run(ctx)
+	case *replayAccount != "" || *replayOrgId != "":
+		runReplay(ctx, *replayAccount, *replayOrgId, *replayApplicationType)
+	case *backfillOrgIds:
+		runOrgIdBackfill(ctx)
+	case *migrate:
+		runMigrations()
+	default:
+		go serveMetrics(ctx)
+		runServer(ctx)
 	}
+
+	closeConnections()
 }
 
-func runServer() {
+func runServer(ctx context.Context) {
 	e := echo.New()
 	logging.InitEchoLogger(e, conf)
 
 	e.Use(middleware.Recover())
+	e.Use(sourcesMiddleware.RequestId)
 	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Format: logging.FormatForMiddleware(conf),
 		Output: &logging.LogWriter{Output: logging.LogOutputFrom(conf.LogHandler),
@@ -45,6 +77,9 @@ func runServer() {
 
 	setupRoutes(e)
 
+	// picking up the rotated PSKs
+	go sourcesMiddleware.WatchPsks(ctx)
+
 	// setting up the DAO functions
 	getSourceDao = getSourceDaoWithTenant
 	getApplicationDao = getApplicationDaoWithTenant
@@ -55,6 +90,8 @@ func runServer() {
 	getEndpointDao = getEndpointDaoWithTenant
 	getMetaDataDao = getMetaDataDaoWithTenant
 	getRhcConnectionDao = getDefaultRhcConnectionDao
+	getAvailabilityCheckDao = getAvailabilityCheckDaoWithTenant
+	getAvailabilityStatusHistoryDao = getAvailabilityStatusHistoryDaoWithTenant
 
 	// Set up marketplace's token management functions
 	dao.GetMarketplaceTokenCacher = dao.GetMarketplaceTokenCacherWithTenantId
@@ -69,5 +106,67 @@ func runServer() {
 		e.Logger.Fatal(err)
 	}
 
-	e.Logger.Fatal(e.Start(":8000"))
+	err = serve(ctx, e, ":8000")
+	if err != nil {
+		e.Logger.Error(err)
+	}
+}
+
+// runReplay re-emits the current state of the tenant with the given account number or org id to the event stream.
+func runReplay(ctx context.Context, accountNumber, orgId, applicationType string) {
+	tenant, err := dao.GetTenantDao().TenantByIdentifiers(accountNumber, orgId)
+	if err != nil {
+		logging.Log.Errorf("unable to find the tenant for account number %q and org id %q: %s", accountNumber, orgId, err)
+		os.Exit(1)
+	}
+
+	var applicationTypeId int64
+	if applicationType != "" {
+		err = dao.PopulateStaticTypeCache()
+		if err != nil {
+			logging.Log.Errorf("unable to populate the type cache: %s", err)
+			os.Exit(1)
+		}
+
+		applicationTypeId, err = service.ApplicationTypeIdFrom(applicationType)
+		if err != nil {
+			logging.Log.Errorf("unable to find the application type %q: %s", applicationType, err)
+			os.Exit(1)
+		}
+	}
+
+	_, err = service.ReplayTenant(ctx, tenant, service.ReplayOptionsFromConfig(applicationTypeId))
+	if err != nil {
+		logging.Log.Errorf("unable to replay the state of tenant %d: %s", tenant.Id, err)
+		os.Exit(1)
+	}
+}
+
+// runOrgIdBackfill sets the org id of the tenants which only have an account number, by asking the tenant translator.
+func runOrgIdBackfill(ctx context.Context) {
+	if conf.TenantTranslatorUrl == "" {
+		logging.Log.Errorf("unable to backfill the org ids: TENANT_TRANSLATOR_URL is not set")
+		os.Exit(1)
+	}
+
+	result, err := service.BackfillOrgIds(ctx, service.NewTenantTranslator(conf.TenantTranslatorUrl))
+	if err != nil {
+		logging.Log.Errorf("unable to backfill the org ids: %s", err)
+		os.Exit(1)
+	}
+
+	logging.Log.Infof("Backfilled the org id of %d tenants, %d tenants have no org id", result.Updated, result.Unmapped)
+}
+
+// runMigrations applies the schema changes of this service, which must happen before its new version gets rolled out.
+func runMigrations() {
+	logging.Log.Info("Migrating the database...")
+
+	err := dao.Migrate()
+	if err != nil {
+		logging.Log.Errorf("unable to migrate the database: %s", err)
+		os.Exit(1)
+	}
+
+	logging.Log.Info("Migrating the database...Complete")
 }
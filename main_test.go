@@ -5,6 +5,7 @@ import (
 	"os"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/RedHatInsights/sources-api-go/dao"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/database"
@@ -12,6 +13,7 @@ import (
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/parser"
 	l "github.com/RedHatInsights/sources-api-go/logger"
 	"github.com/RedHatInsights/sources-api-go/middleware"
+	m "github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 )
@@ -25,6 +27,9 @@ var (
 	mockMetaDataDao                  dao.MetaDataDao
 	mockRhcConnectionDao             dao.RhcConnectionDao
 	mockApplicationAuthenticationDao dao.ApplicationAuthenticationDao
+	mockAvailabilityCheckDao         dao.AvailabilityCheckDao
+	mockAvailabilityStatusHistoryDao dao.AvailabilityStatusHistoryDao
+	mockScheduledResumeDao           dao.ScheduledResumeDao
 )
 
 func TestMain(t *testing.M) {
@@ -45,6 +50,8 @@ func TestMain(t *testing.M) {
 		getMetaDataDao = getMetaDataDaoWithTenant
 		getRhcConnectionDao = getDefaultRhcConnectionDao
 		getApplicationAuthenticationDao = getApplicationAuthenticationDaoWithTenant
+		getAvailabilityCheckDao = getAvailabilityCheckDaoWithTenant
+		getAvailabilityStatusHistoryDao = getAvailabilityStatusHistoryDaoWithTenant
 
 		database.CreateFixtures()
 		err := dao.PopulateStaticTypeCache()
@@ -72,6 +79,26 @@ func TestMain(t *testing.M) {
 			return mockApplicationAuthenticationDao, nil
 		}
 
+		// the services check whether the resources are paused, so the default DAOs get replaced as well.
+		dao.GetSourceDao = func(_ *int64) dao.SourceDao { return mockSourceDao }
+		dao.GetApplicationDao = func(_ *int64) dao.ApplicationDao { return mockApplicationDao }
+		dao.GetEndpointDao = func(_ *int64) dao.EndpointDao { return mockEndpointDao }
+		dao.GetApplicationAuthenticationDao = func(_ *int64) dao.ApplicationAuthenticationDao {
+			return mockApplicationAuthenticationDao
+		}
+
+		// the services also use the availability check DAO, so the default one gets replaced as well.
+		mockAvailabilityCheckDao = &dao.MockAvailabilityCheckDao{AvailabilityChecks: fixtures.TestAvailabilityCheckData}
+		getAvailabilityCheckDao = func(c echo.Context) (dao.AvailabilityCheckDao, error) { return mockAvailabilityCheckDao, nil }
+		dao.GetAvailabilityCheckDao = func(_ *int64) dao.AvailabilityCheckDao { return mockAvailabilityCheckDao }
+		mockAvailabilityStatusHistoryDao = &dao.MockAvailabilityStatusHistoryDao{History: fixtures.TestAvailabilityStatusHistoryData}
+		getAvailabilityStatusHistoryDao = func(c echo.Context) (dao.AvailabilityStatusHistoryDao, error) {
+			return mockAvailabilityStatusHistoryDao, nil
+		}
+		dao.GetAvailabilityStatusHistoryDao = func(_ *int64) dao.AvailabilityStatusHistoryDao { return mockAvailabilityStatusHistoryDao }
+		mockScheduledResumeDao = &dao.MockScheduledResumeDao{}
+		dao.GetScheduledResumeDao = func(_ *int64) dao.ScheduledResumeDao { return mockScheduledResumeDao }
+
 	}
 
 	code := t.Run()
@@ -103,6 +130,21 @@ func SortByStringValueOnKey(field string, data []interface{}) {
 	})
 }
 
+// usePausedSource replaces the source DAOs, for the duration of the test, with one which only has a paused copy of
+// the given source.
+func usePausedSource(t *testing.T, source m.Source) {
+	source.PausedAt = time.Now()
+	pausedSourceDao := &dao.MockSourceDao{Sources: []m.Source{source}}
+
+	previousGetSourceDao, previousDaoGetSourceDao := getSourceDao, dao.GetSourceDao
+	getSourceDao = func(c echo.Context) (dao.SourceDao, error) { return pausedSourceDao, nil }
+	dao.GetSourceDao = func(_ *int64) dao.SourceDao { return pausedSourceDao }
+
+	t.Cleanup(func() {
+		getSourceDao, dao.GetSourceDao = previousGetSourceDao, previousDaoGetSourceDao
+	})
+}
+
 func ErrorHandlingContext(handler echo.HandlerFunc) func(echo.Context) error {
 	return middleware.HandleErrors(handler)
 }
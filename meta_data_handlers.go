@@ -21,9 +21,9 @@ func getMetaDataDaoWithTenant(c echo.Context) (dao.MetaDataDao, error) {
 	}
 
 	if tenantId == 0 && err == nil {
-		return dao.GetMetaDataDao(nil), nil
+		return dao.GetMetaDataDao(nil).WithContext(c.Request().Context()), nil
 	} else {
-		return dao.GetMetaDataDao(&tenantId), nil
+		return dao.GetMetaDataDao(&tenantId).WithContext(c.Request().Context()), nil
 	}
 }
 
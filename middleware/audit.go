@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/service"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+	"github.com/redhatinsights/platform-go-middlewares/identity"
+)
+
+const (
+	// auditResponseLimit caps how much of the responses of the created resources gets buffered to find their ids.
+	auditResponseLimit = 64 * 1024
+	// exposeEncryptedAttribute is the query parameter that makes the internal API return the authentications' secrets.
+	exposeEncryptedAttribute = "expose_encrypted_attribute[]"
+)
+
+// Audit records in the audit log the requests which create, modify or delete the resources, and the ones which read
+// the authentications' secrets, once they have been answered. It goes before the error handling middleware, so that
+// it sees the status of the errors that the handlers return.
+func Audit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		method := c.Request().Method
+		secretRead := method == http.MethodGet && len(c.QueryParams()[exposeEncryptedAttribute]) > 0
+
+		if method != http.MethodPost && method != http.MethodPatch && method != http.MethodDelete && !secretRead {
+			return next(c)
+		}
+
+		var fields []string
+		if secretRead {
+			fields = c.QueryParams()[exposeEncryptedAttribute]
+		} else {
+			fields = bodyFields(c)
+		}
+
+		// the ids of the created resources are only known once they are in the response.
+		var recorder *auditResponseRecorder
+		if method == http.MethodPost && len(c.ParamNames()) == 0 {
+			recorder = &auditResponseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+		}
+
+		err := next(c)
+
+		if recorder != nil {
+			c.Response().Writer = recorder.ResponseWriter
+		}
+
+		status := c.Response().Status
+		if err != nil && !c.Response().Committed {
+			status = http.StatusInternalServerError
+			if httpErr, ok := err.(*echo.HTTPError); ok {
+				status = httpErr.Code
+			}
+		}
+
+		actorType, actor := auditActor(c)
+		entry := &model.AuditLog{
+			ActorType: actorType,
+			Actor:     actor,
+			Method:    method,
+			Route:     c.Path(),
+			Fields:    strings.Join(fields, ","),
+			RequestID: c.Request().Header.Get(util.RequestIdHeader),
+			Status:    status,
+			Outcome:   auditOutcome(status),
+		}
+
+		if tenantId, ok := c.Get("tenantID").(int64); ok {
+			entry.TenantID = &tenantId
+		}
+
+		if permission, ok := routePermission(c); ok {
+			entry.ResourceType = permission.Resource
+		}
+
+		if values := c.ParamValues(); len(values) > 0 {
+			entry.ResourceID = values[0]
+		} else if recorder != nil && status < http.StatusBadRequest {
+			entry.ResourceID = recorder.createdId()
+		}
+
+		service.RecordAudit(entry)
+
+		return err
+	}
+}
+
+// auditActor returns who made the request: the PSK's name, the system's cn or cluster id, or the user's name.
+func auditActor(c echo.Context) (string, string) {
+	if name, ok := c.Get("psk-name").(string); ok {
+		return model.AuditActorPsk, name
+	}
+
+	id, ok := c.Get("identity").(identity.XRHID)
+	if !ok {
+		return model.AuditActorUnknown, ""
+	}
+
+	if id.Identity.System != nil {
+		if cn, ok := id.Identity.System["cn"]; ok && cn != nil {
+			return model.AuditActorSystem, fmt.Sprintf("cn:%v", cn)
+		}
+
+		if clusterId, ok := id.Identity.System["cluster_id"]; ok && clusterId != nil {
+			return model.AuditActorSystem, fmt.Sprintf("cluster_id:%v", clusterId)
+		}
+	}
+
+	if id.Identity.User.Username != "" {
+		return model.AuditActorUser, id.Identity.User.Username
+	}
+
+	return model.AuditActorUnknown, ""
+}
+
+// auditOutcome classifies the response's status.
+func auditOutcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return model.AuditOutcomeDenied
+	case status >= http.StatusBadRequest:
+		return model.AuditOutcomeFailure
+	default:
+		return model.AuditOutcomeSuccess
+	}
+}
+
+// bodyFields returns the sorted names of the top level fields of the request's JSON payload, leaving the payload in
+// place for the handler. The values are never looked at, since they might be secrets.
+func bodyFields(c echo.Context) []string {
+	if c.Request().Body == nil {
+		return nil
+	}
+
+	raw, err := io.ReadAll(c.Request().Body)
+	c.Request().Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+
+	payload := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil
+	}
+
+	fields := make([]string, 0, len(payload))
+	for field := range payload {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// auditResponseRecorder keeps the beginning of the response, where the id of the created resource is.
+type auditResponseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *auditResponseRecorder) Write(b []byte) (int, error) {
+	if room := auditResponseLimit - r.body.Len(); room > 0 {
+		if len(b) > room {
+			r.body.Write(b[:room])
+		} else {
+			r.body.Write(b)
+		}
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+// createdId returns the id of the resource in the response, if there is one.
+func (r *auditResponseRecorder) createdId() string {
+	created := struct {
+		ID interface{} `json:"id"`
+	}{}
+
+	if err := json.Unmarshal(r.body.Bytes(), &created); err != nil || created.ID == nil {
+		return ""
+	}
+
+	return fmt.Sprint(created.ID)
+}
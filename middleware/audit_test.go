@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	"github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+	"github.com/redhatinsights/platform-go-middlewares/identity"
+)
+
+// useAuditLogDao makes the audit middleware record the entries in a mock DAO, which gets returned.
+func useAuditLogDao(t *testing.T) *dao.MockAuditLogDao {
+	mock := &dao.MockAuditLogDao{}
+
+	original := dao.GetAuditLogDao
+	dao.GetAuditLogDao = func() dao.AuditLogDao { return mock }
+	t.Cleanup(func() { dao.GetAuditLogDao = original })
+
+	RoutePermissions = map[string]Permission{
+		"POST /sources":                      {Resource: "source", Verb: VerbWrite},
+		"PATCH /sources/:id":                 {Resource: "source", Verb: VerbWrite},
+		"GET /sources/:id":                   {Resource: "source", Verb: VerbRead},
+		"GET /internal/authentications/:uid": {Resource: "authentication", Verb: VerbRead},
+	}
+	t.Cleanup(func() { RoutePermissions = map[string]Permission{} })
+
+	return mock
+}
+
+// auditedRequest runs the request through the audit and error handling middlewares, on the given route.
+func auditedRequest(t *testing.T, method, target, route, body string, values map[string]interface{}, handler echo.HandlerFunc) {
+	c, _ := request.CreateTestContext(method, target, strings.NewReader(body), values)
+	c.Request().Header.Set("x-rh-insights-request-id", "request-1")
+	c.SetPath(route)
+
+	if strings.Contains(route, ":") {
+		c.SetParamNames(route[strings.LastIndex(route, ":")+1:])
+		c.SetParamValues(target[strings.LastIndex(target, "/")+1 : strings.Index(target+"?", "?")])
+	}
+
+	err := Audit(HandleErrors(handler))(c)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+}
+
+// TestAuditMutations tests that the creations and the modifications get recorded along with their actor, tenant,
+// resource, outcome and changed fields, but without the fields' values.
+func TestAuditMutations(t *testing.T) {
+	auditLog := useAuditLogDao(t)
+
+	auditedRequest(t, http.MethodPost, "/sources", "/sources", `{"name": "secret name", "source_type_id": "1"}`,
+		map[string]interface{}{"psk-name": "cost", "tenantID": int64(1)},
+		func(c echo.Context) error {
+			return c.JSON(http.StatusCreated, map[string]interface{}{"id": "15", "name": "secret name"})
+		},
+	)
+
+	auditedRequest(t, http.MethodPatch, "/sources/16", "/sources/:id", `{"availability_status": "available"}`,
+		map[string]interface{}{"identity": identity.XRHID{Identity: identity.Identity{User: identity.User{Username: "jdoe"}}}},
+		func(c echo.Context) error {
+			return util.NewErrNotFound("source")
+		},
+	)
+
+	auditedRequest(t, http.MethodPost, "/sources", "/sources", `{}`,
+		map[string]interface{}{"identity": identity.XRHID{Identity: identity.Identity{System: map[string]interface{}{"cn": "abc"}}}},
+		func(c echo.Context) error {
+			return c.JSON(http.StatusForbidden, util.ErrorDoc(c.Request().Context(), "Forbidden", "403"))
+		},
+	)
+
+	want := []model.AuditLog{
+		{ActorType: model.AuditActorPsk, Actor: "cost", Method: http.MethodPost, Route: "/sources", ResourceType: "source", ResourceID: "15", Fields: "name,source_type_id", Status: http.StatusCreated, Outcome: model.AuditOutcomeSuccess},
+		{ActorType: model.AuditActorUser, Actor: "jdoe", Method: http.MethodPatch, Route: "/sources/:id", ResourceType: "source", ResourceID: "16", Fields: "availability_status", Status: http.StatusNotFound, Outcome: model.AuditOutcomeFailure},
+		{ActorType: model.AuditActorSystem, Actor: "cn:abc", Method: http.MethodPost, Route: "/sources", ResourceType: "source", Status: http.StatusForbidden, Outcome: model.AuditOutcomeDenied},
+	}
+
+	if len(auditLog.Logs) != len(want) {
+		t.Fatalf(`want "%d" entries, got "%d"`, len(want), len(auditLog.Logs))
+	}
+
+	for i, entry := range auditLog.Logs {
+		if (i == 0) != (entry.TenantID != nil) || (entry.TenantID != nil && *entry.TenantID != 1) {
+			t.Errorf(`entry "%d": unexpected tenant "%v"`, i, entry.TenantID)
+		}
+
+		if entry.RequestID != "request-1" {
+			t.Errorf(`entry "%d": want request id "request-1", got "%s"`, i, entry.RequestID)
+		}
+
+		got := entry
+		got.ID, got.TenantID, got.RequestID = 0, nil, ""
+		if got != want[i] {
+			t.Errorf(`entry "%d": want "%+v", got "%+v"`, i, want[i], got)
+		}
+	}
+}
+
+// TestAuditSecretReads tests that only the reads which expose the secrets get recorded.
+func TestAuditSecretReads(t *testing.T) {
+	auditLog := useAuditLogDao(t)
+
+	read := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	auditedRequest(t, http.MethodGet, "/sources/1", "/sources/:id", "", map[string]interface{}{"psk-name": "cost"}, read)
+	auditedRequest(t, http.MethodGet, "/internal/authentications/abc", "/internal/authentications/:uid", "", map[string]interface{}{"psk-name": "cost"}, read)
+	auditedRequest(t, http.MethodGet, "/internal/authentications/abc?expose_encrypted_attribute[]=password", "/internal/authentications/:uid", "", map[string]interface{}{"psk-name": "cost"}, read)
+
+	if len(auditLog.Logs) != 1 {
+		t.Fatalf(`want a single entry, got "%d"`, len(auditLog.Logs))
+	}
+
+	entry := auditLog.Logs[0]
+	if entry.ResourceType != "authentication" || entry.ResourceID != "abc" || entry.Fields != "password" || entry.Actor != "cost" || entry.Outcome != model.AuditOutcomeSuccess {
+		t.Errorf(`unexpected entry "%+v"`, entry)
+	}
+}
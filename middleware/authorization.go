@@ -15,20 +15,36 @@ import (
 )
 
 var (
-	psks            = config.Get().Psks
+	psks            = NewPskStore(config.Get().PskFile, config.Get().Psks, config.Get().PsksNamed)
 	bypassRbac      = config.Get().BypassRbac
-	rbacClient Rbac = &RbacClient{client: rbac.NewClient(os.Getenv("RBAC_URL"), "sources")}
+	rbacClient Rbac = NewRbacCache(
+		&RbacClient{client: rbac.NewClient(os.Getenv("RBAC_URL"), rbacApplication)},
+		time.Duration(config.Get().RbacCacheTtl)*time.Second,
+		time.Duration(config.Get().RbacCacheNegativeTtl)*time.Second,
+	)
 )
 
 /*
-	Takes the information stored in the context and returns a 401 if we do not
-	have authorization to perform "write" things such as POST/PATCH/DELETE.
+	Takes the information stored in the context and returns a 403 if the
+	request is not authorized to go through the route, or a 401 if it is not
+	authenticated at all.
 
 	1. Checks for PSK (if present) and if it is there and matches any of the
-	   PSKs we approve, lets it through.
-
-	2. Sends the x-rh-identity header off to rbac to get an ACL list, and
-	   returns whether or not it contains the correct `sources:*:*` permission.
+	   PSKs we approve, lets it through as long as the route, the verb and the
+	   tenant are within the PSK's scopes. The PSK's name gets logged along
+	   with the request.
+
+	2. Checks whether the x-rh-identity header belongs to a system (cn or
+	   cluster_id), and if it does lets it through as long as the system's
+	   policy in SystemPolicies grants the route, and the source the route
+	   targets belongs to the system.
+
+	3. Sends the x-rh-identity header off to rbac to get an ACL list, and
+	   returns whether or not it grants the permission the route requires in
+	   RoutePermissions, e.g. `sources:source:read`, taking into account the
+	   resource definitions of the ACL. The ACLs are cached for a short while,
+	   unless an internal request carries the `x-rh-sources-bypass-rbac-cache`
+	   header.
 */
 func PermissionCheck(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -41,8 +57,20 @@ func PermissionCheck(next echo.HandlerFunc) echo.HandlerFunc {
 				return fmt.Errorf("error casting psk to string: %v", c.Get("psk"))
 			}
 
-			if !pskMatches(psk) {
-				return c.JSON(http.StatusUnauthorized, util.ErrorDoc("Unauthorized Action: Incorrect PSK", "401"))
+			key, ok := psks.Match(psk)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, util.ErrorDoc(c.Request().Context(), "Unauthorized Action: Incorrect PSK", "401"))
+			}
+
+			c.Set("psk-name", key.Name)
+			c.Request().Header.Set(PskNameHeader, key.Name)
+
+			if !key.allowsRoute(c) {
+				return c.JSON(http.StatusForbidden, util.ErrorDoc(c.Request().Context(), fmt.Sprintf("Forbidden Action: the PSK %q is not allowed to use this route", key.Name), "403"))
+			}
+
+			if !key.allowsTenant(c) {
+				return c.JSON(http.StatusForbidden, util.ErrorDoc(c.Request().Context(), fmt.Sprintf("Forbidden Action: the PSK %q is not allowed to act on behalf of this tenant", key.Name), "403"))
 			}
 
 		case c.Get("x-rh-identity") != nil:
@@ -58,25 +86,20 @@ func PermissionCheck(next echo.HandlerFunc) echo.HandlerFunc {
 			// system-auth is treated completely differently than
 			// org_admin/rbac/psk
 			if identity.Identity.System != nil {
-				// system-auth only allows GET and POST requests.
-				if c.Request().Method != http.MethodGet && c.Request().Method != http.MethodPost {
-					c.Response().Header().Set("Allow", "GET, POST")
-					return c.JSON(http.StatusMethodNotAllowed, util.ErrorDoc("Method not allowed", "405"))
-				}
-
-				// basically we're checking whether cn or cluster_id is set in
-				// the system section of the header, if it is then this request
-				// can go through (but only if it's a POST)
+				// the system's policy decides which routes it may use, and
+				// it may only use them on the sources it owns.
 				//
 				// we're returning early because this is easier than a goto.
-				switch {
-				case identity.Identity.System["cluster_id"] != nil:
-					return next(c)
-				case identity.Identity.System["cn"] != nil:
-					return next(c)
-				default:
-					return c.JSON(http.StatusUnauthorized, util.ErrorDoc("Unauthorized Action: system authorization only supports cn/cluster_id authorization", "401"))
+				granted, reason, err := systemAllowed(c, identity.Identity.System)
+				if err != nil {
+					return fmt.Errorf("error checking the system policy: %w", err)
 				}
+
+				if !granted {
+					return c.JSON(http.StatusForbidden, util.ErrorDoc(c.Request().Context(), "Forbidden Action: "+reason, "403"))
+				}
+
+				return next(c)
 			}
 
 			// otherwise, ship the xrhid off to rbac and check access rights.
@@ -85,45 +108,60 @@ func PermissionCheck(next echo.HandlerFunc) echo.HandlerFunc {
 				return fmt.Errorf("error casting x-rh-identity to string: %v", c.Get("x-rh-identity"))
 			}
 
-			allowed, err := rbacClient.Allowed(rhid)
+			permission, ok := routePermission(c)
+			if !ok {
+				return c.JSON(http.StatusForbidden, util.ErrorDoc(c.Request().Context(), "Forbidden Action: no permission grants access to this route", "403"))
+			}
+
+			acl, err := rbacAccess(rhid, c.Get("bypass-rbac-cache") != nil)
 			if err != nil {
 				return fmt.Errorf("error hitting rbac: %v", err)
 			}
 
-			if !allowed {
-				return c.JSON(http.StatusUnauthorized, util.ErrorDoc("Unauthorized Action: Missing RBAC permissions", "401"))
+			granted, err := allowed(c, acl, permission)
+			if err != nil {
+				return fmt.Errorf("error matching the rbac resource definitions: %w", err)
+			}
+
+			if !granted {
+				return c.JSON(http.StatusForbidden, util.ErrorDoc(c.Request().Context(), fmt.Sprintf("Forbidden Action: Missing RBAC permission %s", permission), "403"))
 			}
 
 		default:
-			return c.JSON(http.StatusUnauthorized, util.ErrorDoc("Authentication required by either [x-rh-identity] or [x-rh-sources-psk]", "401"))
+			return c.JSON(http.StatusUnauthorized, util.ErrorDoc(c.Request().Context(), "Authentication required by either [x-rh-identity] or [x-rh-sources-psk]", "401"))
 		}
 
 		return next(c)
 	}
 }
 
-func pskMatches(psk string) bool {
-	return util.SliceContainsString(psks, psk)
+// WatchPsks reloads the PSK file, so that the rotated keys get picked up, until the context gets cancelled.
+func WatchPsks(ctx context.Context) {
+	psks.Watch(ctx, time.Duration(config.Get().PskReloadInterval)*time.Second)
+}
+
+// rbacAccess returns the access list of the identity, skipping the cache when asked to.
+func rbacAccess(xrhid string, bypassCache bool) (rbac.AccessList, error) {
+	if cache, ok := rbacClient.(*RbacCache); ok && bypassCache {
+		return cache.Refresh(xrhid)
+	}
+
+	return rbacClient.Access(xrhid)
 }
 
 type Rbac interface {
-	Allowed(string) (bool, error)
+	// Access returns the access list of the principal behind the given identity.
+	Access(xrhid string) (rbac.AccessList, error)
 }
 
 type RbacClient struct {
 	client rbac.Client
 }
 
-// fetches an access list from RBAC based on RBAC_URL and returns whether or not
-// the xrhid has the `sources:*:*` permission
-func (r *RbacClient) Allowed(xrhid string) (bool, error) {
+// fetches an access list for the sources application from RBAC based on RBAC_URL
+func (r *RbacClient) Access(xrhid string) (rbac.AccessList, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	acl, err := r.client.GetAccess(ctx, xrhid, "")
-	if err != nil {
-		return false, err
-	}
-
-	return acl.IsAllowed("sources", "*", "*"), nil
+	return r.client.GetAccess(ctx, xrhid, "")
 }
@@ -3,9 +3,16 @@ package middleware
 import (
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/RedHatInsights/rbac-client-go"
+	"github.com/RedHatInsights/sources-api-go/dao"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 	"github.com/redhatinsights/platform-go-middlewares/identity"
 )
@@ -37,18 +44,18 @@ func TestRbacDisabled(t *testing.T) {
 }
 
 func TestPSKMatches(t *testing.T) {
-	psks = []string{"1234"}
-	if pskMatches("1234") != true {
+	psks = NewPskStore("", []string{"1234"}, false)
+	if _, ok := psks.Match("1234"); !ok {
 		t.Errorf("psk didn't match when it should have")
 	}
 
-	if pskMatches("12345") == true {
+	if _, ok := psks.Match("12345"); ok {
 		t.Errorf("psk matched when it should not have")
 	}
 }
 
 func TestGoodPSK(t *testing.T) {
-	psks = []string{"1234"}
+	psks = NewPskStore("", []string{"1234"}, false)
 	c, rec := request.CreateTestContext(
 		http.MethodPost,
 		"/",
@@ -67,7 +74,7 @@ func TestGoodPSK(t *testing.T) {
 }
 
 func TestBadPSK(t *testing.T) {
-	psks = []string{"abcdef"}
+	psks = NewPskStore("", []string{"abcdef"}, false)
 	c, rec := request.CreateTestContext(
 		http.MethodPost,
 		"/",
@@ -86,7 +93,7 @@ func TestBadPSK(t *testing.T) {
 }
 
 func TestNoPSK(t *testing.T) {
-	psks = []string{"abcdef"}
+	psks = NewPskStore("", []string{"abcdef"}, false)
 	c, rec := request.CreateTestContext(
 		"POST",
 		"/",
@@ -104,45 +111,58 @@ func TestNoPSK(t *testing.T) {
 	}
 }
 
-func TestSystemClusterID(t *testing.T) {
-	c, rec := request.CreateTestContext(
-		http.MethodPost,
-		"/",
-		nil,
-		map[string]interface{}{
-			"x-rh-identity": "dummy",
-			"identity": identity.XRHID{
-				Identity: identity.Identity{
-					System: map[string]interface{}{"cluster_id": "test_cluster"},
-				},
-			},
-		},
-	)
+// yay dummy structs!
+type dummyRbac struct {
+	acl    rbac.AccessList
+	blowup bool
+}
 
-	err := permCheckOrElse204(c)
-	if err != nil {
-		t.Errorf("caught an error when there should not have been one")
+func (d dummyRbac) Access(_ string) (rbac.AccessList, error) {
+	if d.blowup {
+		return nil, errors.New("kablooey!")
 	}
 
-	if rec.Code != 204 {
-		t.Errorf("%v was returned instead of %v", rec.Code, 204)
-	}
+	return d.acl, nil
 }
 
-func TestSystemCN(t *testing.T) {
+const wildXrhid = "a wild xrhid - i mean eyJlbnRpdGxlbWVudHMiOnsiaW5zaWdodHMiOnsiaXNfZW50aXRsZWQiOnRydWV9LCJtaWdyYXRpb25zIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwiaHlicmlkX2Nsb3VkIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwib3BlbnNoaWZ0Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwic21hcnRfbWFuYWdlbWVudCI6eyJpc19lbnRpdGxlZCI6dHJ1Z"
+
+// rbacTestContext returns a context for the "POST /sources/:id" route, which requires the "sources:source:write"
+// permission restricted to the source in the path.
+func rbacTestContext(t *testing.T, sourceId string) (echo.Context, *httptest.ResponseRecorder) {
+	RoutePermissions = map[string]Permission{
+		"POST /sources/:id": {Resource: "source", Verb: VerbWrite, Source: SourceParam("id")},
+	}
+	t.Cleanup(func() { RoutePermissions = map[string]Permission{} })
+
 	c, rec := request.CreateTestContext(
 		http.MethodPost,
-		"/",
+		"/sources/"+sourceId,
 		nil,
 		map[string]interface{}{
-			"x-rh-identity": "dummy",
-			"identity": identity.XRHID{
-				Identity: identity.Identity{
-					System: map[string]interface{}{"cn": "test_cert"},
-				},
-			},
+			"x-rh-identity": wildXrhid,
+			"identity":      identity.XRHID{Identity: identity.Identity{}},
+			"tenantID":      int64(1),
 		},
 	)
+	c.SetPath("/sources/:id")
+	c.SetParamNames("id")
+	c.SetParamValues(sourceId)
+
+	return c, rec
+}
+
+// useSourceDao makes the permission check find the given sources.
+func useSourceDao(t *testing.T, sources []m.Source) {
+	original := dao.GetSourceDao
+	dao.GetSourceDao = func(*int64) dao.SourceDao { return &dao.MockSourceDao{Sources: sources} }
+	t.Cleanup(func() { dao.GetSourceDao = original })
+}
+
+func TestRbacWithAccess(t *testing.T) {
+	rbacClient = dummyRbac{acl: rbac.AccessList{{Permission: "sources:*:*"}}}
+
+	c, rec := rbacTestContext(t, "1")
 
 	err := permCheckOrElse204(c)
 	if err != nil {
@@ -154,128 +174,155 @@ func TestSystemCN(t *testing.T) {
 	}
 }
 
-func TestSystemPatch(t *testing.T) {
-	c, rec := request.CreateTestContext(
-		http.MethodPatch,
-		"/",
-		nil,
-		map[string]interface{}{
-			"x-rh-identity": "dummy",
-			"identity": identity.XRHID{
-				Identity: identity.Identity{
-					System: map[string]interface{}{"cn": "test_cert"},
-				},
-			},
-		},
-	)
+func TestRbacWithoutAccess(t *testing.T) {
+	rbacClient = dummyRbac{acl: rbac.AccessList{{Permission: "sources:source:read"}, {Permission: "cost-management:*:*"}}}
+
+	c, rec := rbacTestContext(t, "1")
 
 	err := permCheckOrElse204(c)
 	if err != nil {
 		t.Errorf("caught an error when there should not have been one")
 	}
 
-	if rec.Code != 405 {
-		t.Errorf("%v was returned instead of %v", rec.Code, 405)
+	if rec.Code != 403 {
+		t.Errorf("%v was returned instead of %v", rec.Code, 403)
 	}
 }
 
-func TestSystemDelete(t *testing.T) {
-	c, rec := request.CreateTestContext(
-		http.MethodDelete,
-		"/",
-		nil,
-		map[string]interface{}{
-			"x-rh-identity": "dummy",
-			"identity": identity.XRHID{
-				Identity: identity.Identity{
-					System: map[string]interface{}{"cn": "test_cert"},
-				},
-			},
-		},
-	)
+func TestRbacUnknownRoute(t *testing.T) {
+	rbacClient = dummyRbac{acl: rbac.AccessList{{Permission: "sources:*:*"}}}
+
+	c, rec := rbacTestContext(t, "1")
+	c.SetPath("/not/mapped")
 
 	err := permCheckOrElse204(c)
 	if err != nil {
 		t.Errorf("caught an error when there should not have been one")
 	}
 
-	if rec.Code != 405 {
-		t.Errorf("%v was returned instead of %v", rec.Code, 405)
+	if rec.Code != 403 {
+		t.Errorf("%v was returned instead of %v", rec.Code, 403)
 	}
 }
 
-// yay dummy structs!
-type dummyRbac struct {
-	access bool
-	blowup bool
-}
+func TestRbacResourceDefinitions(t *testing.T) {
+	useSourceDao(t, []m.Source{
+		{ID: 1, SourceType: m.SourceType{Name: "amazon"}},
+		{ID: 2, SourceType: m.SourceType{Name: "azure"}},
+	})
+
+	restricted := func(key, operation, value string) rbac.AccessList {
+		return rbac.AccessList{{
+			Permission:          "sources:source:write",
+			ResourceDefinitions: []rbac.ResourceDefinition{{Filter: rbac.ResourceDefinitionFilter{Key: key, Operation: operation, Value: value}}},
+		}}
+	}
 
-func (d dummyRbac) Allowed(_ string) (bool, error) {
-	if d.blowup {
-		return false, errors.New("kablooey!")
+	tests := []struct {
+		name     string
+		acl      rbac.AccessList
+		sourceId string
+		want     int
+	}{
+		{"source id equal", restricted("sources.source_id", "equal", "1"), "1", 204},
+		{"another source id", restricted("sources.source_id", "equal", "1"), "2", 403},
+		{"source id in", restricted("sources.source_id", "in", "3, 2"), "2", 204},
+		{"source type equal", restricted("sources.source_type", "equal", "amazon"), "1", 204},
+		{"another source type", restricted("sources.source_type", "equal", "amazon"), "2", 403},
+		{"unknown key", restricted("sources.name", "equal", "amazon"), "1", 403},
+		{"unknown operation", restricted("sources.source_id", "like", "1"), "1", 403},
+		{"missing source", restricted("sources.source_id", "equal", "3"), "3", 403},
+		{"invalid source id", restricted("sources.source_id", "equal", "1"), "abc", 403},
 	}
 
-	return d.access, nil
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rbacClient = dummyRbac{acl: test.acl}
+
+			c, rec := rbacTestContext(t, test.sourceId)
+
+			err := permCheckOrElse204(c)
+			if err != nil {
+				t.Errorf("caught an error when there should not have been one: %s", err)
+			}
+
+			if rec.Code != test.want {
+				t.Errorf("%v was returned instead of %v", rec.Code, test.want)
+			}
+		})
+	}
 }
 
-func TestRbacWithAccess(t *testing.T) {
-	rbacClient = dummyRbac{access: true}
+// TestRbacResourceDefinitionsWithoutSource tests that the restricted accesses don't grant the routes which neither
+// target a single source nor list the resources of the sources, such as the creation of the sources.
+func TestRbacResourceDefinitionsWithoutSource(t *testing.T) {
+	rbacClient = dummyRbac{acl: rbac.AccessList{{
+		Permission:          "sources:source:write",
+		ResourceDefinitions: []rbac.ResourceDefinition{{Filter: rbac.ResourceDefinitionFilter{Key: "sources.source_id", Operation: "equal", Value: "1"}}},
+	}}}
 
-	c, rec := request.CreateTestContext(
-		"POST",
-		"/",
-		nil,
-		map[string]interface{}{
-			"x-rh-identity": "a wild xrhid - i mean eyJlbnRpdGxlbWVudHMiOnsiaW5zaWdodHMiOnsiaXNfZW50aXRsZWQiOnRydWV9LCJtaWdyYXRpb25zIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwiaHlicmlkX2Nsb3VkIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwib3BlbnNoaWZ0Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwic21hcnRfbWFuYWdlbWVudCI6eyJpc19lbnRpdGxlZCI6dHJ1Z",
-			"identity":      identity.XRHID{Identity: identity.Identity{}},
-		},
-	)
+	c, rec := rbacTestContext(t, "1")
+	RoutePermissions["POST /sources/:id"] = Permission{Resource: "source", Verb: VerbWrite}
 
 	err := permCheckOrElse204(c)
 	if err != nil {
 		t.Errorf("caught an error when there should not have been one")
 	}
 
-	if rec.Code != 204 {
-		t.Errorf("%v was returned instead of %v", rec.Code, 204)
+	if rec.Code != 403 {
+		t.Errorf("%v was returned instead of %v", rec.Code, 403)
 	}
 }
 
-func TestRbacWithoutAccess(t *testing.T) {
-	rbacClient = dummyRbac{access: false}
+// TestRbacResourceDefinitionsScopeLists tests that the restricted accesses grant the list routes, which then only list
+// the resources of the sources that the resource definitions grant, while the unrestricted accesses list everything.
+func TestRbacResourceDefinitionsScopeLists(t *testing.T) {
+	definition := func(key, operation, value string) rbac.ResourceDefinition {
+		return rbac.ResourceDefinition{Filter: rbac.ResourceDefinitionFilter{Key: key, Operation: operation, Value: value}}
+	}
 
-	c, rec := request.CreateTestContext(
-		"POST",
-		"/",
-		nil,
-		map[string]interface{}{
-			"x-rh-identity": "a wild xrhid - i mean eyJlbnRpdGxlbWVudHMiOnsiaW5zaWdodHMiOnsiaXNfZW50aXRsZWQiOnRydWV9LCJtaWdyYXRpb25zIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwiaHlicmlkX2Nsb3VkIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwib3BlbnNoaWZ0Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwic21hcnRfbWFuYWdlbWVudCI6eyJpc19lbnRpdGxlZCI6dHJ1Z",
-			"identity":      identity.XRHID{Identity: identity.Identity{}},
-		},
-	)
+	restricted := rbac.AccessList{
+		{Permission: "sources:source:read", ResourceDefinitions: []rbac.ResourceDefinition{definition("sources.source_id", "in", "1, abc")}},
+		{Permission: "sources:*:read", ResourceDefinitions: []rbac.ResourceDefinition{definition("sources.source_type", "equal", "amazon")}},
+		{Permission: "sources:source:write", ResourceDefinitions: []rbac.ResourceDefinition{definition("sources.source_id", "equal", "2")}},
+	}
 
-	err := permCheckOrElse204(c)
-	if err != nil {
-		t.Errorf("caught an error when there should not have been one")
+	tests := []struct {
+		name  string
+		acl   rbac.AccessList
+		scope interface{}
+	}{
+		{"restricted", restricted, util.SourceScope{SourceIds: []int64{1}, SourceTypes: []string{"amazon"}}.Filter("id")},
+		{"unrestricted", append(restricted, rbac.Access{Permission: "sources:source:read"}), nil},
 	}
 
-	if rec.Code != 401 {
-		t.Errorf("%v was returned instead of %v", rec.Code, 401)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rbacClient = dummyRbac{acl: test.acl}
+
+			c, rec := rbacTestContext(t, "1")
+			RoutePermissions["POST /sources/:id"] = Permission{Resource: "source", Verb: VerbRead, ScopeColumn: "id"}
+
+			err := permCheckOrElse204(c)
+			if err != nil {
+				t.Errorf("caught an error when there should not have been one: %s", err)
+			}
+
+			if rec.Code != 204 {
+				t.Errorf("%v was returned instead of %v", rec.Code, 204)
+			}
+
+			if !reflect.DeepEqual(c.Get(sourceScopeKey), test.scope) {
+				t.Errorf("want the source scope %v, got %v", test.scope, c.Get(sourceScopeKey))
+			}
+		})
 	}
 }
 
 func TestRbacNoConnection(t *testing.T) {
-	rbacClient = dummyRbac{access: false, blowup: true}
+	rbacClient = dummyRbac{blowup: true}
 
-	c, _ := request.CreateTestContext(
-		"POST",
-		"/",
-		nil,
-		map[string]interface{}{
-			"x-rh-identity": "a wild xrhid - i mean eyJlbnRpdGxlbWVudHMiOnsiaW5zaWdodHMiOnsiaXNfZW50aXRsZWQiOnRydWV9LCJtaWdyYXRpb25zIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwiaHlicmlkX2Nsb3VkIjp7ImlzX2VudGl0bGVkIjp0cnVlfSwib3BlbnNoaWZ0Ijp7ImlzX2VudGl0bGVkIjp0cnVlfSwic21hcnRfbWFuYWdlbWVudCI6eyJpc19lbnRpdGxlZCI6dHJ1Z",
-			"identity":      identity.XRHID{Identity: identity.Identity{}},
-		},
-	)
+	c, _ := rbacTestContext(t, "1")
 
 	err := permCheckOrElse204(c)
 
@@ -283,3 +330,40 @@ func TestRbacNoConnection(t *testing.T) {
 		t.Errorf("no error was returned when we were expecting one!")
 	}
 }
+
+func TestPermissionString(t *testing.T) {
+	permission := Permission{Resource: "authentication", Verb: VerbWrite}
+	if permission.String() != "sources:authentication:write" {
+		t.Errorf(`want "sources:authentication:write", got %q`, permission)
+	}
+}
+
+func TestRbacCacheBypass(t *testing.T) {
+	client := &countingRbac{acl: grantingAcl}
+	rbacClient = NewRbacCache(client, time.Minute, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		c, rec := rbacTestContext(t, "1")
+
+		err := permCheckOrElse204(c)
+		if err != nil {
+			t.Errorf("caught an error when there should not have been one")
+		}
+
+		if rec.Code != 204 {
+			t.Errorf("%v was returned instead of %v", rec.Code, 204)
+		}
+	}
+
+	if client.lookups != 1 {
+		t.Errorf("want the access list to be cached, got %d lookups", client.lookups)
+	}
+
+	c, _ := rbacTestContext(t, "1")
+	c.Set("bypass-rbac-cache", true)
+
+	_ = permCheckOrElse204(c)
+	if client.lookups != 2 {
+		t.Errorf("want the cache to be bypassed, got %d lookups", client.lookups)
+	}
+}
@@ -18,13 +18,16 @@ func HandleErrors(next echo.HandlerFunc) echo.HandlerFunc {
 			switch err.(type) {
 			case util.ErrNotFound:
 				statusCode = http.StatusNotFound
-				message = util.ErrorDoc(err.Error(), "404")
+				message = util.ErrorDoc(c.Request().Context(), err.Error(), "404")
 			case util.ErrBadRequest:
 				statusCode = http.StatusBadRequest
-				message = util.ErrorDoc(err.Error(), "400")
+				message = util.ErrorDoc(c.Request().Context(), err.Error(), "400")
+			case util.ErrConflict:
+				statusCode = http.StatusConflict
+				message = util.ErrorDoc(c.Request().Context(), err.Error(), "409")
 			default:
 				statusCode = http.StatusInternalServerError
-				message = util.ErrorDoc(fmt.Sprintf("Internal Server Error: %v", err.Error()), "500")
+				message = util.ErrorDoc(c.Request().Context(), fmt.Sprintf("Internal Server Error: %v", err.Error()), "500")
 			}
 			return c.JSON(statusCode, message)
 		}
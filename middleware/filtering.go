@@ -14,6 +14,11 @@ func SortAndFilter(next echo.HandlerFunc) echo.HandlerFunc {
 			filters = append(filters, *sort)
 		}
 
+		// the permission check restricts the lists to the sources that the RBAC resource definitions grant.
+		if scope, ok := c.Get(sourceScopeKey).(util.Filter); ok {
+			filters = append(filters, scope)
+		}
+
 		c.Set("filters", filters)
 		return next(c)
 	}
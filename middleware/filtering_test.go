@@ -3,9 +3,11 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 )
 
@@ -107,3 +109,27 @@ func TestParseSortingMultiple(t *testing.T) {
 		t.Error("sort[1] value did not get parsed correctly")
 	}
 }
+
+// TestSortAndFilterAppendsSourceScope tests that the source scope that the permission check sets gets added to the
+// request's filters.
+func TestSortAndFilterAppendsSourceScope(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/sources/v3.1/sources?filter[name]=test", nil)
+	c := e.NewContext(req, nil)
+
+	scope := util.SourceScope{SourceIds: []int64{1}}.Filter("id")
+	c.Set(sourceScopeKey, scope)
+
+	err := SortAndFilter(func(c echo.Context) error { return nil })(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filters, ok := c.Get("filters").([]util.Filter)
+	if !ok || len(filters) != 2 {
+		t.Fatalf("want the name filter and the source scope, got %v", c.Get("filters"))
+	}
+
+	if !reflect.DeepEqual(filters[1], scope) {
+		t.Errorf("want the source scope %v, got %v", scope, filters[1])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 	"github.com/redhatinsights/platform-go-middlewares/identity"
 )
@@ -21,11 +22,19 @@ import (
       of known keys which are set in vault, if it matches any of them the
       request is authorized.
 
-    3. `x-rh-sources-account-number`: used with a PSK to access a certain
-       account. Only accessible from within the CRC cluster.
+    3. `x-rh-sources-account-number` and `x-rh-sources-org-id`: used with a
+       PSK to access a certain account or organization. Only accessible from
+       within the CRC cluster.
+
+    The `x-rh-sources-bypass-rbac-cache` header is left to the
+    AllowRbacCacheBypass middleware, since only the internal routes honor it.
 */
 func ParseHeaders(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		// the PSK name is only set once the PSK is checked, so the callers can't
+		// make the requests look like somebody else's.
+		c.Request().Header.Del(PskNameHeader)
+
 		// the PSK related headers - just storing them as raw strings.
 		if c.Request().Header.Get("x-rh-sources-psk") != "" {
 			c.Set("psk", c.Request().Header.Get("x-rh-sources-psk"))
@@ -35,6 +44,10 @@ func ParseHeaders(next echo.HandlerFunc) echo.HandlerFunc {
 			c.Set("psk-account", c.Request().Header.Get("x-rh-sources-account-number"))
 		}
 
+		if c.Request().Header.Get("x-rh-sources-org-id") != "" {
+			c.Set("psk-org-id", c.Request().Header.Get("x-rh-sources-org-id"))
+		}
+
 		// parsing the base64-encoded identity header if present
 		if c.Request().Header.Get("x-rh-identity") != "" {
 			// store it raw first.
@@ -54,12 +67,27 @@ func ParseHeaders(next echo.HandlerFunc) echo.HandlerFunc {
 			// store the parsed header for later usage.
 			c.Set("identity", id)
 
-			// store whether or not this a cert-auth based request
-			if id.Identity.System != nil && id.Identity.System["cn"] != nil {
-				c.Set("cert-auth", true)
+			// the identity library doesn't know about the top level org_id, so
+			// it gets parsed on its own.
+			if orgId := util.OrgIdFromIdentity(idRaw); orgId != "" {
+				c.Set("identity-org-id", orgId)
 			}
 		}
 
 		return next(c)
 	}
 }
+
+// AllowRbacCacheBypass honors the `x-rh-sources-bypass-rbac-cache` header,
+// which makes the internal callers that need to see the permissions they have
+// just granted skip the cached RBAC access lists. It must only be used on the
+// internal routes, so that the public callers can't flood RBAC with lookups.
+func AllowRbacCacheBypass(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Header.Get("x-rh-sources-bypass-rbac-cache") == "true" {
+			c.Set("bypass-rbac-cache", true)
+		}
+
+		return next(c)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
 	"github.com/redhatinsights/platform-go-middlewares/identity"
 )
@@ -25,6 +26,7 @@ func TestParseAll(t *testing.T) {
 	c.Request().Header.Set("x-rh-identity", xrhid)
 	c.Request().Header.Set("x-rh-sources-psk", "1234")
 	c.Request().Header.Set("x-rh-sources-account-number", "9876")
+	c.Request().Header.Set("x-rh-sources-bypass-rbac-cache", "true")
 
 	err := parseOrElse204(c)
 	if err != nil {
@@ -43,6 +45,11 @@ func TestParseAll(t *testing.T) {
 		t.Errorf("%v was set as psk-account instead of %v", c.Get("psk-account").(string), "9876")
 	}
 
+	// the cache bypass is only honored on the internal routes.
+	if c.Get("bypass-rbac-cache") != nil {
+		t.Errorf("want the bypass-rbac-cache header ignored, got %v", c.Get("bypass-rbac-cache"))
+	}
+
 	id, _ := c.Get("identity").(identity.XRHID)
 
 	if id.Identity.AccountNumber != "12345" {
@@ -50,6 +57,26 @@ func TestParseAll(t *testing.T) {
 	}
 }
 
+func TestAllowRbacCacheBypass(t *testing.T) {
+	bypassOrElse204 := AllowRbacCacheBypass(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	for _, value := range []string{"true", "false", ""} {
+		c, _ := request.CreateTestContext(http.MethodGet, "/", nil, map[string]interface{}{})
+		c.Request().Header.Set("x-rh-sources-bypass-rbac-cache", value)
+
+		err := bypassOrElse204(c)
+		if err != nil {
+			t.Errorf("caught an error when there should not have been one: %v", err)
+		}
+
+		if want := value == "true"; (c.Get("bypass-rbac-cache") == true) != want {
+			t.Errorf("header %q: want the cache bypassed %v, got %v", value, want, c.Get("bypass-rbac-cache"))
+		}
+	}
+}
+
 func TestBadIdentityBase64(t *testing.T) {
 	c, rec := request.CreateTestContext(
 		http.MethodGet,
@@ -131,3 +158,32 @@ func TestOnlyPskHeaders(t *testing.T) {
 		t.Errorf("an identity was present when none was specified")
 	}
 }
+
+func TestParseOrgIds(t *testing.T) {
+	c, rec := request.CreateTestContext(
+		http.MethodGet,
+		"/",
+		nil,
+		map[string]interface{}{},
+	)
+
+	c.Request().Header.Set("x-rh-identity", util.GeneratedXRhIdentity("", "5678"))
+	c.Request().Header.Set("x-rh-sources-org-id", "9876")
+
+	err := parseOrElse204(c)
+	if err != nil {
+		t.Errorf("caught an error when there should not have been one: %v", err)
+	}
+
+	if rec.Code != 204 {
+		t.Errorf("%v was returned instead of %v", rec.Code, 204)
+	}
+
+	if c.Get("identity-org-id") != "5678" {
+		t.Errorf("%v was set as identity-org-id instead of %v", c.Get("identity-org-id"), "5678")
+	}
+
+	if c.Get("psk-org-id") != "9876" {
+		t.Errorf("%v was set as psk-org-id instead of %v", c.Get("psk-org-id"), "9876")
+	}
+}
@@ -0,0 +1,356 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/RedHatInsights/rbac-client-go"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// rbacApplication is the application the permissions are granted for in RBAC.
+	rbacApplication = "sources"
+
+	// VerbRead grants reading the resources.
+	VerbRead = "read"
+	// VerbWrite grants creating, modifying, pausing and deleting the resources.
+	VerbWrite = "write"
+
+	// resourceDefinitionSourceId is the attribute filter key which restricts an access to the given source ids.
+	resourceDefinitionSourceId = "sources.source_id"
+	// resourceDefinitionSourceType is the attribute filter key which restricts an access to the sources of the given
+	// source type names.
+	resourceDefinitionSourceType = "sources.source_type"
+)
+
+// sourceScopeKey is the key of the request's source scope filter, which the filtering middleware adds to the lists'
+// filters.
+const sourceScopeKey = "source-scope"
+
+// errSourceNotFound is returned by the source finders when the request's source doesn't exist.
+var errSourceNotFound = errors.New("the source of the request was not found")
+
+// Permission is what a route requires from the principal's RBAC access list.
+type Permission struct {
+	// Resource and Verb form the "sources:<resource>:<verb>" permission.
+	Resource string
+	Verb     string
+	// Source finds the source that the request targets, which the resource definitions of the access list get
+	// matched against.
+	Source SourceFinder
+	// ScopeColumn is the column which holds the source ids of the resources that the list routes return. The
+	// accesses with resource definitions grant these routes too, but the lists only return the resources of the
+	// sources they grant. The routes with neither a source finder nor a scope column are only granted by the accesses
+	// without resource definitions.
+	ScopeColumn string
+}
+
+// String returns the permission as RBAC spells it.
+func (p Permission) String() string {
+	return fmt.Sprintf("%s:%s:%s", rbacApplication, p.Resource, p.Verb)
+}
+
+// RoutePermissions maps every route that goes through the permission check, as its method and path separated by a
+// space, to the permission it requires. The routes without an entry are denied.
+var RoutePermissions = map[string]Permission{}
+
+// routePermission returns the permission required by the request's route.
+func routePermission(c echo.Context) (Permission, bool) {
+	permission, ok := RoutePermissions[c.Request().Method+" "+c.Path()]
+	return permission, ok
+}
+
+// allowed tells whether the access list grants the permission. The accesses with resource definitions only grant it
+// when the source that the request targets matches any of their attribute filters, so the source is only looked up
+// when no access grants the permission unrestrictedly. On the list routes, they restrict the list to the sources
+// they grant instead.
+func allowed(c echo.Context, acl rbac.AccessList, permission Permission) (bool, error) {
+	restricted := make([]rbac.Access, 0)
+
+	for _, access := range acl {
+		if access.Application() != rbacApplication || !matchesWildcard(access.Resource(), permission.Resource) || !matchesWildcard(access.Verb(), permission.Verb) {
+			continue
+		}
+
+		if len(access.ResourceDefinitions) == 0 {
+			return true, nil
+		}
+
+		restricted = append(restricted, access)
+	}
+
+	if len(restricted) == 0 {
+		return false, nil
+	}
+
+	if permission.Source == nil {
+		if permission.ScopeColumn == "" {
+			return false, nil
+		}
+
+		c.Set(sourceScopeKey, grantedScope(restricted).Filter(permission.ScopeColumn))
+		return true, nil
+	}
+
+	tenantId, ok := c.Get("tenantID").(int64)
+	if !ok {
+		return false, fmt.Errorf("the tenant is required to match the resource definitions")
+	}
+
+	sourceId, err := permission.Source(c)
+	if errors.Is(err, errSourceNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	source, err := dao.GetSourceDao(&tenantId).GetByIdWithPreload(&sourceId, "SourceType")
+	if errors.Is(err, util.ErrNotFoundEmpty) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, access := range restricted {
+		for _, definition := range access.ResourceDefinitions {
+			if definitionMatches(definition.Filter, strconv.FormatInt(source.ID, 10), source.SourceType.Name) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// grantedScope returns the source ids and the source type names that the resource definitions of the accesses grant.
+// The invalid source ids are left out, since they can't match any source.
+func grantedScope(accesses []rbac.Access) util.SourceScope {
+	scope := util.SourceScope{SourceIds: make([]int64, 0), SourceTypes: make([]string, 0)}
+
+	for _, access := range accesses {
+		for _, definition := range access.ResourceDefinitions {
+			for _, value := range definitionValues(definition.Filter) {
+				switch definition.Filter.Key {
+				case resourceDefinitionSourceId:
+					if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+						scope.SourceIds = append(scope.SourceIds, id)
+					}
+				case resourceDefinitionSourceType:
+					scope.SourceTypes = append(scope.SourceTypes, value)
+				}
+			}
+		}
+	}
+
+	return scope
+}
+
+// definitionMatches tells whether the attribute filter matches the source with the given id and source type name.
+// The filters on unknown attributes, or with unknown operations, don't match anything.
+func definitionMatches(filter rbac.ResourceDefinitionFilter, sourceId, sourceTypeName string) bool {
+	var attribute string
+	switch filter.Key {
+	case resourceDefinitionSourceId:
+		attribute = sourceId
+	case resourceDefinitionSourceType:
+		attribute = sourceTypeName
+	default:
+		return false
+	}
+
+	for _, value := range definitionValues(filter) {
+		if value == attribute {
+			return true
+		}
+	}
+
+	return false
+}
+
+// definitionValues returns the values that the attribute filter accepts. The filters with unknown operations don't
+// accept any.
+func definitionValues(filter rbac.ResourceDefinitionFilter) []string {
+	switch filter.Operation {
+	case "equal":
+		return []string{filter.Value}
+	case "in":
+		values := strings.Split(filter.Value, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// matchesWildcard tells whether the part of a granted permission covers the required one.
+func matchesWildcard(granted, required string) bool {
+	return granted == required || granted == "*"
+}
+
+// SourceFinder returns the id of the source that the request targets.
+type SourceFinder func(c echo.Context) (int64, error)
+
+// SourceParam finds the source in the given path parameter.
+func SourceParam(param string) SourceFinder {
+	return func(c echo.Context) (int64, error) {
+		return idParam(c, param)
+	}
+}
+
+// ApplicationParam finds the source of the application in the given path parameter.
+func ApplicationParam(param string) SourceFinder {
+	return func(c echo.Context) (int64, error) {
+		id, err := idParam(c, param)
+		if err != nil {
+			return 0, err
+		}
+
+		return applicationSource(c, id)
+	}
+}
+
+// EndpointParam finds the source of the endpoint in the given path parameter.
+func EndpointParam(param string) SourceFinder {
+	return func(c echo.Context) (int64, error) {
+		id, err := idParam(c, param)
+		if err != nil {
+			return 0, err
+		}
+
+		endpoint, err := dao.GetEndpointDao(tenantOf(c)).GetById(&id)
+		if err != nil {
+			return 0, notFound(err)
+		}
+
+		return endpoint.SourceID, nil
+	}
+}
+
+// AuthenticationParam finds the source of the authentication in the given path parameter.
+func AuthenticationParam(param string) SourceFinder {
+	return func(c echo.Context) (int64, error) {
+		auth, err := dao.GetAuthenticationDao(tenantOf(c)).GetById(c.Param(param))
+		if err != nil {
+			return 0, notFound(err)
+		}
+
+		return auth.SourceID, nil
+	}
+}
+
+// ApplicationAuthenticationParam finds the source of the application authentication in the given path parameter.
+func ApplicationAuthenticationParam(param string) SourceFinder {
+	return func(c echo.Context) (int64, error) {
+		id, err := idParam(c, param)
+		if err != nil {
+			return 0, err
+		}
+
+		appAuth, err := dao.GetApplicationAuthenticationDao(tenantOf(c)).GetById(&id)
+		if err != nil {
+			return 0, notFound(err)
+		}
+
+		return applicationSource(c, appAuth.ApplicationID)
+	}
+}
+
+// SourceInBody finds the source in the request's payload, either in its "source_id" or as the resource the payload
+// refers to through its "resource_type" and "resource_id". The payload is left in place for the handler.
+func SourceInBody(c echo.Context) (int64, error) {
+	raw, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return 0, err
+	}
+	c.Request().Body = io.NopCloser(bytes.NewReader(raw))
+
+	payload := struct {
+		SourceID     interface{} `json:"source_id"`
+		ResourceType string      `json:"resource_type"`
+		ResourceID   interface{} `json:"resource_id"`
+	}{}
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return 0, errSourceNotFound
+	}
+
+	if payload.SourceID != nil {
+		id, err := util.InterfaceToInt64(payload.SourceID)
+		if err != nil {
+			return 0, errSourceNotFound
+		}
+
+		return id, nil
+	}
+
+	resourceId, err := util.InterfaceToInt64(payload.ResourceID)
+	if err != nil {
+		return 0, errSourceNotFound
+	}
+
+	switch payload.ResourceType {
+	case "Source":
+		return resourceId, nil
+	case "Application":
+		return applicationSource(c, resourceId)
+	case "Endpoint":
+		endpoint, err := dao.GetEndpointDao(tenantOf(c)).GetById(&resourceId)
+		if err != nil {
+			return 0, notFound(err)
+		}
+
+		return endpoint.SourceID, nil
+	default:
+		return 0, errSourceNotFound
+	}
+}
+
+// applicationSource returns the id of the application's source.
+func applicationSource(c echo.Context, applicationId int64) (int64, error) {
+	application, err := dao.GetApplicationDao(tenantOf(c)).GetById(&applicationId)
+	if err != nil {
+		return 0, notFound(err)
+	}
+
+	return application.SourceID, nil
+}
+
+// idParam parses the numeric id in the given path parameter. An invalid id can't point to any source.
+func idParam(c echo.Context, param string) (int64, error) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		return 0, errSourceNotFound
+	}
+
+	return id, nil
+}
+
+// tenantOf returns the request's tenant, which the tenancy middleware has already set.
+func tenantOf(c echo.Context) *int64 {
+	tenantId, _ := c.Get("tenantID").(int64)
+	return &tenantId
+}
+
+// notFound turns the "not found" errors of the DAOs into the error the finders return for missing sources.
+func notFound(err error) error {
+	if errors.Is(err, util.ErrNotFoundEmpty) {
+		return errSourceNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+)
+
+// PskNameHeader is the request header the name of the PSK the request was authenticated with is stored in, so that
+// the request logs show which service made the request.
+const PskNameHeader = "x-rh-sources-psk-name"
+
+// Psk is a pre-shared key along with the scopes it is restricted to. The empty scopes don't restrict anything.
+type Psk struct {
+	// Name identifies the service the key was handed to, and is what gets logged instead of the secret.
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+	// Routes are the routes the key is allowed to call, as their method and path separated by a space, such as
+	// "GET /api/sources/v3.1/sources/:id". The method may be "*", and the paths ending with "*" match any route under
+	// them.
+	Routes []string `json:"routes"`
+	// Verbs are the verbs of the route permissions the key is allowed to use, "read" and "write".
+	Verbs []string `json:"verbs"`
+	// AccountNumbers and OrgIds are the tenants the key is allowed to act on behalf of.
+	AccountNumbers []string `json:"account_numbers"`
+	OrgIds         []string `json:"org_ids"`
+
+	hash [sha256.Size]byte
+}
+
+// allowsRoute tells whether the key may call the request's route.
+func (p *Psk) allowsRoute(c echo.Context) bool {
+	if len(p.Routes) > 0 {
+		allowed := false
+		for _, route := range p.Routes {
+			if routeMatches(route, c.Request().Method, c.Path()) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return false
+		}
+	}
+
+	if len(p.Verbs) == 0 {
+		return true
+	}
+
+	// the routes which don't map to any permission can't be matched against the verbs.
+	permission, ok := routePermission(c)
+	if !ok {
+		return false
+	}
+
+	return util.SliceContainsString(p.Verbs, permission.Verb)
+}
+
+// allowsTenant tells whether the key may act on behalf of the request's tenant.
+func (p *Psk) allowsTenant(c echo.Context) bool {
+	if len(p.AccountNumbers) == 0 && len(p.OrgIds) == 0 {
+		return true
+	}
+
+	if account, ok := c.Get("psk-account").(string); ok && util.SliceContainsString(p.AccountNumbers, account) {
+		return true
+	}
+
+	if orgId, ok := c.Get("psk-org-id").(string); ok && util.SliceContainsString(p.OrgIds, orgId) {
+		return true
+	}
+
+	return false
+}
+
+// routeMatches tells whether the allowed route covers the request's method and route path.
+func routeMatches(route, method, path string) bool {
+	parts := strings.SplitN(strings.TrimSpace(route), " ", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	if parts[0] != "*" && !strings.EqualFold(parts[0], method) {
+		return false
+	}
+
+	if strings.HasSuffix(parts[1], "*") {
+		return strings.HasPrefix(path, strings.TrimSuffix(parts[1], "*"))
+	}
+
+	return parts[1] == path
+}
+
+// PskStore holds the known PSKs. They are loaded from the PSK file, which gets reloaded when it changes so that the
+// keys can be rotated without a restart, and from the SOURCES_PSKS environment variable.
+type PskStore struct {
+	// path is the JSON file the keys are loaded from. It is optional.
+	path string
+	// fallback are the keys from the environment, which are just secrets unless they are named.
+	fallback []string
+	// namedFallback tells that the keys from the environment are in the "name:secret" format, which has to be opted
+	// into since the legacy secrets may contain colons themselves.
+	namedFallback bool
+
+	mutex   sync.RWMutex
+	keys    []Psk
+	content []byte
+}
+
+// NewPskStore returns a store with the keys of the given file and environment entries. A file that can't be loaded
+// leaves the store with the keys of the environment only.
+func NewPskStore(path string, fallback []string, namedFallback bool) *PskStore {
+	store := &PskStore{path: path, fallback: fallback, namedFallback: namedFallback}
+
+	err := store.Reload()
+	if err != nil {
+		logger.Log.Errorf("Unable to load the PSKs, only using the ones from the environment: %s", err)
+		store.keys, _ = parsePsks(nil, fallback, namedFallback)
+	}
+
+	return store
+}
+
+// Reload loads the keys again when the file changed. When the file can't be read or parsed the current keys are
+// kept, so that a botched rotation doesn't lock every service out.
+func (ps *PskStore) Reload() error {
+	var content []byte
+	if ps.path != "" {
+		var err error
+		content, err = os.ReadFile(ps.path)
+
+		// the PSK file is optional, but once its keys are loaded a missing file is most likely a secret being
+		// remounted, which must not log every service out.
+		if errors.Is(err, fs.ErrNotExist) {
+			ps.mutex.RLock()
+			loaded := ps.content != nil
+			ps.mutex.RUnlock()
+
+			if loaded {
+				return fmt.Errorf("the PSK file is missing: %w", err)
+			}
+		} else if err != nil {
+			return fmt.Errorf("unable to read the PSK file: %w", err)
+		}
+	}
+
+	ps.mutex.RLock()
+	unchanged := ps.keys != nil && bytes.Equal(content, ps.content)
+	ps.mutex.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	keys, err := parsePsks(content, ps.fallback, ps.namedFallback)
+	if err != nil {
+		return err
+	}
+
+	ps.mutex.Lock()
+	ps.keys = keys
+	ps.content = content
+	ps.mutex.Unlock()
+
+	if ps.path != "" {
+		logger.Log.Infof("Loaded %d PSKs from %s", len(keys), ps.path)
+	}
+
+	return nil
+}
+
+// Watch reloads the keys every interval until the context gets cancelled.
+func (ps *PskStore) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := ps.Reload()
+			if err != nil {
+				logger.Log.Errorf("Unable to reload the PSKs, keeping the previous ones: %s", err)
+			}
+		}
+	}
+}
+
+// Match returns the key with the given secret. Every key gets compared in constant time, so that the time it takes
+// doesn't tell how much of the secret was right or which key it was close to.
+func (ps *PskStore) Match(secret string) (*Psk, bool) {
+	hash := sha256.Sum256([]byte(secret))
+
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	var match *Psk
+	for i := range ps.keys {
+		if subtle.ConstantTimeCompare(hash[:], ps.keys[i].hash[:]) == 1 && match == nil {
+			match = &ps.keys[i]
+		}
+	}
+
+	return match, match != nil
+}
+
+// parsePsks parses the JSON array of keys of the PSK file, and adds the unrestricted keys of the environment to them.
+func parsePsks(content []byte, fallback []string, namedFallback bool) ([]Psk, error) {
+	keys := make([]Psk, 0)
+
+	if len(bytes.TrimSpace(content)) > 0 {
+		err := json.Unmarshal(content, &keys)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the PSK file: %w", err)
+		}
+	}
+
+	for i, entry := range fallback {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		// the keys without name are named after their position, which is enough to tell them apart in the logs.
+		name, secret := "psk-"+strconv.Itoa(i), entry
+		if namedFallback {
+			parts := strings.SplitN(entry, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("the PSK number %d of the environment is not in the \"name:secret\" format", i)
+			}
+
+			name, secret = parts[0], parts[1]
+		}
+
+		keys = append(keys, Psk{Name: name, Secret: secret})
+	}
+
+	names := make(map[string]bool, len(keys))
+	valid := make([]Psk, 0, len(keys))
+	for _, key := range keys {
+		if key.Name == "" || key.Secret == "" {
+			return nil, fmt.Errorf("the PSK %q needs both a name and a secret", key.Name)
+		}
+
+		if names[key.Name] {
+			return nil, fmt.Errorf("the PSK name %q is used more than once", key.Name)
+		}
+		names[key.Name] = true
+
+		key.hash = sha256.Sum256([]byte(key.Secret))
+		valid = append(valid, key)
+	}
+
+	return valid, nil
+}
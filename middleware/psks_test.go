@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	"github.com/labstack/echo/v4"
+)
+
+const scopedPsks = `[
+	{"name": "reader", "secret": "reader-secret", "verbs": ["read"]},
+	{"name": "pauser", "secret": "pauser-secret", "routes": ["POST /sources/:id/pause"]},
+	{"name": "tenant", "secret": "tenant-secret", "account_numbers": ["12345"], "org_ids": ["67890"]}
+]`
+
+// writePskFile writes the PSK file and returns its path.
+func writePskFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "psks.json")
+
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// TestPskStoreLoad tests that the keys get loaded from both the file and the environment, and that the keys from the
+// environment are named after their position.
+func TestPskStoreLoad(t *testing.T) {
+	store := NewPskStore(writePskFile(t, scopedPsks), []string{"legacy", "with:colon", ""}, false)
+
+	testCases := []struct {
+		secret string
+		want   string
+	}{
+		{secret: "reader-secret", want: "reader"},
+		{secret: "pauser-secret", want: "pauser"},
+		{secret: "legacy", want: "psk-0"},
+		{secret: "with:colon", want: "psk-1"},
+		{secret: "colon", want: ""},
+		{secret: "", want: ""},
+		{secret: "reader-secre", want: ""},
+	}
+
+	for _, tc := range testCases {
+		key, ok := store.Match(tc.secret)
+		if ok != (tc.want != "") || (ok && key.Name != tc.want) {
+			t.Errorf(`secret "%s": want key "%s", got "%v"`, tc.secret, tc.want, key)
+		}
+	}
+}
+
+// TestPskStoreNamedEnvironment tests that the keys from the environment are only split into their name and secret
+// when they are in the named format, and that the entries without name are rejected then.
+func TestPskStoreNamedEnvironment(t *testing.T) {
+	store := NewPskStore("", []string{"named:secret:with:colons"}, true)
+
+	if key, ok := store.Match("secret:with:colons"); !ok || key.Name != "named" {
+		t.Errorf(`want the key "named", got "%v"`, key)
+	}
+
+	_, err := parsePsks(nil, []string{"named:secret", "unnamed"}, true)
+	if err == nil {
+		t.Errorf(`want an error for the key without name`)
+	}
+}
+
+// TestPskStoreReload tests that the rotated keys get picked up, and that a broken file keeps the previous keys.
+func TestPskStoreReload(t *testing.T) {
+	path := writePskFile(t, `[{"name": "service", "secret": "old"}]`)
+	store := NewPskStore(path, nil, false)
+
+	err := os.WriteFile(path, []byte(`[{"name": "service", "secret": "new"}]`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Reload()
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if _, ok := store.Match("old"); ok {
+		t.Errorf(`want the old secret to be rotated out`)
+	}
+
+	if _, ok := store.Match("new"); !ok {
+		t.Errorf(`want the new secret to be loaded`)
+	}
+
+	for _, broken := range []string{`[{"name": "service"`, `[{"name": "a", "secret": "x"}, {"name": "a", "secret": "y"}]`, `[{"secret": "x"}]`} {
+		err = os.WriteFile(path, []byte(broken), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = store.Reload(); err == nil {
+			t.Errorf(`want an error for the PSK file "%s"`, broken)
+		}
+
+		if _, ok := store.Match("new"); !ok {
+			t.Errorf(`want the previous keys to be kept`)
+		}
+	}
+}
+
+// TestPskStoreMissingFile tests that the keys from the environment are used when the file can't be loaded, and that
+// the loaded keys are kept when the file goes missing afterwards.
+func TestPskStoreMissingFile(t *testing.T) {
+	store := NewPskStore(filepath.Join(t.TempDir(), "missing.json"), []string{"env:secret"}, true)
+
+	if key, ok := store.Match("secret"); !ok || key.Name != "env" {
+		t.Errorf(`want the key from the environment, got "%v"`, key)
+	}
+
+	path := writePskFile(t, `[{"name": "service", "secret": "file-secret"}]`)
+	store = NewPskStore(path, nil, false)
+
+	err := os.Remove(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.Reload(); err == nil {
+		t.Errorf(`want an error for the missing PSK file`)
+	}
+
+	if _, ok := store.Match("file-secret"); !ok {
+		t.Errorf(`want the keys of the file to be kept`)
+	}
+}
+
+// TestPskScopes tests that the PSKs are only allowed to use the routes, verbs and tenants of their scopes, and that
+// the name of the PSK gets tagged to the request.
+func TestPskScopes(t *testing.T) {
+	psks = NewPskStore(writePskFile(t, scopedPsks), nil, false)
+	t.Cleanup(func() { psks = NewPskStore("", nil, false) })
+
+	RoutePermissions = map[string]Permission{
+		"GET /sources/:id":        {Resource: "source", Verb: VerbRead},
+		"POST /sources/:id/pause": {Resource: "source", Verb: VerbWrite},
+	}
+	t.Cleanup(func() { RoutePermissions = map[string]Permission{} })
+
+	testCases := []struct {
+		secret  string
+		method  string
+		path    string
+		account string
+		orgId   string
+		want    int
+	}{
+		{secret: "reader-secret", method: http.MethodGet, path: "/sources/:id", want: http.StatusNoContent},
+		{secret: "reader-secret", method: http.MethodPost, path: "/sources/:id/pause", want: http.StatusForbidden},
+		{secret: "reader-secret", method: http.MethodGet, path: "/unmapped", want: http.StatusForbidden},
+		{secret: "pauser-secret", method: http.MethodPost, path: "/sources/:id/pause", want: http.StatusNoContent},
+		{secret: "pauser-secret", method: http.MethodGet, path: "/sources/:id", want: http.StatusForbidden},
+		{secret: "tenant-secret", method: http.MethodGet, path: "/sources/:id", account: "12345", want: http.StatusNoContent},
+		{secret: "tenant-secret", method: http.MethodGet, path: "/sources/:id", orgId: "67890", want: http.StatusNoContent},
+		{secret: "tenant-secret", method: http.MethodGet, path: "/sources/:id", account: "67890", want: http.StatusForbidden},
+		{secret: "tenant-secret", method: http.MethodGet, path: "/sources/:id", want: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		values := map[string]interface{}{"psk": tc.secret}
+		if tc.account != "" {
+			values["psk-account"] = tc.account
+		}
+
+		if tc.orgId != "" {
+			values["psk-org-id"] = tc.orgId
+		}
+
+		c, rec := request.CreateTestContext(tc.method, "/", nil, values)
+		c.SetPath(tc.path)
+
+		err := permCheckOrElse204(c)
+		if err != nil {
+			t.Errorf(`want nil error, got "%s"`, err)
+		}
+
+		if rec.Code != tc.want {
+			t.Errorf(`PSK "%s" on "%s %s": want status "%d", got "%d"`, tc.secret, tc.method, tc.path, tc.want, rec.Code)
+		}
+
+		key, _ := psks.Match(tc.secret)
+		if c.Get("psk-name") != key.Name || c.Request().Header.Get(PskNameHeader) != key.Name {
+			t.Errorf(`want the request to be tagged with the PSK name "%s", got "%v"`, key.Name, c.Get("psk-name"))
+		}
+	}
+}
+
+// TestPskNameHeaderIsNotTrusted tests that the callers can't set the PSK name themselves.
+func TestPskNameHeaderIsNotTrusted(t *testing.T) {
+	c, _ := request.CreateTestContext(http.MethodGet, "/", nil, map[string]interface{}{})
+	c.Request().Header.Set(PskNameHeader, "somebody else")
+
+	err := ParseHeaders(func(c echo.Context) error { return nil })(c)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	if c.Request().Header.Get(PskNameHeader) != "" {
+		t.Errorf(`want the PSK name header to be dropped`)
+	}
+}
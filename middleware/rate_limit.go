@@ -0,0 +1,322 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/RedHatInsights/sources-api-go/redis"
+	"github.com/RedHatInsights/sources-api-go/util"
+	goredis "github.com/go-redis/redis"
+	"github.com/labstack/echo/v4"
+	"github.com/redhatinsights/platform-go-middlewares/identity"
+)
+
+const (
+	// rateLimitKeyPrefix prefixes the keys of the token buckets in Redis.
+	rateLimitKeyPrefix = "sources-api-go:ratelimit:"
+	// rateLimitMemoryPruneSize is the number of buckets the in-memory fallback holds before it drops the full ones.
+	rateLimitMemoryPruneSize = 10000
+)
+
+// RateLimit is the budget of a token bucket: it holds up to Burst requests, and refills at Rate requests per second.
+// A zero rate doesn't limit anything.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns a budget of the given number of requests per minute, which can all be made at once.
+func PerMinute(requests int) RateLimit {
+	return RateLimit{Rate: float64(requests) / 60, Burst: requests}
+}
+
+// Budgets are the rate limits of each kind of request. The secret reads are the internal requests which expose the
+// authentications' passwords, since each one of them hits Vault.
+type Budgets struct {
+	Read       RateLimit
+	Write      RateLimit
+	SecretRead RateLimit
+}
+
+// RateLimits are the budgets of a route group, for each tenant and for each identity or PSK.
+type RateLimits struct {
+	// Group keeps the buckets of the route groups apart.
+	Group    string
+	Tenant   Budgets
+	Identity Budgets
+}
+
+// RateLimitGroups are the budgets of the route groups, in requests per minute. The groups which aren't in here get the
+// default budgets.
+var RateLimitGroups = loadRateLimitGroups(config.Get().RateLimits)
+
+// RateLimitGroup is the configuration of a route group's budgets, in requests per minute.
+type RateLimitGroup struct {
+	Tenant   RateLimitBudgets `json:"tenant"`
+	Identity RateLimitBudgets `json:"identity"`
+}
+
+// RateLimitBudgets are the requests per minute of each kind of request. Zero doesn't limit anything.
+type RateLimitBudgets struct {
+	Read       int `json:"read"`
+	Write      int `json:"write"`
+	SecretRead int `json:"secret_read"`
+}
+
+// defaultRateLimitGroup holds the budgets of the route groups which aren't configured, and the budgets that the
+// configured groups leave out.
+var defaultRateLimitGroup = RateLimitGroup{
+	Tenant:   RateLimitBudgets{Read: 6000, Write: 1200, SecretRead: 600},
+	Identity: RateLimitBudgets{Read: 3000, Write: 600, SecretRead: 300},
+}
+
+// loadRateLimitGroups returns the budgets of the route groups in the given JSON object, keyed by group. Budgets that
+// can't be parsed leave the default ones in place for every group.
+func loadRateLimitGroups(raw string) map[string]RateLimitGroup {
+	groups := make(map[string]RateLimitGroup)
+	if raw == "" {
+		return groups
+	}
+
+	configured := make(map[string]json.RawMessage)
+	err := json.Unmarshal([]byte(raw), &configured)
+	if err != nil {
+		logger.Log.Errorf("Unable to parse the rate limits from the configuration, using the default ones: %s", err)
+		return groups
+	}
+
+	for name, budgets := range configured {
+		group := defaultRateLimitGroup
+
+		err = json.Unmarshal(budgets, &group)
+		if err != nil {
+			logger.Log.Errorf("Unable to parse the rate limits of the %q route group from the configuration, using the default ones: %s", name, err)
+			return make(map[string]RateLimitGroup)
+		}
+
+		groups[name] = group
+	}
+
+	return groups
+}
+
+// RateLimitsFor returns the rate limits of the given route group.
+func RateLimitsFor(group string) RateLimits {
+	budgets, ok := RateLimitGroups[group]
+	if !ok {
+		budgets = defaultRateLimitGroup
+	}
+
+	return RateLimits{
+		Group:    group,
+		Tenant:   budgets.Tenant.perMinute(),
+		Identity: budgets.Identity.perMinute(),
+	}
+}
+
+// perMinute returns the budgets of the configured requests per minute.
+func (b RateLimitBudgets) perMinute() Budgets {
+	return Budgets{
+		Read:       PerMinute(b.Read),
+		Write:      PerMinute(b.Write),
+		SecretRead: PerMinute(b.SecretRead),
+	}
+}
+
+// rateLimitScript takes a token from the bucket at KEYS[1], after refilling it for the time elapsed since it was last
+// used. It returns zero when the request is allowed, and the milliseconds until a token is available otherwise.
+var rateLimitScript = goredis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(bucket[1]) or burst
+local updated = tonumber(bucket[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate / 1000)
+
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+
+return wait
+`)
+
+// bucket is a token bucket of the in-memory fallback.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// tokenBuckets takes the tokens from the buckets in Redis or, when Redis can't be reached, from the ones in memory,
+// which then only limit the requests that the replica gets.
+type tokenBuckets struct {
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+}
+
+// take takes a token from the bucket with the given key, and returns how long to wait for one when there is none.
+func (tb *tokenBuckets) take(key string, limit RateLimit, now time.Time) time.Duration {
+	if redis.Client != nil {
+		wait, err := rateLimitScript.Run(redis.Client, []string{key}, limit.Rate, limit.Burst, now.UnixNano()/int64(time.Millisecond)).Int64()
+		if err == nil {
+			return time.Duration(wait) * time.Millisecond
+		}
+
+		logger.Log.Warnf("unable to take a rate limit token from Redis, falling back to the memory: %s", err)
+	}
+
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	if len(tb.buckets) >= rateLimitMemoryPruneSize {
+		for k, b := range tb.buckets {
+			if refill(b, limit, now) >= float64(limit.Burst) {
+				delete(tb.buckets, k)
+			}
+		}
+	}
+
+	b, ok := tb.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		tb.buckets[key] = b
+	}
+
+	b.tokens = refill(b, limit, now)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration(math.Ceil((1 - b.tokens) / limit.Rate * float64(time.Second)))
+}
+
+// refill returns the tokens the bucket holds at the given time.
+func refill(b *bucket, limit RateLimit, now time.Time) float64 {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+}
+
+// RateLimiter returns the middleware which limits the requests of each tenant, and of each identity or PSK, to the
+// given budgets. The requests over budget get a 429 along with the seconds to wait in the "Retry-After" header. It
+// goes after the header parsing middleware, since the tenant and the identity come from the headers.
+func RateLimiter(limits RateLimits) echo.MiddlewareFunc {
+	buckets := &tokenBuckets{buckets: make(map[string]*bucket)}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			kind, tenantLimit, identityLimit := "read", limits.Tenant.Read, limits.Identity.Read
+			switch {
+			case c.Request().Method == http.MethodGet && len(c.QueryParams()[exposeEncryptedAttribute]) > 0:
+				kind, tenantLimit, identityLimit = "secret_read", limits.Tenant.SecretRead, limits.Identity.SecretRead
+			case c.Request().Method != http.MethodGet && c.Request().Method != http.MethodHead:
+				kind, tenantLimit, identityLimit = "write", limits.Tenant.Write, limits.Identity.Write
+			}
+
+			now := time.Now()
+			prefix := rateLimitKeyPrefix + limits.Group + ":" + kind + ":"
+
+			var wait time.Duration
+			if tenant := rateLimitTenant(c); tenant != "" && tenantLimit.Rate > 0 {
+				wait = buckets.take(prefix+"tenant:"+tenant, tenantLimit, now)
+			}
+
+			// the requests that the tenant's budget rejects don't use up the identity's budget, otherwise a throttled
+			// tenant would drain the budgets of its own users.
+			if subject := rateLimitIdentity(c); wait == 0 && subject != "" && identityLimit.Rate > 0 {
+				wait = buckets.take(prefix+"identity:"+subject, identityLimit, now)
+			}
+
+			if wait > 0 {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				return c.JSON(http.StatusTooManyRequests, util.ErrorDoc(c.Request().Context(), "Too many requests, retry later", "429"))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// rateLimitTenant returns the tenant the request is made on behalf of, by its org id or, when it has none, by its
+// account number. The tenants are only charged for the PSKs which match a known key allowed to act on their behalf,
+// so that the requests with made up keys can't drain the tenants' budgets.
+func rateLimitTenant(c echo.Context) string {
+	if secret, ok := c.Get("psk").(string); ok {
+		if key, ok := psks.Match(secret); !ok || !key.allowsTenant(c) {
+			return ""
+		}
+
+		if orgId, ok := c.Get("psk-org-id").(string); ok {
+			return "org:" + orgId
+		}
+
+		if account, ok := c.Get("psk-account").(string); ok {
+			return "account:" + account
+		}
+
+		return ""
+	}
+
+	if orgId, ok := c.Get("identity-org-id").(string); ok {
+		return "org:" + orgId
+	}
+
+	if id, ok := c.Get("identity").(identity.XRHID); ok && id.Identity.AccountNumber != "" {
+		return "account:" + id.Identity.AccountNumber
+	}
+
+	return ""
+}
+
+// rateLimitIdentity returns who makes the request: the PSK by its name, the system by its cn or cluster id, or the
+// user by its name within its tenant. The PSKs which don't match any known key aren't limited here, since they get
+// rejected anyway.
+func rateLimitIdentity(c echo.Context) string {
+	if secret, ok := c.Get("psk").(string); ok {
+		if key, ok := psks.Match(secret); ok {
+			return "psk:" + key.Name
+		}
+
+		return ""
+	}
+
+	id, ok := c.Get("identity").(identity.XRHID)
+	if !ok {
+		return ""
+	}
+
+	if cn, ok := id.Identity.System["cn"]; ok && cn != nil {
+		return "cn:" + fmt.Sprint(cn)
+	}
+
+	if clusterId, ok := id.Identity.System["cluster_id"]; ok && clusterId != nil {
+		return "cluster_id:" + fmt.Sprint(clusterId)
+	}
+
+	if id.Identity.User.Username != "" {
+		return "user:" + rateLimitTenant(c) + ":" + id.Identity.User.Username
+	}
+
+	return ""
+}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	"github.com/RedHatInsights/sources-api-go/redis"
+	miniredisV2 "github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis"
+	"github.com/labstack/echo/v4"
+	"github.com/redhatinsights/platform-go-middlewares/identity"
+)
+
+// rateLimitedRequest makes the request through the rate limiting middleware and returns the response's status along
+// with its "Retry-After" header.
+func rateLimitedRequest(t *testing.T, mw echo.MiddlewareFunc, method, target string, values map[string]interface{}) (int, string) {
+	c, rec := request.CreateTestContext(method, target, nil, values)
+
+	err := mw(func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })(c)
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	return rec.Code, rec.Header().Get("Retry-After")
+}
+
+func userOf(account, username string) map[string]interface{} {
+	return map[string]interface{}{
+		"identity": identity.XRHID{Identity: identity.Identity{AccountNumber: account, User: identity.User{Username: username}}},
+	}
+}
+
+// TestRateLimitTenant tests that the tenants get their own budgets, and that the requests over budget get a 429
+// along with how long to wait.
+func TestRateLimitTenant(t *testing.T) {
+	mw := RateLimiter(RateLimits{Group: "test", Tenant: Budgets{Read: PerMinute(2), Write: PerMinute(1)}})
+
+	for i, user := range []string{"a", "b"} {
+		status, _ := rateLimitedRequest(t, mw, http.MethodGet, "/", userOf("12345", user))
+		if status != http.StatusNoContent {
+			t.Errorf(`request "%d": want status "%d", got "%d"`, i, http.StatusNoContent, status)
+		}
+	}
+
+	status, retryAfter := rateLimitedRequest(t, mw, http.MethodGet, "/", userOf("12345", "c"))
+	if status != http.StatusTooManyRequests {
+		t.Errorf(`want status "%d", got "%d"`, http.StatusTooManyRequests, status)
+	}
+
+	if seconds, err := strconv.Atoi(retryAfter); err != nil || seconds < 1 || seconds > 30 {
+		t.Errorf(`want a "Retry-After" of up to 30 seconds, got "%s"`, retryAfter)
+	}
+
+	// the writes and the other tenants have budgets of their own.
+	if status, _ := rateLimitedRequest(t, mw, http.MethodPost, "/", userOf("12345", "a")); status != http.StatusNoContent {
+		t.Errorf(`want the write to be allowed, got "%d"`, status)
+	}
+
+	if status, _ := rateLimitedRequest(t, mw, http.MethodGet, "/", userOf("67890", "a")); status != http.StatusNoContent {
+		t.Errorf(`want the other tenant to be allowed, got "%d"`, status)
+	}
+}
+
+// TestRateLimitIdentity tests that the identities and the PSKs get their own budgets, and that the secret reads are
+// limited apart from the other reads.
+func TestRateLimitIdentity(t *testing.T) {
+	psks = NewPskStore("", []string{"cost:secret"}, true)
+	t.Cleanup(func() { psks = NewPskStore("", nil, false) })
+
+	mw := RateLimiter(RateLimits{Group: "test", Identity: Budgets{Read: PerMinute(1), SecretRead: PerMinute(1)}})
+
+	testCases := []struct {
+		target string
+		values map[string]interface{}
+		want   int
+	}{
+		{target: "/", values: userOf("12345", "a"), want: http.StatusNoContent},
+		{target: "/", values: userOf("12345", "a"), want: http.StatusTooManyRequests},
+		{target: "/", values: userOf("12345", "b"), want: http.StatusNoContent},
+		{target: "/", values: map[string]interface{}{"psk": "secret", "psk-account": "1"}, want: http.StatusNoContent},
+		{target: "/", values: map[string]interface{}{"psk": "secret", "psk-account": "2"}, want: http.StatusTooManyRequests},
+		{target: "/?expose_encrypted_attribute[]=password", values: map[string]interface{}{"psk": "secret"}, want: http.StatusNoContent},
+		{target: "/?expose_encrypted_attribute[]=password", values: map[string]interface{}{"psk": "secret"}, want: http.StatusTooManyRequests},
+		{target: "/", values: map[string]interface{}{"psk": "unknown"}, want: http.StatusNoContent},
+		{target: "/", values: map[string]interface{}{"psk": "unknown"}, want: http.StatusNoContent},
+	}
+
+	for i, tc := range testCases {
+		status, _ := rateLimitedRequest(t, mw, http.MethodGet, tc.target, tc.values)
+		if status != tc.want {
+			t.Errorf(`request "%d": want status "%d", got "%d"`, i, tc.want, status)
+		}
+	}
+}
+
+// TestRateLimitUnknownPskTenant tests that the requests with unknown PSKs aren't charged to the tenant they claim to
+// act on behalf of.
+func TestRateLimitUnknownPskTenant(t *testing.T) {
+	psks = NewPskStore("", []string{"secret"}, false)
+	t.Cleanup(func() { psks = NewPskStore("", nil, false) })
+
+	mw := RateLimiter(RateLimits{Group: "test", Tenant: Budgets{Read: PerMinute(1)}})
+
+	testCases := []struct {
+		secret string
+		want   int
+	}{
+		{secret: "unknown", want: http.StatusNoContent},
+		{secret: "unknown", want: http.StatusNoContent},
+		{secret: "secret", want: http.StatusNoContent},
+		{secret: "secret", want: http.StatusTooManyRequests},
+	}
+
+	for i, tc := range testCases {
+		status, _ := rateLimitedRequest(t, mw, http.MethodGet, "/", map[string]interface{}{"psk": tc.secret, "psk-account": "12345"})
+		if status != tc.want {
+			t.Errorf(`request "%d": want status "%d", got "%d"`, i, tc.want, status)
+		}
+	}
+}
+
+// TestRateLimitTenantRejectionKeepsIdentityBudget tests that the requests that the tenant's budget rejects don't use
+// up the identity's budget.
+func TestRateLimitTenantRejectionKeepsIdentityBudget(t *testing.T) {
+	psks = NewPskStore("", []string{"cost:secret"}, true)
+	t.Cleanup(func() { psks = NewPskStore("", nil, false) })
+
+	mw := RateLimiter(RateLimits{Group: "test", Tenant: Budgets{Read: PerMinute(1)}, Identity: Budgets{Read: PerMinute(2)}})
+
+	testCases := []struct {
+		account string
+		want    int
+	}{
+		{account: "1", want: http.StatusNoContent},
+		{account: "1", want: http.StatusTooManyRequests},
+		{account: "2", want: http.StatusNoContent},
+		{account: "3", want: http.StatusTooManyRequests},
+	}
+
+	for i, tc := range testCases {
+		status, _ := rateLimitedRequest(t, mw, http.MethodGet, "/", map[string]interface{}{"psk": "secret", "psk-account": tc.account})
+		if status != tc.want {
+			t.Errorf(`request "%d": want status "%d", got "%d"`, i, tc.want, status)
+		}
+	}
+}
+
+// TestLoadRateLimitGroups tests that the configured route groups override the default budgets they set, and that
+// invalid configurations leave the default budgets in place.
+func TestLoadRateLimitGroups(t *testing.T) {
+	groups := loadRateLimitGroups(`{"internal": {"tenant": {"read": 10}}, "v4": {"identity": {"write": 0}}}`)
+
+	internal := defaultRateLimitGroup
+	internal.Tenant.Read = 10
+
+	v4 := defaultRateLimitGroup
+	v4.Identity.Write = 0
+
+	if !reflect.DeepEqual(groups, map[string]RateLimitGroup{"internal": internal, "v4": v4}) {
+		t.Errorf(`want the configured budgets over the default ones, got "%v"`, groups)
+	}
+
+	for _, raw := range []string{"", "not json", `{"internal": {"tenant": {"read": "ten"}}}`} {
+		if groups := loadRateLimitGroups(raw); len(groups) != 0 {
+			t.Errorf(`want the default budgets for "%s", got "%v"`, raw, groups)
+		}
+	}
+
+	original := RateLimitGroups
+	RateLimitGroups = map[string]RateLimitGroup{"internal": internal}
+	t.Cleanup(func() { RateLimitGroups = original })
+
+	if limits := RateLimitsFor("internal"); limits.Group != "internal" || limits.Tenant.Read != PerMinute(10) {
+		t.Errorf(`want the configured budgets of the "internal" group, got "%v"`, limits)
+	}
+
+	if limits := RateLimitsFor("v3"); limits.Tenant.Read != PerMinute(defaultRateLimitGroup.Tenant.Read) {
+		t.Errorf(`want the default budgets of the "v3" group, got "%v"`, limits)
+	}
+}
+
+// TestRateLimitRedis tests that the replicas share the buckets in Redis, and that the memory is used when Redis goes
+// away.
+func TestRateLimitRedis(t *testing.T) {
+	server, err := miniredisV2.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	redis.Client = goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	defer func() { redis.Client = nil }()
+
+	limits := RateLimits{Group: "test", Tenant: Budgets{Read: PerMinute(1)}}
+	replicas := []echo.MiddlewareFunc{RateLimiter(limits), RateLimiter(limits)}
+
+	if status, _ := rateLimitedRequest(t, replicas[0], http.MethodGet, "/", userOf("12345", "a")); status != http.StatusNoContent {
+		t.Errorf(`want the first request to be allowed, got "%d"`, status)
+	}
+
+	status, retryAfter := rateLimitedRequest(t, replicas[1], http.MethodGet, "/", userOf("12345", "a"))
+	if status != http.StatusTooManyRequests || retryAfter == "" {
+		t.Errorf(`want the other replica to limit the request, got "%d" with "Retry-After" "%s"`, status, retryAfter)
+	}
+
+	server.Close()
+
+	if status, _ := rateLimitedRequest(t, replicas[1], http.MethodGet, "/", userOf("12345", "a")); status != http.StatusNoContent {
+		t.Errorf(`want the memory bucket to allow the request, got "%d"`, status)
+	}
+
+	if status, _ := rateLimitedRequest(t, replicas[1], http.MethodGet, "/", userOf("12345", "a")); status != http.StatusTooManyRequests {
+		t.Errorf(`want the memory bucket to limit the request, got "%d"`, status)
+	}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/RedHatInsights/rbac-client-go"
+	"github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/RedHatInsights/sources-api-go/redis"
+	goredis "github.com/go-redis/redis"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// rbacCacheKeyPrefix prefixes the hash of the identity in the Redis keys of the cached access lists.
+	rbacCacheKeyPrefix = "sources-api-go:rbac:"
+	// rbacMemoryPruneSize is the number of access lists the in-memory cache holds before it drops the expired ones.
+	rbacMemoryPruneSize = 1000
+)
+
+// RbacCache caches the access lists that RBAC returns, so that the bulk requests made with the same identity don't
+// each wait on RBAC. The access lists are stored in Redis, keyed by a hash of the identity, and in memory when Redis
+// can't be reached. The concurrent lookups of the same identity share a single request to RBAC.
+type RbacCache struct {
+	// Client is the RBAC client the access lists are fetched with.
+	Client Rbac
+	// Ttl is how long the access lists are cached for. Zero disables the cache.
+	Ttl time.Duration
+	// NegativeTtl is how long the access lists which don't grant any permission of the application are cached for,
+	// which is usually shorter so that the granted permissions show up quickly. Zero doesn't cache them.
+	NegativeTtl time.Duration
+
+	lookups singleflight.Group
+	mutex   sync.Mutex
+	memory  map[string]cachedAccess
+}
+
+// cachedAccess is an access list held by the in-memory cache.
+type cachedAccess struct {
+	acl     rbac.AccessList
+	expires time.Time
+}
+
+// NewRbacCache returns a cache in front of the given client.
+func NewRbacCache(client Rbac, ttl, negativeTtl time.Duration) *RbacCache {
+	return &RbacCache{Client: client, Ttl: ttl, NegativeTtl: negativeTtl, memory: make(map[string]cachedAccess)}
+}
+
+// Access returns the cached access list of the identity, and fetches it from RBAC when it isn't cached.
+func (rc *RbacCache) Access(xrhid string) (rbac.AccessList, error) {
+	if rc.Ttl <= 0 {
+		return rc.Client.Access(xrhid)
+	}
+
+	key := rbacCacheKey(xrhid)
+	acl, err, _ := rc.lookups.Do(key, func() (interface{}, error) {
+		if acl, ok := rc.get(key); ok {
+			return acl, nil
+		}
+
+		return rc.fetch(key, xrhid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return acl.(rbac.AccessList), nil
+}
+
+// Refresh fetches the access list of the identity from RBAC regardless of the cache, and caches it.
+func (rc *RbacCache) Refresh(xrhid string) (rbac.AccessList, error) {
+	if rc.Ttl <= 0 {
+		return rc.Client.Access(xrhid)
+	}
+
+	key := rbacCacheKey(xrhid)
+	acl, err, _ := rc.lookups.Do("refresh:"+key, func() (interface{}, error) {
+		return rc.fetch(key, xrhid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return acl.(rbac.AccessList), nil
+}
+
+// fetch gets the access list from RBAC and caches it. The errors aren't cached, so that RBAC is retried on the next
+// request.
+func (rc *RbacCache) fetch(key, xrhid string) (rbac.AccessList, error) {
+	acl, err := rc.Client.Access(xrhid)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := rc.Ttl
+	if !grantsAny(acl) {
+		ttl = rc.NegativeTtl
+	}
+
+	if ttl > 0 {
+		rc.set(key, acl, ttl)
+	}
+
+	return acl, nil
+}
+
+// get returns the cached access list, from Redis or, when Redis can't be reached, from memory.
+func (rc *RbacCache) get(key string) (rbac.AccessList, bool) {
+	if redis.Client != nil {
+		raw, err := redis.Client.Get(key).Bytes()
+		switch {
+		case errors.Is(err, goredis.Nil):
+			return nil, false
+		case err == nil:
+			var acl rbac.AccessList
+			if err := json.Unmarshal(raw, &acl); err == nil {
+				return acl, true
+			}
+
+			logger.Log.Warnf("unable to unmarshal the cached RBAC access list: %s", err)
+			return nil, false
+		default:
+			logger.Log.Warnf("unable to get the RBAC access list from Redis, falling back to the memory: %s", err)
+		}
+	}
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	cached, ok := rc.memory[key]
+	if !ok || time.Now().After(cached.expires) {
+		return nil, false
+	}
+
+	return cached.acl, true
+}
+
+// set caches the access list in Redis or, when Redis can't be reached, in memory.
+func (rc *RbacCache) set(key string, acl rbac.AccessList, ttl time.Duration) {
+	if redis.Client != nil {
+		raw, err := json.Marshal(acl)
+		if err != nil {
+			logger.Log.Warnf("unable to marshal the RBAC access list: %s", err)
+			return
+		}
+
+		err = redis.Client.Set(key, raw, ttl).Err()
+		if err == nil {
+			return
+		}
+
+		logger.Log.Warnf("unable to cache the RBAC access list in Redis, falling back to the memory: %s", err)
+	}
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if len(rc.memory) >= rbacMemoryPruneSize {
+		now := time.Now()
+		for k, cached := range rc.memory {
+			if now.After(cached.expires) {
+				delete(rc.memory, k)
+			}
+		}
+	}
+
+	rc.memory[key] = cachedAccess{acl: acl, expires: time.Now().Add(ttl)}
+}
+
+// rbacCacheKey hashes the identity, so that the identities don't end up in Redis.
+func rbacCacheKey(xrhid string) string {
+	hash := sha256.Sum256([]byte(xrhid))
+	return rbacCacheKeyPrefix + hex.EncodeToString(hash[:])
+}
+
+// grantsAny tells whether the access list grants any permission of the application.
+func grantsAny(acl rbac.AccessList) bool {
+	for _, access := range acl {
+		if access.Application() == rbacApplication {
+			return true
+		}
+	}
+
+	return false
+}
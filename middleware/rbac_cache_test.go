@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/rbac-client-go"
+	"github.com/RedHatInsights/sources-api-go/redis"
+	miniredisV2 "github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis"
+)
+
+// countingRbac counts the lookups that reach RBAC.
+type countingRbac struct {
+	acl     rbac.AccessList
+	err     error
+	lookups int32
+	// release, when set, holds the lookups until it gets closed.
+	release chan struct{}
+}
+
+func (cr *countingRbac) Access(_ string) (rbac.AccessList, error) {
+	atomic.AddInt32(&cr.lookups, 1)
+	if cr.release != nil {
+		<-cr.release
+	}
+
+	return cr.acl, cr.err
+}
+
+var grantingAcl = rbac.AccessList{{Permission: "sources:*:*"}}
+
+func TestRbacCacheMemory(t *testing.T) {
+	client := &countingRbac{acl: grantingAcl}
+	cache := NewRbacCache(client, time.Minute, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		acl, err := cache.Access("identity")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(acl) != 1 || acl[0].Permission != "sources:*:*" {
+			t.Errorf("unexpected access list: %+v", acl)
+		}
+	}
+
+	if client.lookups != 1 {
+		t.Errorf("want 1 lookup, got %d", client.lookups)
+	}
+
+	// another identity has its own access list.
+	_, _ = cache.Access("another identity")
+	if client.lookups != 2 {
+		t.Errorf("want 2 lookups, got %d", client.lookups)
+	}
+
+	// the refresh always reaches RBAC.
+	_, _ = cache.Refresh("identity")
+	if client.lookups != 3 {
+		t.Errorf("want 3 lookups, got %d", client.lookups)
+	}
+}
+
+func TestRbacCacheNegative(t *testing.T) {
+	client := &countingRbac{acl: rbac.AccessList{{Permission: "cost-management:*:*"}}}
+
+	cache := NewRbacCache(client, time.Minute, 0)
+	_, _ = cache.Access("identity")
+	_, _ = cache.Access("identity")
+
+	if client.lookups != 2 {
+		t.Errorf("want the access lists without permissions not to be cached, got %d lookups", client.lookups)
+	}
+
+	client.lookups = 0
+	cache = NewRbacCache(client, time.Minute, time.Minute)
+	_, _ = cache.Access("identity")
+	_, _ = cache.Access("identity")
+
+	if client.lookups != 1 {
+		t.Errorf("want the access lists without permissions to be cached, got %d lookups", client.lookups)
+	}
+}
+
+func TestRbacCacheErrors(t *testing.T) {
+	client := &countingRbac{err: errors.New("kablooey!")}
+	cache := NewRbacCache(client, time.Minute, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Access("identity"); err == nil {
+			t.Errorf("want an error, got none")
+		}
+	}
+
+	if client.lookups != 2 {
+		t.Errorf("want the errors not to be cached, got %d lookups", client.lookups)
+	}
+}
+
+func TestRbacCacheDisabled(t *testing.T) {
+	client := &countingRbac{acl: grantingAcl}
+	cache := NewRbacCache(client, 0, time.Minute)
+
+	_, _ = cache.Access("identity")
+	_, _ = cache.Access("identity")
+
+	if client.lookups != 2 {
+		t.Errorf("want 2 lookups, got %d", client.lookups)
+	}
+}
+
+func TestRbacCacheConcurrentLookups(t *testing.T) {
+	client := &countingRbac{acl: grantingAcl, release: make(chan struct{})}
+	cache := NewRbacCache(client, time.Minute, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Access("identity"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// give the lookups the time to pile up behind the first one.
+	time.Sleep(50 * time.Millisecond)
+	close(client.release)
+	wg.Wait()
+
+	if client.lookups != 1 {
+		t.Errorf("want the concurrent lookups to be deduplicated, got %d lookups", client.lookups)
+	}
+}
+
+func TestRbacCacheRedis(t *testing.T) {
+	server, err := miniredisV2.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	redis.Client = goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	defer func() { redis.Client = nil }()
+
+	client := &countingRbac{acl: grantingAcl}
+	cache := NewRbacCache(client, time.Minute, time.Second)
+
+	_, _ = cache.Access("identity")
+	_, _ = cache.Access("identity")
+
+	if client.lookups != 1 {
+		t.Errorf("want 1 lookup, got %d", client.lookups)
+	}
+
+	key := rbacCacheKey("identity")
+	if !server.Exists(key) {
+		t.Fatalf("want the access list in Redis under %q", key)
+	}
+
+	if ttl := server.TTL(key); ttl != time.Minute {
+		t.Errorf("want a TTL of a minute, got %s", ttl)
+	}
+
+	// once expired, the access list is fetched again.
+	server.FastForward(time.Minute)
+	_, _ = cache.Access("identity")
+
+	if client.lookups != 2 {
+		t.Errorf("want 2 lookups, got %d", client.lookups)
+	}
+
+	// when Redis goes away, the memory takes over.
+	server.Close()
+	_, _ = cache.Access("another identity")
+	_, _ = cache.Access("another identity")
+
+	if client.lookups != 3 {
+		t.Errorf("want 3 lookups, got %d", client.lookups)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestId makes sure every request has an id in its "x-rh-insights-request-id" header, generating one when the
+// caller didn't send a valid one. The id is sent back in the response, added to every line of the request's logger,
+// which the request's context carries as well, and forwarded along with the events and the availability check
+// requests that the request raises.
+func RequestId(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(util.RequestIdHeader)
+		if !util.IsValidRequestId(id) {
+			id = util.NewRequestId()
+			c.Request().Header.Set(util.RequestIdHeader, id)
+		}
+
+		c.Set("request-id", id)
+		c.Response().Header().Set(util.RequestIdHeader, id)
+		c.SetLogger(logger.NewRequestLogger(c.Logger(), logrus.Fields{"request_id": id}))
+		// the services and the DAOs get the request's logger through the request's context.
+		c.SetRequest(c.Request().WithContext(logger.ContextWithLogger(c.Request().Context(), logger.WithRequestId(id))))
+
+		return next(c)
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/labstack/echo/v4"
+	logrusEcho "github.com/neko-neko/echo-logrus/v2/log"
+	"github.com/sirupsen/logrus"
+)
+
+// TestRequestId tests that the valid request ids are kept, and that the requests without one get a new one.
+func TestRequestId(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"valid id", "0b7e59ae-5f4b-4d5a-8b43-9e5c1d1c1d2e", true},
+		{"missing id", "", false},
+		{"invalid id", "id\nwith a new line", false},
+		{"too long id", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		c, _ := request.EmptyTestContext()
+		if tt.header != "" {
+			c.Request().Header.Set("x-rh-insights-request-id", tt.header)
+		}
+
+		var seen string
+		err := RequestId(func(c echo.Context) error {
+			seen, _ = c.Get("request-id").(string)
+			return c.NoContent(http.StatusNoContent)
+		})(c)
+		if err != nil {
+			t.Errorf("%s: want nil error, got %q", tt.name, err)
+		}
+
+		if tt.keep && seen != tt.header {
+			t.Errorf("%s: want the request id %q, got %q", tt.name, tt.header, seen)
+		}
+
+		if !tt.keep && (seen == "" || seen == tt.header) {
+			t.Errorf("%s: want a new request id, got %q", tt.name, seen)
+		}
+
+		if got := c.Request().Header.Get("x-rh-insights-request-id"); got != seen {
+			t.Errorf("%s: want the request id %q in the request's header, got %q", tt.name, seen, got)
+		}
+
+		if got := c.Response().Header().Get("x-rh-insights-request-id"); got != seen {
+			t.Errorf("%s: want the request id %q in the response's header, got %q", tt.name, seen, got)
+		}
+	}
+}
+
+// TestRequestIdLogger tests that the request's logger adds the request id to every line.
+func TestRequestIdLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := &logrusEcho.MyLogger{Logger: &logrus.Logger{Out: out, Level: logrus.InfoLevel, Formatter: &logrus.JSONFormatter{}, Hooks: make(logrus.LevelHooks)}}
+
+	c, _ := request.EmptyTestContext()
+	c.SetLogger(logger)
+	c.Request().Header.Set("x-rh-insights-request-id", "request-id")
+
+	err := RequestId(func(c echo.Context) error {
+		c.Logger().Infof("handling the %s request", "test")
+		return nil
+	})(c)
+	if err != nil {
+		t.Errorf("want nil error, got %q", err)
+	}
+
+	if !strings.Contains(out.String(), `"request_id":"request-id"`) || !strings.Contains(out.String(), "handling the test request") {
+		t.Errorf("want the line logged with the request id, got %q", out.String())
+	}
+
+	if _, ok := c.Logger().(*l.RequestLogger); !ok {
+		t.Errorf("want the request's logger, got %T", c.Logger())
+	}
+
+	entry, ok := l.FromContext(c.Request().Context()).(*logrus.Entry)
+	if !ok || entry.Data["request_id"] != "request-id" {
+		t.Errorf("want the request's context to carry the logger with the request id, got %v", l.FromContext(c.Request().Context()))
+	}
+}
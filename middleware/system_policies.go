@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/labstack/echo/v4"
+)
+
+// SystemPolicy is what the system identities of a kind are allowed to do. The systems don't go through RBAC, and they
+// only get to see and modify the sources they own.
+type SystemPolicy struct {
+	// Key is the key of the identity's "system" section which identifies the system.
+	Key string `json:"key"`
+	// SourceTypes are the names of the source types of the sources the system may create and manage.
+	SourceTypes []string `json:"source_types"`
+	// Permissions are the route permissions the system is granted, as "<resource>:<verb>".
+	Permissions []string `json:"permissions"`
+}
+
+// SystemPolicies maps the system identities to what they may do. The first policy whose key is in the identity's
+// "system" section applies. They are the default ones unless the configuration overrides them.
+var SystemPolicies = loadSystemPolicies(config.Get().SystemPolicies)
+
+// defaultSystemPolicies are the policies of the systems which are known to create sources.
+var defaultSystemPolicies = []SystemPolicy{
+	{
+		// the Satellite instances, which authenticate with their certificates.
+		Key:         "cn",
+		SourceTypes: []string{"satellite"},
+		Permissions: []string{"source:read", "source:write", "application:read", "application:write", "endpoint:read", "endpoint:write", "authentication:read", "authentication:write"},
+	},
+	{
+		// the OpenShift clusters, through the cost management operator.
+		Key:         "cluster_id",
+		SourceTypes: []string{"openshift"},
+		Permissions: []string{"source:read", "source:write", "application:read", "application:write", "endpoint:read", "endpoint:write", "authentication:read", "authentication:write"},
+	},
+}
+
+// loadSystemPolicies returns the policies of the given JSON array, or the default ones when it is empty. Policies that
+// can't be parsed leave the default ones in place, since the systems would be locked out otherwise.
+func loadSystemPolicies(raw string) []SystemPolicy {
+	if raw == "" {
+		return defaultSystemPolicies
+	}
+
+	policies := make([]SystemPolicy, 0)
+	err := json.Unmarshal([]byte(raw), &policies)
+	if err != nil {
+		logger.Log.Errorf("Unable to parse the system policies from the configuration, using the default ones: %s", err)
+		return defaultSystemPolicies
+	}
+
+	for _, policy := range policies {
+		if policy.Key == "" {
+			logger.Log.Errorf("The system policies from the configuration need a key each, using the default ones")
+			return defaultSystemPolicies
+		}
+	}
+
+	return policies
+}
+
+// systemOwner returns the owner of the sources for the system in the identity's "system" section, along with the
+// permissions its policy grants.
+func systemOwner(system map[string]interface{}) (*m.SourceOwner, []string, bool) {
+	for _, policy := range SystemPolicies {
+		id, ok := system[policy.Key]
+		if !ok || id == nil || fmt.Sprint(id) == "" {
+			continue
+		}
+
+		return &m.SourceOwner{Key: policy.Key, ID: fmt.Sprint(id), SourceTypes: policy.SourceTypes}, policy.Permissions, true
+	}
+
+	return nil, nil, false
+}
+
+// systemAllowed tells whether the system may use the request's route, and the reason why it may not. The routes
+// which target a source are only allowed on the sources the system owns, and the source collection's handlers only
+// list and create the system's sources, since they find the owner in the context under "source-owner". Any other
+// route that doesn't target a source could reach the ones of other systems, so it is denied.
+func systemAllowed(c echo.Context, system map[string]interface{}) (bool, string, error) {
+	owner, permissions, ok := systemOwner(system)
+	if !ok {
+		return false, "system authorization only supports cn/cluster_id authorization", nil
+	}
+
+	permission, ok := routePermission(c)
+	if !ok || !util.SliceContainsString(permissions, permission.Resource+":"+permission.Verb) {
+		return false, fmt.Sprintf("the %q systems are not allowed to use this route", owner.Key), nil
+	}
+
+	c.Set("source-owner", owner)
+
+	if permission.Source == nil {
+		if permission.Resource == "source" && len(c.ParamNames()) == 0 {
+			return true, "", nil
+		}
+
+		return false, fmt.Sprintf("the %q systems are only allowed to use the routes of their own sources", owner.Key), nil
+	}
+
+	notOwned := fmt.Sprintf("the source does not belong to the %q system %q", owner.Key, owner.ID)
+
+	sourceId, err := permission.Source(c)
+	if errors.Is(err, errSourceNotFound) {
+		return false, notOwned, nil
+	}
+
+	if err != nil {
+		return false, "", err
+	}
+
+	source, err := dao.GetSourceDao(tenantOf(c)).GetByIdWithPreload(&sourceId, "SourceType")
+	if errors.Is(err, util.ErrNotFoundEmpty) {
+		return false, notOwned, nil
+	}
+
+	if err != nil {
+		return false, "", err
+	}
+
+	if !owner.Owns(source) {
+		return false, notOwned, nil
+	}
+
+	return true, "", nil
+}
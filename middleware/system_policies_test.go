@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/labstack/echo/v4"
+	"github.com/redhatinsights/platform-go-middlewares/identity"
+)
+
+// systemTestContext returns a context for the given route and "id" path parameter, made by the system with the given
+// "system" section.
+func systemTestContext(method, path, id string, system map[string]interface{}) echo.Context {
+	c, _ := request.CreateTestContext(
+		method,
+		path,
+		nil,
+		map[string]interface{}{
+			"x-rh-identity": "dummy",
+			"identity":      identity.XRHID{Identity: identity.Identity{System: system}},
+			"tenantID":      int64(1),
+		},
+	)
+
+	c.SetPath(path)
+	if id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+
+	return c
+}
+
+func TestSystemPolicies(t *testing.T) {
+	RoutePermissions = map[string]Permission{
+		"GET /sources":                  {Resource: "source", Verb: VerbRead},
+		"POST /sources":                 {Resource: "source", Verb: VerbWrite},
+		"PATCH /sources/:id":            {Resource: "source", Verb: VerbWrite, Source: SourceParam("id")},
+		"DELETE /sources/:id":           {Resource: "source", Verb: VerbWrite, Source: SourceParam("id")},
+		"GET /endpoints":                {Resource: "endpoint", Verb: VerbRead},
+		"GET /rhc_connections/:id":      {Resource: "rhc_connection", Verb: VerbRead},
+		"GET /source_types/:id/sources": {Resource: "source", Verb: VerbRead},
+		"GET /sources/:id/applications": {Resource: "application", Verb: VerbRead, Source: SourceParam("id")},
+	}
+	t.Cleanup(func() { RoutePermissions = map[string]Permission{} })
+
+	satelliteOwner, clusterOwner, clusterRef := "cn:satellite", "cluster_id:cluster", "cluster"
+	useSourceDao(t, []m.Source{
+		{ID: 1, Owner: &satelliteOwner, SourceType: m.SourceType{Name: "satellite"}},
+		{ID: 2, Owner: &clusterOwner, SourceType: m.SourceType{Name: "openshift"}},
+		{ID: 3, SourceRef: &clusterRef, SourceType: m.SourceType{Name: "openshift"}},
+		{ID: 4, Owner: &clusterOwner, SourceType: m.SourceType{Name: "amazon"}},
+	})
+
+	satellite := map[string]interface{}{"cn": "satellite"}
+	cluster := map[string]interface{}{"cluster_id": "cluster"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		id     string
+		system map[string]interface{}
+		want   int
+	}{
+		{"list sources", http.MethodGet, "/sources", "", satellite, 204},
+		{"create a source", http.MethodPost, "/sources", "", cluster, 204},
+		{"modify an owned source", http.MethodPatch, "/sources/:id", "1", satellite, 204},
+		{"delete an owned source", http.MethodDelete, "/sources/:id", "2", cluster, 204},
+		{"list an owned source's applications", http.MethodGet, "/sources/:id/applications", "2", cluster, 204},
+		{"source ref of the cluster", http.MethodPatch, "/sources/:id", "3", cluster, 204},
+		{"source of another system", http.MethodPatch, "/sources/:id", "2", satellite, 403},
+		{"source of another system type", http.MethodDelete, "/sources/:id", "1", cluster, 403},
+		{"owned source of a type outside the policy", http.MethodDelete, "/sources/:id", "4", cluster, 403},
+		{"missing source", http.MethodPatch, "/sources/:id", "5", cluster, 403},
+		{"route without a source", http.MethodGet, "/endpoints", "", cluster, 403},
+		{"source list of a source type", http.MethodGet, "/source_types/:id/sources", "1", cluster, 403},
+		{"permission outside the policy", http.MethodGet, "/rhc_connections/:id", "1", satellite, 403},
+		{"route without a permission", http.MethodPost, "/unknown", "", satellite, 403},
+		{"unknown system", http.MethodGet, "/sources", "", map[string]interface{}{"other": "system"}, 403},
+		{"empty cn", http.MethodGet, "/sources", "", map[string]interface{}{"cn": ""}, 403},
+	}
+
+	for _, tt := range tests {
+		c := systemTestContext(tt.method, tt.path, tt.id, tt.system)
+
+		err := permCheckOrElse204(c)
+		if err != nil {
+			t.Errorf("%s: want nil error, got %q", tt.name, err)
+		}
+
+		if code := c.Response().Status; code != tt.want {
+			t.Errorf("%s: %v was returned instead of %v", tt.name, code, tt.want)
+		}
+	}
+}
+
+// TestSystemPoliciesSetOwner tests that the owner the handlers scope the sources to is stored in the context.
+func TestSystemPoliciesSetOwner(t *testing.T) {
+	RoutePermissions = map[string]Permission{"GET /sources": {Resource: "source", Verb: VerbRead}}
+	t.Cleanup(func() { RoutePermissions = map[string]Permission{} })
+
+	c := systemTestContext(http.MethodGet, "/sources", "", map[string]interface{}{"cluster_id": "cluster"})
+
+	err := permCheckOrElse204(c)
+	if err != nil {
+		t.Errorf("want nil error, got %q", err)
+	}
+
+	owner, ok := c.Get("source-owner").(*m.SourceOwner)
+	if !ok {
+		t.Fatalf("want the owner in the context, got %v", c.Get("source-owner"))
+	}
+
+	if owner.String() != "cluster_id:cluster" || len(owner.SourceTypes) != 1 || owner.SourceTypes[0] != "openshift" {
+		t.Errorf(`want the owner "cluster_id:cluster" of the "openshift" sources, got %+v`, owner)
+	}
+}
+
+// TestLoadSystemPolicies tests that the configured policies replace the default ones, and that the default ones are
+// kept when the configured ones are invalid.
+func TestLoadSystemPolicies(t *testing.T) {
+	policies := loadSystemPolicies(`[{"key": "cn", "source_types": ["satellite"], "permissions": ["source:read"]}]`)
+	if len(policies) != 1 || policies[0].Key != "cn" || len(policies[0].Permissions) != 1 || policies[0].Permissions[0] != "source:read" {
+		t.Errorf(`want the configured policy, got "%v"`, policies)
+	}
+
+	for _, raw := range []string{"", "[{", `[{"source_types": ["satellite"]}]`} {
+		policies = loadSystemPolicies(raw)
+		if len(policies) != len(defaultSystemPolicies) || policies[0].Key != defaultSystemPolicies[0].Key {
+			t.Errorf(`configuration "%s": want the default policies, got "%v"`, raw, policies)
+		}
+	}
+}
@@ -13,7 +13,7 @@ import (
 /*
 	Parses all authorization related things into request context, notably:
 
-	1. 'psk' -> x-rh-sources-psk
+	1. 'psk' -> x-rh-sources-psk, along with 'psk-account' and 'psk-org-id'
 
 	2. 'identity' -> parsed version of identity header as a XRHID struct
 
@@ -27,16 +27,21 @@ import (
 func Tenancy(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		switch {
-		case c.Get("psk-account") != nil:
+		case c.Get("psk-account") != nil || c.Get("psk-org-id") != nil:
 			accountNumber, ok := c.Get("psk-account").(string)
-			if !ok {
+			if !ok && c.Get("psk-account") != nil {
 				return fmt.Errorf("failed to cast account-number to string")
 			}
 
-			c.Logger().Debugf("Looking up Tenant ID for account number %v", accountNumber)
+			orgId, ok := c.Get("psk-org-id").(string)
+			if !ok && c.Get("psk-org-id") != nil {
+				return fmt.Errorf("failed to cast org-id to string")
+			}
+
+			c.Logger().Debugf("Looking up Tenant ID for org id %v or account number %v", orgId, accountNumber)
 
 			tenantDao := dao.GetTenantDao()
-			t, err := tenantDao.GetOrCreateTenantID(accountNumber)
+			t, err := tenantDao.GetOrCreateTenantID(accountNumber, orgId)
 			if err != nil {
 				return fmt.Errorf("failed to get or create tenant for request")
 			}
@@ -49,14 +54,15 @@ func Tenancy(next echo.HandlerFunc) echo.HandlerFunc {
 				return fmt.Errorf("failed to cast account-number to string")
 			}
 
-			if identity.Identity.AccountNumber == "" {
-				return fmt.Errorf("account number not present in x-rh-identity")
+			orgId, _ := c.Get("identity-org-id").(string)
+			if identity.Identity.AccountNumber == "" && orgId == "" {
+				return fmt.Errorf("neither an account number nor an org id present in x-rh-identity")
 			}
 
-			c.Logger().Debugf("Looking up Tenant ID for account number %v", identity.Identity.AccountNumber)
+			c.Logger().Debugf("Looking up Tenant ID for org id %v or account number %v", orgId, identity.Identity.AccountNumber)
 
 			tenantDao := dao.GetTenantDao()
-			t, err := tenantDao.GetOrCreateTenantID(identity.Identity.AccountNumber)
+			t, err := tenantDao.GetOrCreateTenantID(identity.Identity.AccountNumber, orgId)
 			if err != nil {
 				return fmt.Errorf("failed to get or create tenant for request: %v", err)
 			}
@@ -64,7 +70,7 @@ func Tenancy(next echo.HandlerFunc) echo.HandlerFunc {
 			c.Set("tenantID", *t)
 
 		default:
-			return c.JSON(http.StatusUnauthorized, util.ErrorDoc("Authentication required by either [x-rh-identity] or [x-rh-sources-psk]", "401"))
+			return c.JSON(http.StatusUnauthorized, util.ErrorDoc(c.Request().Context(), "Authentication required by either [x-rh-identity] or [x-rh-sources-psk]", "401"))
 		}
 
 		return next(c)
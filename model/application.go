@@ -48,6 +48,7 @@ func (app *Application) ToEvent() interface{} {
 		AvailabilityStatusError: util.StringValueOrNil(app.AvailabilityStatusError),
 		SourceID:                app.SourceID,
 		Tenant:                  &app.Tenant.ExternalTenant,
+		OrgID:                   &app.Tenant.OrgID,
 	}
 
 	return appEvent
@@ -27,7 +27,7 @@ type ApplicationAuthentication struct {
 	AuthenticationUID string `json:"-"`
 }
 
-func (aa *ApplicationAuthentication) ToEvent() *ApplicationAuthenticationEvent {
+func (aa *ApplicationAuthentication) ToEvent() interface{} {
 	aaEvent := &ApplicationAuthenticationEvent{
 		ID:                aa.ID,
 		PauseEvent:        PauseEvent{PausedAt: util.DateTimeToRecordFormat(aa.PausedAt)},
@@ -37,6 +37,7 @@ func (aa *ApplicationAuthentication) ToEvent() *ApplicationAuthenticationEvent {
 		AuthenticationID:  aa.AuthenticationID,
 		AuthenticationUID: aa.AuthenticationUID,
 		Tenant:            &aa.Tenant.ExternalTenant,
+		OrgID:             &aa.Tenant.OrgID,
 		VaultPath:         aa.VaultPath,
 	}
 
@@ -49,6 +50,7 @@ func (aa *ApplicationAuthentication) ToResponse() *ApplicationAuthenticationResp
 	authId := strconv.FormatInt(aa.AuthenticationID, 10)
 
 	return &ApplicationAuthenticationResponse{
+		PauseResponse:     PauseResponse{PausedAt: util.DateTimeToRFC3339(aa.PausedAt)},
 		ID:                id,
 		AuthenticationUID: aa.AuthenticationUID,
 		CreatedAt:         util.DateTimeToRFC3339(aa.CreatedAt),
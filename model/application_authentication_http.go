@@ -1,6 +1,8 @@
 package model
 
 type ApplicationAuthenticationResponse struct {
+	PauseResponse
+
 	ID        string `json:"id"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
@@ -45,7 +45,8 @@ func (a *ApplicationType) ToResponse() *ApplicationTypeResponse {
 }
 
 // AvailabilityCheckURL returns the application's availability check URL, e.g. where to send the
-// request for the client to re-check the application's availability status.
+// request for the client to re-check the application's availability status. It is only used for
+// the application types without an availability check dispatcher in the seeds or the config.
 func (at *ApplicationType) AvailabilityCheckURL() *url.URL {
 	// Transforms the path-style name to a prefix set in the ENV
 	// e.g. /insights/platform/cloud-meter -> CLOUD_METER
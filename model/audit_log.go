@@ -0,0 +1,80 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// Types of the actors of the audited requests.
+const (
+	// AuditActorUser is a user authenticated with an identity header.
+	AuditActorUser = "user"
+	// AuditActorPsk is a service authenticated with a pre-shared key, identified by the key's name.
+	AuditActorPsk = "psk"
+	// AuditActorSystem is a system authenticated with a certificate or as a cluster, identified by its cn or cluster id.
+	AuditActorSystem = "system"
+	// AuditActorUnknown is a caller that didn't authenticate.
+	AuditActorUnknown = "unknown"
+)
+
+// Outcomes of the audited requests.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeDenied  = "denied"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditLog is an append-only record of a request which changed a resource or read a secret. Only the names of the
+// changed fields are recorded, never their values.
+type AuditLog struct {
+	ID        int64     `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	// TenantID is empty for the requests which were rejected before their tenant was known.
+	TenantID *int64 `gorm:"index" json:"tenant_id"`
+
+	ActorType string `json:"actor_type"`
+	Actor     string `gorm:"index" json:"actor"`
+
+	Method       string `json:"method"`
+	Route        string `json:"route"`
+	ResourceType string `gorm:"index:idx_audit_logs_resource" json:"resource_type"`
+	ResourceID   string `gorm:"index:idx_audit_logs_resource" json:"resource_id"`
+	// Fields are the names of the changed or read fields, separated by commas.
+	Fields string `json:"fields"`
+
+	RequestID string `gorm:"index" json:"request_id"`
+	Status    int    `json:"status"`
+	Outcome   string `json:"outcome"`
+}
+
+func (al *AuditLog) ToResponse() *AuditLogResponse {
+	var tenantId string
+	if al.TenantID != nil {
+		tenantId = strconv.FormatInt(*al.TenantID, 10)
+	}
+
+	fields := make([]string, 0)
+	if al.Fields != "" {
+		fields = strings.Split(al.Fields, ",")
+	}
+
+	return &AuditLogResponse{
+		ID:           strconv.FormatInt(al.ID, 10),
+		CreatedAt:    util.DateTimeToRFC3339(al.CreatedAt),
+		TenantID:     tenantId,
+		ActorType:    al.ActorType,
+		Actor:        al.Actor,
+		Method:       al.Method,
+		Route:        al.Route,
+		ResourceType: al.ResourceType,
+		ResourceID:   al.ResourceID,
+		Fields:       fields,
+		RequestID:    al.RequestID,
+		Status:       al.Status,
+		Outcome:      al.Outcome,
+	}
+}
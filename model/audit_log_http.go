@@ -0,0 +1,18 @@
+package model
+
+// AuditLogResponse represents a request which changed a resource or read a secret.
+type AuditLogResponse struct {
+	ID           string   `json:"id"`
+	CreatedAt    string   `json:"created_at"`
+	TenantID     string   `json:"tenant_id,omitempty"`
+	ActorType    string   `json:"actor_type"`
+	Actor        string   `json:"actor"`
+	Method       string   `json:"method"`
+	Route        string   `json:"route"`
+	ResourceType string   `json:"resource_type"`
+	ResourceID   string   `json:"resource_id,omitempty"`
+	Fields       []string `json:"fields"`
+	RequestID    string   `json:"request_id,omitempty"`
+	Status       int      `json:"status"`
+	Outcome      string   `json:"outcome"`
+}
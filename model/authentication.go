@@ -123,6 +123,7 @@ func (auth *Authentication) ToEvent() *AuthenticationEvent {
 		ResourceType:            auth.ResourceType,
 		ResourceID:              auth.ResourceID,
 		Tenant:                  &auth.Tenant.ExternalTenant,
+		OrgID:                   &auth.Tenant.OrgID,
 		SourceID:                auth.SourceID,
 	}
 
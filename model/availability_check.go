@@ -0,0 +1,118 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// Availability check statuses.
+const (
+	AvailabilityCheckPending   = "pending"
+	AvailabilityCheckCompleted = "completed"
+)
+
+// Availability check targets' dispatch statuses.
+const (
+	// DispatchPending means the availability check request hasn't been sent yet.
+	DispatchPending = "pending"
+	// DispatchSent means the availability check request was sent, and the status message is awaited.
+	DispatchSent = "dispatched"
+	// DispatchFailed means the availability check request couldn't be sent.
+	DispatchFailed = "failed"
+	// DispatchSkipped means there was no one to send the availability check request to.
+	DispatchSkipped = "skipped"
+)
+
+// AvailabilityCheck is a record of an availability check requested for a source, which gets completed once every
+// target has either answered through a status message, or couldn't be dispatched.
+type AvailabilityCheck struct {
+	ID          int64      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	SourceID int64 `json:"source_id"`
+	Source   Source
+
+	TenantID int64
+	Tenant   Tenant
+
+	// RequestID is the id of the request which asked for the check, which is forwarded with the availability check
+	// requests.
+	RequestID string `json:"request_id"`
+
+	Targets []AvailabilityCheckTarget
+}
+
+// AvailabilityCheckTarget is one of the resources whose availability was requested as part of an availability check.
+type AvailabilityCheckTarget struct {
+	ID        int64     `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	AvailabilityCheckID int64 `json:"availability_check_id"`
+	TenantID            int64
+
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+
+	DispatchStatus string     `json:"dispatch_status"`
+	DispatchError  string     `json:"dispatch_error"`
+	ResponseCode   int        `json:"response_code"`
+	DispatchedAt   *time.Time `json:"dispatched_at"`
+
+	Status      string     `json:"status"`
+	StatusError string     `json:"status_error"`
+	RespondedAt *time.Time `json:"responded_at"`
+}
+
+// Answered returns true when the target won't receive any more status messages.
+func (act *AvailabilityCheckTarget) Answered() bool {
+	return act.RespondedAt != nil || act.DispatchStatus == DispatchFailed || act.DispatchStatus == DispatchSkipped
+}
+
+func (ac *AvailabilityCheck) ToResponse() *AvailabilityCheckResponse {
+	status := AvailabilityCheckPending
+	if ac.CompletedAt != nil {
+		status = AvailabilityCheckCompleted
+	}
+
+	targets := make([]AvailabilityCheckTargetResponse, len(ac.Targets))
+	for i, target := range ac.Targets {
+		targets[i] = *target.ToResponse()
+	}
+
+	return &AvailabilityCheckResponse{
+		ID:          strconv.FormatInt(ac.ID, 10),
+		SourceID:    strconv.FormatInt(ac.SourceID, 10),
+		Status:      status,
+		CreatedAt:   util.DateTimeToRFC3339(ac.CreatedAt),
+		CompletedAt: timePointerToRFC3339(ac.CompletedAt),
+		Targets:     targets,
+	}
+}
+
+func (act *AvailabilityCheckTarget) ToResponse() *AvailabilityCheckTargetResponse {
+	return &AvailabilityCheckTargetResponse{
+		ResourceType:   act.ResourceType,
+		ResourceID:     act.ResourceID,
+		DispatchStatus: act.DispatchStatus,
+		DispatchError:  act.DispatchError,
+		ResponseCode:   act.ResponseCode,
+		DispatchedAt:   timePointerToRFC3339(act.DispatchedAt),
+		Status:         act.Status,
+		StatusError:    act.StatusError,
+		RespondedAt:    timePointerToRFC3339(act.RespondedAt),
+	}
+}
+
+// timePointerToRFC3339 formats the time if it is set.
+func timePointerToRFC3339(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return util.DateTimeToRFC3339(*t)
+}
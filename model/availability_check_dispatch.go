@@ -0,0 +1,39 @@
+package model
+
+// Availability check dispatch strategies.
+const (
+	// DispatchStrategyHttp sends the availability check request to an HTTP endpoint.
+	DispatchStrategyHttp = "http"
+	// DispatchStrategyKafka publishes the availability check request to a Kafka topic.
+	DispatchStrategyKafka = "kafka"
+	// DispatchStrategyNoop doesn't send the availability check request anywhere.
+	DispatchStrategyNoop = "noop"
+)
+
+// AvailabilityCheckDispatch configures how the availability check requests are sent for an application type or a
+// source type.
+type AvailabilityCheckDispatch struct {
+	// Strategy is one of "http", "kafka" or "noop".
+	Strategy string `json:"strategy"`
+
+	// URL is where the HTTP requests are sent to. URLEnv names an environment variable holding the URL instead, which
+	// takes precedence, since the URLs usually differ between the environments.
+	URL    string `json:"url,omitempty"`
+	URLEnv string `json:"url_env,omitempty"`
+	// TimeoutSeconds limits the time spent waiting for each HTTP request.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// Retries is the number of times a failed HTTP request is retried.
+	Retries int `json:"retries,omitempty"`
+	// HeadersFromEnv maps the extra HTTP headers to the environment variables holding their values, so that the
+	// credentials are kept out of the configuration.
+	HeadersFromEnv map[string]string `json:"headers_from_env,omitempty"`
+
+	// Topic is the Kafka topic the requests are published to.
+	Topic string `json:"topic,omitempty"`
+}
+
+// AvailabilityCheckDispatchers holds the dispatch configurations keyed by application type and source type names.
+type AvailabilityCheckDispatchers struct {
+	ApplicationTypes map[string]AvailabilityCheckDispatch `json:"application_types"`
+	SourceTypes      map[string]AvailabilityCheckDispatch `json:"source_types"`
+}
@@ -0,0 +1,23 @@
+package model
+
+// AvailabilityCheckResponse represents an availability check and the state of each of its targets.
+type AvailabilityCheckResponse struct {
+	ID          string                            `json:"id"`
+	SourceID    string                            `json:"source_id"`
+	Status      string                            `json:"status"`
+	CreatedAt   string                            `json:"created_at"`
+	CompletedAt string                            `json:"completed_at,omitempty"`
+	Targets     []AvailabilityCheckTargetResponse `json:"targets"`
+}
+
+type AvailabilityCheckTargetResponse struct {
+	ResourceType   string `json:"resource_type"`
+	ResourceID     string `json:"resource_id"`
+	DispatchStatus string `json:"dispatch_status"`
+	DispatchError  string `json:"dispatch_error,omitempty"`
+	ResponseCode   int    `json:"response_code,omitempty"`
+	DispatchedAt   string `json:"dispatched_at,omitempty"`
+	Status         string `json:"status,omitempty"`
+	StatusError    string `json:"status_error,omitempty"`
+	RespondedAt    string `json:"responded_at,omitempty"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// Origins of the availability status transitions.
+const (
+	// AvailabilityStatusOriginStatusMessage means the status was reported through the status topic.
+	AvailabilityStatusOriginStatusMessage = "status_message"
+	// AvailabilityStatusOriginApi means the status was set through the API.
+	AvailabilityStatusOriginApi = "api"
+	// AvailabilityStatusOriginDerived means the status was derived from the statuses of the source's children.
+	AvailabilityStatusOriginDerived = "derived"
+	// AvailabilityStatusOriginVerifier means the status was set by one of the built-in verifiers.
+	AvailabilityStatusOriginVerifier = "verifier"
+)
+
+// AvailabilityStatusHistory is an append-only record of an availability status transition of a resource.
+type AvailabilityStatusHistory struct {
+	ID        int64     `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	TenantID int64
+	Tenant   Tenant
+
+	ResourceType string `gorm:"index:idx_availability_status_history_resource" json:"resource_type"`
+	ResourceID   string `gorm:"index:idx_availability_status_history_resource" json:"resource_id"`
+
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+	StatusError    string `json:"status_error"`
+	Origin         string `json:"origin"`
+}
+
+// TableName keeps the table's name in singular, since "history" already refers to the whole set of records.
+func (AvailabilityStatusHistory) TableName() string {
+	return "availability_status_history"
+}
+
+func (ash *AvailabilityStatusHistory) ToResponse() *AvailabilityStatusHistoryResponse {
+	return &AvailabilityStatusHistoryResponse{
+		ID:             strconv.FormatInt(ash.ID, 10),
+		CreatedAt:      util.DateTimeToRFC3339(ash.CreatedAt),
+		ResourceType:   ash.ResourceType,
+		ResourceID:     ash.ResourceID,
+		Status:         ash.Status,
+		PreviousStatus: ash.PreviousStatus,
+		StatusError:    ash.StatusError,
+		Origin:         ash.Origin,
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+// AvailabilityStatusHistoryResponse represents an availability status transition of a resource.
+type AvailabilityStatusHistoryResponse struct {
+	ID             string `json:"id"`
+	CreatedAt      string `json:"created_at"`
+	ResourceType   string `json:"resource_type"`
+	ResourceID     string `json:"resource_id"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	StatusError    string `json:"status_error,omitempty"`
+	Origin         string `json:"origin"`
+}
@@ -6,6 +6,12 @@ type Pause struct {
 	PausedAt time.Time `json:"paused_at,omitempty"`
 }
 
+// PauseRequest is the optional payload of the pause calls. When "resume_at" is given, the resource gets resumed on its
+// own at that time.
+type PauseRequest struct {
+	ResumeAt *time.Time `json:"resume_at"`
+}
+
 type AvailabilityStatus struct {
 	AvailabilityStatus string    `json:"availability_status,omitempty"`
 	LastCheckedAt      time.Time `json:"last_checked_at,omitempty"`
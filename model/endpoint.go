@@ -26,6 +26,9 @@ type Endpoint struct {
 	ReceptorNode            *string `json:"receptor_node,omitempty"`
 	AvailabilityStatusError *string `json:"availability_status_error,omitempty"`
 
+	// Certificate is the certificate the endpoint presented when it was last probed, if it was.
+	Certificate *EndpointCertificate `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+
 	SourceID int64 `json:"source_id"`
 	Source   Source
 
@@ -33,6 +36,52 @@ type Endpoint struct {
 	Tenant   Tenant
 }
 
+func (endpoint *Endpoint) UpdateFromRequest(req *EndpointEditRequest) {
+	if req.Default != nil {
+		endpoint.Default = req.Default
+	}
+
+	if req.ReceptorNode != nil {
+		endpoint.ReceptorNode = req.ReceptorNode
+	}
+
+	if req.Role != nil {
+		endpoint.Role = req.Role
+	}
+
+	if req.Scheme != nil {
+		endpoint.Scheme = req.Scheme
+	}
+
+	if req.Host != nil {
+		endpoint.Host = req.Host
+	}
+
+	if req.Port != nil {
+		endpoint.Port = req.Port
+	}
+
+	if req.Path != nil {
+		endpoint.Path = req.Path
+	}
+
+	if req.VerifySsl != nil {
+		endpoint.VerifySsl = req.VerifySsl
+	}
+
+	if req.CertificateAuthority != nil {
+		endpoint.CertificateAuthority = req.CertificateAuthority
+	}
+
+	if req.AvailabilityStatus != nil {
+		endpoint.AvailabilityStatus = AvailabilityStatus{AvailabilityStatus: *req.AvailabilityStatus}
+	}
+
+	if req.AvailabilityStatusError != nil {
+		endpoint.AvailabilityStatusError = req.AvailabilityStatusError
+	}
+}
+
 func (endpoint *Endpoint) ToEvent() interface{} {
 	asEvent := AvailabilityStatusEvent{AvailabilityStatus: util.StringValueOrNil(endpoint.AvailabilityStatus.AvailabilityStatus),
 		LastAvailableAt: util.DateTimeToRecordFormat(endpoint.LastAvailableAt),
@@ -56,6 +105,7 @@ func (endpoint *Endpoint) ToEvent() interface{} {
 		UpdatedAt:               util.DateTimeToRecordFormat(endpoint.UpdatedAt),
 		AvailabilityStatusError: util.StringValueOrNil(endpoint.AvailabilityStatusError),
 		Tenant:                  &endpoint.Tenant.ExternalTenant,
+		OrgID:                   &endpoint.Tenant.OrgID,
 	}
 
 	return endpointEvent
@@ -70,7 +120,7 @@ func (endpoint *Endpoint) ToResponse() *EndpointResponse {
 		LastAvailableAt:    util.DateTimeToRFC3339(endpoint.LastAvailableAt),
 	}
 
-	return &EndpointResponse{
+	response := &EndpointResponse{
 		AvailabilityStatusResponse: asResponse,
 		PauseResponse:              PauseResponse{PausedAt: util.DateTimeToRFC3339(endpoint.PausedAt)},
 		ID:                         id,
@@ -88,4 +138,12 @@ func (endpoint *Endpoint) ToResponse() *EndpointResponse {
 		AvailabilityStatusError:    endpoint.AvailabilityStatusError,
 		SourceID:                   sourceId,
 	}
+
+	if endpoint.Certificate != nil {
+		response.CertificateSubject = &endpoint.Certificate.Subject
+		response.CertificateIssuer = &endpoint.Certificate.Issuer
+		response.CertificateNotAfter = util.StringValueOrNil(util.DateTimeToRFC3339(endpoint.Certificate.NotAfter))
+	}
+
+	return response
 }
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// EndpointCertificate is the certificate an endpoint presented the last time its connectivity was probed.
+type EndpointCertificate struct {
+	ID        int64     `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	EndpointID int64 `gorm:"uniqueIndex" json:"endpoint_id"`
+	TenantID   int64
+
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"not_after"`
+}
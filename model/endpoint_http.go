@@ -19,6 +19,10 @@ type EndpointResponse struct {
 	ReceptorNode            *string `json:"receptor_node,omitempty"`
 	AvailabilityStatusError *string `json:"availability_status_error,omitempty"`
 
+	CertificateSubject  *string `json:"certificate_subject,omitempty"`
+	CertificateIssuer   *string `json:"certificate_issuer,omitempty"`
+	CertificateNotAfter *string `json:"certificate_not_after,omitempty"`
+
 	SourceID string `json:"source_id"`
 }
 
@@ -36,3 +40,18 @@ type EndpointCreateRequest struct {
 	SourceID             int64       `json:"-"`
 	SourceIDRaw          interface{} `json:"source_id"`
 }
+
+// EndpointEditRequest is the payload of the endpoint's update. Only the given fields get updated.
+type EndpointEditRequest struct {
+	Default                 *bool   `json:"default"`
+	ReceptorNode            *string `json:"receptor_node"`
+	Role                    *string `json:"role"`
+	Scheme                  *string `json:"scheme"`
+	Host                    *string `json:"host"`
+	Port                    *int    `json:"port"`
+	Path                    *string `json:"path"`
+	VerifySsl               *bool   `json:"verify_ssl"`
+	CertificateAuthority    *string `json:"certificate_authority"`
+	AvailabilityStatus      *string `json:"availability_status"`
+	AvailabilityStatusError *string `json:"availability_status_error"`
+}
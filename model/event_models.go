@@ -31,6 +31,7 @@ type ApplicationEvent struct {
 	SourceID          int64   `json:"source_id"`
 	ApplicationTypeID int64   `json:"application_type_id"`
 	Tenant            *string `json:"tenant"`
+	OrgID             *string `json:"org_id"`
 }
 
 type AuthenticationEvent struct {
@@ -49,6 +50,7 @@ type AuthenticationEvent struct {
 	ResourceID              int64                  `json:"resource_id"`
 	SourceID                int64                  `json:"source_id"`
 	Tenant                  *string                `json:"tenant"`
+	OrgID                   *string                `json:"org_id"`
 }
 
 type ApplicationAuthenticationEvent struct {
@@ -63,6 +65,7 @@ type ApplicationAuthenticationEvent struct {
 	AuthenticationUID string `json:"authentication_uid"`
 
 	Tenant    *string `json:"tenant"`
+	OrgID     *string `json:"org_id"`
 	VaultPath string  `json:"vault_path"`
 }
 
@@ -87,6 +90,7 @@ type EndpointEvent struct {
 
 	SourceID int64   `json:"source_id"`
 	Tenant   *string `json:"tenant"`
+	OrgID    *string `json:"org_id"`
 }
 
 type SourceEvent struct {
@@ -104,6 +108,7 @@ type SourceEvent struct {
 	AppCreationWorkflow *string `json:"app_creation_workflow"`
 	SourceTypeID        *int64  `json:"source_type_id"`
 	Tenant              *string `json:"tenant"`
+	OrgID               *string `json:"org_id"`
 }
 
 type RhcConnectionEvent struct {
@@ -33,12 +33,18 @@ func (r *RhcConnection) ToEvent() interface{} {
 		LastCheckedAt:      util.DateTimeToRecordFormat(r.LastCheckedAt),
 	}
 
+	sourceIds := make([]string, len(r.Sources))
+	for i, src := range r.Sources {
+		sourceIds[i] = strconv.FormatInt(src.ID, 10)
+	}
+
 	rhcConnectionEvent := &RhcConnectionEvent{
 		ID:                      &id,
 		RhcId:                   &r.RhcId,
 		Extra:                   r.Extra,
 		AvailabilityStatusEvent: asEvent,
 		AvailabilityStatusError: &r.AvailabilityStatusError,
+		SourceIds:               sourceIds,
 		CreatedAt:               util.DateTimeToRecordFormat(r.CreatedAt),
 		UpdatedAt:               util.DateTimeToRecordFormat(r.UpdatedAt),
 	}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ScheduledResume is a paused resource which gets resumed on its own once "resume_at" is reached.
+type ScheduledResume struct {
+	ID        int64     `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	TenantID int64
+	Tenant   Tenant
+
+	ResourceType string    `gorm:"uniqueIndex:idx_scheduled_resumes_resource" json:"resource_type"`
+	ResourceID   int64     `gorm:"uniqueIndex:idx_scheduled_resumes_resource" json:"resource_id"`
+	ResumeAt     time.Time `gorm:"index" json:"resume_at"`
+}
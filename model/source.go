@@ -31,6 +31,8 @@ type Source struct {
 	Imported            *string `json:"imported,omitempty"`
 	SourceRef           *string `json:"source_ref,omitempty"`
 	AppCreationWorkflow string  `gorm:"default:manual_configuration" json:"app_creation_workflow"`
+	// Owner is the system which created the source, as "<key>:<id>" after the key of the identity's "system" section.
+	Owner *string `gorm:"index" json:"-"`
 
 	SourceType   SourceType
 	SourceTypeID int64 `json:"source_type_id"`
@@ -62,6 +64,7 @@ func (src *Source) ToEvent() interface{} {
 		AppCreationWorkflow:     &src.AppCreationWorkflow,
 		SourceTypeID:            &src.SourceTypeID,
 		Tenant:                  &src.Tenant.ExternalTenant,
+		OrgID:                   &src.Tenant.OrgID,
 	}
 
 	return sourceEvent
@@ -97,6 +100,7 @@ func (src *Source) ToInternalResponse() *SourceInternalResponse {
 	id := strconv.FormatInt(src.ID, 10)
 
 	source := &SourceInternalResponse{
+		PauseResponse:      PauseResponse{PausedAt: util.DateTimeToRFC3339(src.PausedAt)},
 		Id:                 &id,
 		AvailabilityStatus: &src.AvailabilityStatus.AvailabilityStatus,
 		ExternalTenant:     &src.Tenant.ExternalTenant,
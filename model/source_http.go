@@ -48,6 +48,8 @@ type SourceResponse struct {
 // SourceInternalResponse represents the structure we will return
 // when a source is requested from the internal endpoint.
 type SourceInternalResponse struct {
+	PauseResponse
+
 	Id                 *string `json:"id"`
 	AvailabilityStatus *string `json:"availability_status"`
 	ExternalTenant     *string `json:"tenant"`
@@ -0,0 +1,38 @@
+package model
+
+// SourceOwner is a system identity, which only gets to see and modify the sources it owns: the ones it created, and
+// the ones created before the owners were tracked whose source ref is its id, such as the OpenShift sources that
+// point to their cluster.
+type SourceOwner struct {
+	// Key is the key of the identity's "system" section which identifies the system, such as "cn" or "cluster_id".
+	Key string
+	ID  string
+	// SourceTypes are the names of the source types of the sources the system may own.
+	SourceTypes []string
+}
+
+// String returns the owner as it is stored in the sources' "owner" column.
+func (o *SourceOwner) String() string {
+	return o.Key + ":" + o.ID
+}
+
+// Owns tells whether the source belongs to the system. The source must have its source type preloaded.
+func (o *SourceOwner) Owns(src *Source) bool {
+	allowedType := false
+	for _, name := range o.SourceTypes {
+		if src.SourceType.Name == name {
+			allowedType = true
+			break
+		}
+	}
+
+	if !allowedType {
+		return false
+	}
+
+	if src.Owner != nil {
+		return *src.Owner == o.String()
+	}
+
+	return src.SourceRef != nil && *src.SourceRef == o.ID
+}
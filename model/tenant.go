@@ -4,7 +4,8 @@ import "time"
 
 type Tenant struct {
 	Id             int64
-	ExternalTenant string
+	ExternalTenant string `gorm:"uniqueIndex:index_tenants_on_external_tenant_without_org_id,where:external_tenant <> '' AND (org_id IS NULL OR org_id = '')"`
+	OrgID          string `gorm:"uniqueIndex:index_tenants_on_org_id_unique,where:org_id <> ''"`
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
@@ -16,3 +16,12 @@ func Init() {
 		Password: cfg.CachePassword,
 	})
 }
+
+// Close closes the Redis client, releasing its connections.
+func Close() error {
+	if Client == nil {
+		return nil
+	}
+
+	return Client.Close()
+}
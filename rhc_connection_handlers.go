@@ -21,18 +21,18 @@ func getDefaultRhcConnectionDao(c echo.Context) (dao.RhcConnectionDao, error) {
 		return nil, err
 	}
 
-	return dao.GetRhcConnectionDao(&tenantId), nil
+	return dao.GetRhcConnectionDao(&tenantId).WithContext(c.Request().Context()), nil
 }
 
 func RhcConnectionList(c echo.Context) error {
 	filters, err := getFilters(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	limit, offset, err := getLimitAndOffset(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	rhcConnectionDao, err := getRhcConnectionDao(c)
@@ -58,7 +58,7 @@ func RhcConnectionGetById(c echo.Context) error {
 
 	rhcConnectionId, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc("invalid id provided ", "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), "invalid id provided ", "400"))
 	}
 
 	rhcConnectionDao, err := getRhcConnectionDao(c)
@@ -72,7 +72,7 @@ func RhcConnectionGetById(c echo.Context) error {
 		if errors.Is(err, util.ErrNotFoundEmpty) {
 			return err
 		}
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	return c.JSON(http.StatusOK, rhcConnection.ToResponse())
@@ -86,7 +86,12 @@ func RhcConnectionCreate(c echo.Context) error {
 
 	err := service.ValidateRhcConnectionRequest(input)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
+	}
+
+	err = checkNotPaused(c, service.PausedSource, input.SourceId)
+	if err != nil {
+		return err
 	}
 
 	rhcConnection := &model.RhcConnection{
@@ -115,7 +120,7 @@ func RhcConnectionUpdate(c echo.Context) error {
 
 	rhcConnectionId, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc("invalid id provided ", "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), "invalid id provided ", "400"))
 	}
 
 	input := &model.RhcConnectionUpdateRequest{}
@@ -133,7 +138,15 @@ func RhcConnectionUpdate(c echo.Context) error {
 		if errors.Is(err, util.ErrNotFoundEmpty) {
 			return err
 		}
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
+	}
+
+	// the connection can't change while any of the sources it is linked to is paused.
+	for _, src := range dbRhcConnection.Sources {
+		err = checkNotPaused(c, service.PausedSource, src.ID)
+		if err != nil {
+			return err
+		}
 	}
 
 	dbRhcConnection.UpdateFromRequest(input)
@@ -152,7 +165,7 @@ func RhcConnectionDelete(c echo.Context) error {
 
 	rhcConnectionId, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc("invalid id provided ", "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), "invalid id provided ", "400"))
 	}
 
 	rhcConnectionDao, err := getRhcConnectionDao(c)
@@ -176,17 +189,17 @@ func RhcConnectionSourcesList(c echo.Context) error {
 
 	rhcConnectionId, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc("invalid id provided ", "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), "invalid id provided ", "400"))
 	}
 
 	filters, err := getFilters(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	limit, offset, err := getLimitAndOffset(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	// Check if the given rhcConnection exists.
@@ -200,7 +213,7 @@ func RhcConnectionSourcesList(c echo.Context) error {
 		if errors.Is(err, util.ErrNotFoundEmpty) {
 			return err
 		}
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	sourceDao, err := getSourceDao(c)
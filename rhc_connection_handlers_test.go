@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/parser"
 	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
@@ -178,6 +179,40 @@ func TestRhcConnectionCreate(t *testing.T) {
 	}
 }
 
+// TestRhcConnectionCreatePausedSource tests that a connection can't be linked to a paused source.
+func TestRhcConnectionCreatePausedSource(t *testing.T) {
+	usePausedSource(t, fixtures.TestSourceData[0])
+
+	requestBody := model.RhcConnectionCreateRequest{
+		SourceIdRaw: fixtures.TestSourceData[0].ID,
+		RhcId:       "12345",
+	}
+
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/rhc_connections",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	conflictRhcConnectionCreate := ErrorHandlingContext(RhcConnectionCreate)
+	err = conflictRhcConnectionCreate(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.ConflictTest(t, rec)
+}
+
 func TestRhcConnectionCreateInvalidInput(t *testing.T) {
 	requestBody := model.RhcConnectionCreateRequest{
 		Extra:    nil,
@@ -245,6 +280,40 @@ func TestRhcConnectionUpdate(t *testing.T) {
 	}
 }
 
+// TestRhcConnectionUpdatePausedSource tests that a connection can't be updated while any of its sources is paused.
+func TestRhcConnectionUpdatePausedSource(t *testing.T) {
+	usePausedSource(t, fixtures.TestSourceData[0])
+
+	body, err := json.Marshal(model.RhcConnectionUpdateRequest{})
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	id := strconv.FormatInt(fixtures.TestRhcConnectionData[1].ID, 10)
+
+	c, rec := request.CreateTestContext(
+		http.MethodPatch,
+		"/api/sources/v3.1/rhc_connections/"+id,
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+
+	conflictRhcConnectionUpdate := ErrorHandlingContext(RhcConnectionUpdate)
+	err = conflictRhcConnectionUpdate(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.ConflictTest(t, rec)
+}
+
 func TestRhcConnectionUpdateNotFound(t *testing.T) {
 	invalidId := "12345"
 
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"github.com/RedHatInsights/sources-api-go/middleware"
+)
+
+const (
+	v3Prefix       = "/api/sources/v3.1"
+	internalPrefix = "/internal/v2.0"
+)
+
+// read and write build the permissions of the routes. The source finder is what allows the RBAC resource definitions
+// to grant access to the route, and it is left empty for the routes which don't target a single source.
+func read(resource string, source middleware.SourceFinder) middleware.Permission {
+	return middleware.Permission{Resource: resource, Verb: middleware.VerbRead, Source: source}
+}
+
+// list builds the permission of the list routes which the RBAC resource definitions restrict to the sources they
+// grant, through the column of the listed resources which holds their source ids.
+func list(resource string, column string) middleware.Permission {
+	return middleware.Permission{Resource: resource, Verb: middleware.VerbRead, ScopeColumn: column}
+}
+
+func write(resource string, source middleware.SourceFinder) middleware.Permission {
+	return middleware.Permission{Resource: resource, Verb: middleware.VerbWrite, Source: source}
+}
+
+// routePermissions maps every route that goes through the permission check to the RBAC permission it requires. The
+// routes which aren't in here are denied.
+var routePermissions = map[string]middleware.Permission{
+	// Sources
+	"GET " + v3Prefix + "/sources":                                          list("source", "id"),
+	"GET " + v3Prefix + "/sources/:id":                                      read("source", middleware.SourceParam("id")),
+	"POST " + v3Prefix + "/sources":                                         write("source", nil),
+	"PATCH " + v3Prefix + "/sources/:id":                                    write("source", middleware.SourceParam("id")),
+	"DELETE " + v3Prefix + "/sources/:id":                                   write("source", middleware.SourceParam("id")),
+	"POST " + v3Prefix + "/sources/:source_id/check_availability":           write("source", middleware.SourceParam("source_id")),
+	"GET " + v3Prefix + "/sources/:source_id/availability_checks/:check_id": read("source", middleware.SourceParam("source_id")),
+	"GET " + v3Prefix + "/sources/:source_id/availability_history":          read("source", middleware.SourceParam("source_id")),
+	"GET " + v3Prefix + "/sources/:source_id/application_types":             read("source", middleware.SourceParam("source_id")),
+	"GET " + v3Prefix + "/sources/:source_id/applications":                  read("application", middleware.SourceParam("source_id")),
+	"GET " + v3Prefix + "/sources/:source_id/endpoints":                     read("endpoint", middleware.SourceParam("source_id")),
+	"GET " + v3Prefix + "/sources/:source_id/authentications":               read("authentication", middleware.SourceParam("source_id")),
+	"GET " + v3Prefix + "/sources/:source_id/rhc_connections":               read("rhc_connection", middleware.SourceParam("source_id")),
+	"POST " + v3Prefix + "/sources/:source_id/pause":                        write("source", middleware.SourceParam("source_id")),
+	"POST " + v3Prefix + "/sources/:source_id/unpause":                      write("source", middleware.SourceParam("source_id")),
+
+	// Applications
+	"GET " + v3Prefix + "/applications":                                      list("application", "source_id"),
+	"GET " + v3Prefix + "/applications/:id":                                  read("application", middleware.ApplicationParam("id")),
+	"POST " + v3Prefix + "/applications":                                     write("application", middleware.SourceInBody),
+	"PATCH " + v3Prefix + "/applications/:id":                                write("application", middleware.ApplicationParam("id")),
+	"DELETE " + v3Prefix + "/applications/:id":                               write("application", middleware.ApplicationParam("id")),
+	"GET " + v3Prefix + "/applications/:application_id/authentications":      read("authentication", middleware.ApplicationParam("application_id")),
+	"GET " + v3Prefix + "/applications/:application_id/availability_history": read("application", middleware.ApplicationParam("application_id")),
+	"POST " + v3Prefix + "/applications/:id/pause":                           write("application", middleware.ApplicationParam("id")),
+	"POST " + v3Prefix + "/applications/:id/unpause":                         write("application", middleware.ApplicationParam("id")),
+
+	// Authentications
+	"GET " + v3Prefix + "/authentications":         read("authentication", nil),
+	"GET " + v3Prefix + "/authentications/:uid":    read("authentication", middleware.AuthenticationParam("uid")),
+	"POST " + v3Prefix + "/authentications":        write("authentication", middleware.SourceInBody),
+	"PATCH " + v3Prefix + "/authentications/:uid":  write("authentication", middleware.AuthenticationParam("uid")),
+	"DELETE " + v3Prefix + "/authentications/:uid": write("authentication", middleware.AuthenticationParam("uid")),
+
+	// ApplicationTypes
+	"GET " + v3Prefix + "/application_types/:application_type_id/sources": list("source", "id"),
+
+	// Endpoints
+	"GET " + v3Prefix + "/endpoints":                                   list("endpoint", "source_id"),
+	"GET " + v3Prefix + "/endpoints/:id":                               read("endpoint", middleware.EndpointParam("id")),
+	"POST " + v3Prefix + "/endpoints":                                  write("endpoint", middleware.SourceInBody),
+	"PATCH " + v3Prefix + "/endpoints/:id":                             write("endpoint", middleware.EndpointParam("id")),
+	"DELETE " + v3Prefix + "/endpoints/:id":                            write("endpoint", middleware.EndpointParam("id")),
+	"POST " + v3Prefix + "/endpoints/:id/probe":                        write("endpoint", middleware.EndpointParam("id")),
+	"POST " + v3Prefix + "/endpoints/:id/pause":                        write("endpoint", middleware.EndpointParam("id")),
+	"POST " + v3Prefix + "/endpoints/:id/unpause":                      write("endpoint", middleware.EndpointParam("id")),
+	"GET " + v3Prefix + "/endpoints/:endpoint_id/authentications":      read("authentication", middleware.EndpointParam("endpoint_id")),
+	"GET " + v3Prefix + "/endpoints/:endpoint_id/availability_history": read("endpoint", middleware.EndpointParam("endpoint_id")),
+
+	// ApplicationAuthentications
+	"GET " + v3Prefix + "/application_authentications":                                                read("application_authentication", nil),
+	"GET " + v3Prefix + "/application_authentications/:id":                                            read("application_authentication", middleware.ApplicationAuthenticationParam("id")),
+	"POST " + v3Prefix + "/application_authentications/:id/pause":                                     write("application_authentication", middleware.ApplicationAuthenticationParam("id")),
+	"POST " + v3Prefix + "/application_authentications/:id/unpause":                                   write("application_authentication", middleware.ApplicationAuthenticationParam("id")),
+	"GET " + v3Prefix + "/application_authentications/:application_authentication_id/authentications": read("authentication", middleware.ApplicationAuthenticationParam("application_authentication_id")),
+
+	// SourceTypes
+	"GET " + v3Prefix + "/source_types/:source_type_id/sources": list("source", "id"),
+
+	// Red Hat Connector Connections
+	"GET " + v3Prefix + "/rhc_connections":             read("rhc_connection", nil),
+	"GET " + v3Prefix + "/rhc_connections/:id":         read("rhc_connection", nil),
+	"POST " + v3Prefix + "/rhc_connections":            write("rhc_connection", nil),
+	"PATCH " + v3Prefix + "/rhc_connections/:id":       write("rhc_connection", nil),
+	"DELETE " + v3Prefix + "/rhc_connections/:id":      write("rhc_connection", nil),
+	"GET " + v3Prefix + "/rhc_connections/:id/sources": read("source", nil),
+
+	// Internal API
+	"GET " + internalPrefix + "/authentications/:uuid": read("authentication", nil),
+	"GET " + internalPrefix + "/sources":               read("source", nil),
+	"POST " + internalPrefix + "/replay":               write("replay", nil),
+	"GET " + internalPrefix + "/audit_logs":            read("audit_log", nil),
+}
@@ -11,7 +11,8 @@ var listMiddleware = []echo.MiddlewareFunc{
 	middleware.SortAndFilter, middleware.Pagination,
 }
 
-var tenancyWithListMiddleware = append([]echo.MiddlewareFunc{middleware.Tenancy}, listMiddleware...)
+var tenancyMiddleware = []echo.MiddlewareFunc{middleware.Tenancy, middleware.PermissionCheck}
+var tenancyWithListMiddleware = append([]echo.MiddlewareFunc{middleware.Tenancy, middleware.PermissionCheck}, listMiddleware...)
 var permissionMiddleware = []echo.MiddlewareFunc{middleware.Tenancy, middleware.PermissionCheck, middleware.RaiseEvent}
 var permissionWithListMiddleware = append(listMiddleware, middleware.PermissionCheck)
 
@@ -20,39 +21,48 @@ func setupRoutes(e *echo.Echo) {
 		return c.String(http.StatusOK, "OK")
 	})
 
-	v3 := e.Group("/api/sources/v3.1", middleware.Timing, middleware.HandleErrors, middleware.ParseHeaders)
+	middleware.RoutePermissions = routePermissions
+
+	v3 := e.Group(v3Prefix, middleware.Timing, middleware.Audit, middleware.HandleErrors, middleware.ParseHeaders, middleware.RateLimiter(middleware.RateLimitsFor("v3")))
 
 	//openapi
 	v3.GET("/openapi.json", PublicOpenApiv31)
 
+	// Event schemas
+	v3.GET("/event_schemas", EventSchemaList)
+	v3.GET("/event_schemas/:version/:name", EventSchemaGet)
+
 	// Sources
 	v3.GET("/sources", SourceList, tenancyWithListMiddleware...)
-	v3.GET("/sources/:id", SourceGet, middleware.Tenancy)
+	v3.GET("/sources/:id", SourceGet, tenancyMiddleware...)
 	v3.POST("/sources", SourceCreate, permissionMiddleware...)
 	v3.PATCH("/sources/:id", SourceEdit, permissionMiddleware...)
 	v3.DELETE("/sources/:id", SourceDelete, permissionMiddleware...)
-	v3.POST("/sources/:source_id/check_availability", SourceCheckAvailability, middleware.Tenancy)
+	v3.POST("/sources/:source_id/check_availability", SourceCheckAvailability, tenancyMiddleware...)
+	v3.GET("/sources/:source_id/availability_checks/:check_id", SourceAvailabilityCheckGet, tenancyMiddleware...)
+	v3.GET("/sources/:source_id/availability_history", SourceAvailabilityHistory, tenancyWithListMiddleware...)
 	v3.GET("/sources/:source_id/application_types", SourceListApplicationTypes, tenancyWithListMiddleware...)
 	v3.GET("/sources/:source_id/applications", SourceListApplications, tenancyWithListMiddleware...)
 	v3.GET("/sources/:source_id/endpoints", SourceListEndpoint, tenancyWithListMiddleware...)
 	v3.GET("/sources/:source_id/authentications", SourceListAuthentications, tenancyWithListMiddleware...)
 	v3.GET("/sources/:source_id/rhc_connections", SourcesRhcConnectionList, tenancyWithListMiddleware...)
-	v3.POST("/sources/:source_id/pause", SourcePause, middleware.Tenancy)
-	v3.POST("/sources/:source_id/unpause", SourceResume, middleware.Tenancy)
+	v3.POST("/sources/:source_id/pause", SourcePause, tenancyMiddleware...)
+	v3.POST("/sources/:source_id/unpause", SourceResume, tenancyMiddleware...)
 
 	// Applications
 	v3.GET("/applications", ApplicationList, tenancyWithListMiddleware...)
-	v3.GET("/applications/:id", ApplicationGet, middleware.Tenancy)
+	v3.GET("/applications/:id", ApplicationGet, tenancyMiddleware...)
 	v3.POST("/applications", ApplicationCreate, permissionMiddleware...)
 	v3.PATCH("/applications/:id", ApplicationEdit, permissionMiddleware...)
 	v3.DELETE("/applications/:id", ApplicationDelete, permissionMiddleware...)
 	v3.GET("/applications/:application_id/authentications", ApplicationListAuthentications, tenancyWithListMiddleware...)
-	v3.POST("/applications/:id/pause", ApplicationPause, middleware.Tenancy)
-	v3.POST("/applications/:id/unpause", ApplicationResume, middleware.Tenancy)
+	v3.GET("/applications/:application_id/availability_history", ApplicationAvailabilityHistory, tenancyWithListMiddleware...)
+	v3.POST("/applications/:id/pause", ApplicationPause, tenancyMiddleware...)
+	v3.POST("/applications/:id/unpause", ApplicationResume, tenancyMiddleware...)
 
 	// Authentications
 	v3.GET("/authentications", AuthenticationList, tenancyWithListMiddleware...)
-	v3.GET("/authentications/:uid", AuthenticationGet, middleware.Tenancy)
+	v3.GET("/authentications/:uid", AuthenticationGet, tenancyMiddleware...)
 	v3.POST("/authentications", AuthenticationCreate, permissionMiddleware...)
 	v3.PATCH("/authentications/:uid", AuthenticationUpdate, permissionMiddleware...)
 	v3.DELETE("/authentications/:uid", AuthenticationDelete, permissionMiddleware...)
@@ -64,14 +74,21 @@ func setupRoutes(e *echo.Echo) {
 
 	// Endpoints
 	v3.GET("/endpoints", EndpointList, tenancyWithListMiddleware...)
-	v3.GET("/endpoints/:id", EndpointGet, middleware.Tenancy)
+	v3.GET("/endpoints/:id", EndpointGet, tenancyMiddleware...)
 	v3.POST("/endpoints", EndpointCreate, permissionMiddleware...)
+	v3.PATCH("/endpoints/:id", EndpointEdit, permissionMiddleware...)
 	v3.DELETE("/endpoints/:id", EndpointDelete, permissionMiddleware...)
+	v3.POST("/endpoints/:id/probe", EndpointProbe, tenancyMiddleware...)
+	v3.POST("/endpoints/:id/pause", EndpointPause, tenancyMiddleware...)
+	v3.POST("/endpoints/:id/unpause", EndpointResume, tenancyMiddleware...)
 	v3.GET("/endpoints/:endpoint_id/authentications", EndpointListAuthentications, tenancyWithListMiddleware...)
+	v3.GET("/endpoints/:endpoint_id/availability_history", EndpointAvailabilityHistory, tenancyWithListMiddleware...)
 
 	// ApplicationAuthentications
 	v3.GET("/application_authentications", ApplicationAuthenticationList, tenancyWithListMiddleware...)
-	v3.GET("/application_authentications/:id", ApplicationAuthenticationGet, middleware.Tenancy)
+	v3.GET("/application_authentications/:id", ApplicationAuthenticationGet, tenancyMiddleware...)
+	v3.POST("/application_authentications/:id/pause", ApplicationAuthenticationPause, tenancyMiddleware...)
+	v3.POST("/application_authentications/:id/unpause", ApplicationAuthenticationResume, tenancyMiddleware...)
 	v3.GET("/application_authentications/:application_authentication_id/authentications", ApplicationAuthenticationListAuthentications, tenancyWithListMiddleware...)
 
 	// AppMetaData
@@ -95,11 +112,17 @@ func setupRoutes(e *echo.Echo) {
 	/**            **\
 	 * Internal API *
 	\**            **/
-	internal := e.Group("/internal/v2.0", middleware.HandleErrors, middleware.ParseHeaders)
+	internal := e.Group(internalPrefix, middleware.Audit, middleware.HandleErrors, middleware.ParseHeaders, middleware.AllowRbacCacheBypass, middleware.RateLimiter(middleware.RateLimitsFor("internal")))
 
 	// Authentications
 	internal.GET("/authentications/:uuid", InternalAuthenticationGet, permissionMiddleware...)
 
 	// Sources
 	internal.GET("/sources", InternalSourceList, permissionWithListMiddleware...)
+
+	// Event replay
+	internal.POST("/replay", InternalTenantReplay, tenancyMiddleware...)
+
+	// Audit log
+	internal.GET("/audit_logs", InternalAuditLogList, permissionWithListMiddleware...)
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/labstack/echo/v4"
+)
+
+// publicRoutes are the routes which don't require any permission, since they don't expose any tenant's data.
+var publicRoutes = map[string]bool{
+	"GET /health":                                                               true,
+	"GET " + v3Prefix + "/openapi.json":                                         true,
+	"GET " + v3Prefix + "/event_schemas":                                        true,
+	"GET " + v3Prefix + "/event_schemas/:version/:name":                         true,
+	"GET " + v3Prefix + "/application_types":                                    true,
+	"GET " + v3Prefix + "/application_types/:id":                                true,
+	"GET " + v3Prefix + "/app_meta_data":                                        true,
+	"GET " + v3Prefix + "/app_meta_data/:id":                                    true,
+	"GET " + v3Prefix + "/application_types/:application_type_id/app_meta_data": true,
+	"GET " + v3Prefix + "/source_types":                                         true,
+	"GET " + v3Prefix + "/source_types/:id":                                     true,
+}
+
+// pathParam matches the parameters of the routes' paths.
+var pathParam = regexp.MustCompile(`:\w+`)
+
+// TestRoutePermissionsCoverRoutes tests that every route is either public or mapped to a permission, so that the new
+// routes don't end up unprotected by mistake, and that the permissions don't refer to routes which don't exist.
+func TestRoutePermissionsCoverRoutes(t *testing.T) {
+	e := echo.New()
+	setupRoutes(e)
+
+	routes := make(map[string]bool)
+	for _, route := range e.Routes() {
+		// skip the "not found" routes that echo registers for the groups.
+		if !strings.HasPrefix(route.Name, "github.com/RedHatInsights/sources-api-go.") {
+			continue
+		}
+
+		key := route.Method + " " + route.Path
+		routes[key] = true
+
+		_, protected := routePermissions[key]
+		if !protected && !publicRoutes[key] {
+			t.Errorf(`the route "%s" is neither public nor mapped to a permission`, key)
+		}
+
+		if protected && publicRoutes[key] {
+			t.Errorf(`the route "%s" is both public and mapped to a permission`, key)
+		}
+	}
+
+	for key := range routePermissions {
+		if !routes[key] {
+			t.Errorf(`the permission of the route "%s" refers to a route which doesn't exist`, key)
+		}
+	}
+
+	for key := range publicRoutes {
+		if !routes[key] {
+			t.Errorf(`the public route "%s" doesn't exist`, key)
+		}
+	}
+}
+
+// TestRoutesCheckPermissions tests that the permission check actually runs on every route which isn't public, by
+// sending each of them a request with an unknown PSK, which only the permission check rejects as such.
+func TestRoutesCheckPermissions(t *testing.T) {
+	originalTenantDao := dao.GetTenantDao
+	originalAuditLogDao := dao.GetAuditLogDao
+	dao.GetTenantDao = func() dao.TenantDao { return &dao.MockTenantDao{} }
+	dao.GetAuditLogDao = func() dao.AuditLogDao { return &dao.MockAuditLogDao{} }
+	t.Cleanup(func() {
+		dao.GetTenantDao = originalTenantDao
+		dao.GetAuditLogDao = originalAuditLogDao
+	})
+
+	e := echo.New()
+	setupRoutes(e)
+
+	for _, route := range e.Routes() {
+		key := route.Method + " " + route.Path
+		if !strings.HasPrefix(route.Name, "github.com/RedHatInsights/sources-api-go.") || publicRoutes[key] {
+			continue
+		}
+
+		req := httptest.NewRequest(route.Method, pathParam.ReplaceAllString(route.Path, "1"), strings.NewReader("{}"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("x-rh-sources-psk", "unknown")
+		req.Header.Set("x-rh-sources-org-id", "routes")
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "Incorrect PSK") {
+			t.Errorf(`want the route "%s" rejected by the permission check, got status "%d" and body "%s"`, key, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRoutePermissions(t *testing.T) {
+	tests := []struct {
+		method     string
+		path       string
+		permission string
+		restricted bool
+	}{
+		{http.MethodGet, v3Prefix + "/sources", "sources:source:read", false},
+		{http.MethodGet, v3Prefix + "/sources/:id", "sources:source:read", true},
+		{http.MethodPost, v3Prefix + "/sources", "sources:source:write", false},
+		{http.MethodDelete, v3Prefix + "/sources/:id", "sources:source:write", true},
+		{http.MethodPost, v3Prefix + "/sources/:source_id/check_availability", "sources:source:write", true},
+		{http.MethodPost, v3Prefix + "/sources/:source_id/pause", "sources:source:write", true},
+		{http.MethodGet, v3Prefix + "/sources/:source_id/authentications", "sources:authentication:read", true},
+		{http.MethodPost, v3Prefix + "/applications", "sources:application:write", true},
+		{http.MethodPatch, v3Prefix + "/applications/:id", "sources:application:write", true},
+		{http.MethodGet, v3Prefix + "/authentications/:uid", "sources:authentication:read", true},
+		{http.MethodPost, v3Prefix + "/authentications", "sources:authentication:write", true},
+		{http.MethodDelete, v3Prefix + "/endpoints/:id", "sources:endpoint:write", true},
+		{http.MethodPost, v3Prefix + "/endpoints/:id/probe", "sources:endpoint:write", true},
+		{http.MethodPost, v3Prefix + "/application_authentications/:id/unpause", "sources:application_authentication:write", true},
+		{http.MethodGet, v3Prefix + "/source_types/:source_type_id/sources", "sources:source:read", false},
+		{http.MethodPatch, v3Prefix + "/rhc_connections/:id", "sources:rhc_connection:write", false},
+		{http.MethodGet, internalPrefix + "/authentications/:uuid", "sources:authentication:read", false},
+		{http.MethodPost, internalPrefix + "/replay", "sources:replay:write", false},
+	}
+
+	for _, test := range tests {
+		permission, ok := routePermissions[test.method+" "+test.path]
+		if !ok {
+			t.Errorf(`the route "%s %s" has no permission`, test.method, test.path)
+			continue
+		}
+
+		if permission.String() != test.permission {
+			t.Errorf(`want "%s" for the route "%s %s", got "%s"`, test.permission, test.method, test.path, permission)
+		}
+
+		if (permission.Source != nil) != test.restricted {
+			t.Errorf(`want the resource definitions to apply to the route "%s %s": %t`, test.method, test.path, test.restricted)
+		}
+	}
+}
+
+// TestListRoutePermissions tests which list routes the RBAC resource definitions restrict to the sources they grant,
+// and that the rest of the routes which don't target a single source stay denied to them.
+func TestListRoutePermissions(t *testing.T) {
+	tests := map[string]string{
+		"GET " + v3Prefix + "/sources":                                        "id",
+		"GET " + v3Prefix + "/applications":                                   "source_id",
+		"GET " + v3Prefix + "/endpoints":                                      "source_id",
+		"GET " + v3Prefix + "/application_types/:application_type_id/sources": "id",
+		"GET " + v3Prefix + "/source_types/:source_type_id/sources":           "id",
+		"POST " + v3Prefix + "/sources":                                       "",
+		"GET " + v3Prefix + "/authentications":                                "",
+		"GET " + v3Prefix + "/application_authentications":                    "",
+		"GET " + v3Prefix + "/rhc_connections":                                "",
+		"GET " + internalPrefix + "/sources":                                  "",
+	}
+
+	for route, column := range tests {
+		if permission := routePermissions[route]; permission.ScopeColumn != column {
+			t.Errorf(`want the scope column "%s" for the route "%s", got "%s"`, column, route, permission.ScopeColumn)
+		}
+	}
+}
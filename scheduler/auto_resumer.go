@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+const (
+	// autoResumerLeaseKey is the Redis key of the lease that a replica must hold to resume the paused resources.
+	autoResumerLeaseKey = "sources-api-go:scheduled-resumes"
+	// autoResumerInterval is how often the due resources get resumed.
+	autoResumerInterval = time.Minute
+	// autoResumerBatchSize is how many resources get resumed at most per interval.
+	autoResumerBatchSize = 1000
+)
+
+// AutoResumer resumes the paused resources once the "resume_at" they were paused with is reached. The replicas
+// coordinate through a lease so that every resource only gets resumed once.
+type AutoResumer struct {
+	// Lease coordinates the replicas.
+	Lease Lease
+	// ScheduledResumeDao lists the resources which are due.
+	ScheduledResumeDao dao.ScheduledResumeDao
+	// Resume resumes a resource and raises its events.
+	Resume func(entry *m.ScheduledResume) error
+}
+
+// Run resumes the due resources until the given context is cancelled.
+func (ar *AutoResumer) Run(ctx context.Context) {
+	ticker := time.NewTicker(autoResumerInterval)
+	defer ticker.Stop()
+
+	for {
+		ar.resume(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// resume resumes the due resources, provided that no other replica is doing it already. The lease is shorter than
+// the interval so that the next attempt always finds it expired.
+func (ar *AutoResumer) resume(ctx context.Context) {
+	acquired, err := ar.Lease.Acquire(autoResumerLeaseKey, autoResumerInterval/2)
+	if err != nil {
+		l.Log.Errorf("Unable to acquire the scheduled resumes lease: %s", err)
+		return
+	}
+
+	if !acquired {
+		return
+	}
+
+	due, err := ar.ScheduledResumeDao.ListDue(time.Now(), autoResumerBatchSize)
+	if err != nil {
+		l.Log.Errorf("Unable to list the scheduled resumes: %s", err)
+		return
+	}
+
+	for i := range due {
+		// stop in between resources so that the shutdown doesn't have to wait for the whole batch.
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := ar.Resume(&due[i])
+		if err != nil {
+			l.Log.Errorf("Unable to resume %s %d: %s", due[i].ResourceType, due[i].ResourceID, err)
+			continue
+		}
+
+		l.Log.Infof("Resumed %s %d as scheduled", due[i].ResourceType, due[i].ResourceID)
+	}
+}
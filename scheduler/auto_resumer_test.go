@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/go-redis/redis"
+)
+
+// TestAutoResumeDueResources tests that only the due resources get resumed, and that only one replica resumes them.
+func TestAutoResumeDueResources(t *testing.T) {
+	miniredis.FlushAll()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	resumeDao := &dao.MockScheduledResumeDao{}
+	_ = resumeDao.Schedule("Endpoint", 1, time.Now().Add(-time.Minute))
+	_ = resumeDao.Schedule("ApplicationAuthentication", 2, time.Now().Add(time.Hour))
+
+	var resumed []string
+	resumer := &AutoResumer{
+		Lease:              &RedisLease{Client: client, Owner: "test"},
+		ScheduledResumeDao: resumeDao,
+		Resume: func(entry *m.ScheduledResume) error {
+			resumed = append(resumed, entry.ResourceType)
+			return resumeDao.Done(entry)
+		},
+	}
+
+	resumer.resume(context.Background())
+
+	if len(resumed) != 1 || resumed[0] != "Endpoint" {
+		t.Errorf("want only the endpoint resumed, got %v", resumed)
+	}
+
+	if len(resumeDao.Scheduled) != 1 || resumeDao.Scheduled[0].ResourceType != "ApplicationAuthentication" {
+		t.Errorf("want only the application authentication's schedule left, got %+v", resumeDao.Scheduled)
+	}
+
+	// another replica doesn't resume anything while the lease is held.
+	_ = resumeDao.Schedule("Source", 3, time.Now().Add(-time.Minute))
+	other := *resumer
+	other.Lease = &RedisLease{Client: client, Owner: "other"}
+	other.resume(context.Background())
+
+	if len(resumed) != 1 {
+		t.Errorf("want no resumption while the lease is held, got %v", resumed)
+	}
+}
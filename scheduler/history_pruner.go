@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+)
+
+const (
+	// historyPrunerLeaseKey is the Redis key of the lease that a replica must hold to prune the availability history.
+	historyPrunerLeaseKey = "sources-api-go:availability-history:prune"
+	// historyPrunerInterval is how often the availability history gets pruned.
+	historyPrunerInterval = 24 * time.Hour
+	// historyPrunerAttemptInterval is how often the replicas try to acquire the lease, so that the pruning doesn't
+	// get delayed for a whole interval when the replica that held the lease goes away.
+	historyPrunerAttemptInterval = time.Hour
+)
+
+// AvailabilityHistoryPruner deletes the availability status transitions which are older than the retention period.
+// The replicas coordinate through a lease so that the pruning only runs once per interval.
+type AvailabilityHistoryPruner struct {
+	// Retention is for how long the transitions are kept.
+	Retention time.Duration
+	// Lease coordinates the replicas.
+	Lease Lease
+	// HistoryDao prunes the transitions.
+	HistoryDao dao.AvailabilityStatusHistoryDao
+}
+
+// Run prunes the availability history until the given context is cancelled.
+func (ahp *AvailabilityHistoryPruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(historyPrunerAttemptInterval)
+	defer ticker.Stop()
+
+	for {
+		ahp.prune()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prune deletes the expired transitions, provided that no other replica has done it already in the current interval.
+func (ahp *AvailabilityHistoryPruner) prune() {
+	acquired, err := ahp.Lease.Acquire(historyPrunerLeaseKey, historyPrunerInterval)
+	if err != nil {
+		l.Log.Errorf("Unable to acquire the availability history pruning lease: %s", err)
+		return
+	}
+
+	if !acquired {
+		return
+	}
+
+	l.Log.Info("Pruning the availability history...")
+
+	pruned, err := ahp.HistoryDao.Prune(time.Now().Add(-ahp.Retention))
+	if err != nil {
+		l.Log.Errorf("Unable to prune the availability history: %s", err)
+		return
+	}
+
+	l.Log.Infof("Pruning the availability history...Complete: %d transitions deleted", pruned)
+}
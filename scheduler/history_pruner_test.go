@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/go-redis/redis"
+)
+
+// TestPruneAvailabilityHistory tests that only the expired transitions get deleted, and that the pruning only runs
+// once per interval.
+func TestPruneAvailabilityHistory(t *testing.T) {
+	miniredis.FlushAll()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	historyDao := &dao.MockAvailabilityStatusHistoryDao{History: []m.AvailabilityStatusHistory{
+		{ID: 1, CreatedAt: time.Now().Add(-48 * time.Hour)},
+		{ID: 2, CreatedAt: time.Now()},
+	}}
+
+	pruner := &AvailabilityHistoryPruner{
+		Retention:  24 * time.Hour,
+		Lease:      &RedisLease{Client: client, Owner: "test"},
+		HistoryDao: historyDao,
+	}
+
+	pruner.prune()
+
+	if len(historyDao.History) != 1 || historyDao.History[0].ID != 2 {
+		t.Errorf("want only the transition 2 kept, got %v", historyDao.History)
+	}
+
+	historyDao.History = append(historyDao.History, m.AvailabilityStatusHistory{ID: 3, CreatedAt: time.Now().Add(-48 * time.Hour)})
+	pruner.prune()
+
+	if len(historyDao.History) != 2 {
+		t.Errorf("want no pruning while the lease is held, got %d transitions left", len(historyDao.History))
+	}
+}
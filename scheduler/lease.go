@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Lease is an exclusive, expiring claim over a key, shared between the replicas.
+type Lease interface {
+	// Acquire tries to take the lease for the given duration. It returns false if another replica holds it.
+	Acquire(key string, ttl time.Duration) (bool, error)
+}
+
+// RedisLease implements the lease with Redis keys that expire on their own, so that a replica that dies doesn't hold
+// the lease forever.
+type RedisLease struct {
+	Client *redis.Client
+	// Owner is stored as the key's value to be able to tell which replica holds the lease.
+	Owner string
+}
+
+func (rl *RedisLease) Acquire(key string, ttl time.Duration) (bool, error) {
+	return rl.Client.SetNX(key, rl.Owner, ttl).Result()
+}
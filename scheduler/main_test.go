@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/parser"
+	"github.com/RedHatInsights/sources-api-go/logger"
+	miniredisV2 "github.com/alicebob/miniredis/v2"
+)
+
+var miniredis *miniredisV2.Miniredis
+
+func TestMain(t *testing.M) {
+	_ = parser.ParseFlags()
+	logger.InitLogger(config)
+
+	miniredis = miniredisV2.NewMiniRedis()
+	err := miniredis.Start()
+	if err != nil {
+		log.Fatalf("Could not initialize Miniredis: %s", err)
+	}
+
+	result := t.Run()
+
+	miniredis.Close()
+
+	os.Exit(result)
+}
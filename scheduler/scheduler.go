@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	c "github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/redis"
+	"github.com/RedHatInsights/sources-api-go/service"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+const (
+	// leaseKeyFormat is the Redis key of the lease that a replica must hold to check the sources of a source type.
+	leaseKeyFormat = "sources-api-go:availability-checks:source-type:%d"
+	// sourceTypesPageSize is the number of source types listed at once when looking for the source types to schedule.
+	sourceTypesPageSize = 100
+)
+
+var config = c.Get()
+
+// AvailabilityCheckScheduler periodically requests the availability checks of every unpaused source, grouped by
+// source type. Every source type gets its own jittered timer, and the replicas coordinate through a lease so that
+// each source type is only checked by one of them per interval.
+type AvailabilityCheckScheduler struct {
+	// Interval is the time between two checks of the same source type.
+	Interval time.Duration
+	// Jitter is the fraction of the interval used to randomly spread the checks.
+	Jitter float64
+	// Lease coordinates the replicas.
+	Lease Lease
+	// SourceTypeDao lists the source types to schedule.
+	SourceTypeDao dao.SourceTypeDao
+	// SourceDao lists the sources to check.
+	SourceDao dao.SourceDao
+	// Check requests the availability check of a source.
+	Check func(source *m.Source)
+}
+
+// Run schedules the availability checks until the given context is cancelled. Once that happens, it waits for the
+// in-flight checks to finish before returning.
+func Run(ctx context.Context) {
+	// the replicas must not share the same random delays, otherwise the jitter would be useless.
+	rand.Seed(time.Now().UnixNano())
+
+	scheduler := &AvailabilityCheckScheduler{
+		Interval:      time.Duration(config.AvailabilityCheckInterval) * time.Second,
+		Jitter:        config.AvailabilityCheckJitter,
+		Lease:         &RedisLease{Client: redis.Client, Owner: config.Hostname},
+		SourceTypeDao: dao.GetSourceTypeDao(),
+		SourceDao:     dao.GetSourceDao(nil),
+		Check:         requestAvailabilityCheck,
+	}
+
+	// a jitter of a whole interval or more would leave the lease without an expiration.
+	if scheduler.Jitter < 0 || scheduler.Jitter >= 1 {
+		l.Log.Warnf("Invalid availability check jitter %v, using 0.1 instead", scheduler.Jitter)
+		scheduler.Jitter = 0.1
+	}
+
+	var wg sync.WaitGroup
+
+	if config.AvailabilityHistoryDays > 0 {
+		pruner := &AvailabilityHistoryPruner{
+			Retention:  time.Duration(config.AvailabilityHistoryDays) * 24 * time.Hour,
+			Lease:      scheduler.Lease,
+			HistoryDao: dao.GetAvailabilityStatusHistoryDao(nil),
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pruner.Run(ctx)
+		}()
+	}
+
+	resumer := &AutoResumer{
+		Lease:              scheduler.Lease,
+		ScheduledResumeDao: dao.GetScheduledResumeDao(nil),
+		Resume:             service.ResumeScheduled,
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resumer.Run(ctx)
+	}()
+
+	// the source types get listed again on every interval, so that the ones which get seeded while the scheduler is
+	// running get scheduled too.
+	scheduled := make(map[int64]bool)
+	for {
+		scheduler.scheduleNewSourceTypes(ctx, &wg, scheduled)
+
+		select {
+		case <-ctx.Done():
+			l.Log.Info("Stopping the availability check scheduler...")
+			wg.Wait()
+			l.Log.Info("Stopping the availability check scheduler...Complete")
+
+			return
+		case <-time.After(scheduler.Interval):
+		}
+	}
+}
+
+// scheduleNewSourceTypes starts scheduling the availability checks of the source types which aren't in the given set
+// yet, and adds them to it.
+func (acs *AvailabilityCheckScheduler) scheduleNewSourceTypes(ctx context.Context, wg *sync.WaitGroup, scheduled map[int64]bool) {
+	sourceTypes, err := acs.listSourceTypes()
+	if err != nil {
+		l.Log.Errorf("Unable to list the source types to schedule the availability checks: %s", err)
+		return
+	}
+
+	for _, sourceType := range sourceTypes {
+		if scheduled[sourceType.Id] {
+			continue
+		}
+		scheduled[sourceType.Id] = true
+
+		l.Log.Infof("Scheduling the availability checks of source type %q every %s", sourceType.Name, acs.Interval)
+
+		wg.Add(1)
+		go func(sourceType m.SourceType) {
+			defer wg.Done()
+			acs.scheduleSourceType(ctx, sourceType)
+		}(sourceType)
+	}
+}
+
+// listSourceTypes pages through every source type, ordered by id so that none of them gets skipped between pages.
+func (acs *AvailabilityCheckScheduler) listSourceTypes() ([]m.SourceType, error) {
+	byId := []util.Filter{{Operation: "sort_by", Value: []string{"id"}}}
+
+	sourceTypes := make([]m.SourceType, 0)
+	for offset := 0; ; offset += sourceTypesPageSize {
+		page, _, err := acs.SourceTypeDao.List(sourceTypesPageSize, offset, byId)
+		if err != nil {
+			return nil, err
+		}
+
+		sourceTypes = append(sourceTypes, page...)
+
+		if len(page) < sourceTypesPageSize {
+			return sourceTypes, nil
+		}
+	}
+}
+
+// scheduleSourceType checks the sources of the given source type every jittered interval, as long as the lease can be
+// acquired. The first check is randomly delayed within the first interval, so that the source types and the replicas
+// don't all start at once.
+func (acs *AvailabilityCheckScheduler) scheduleSourceType(ctx context.Context, sourceType m.SourceType) {
+	delay := time.Duration(rand.Int63n(int64(acs.Interval) + 1))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		acs.checkSourceType(ctx, sourceType)
+		delay = acs.nextDelay()
+	}
+}
+
+// nextDelay returns the interval, randomly shifted by up to the jitter fraction in either direction.
+func (acs *AvailabilityCheckScheduler) nextDelay() time.Duration {
+	spread := int64(float64(acs.Interval) * acs.Jitter)
+	if spread <= 0 {
+		return acs.Interval
+	}
+
+	return acs.Interval + time.Duration(rand.Int63n(2*spread+1)-spread)
+}
+
+// leaseDuration returns for how long a replica keeps the lease of a source type. It is the shortest possible delay
+// between two checks, which makes sure the lease has expired by the time any replica tries to check the source type
+// again.
+func (acs *AvailabilityCheckScheduler) leaseDuration() time.Duration {
+	return acs.Interval - time.Duration(float64(acs.Interval)*acs.Jitter)
+}
+
+// checkSourceType requests the availability checks for every unpaused source of the given source type, provided that
+// no other replica has done it already in the current interval.
+func (acs *AvailabilityCheckScheduler) checkSourceType(ctx context.Context, sourceType m.SourceType) {
+	acquired, err := acs.Lease.Acquire(fmt.Sprintf(leaseKeyFormat, sourceType.Id), acs.leaseDuration())
+	if err != nil {
+		l.Log.Errorf("Unable to acquire the availability checks lease for source type %q: %s", sourceType.Name, err)
+		return
+	}
+
+	if !acquired {
+		l.Log.Debugf("Skipping the availability checks for source type %q, another replica is running them", sourceType.Name)
+		return
+	}
+
+	l.Log.Infof("Requesting the availability checks for source type %q...", sourceType.Name)
+
+	checked := 0
+	for offset := 0; ; offset += dao.DEFAULT_LIMIT {
+		sources, err := acs.SourceDao.ListForAvailabilityChecks(sourceType.Id, dao.DEFAULT_LIMIT, offset)
+		if err != nil {
+			l.Log.Errorf("Unable to list the sources of source type %q: %s", sourceType.Name, err)
+			return
+		}
+
+		for i := range sources {
+			// stop in between sources so that the shutdown doesn't have to wait for the whole source type.
+			if ctx.Err() != nil {
+				return
+			}
+
+			acs.Check(&sources[i])
+			checked++
+		}
+
+		if len(sources) < dao.DEFAULT_LIMIT {
+			break
+		}
+	}
+
+	l.Log.Infof("Requesting the availability checks for source type %q...Complete: %d sources checked", sourceType.Name, checked)
+}
+
+// requestAvailabilityCheck records the availability check and requests it, under a request id of its own.
+func requestAvailabilityCheck(source *m.Source) {
+	check, err := service.NewAvailabilityCheck(source, util.NewRequestId())
+	if err != nil {
+		l.Log.Errorf("Unable to create the availability check for source %d: %s", source.ID, err)
+		return
+	}
+
+	service.RequestAvailabilityCheck(source, check)
+}
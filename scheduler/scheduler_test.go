@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/go-redis/redis"
+)
+
+// setUpScheduler returns a scheduler which records the checked sources, and uses a fresh Redis lease.
+func setUpScheduler(t *testing.T, sources []m.Source) (*AvailabilityCheckScheduler, *[]int64) {
+	miniredis.FlushAll()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	checked := make([]int64, 0)
+	scheduler := &AvailabilityCheckScheduler{
+		Interval:  time.Hour,
+		Jitter:    0.1,
+		Lease:     &RedisLease{Client: client, Owner: "test"},
+		SourceDao: &dao.MockSourceDao{Sources: sources},
+		Check:     func(source *m.Source) { checked = append(checked, source.ID) },
+	}
+
+	return scheduler, &checked
+}
+
+// TestCheckSourceType tests that only the unpaused sources of the given source type get checked.
+func TestCheckSourceType(t *testing.T) {
+	scheduler, checked := setUpScheduler(t, []m.Source{
+		{ID: 1, SourceTypeID: 1},
+		{ID: 2, SourceTypeID: 2},
+		{ID: 3, SourceTypeID: 1, Pause: m.Pause{PausedAt: time.Now()}},
+		{ID: 4, SourceTypeID: 1},
+	})
+
+	scheduler.checkSourceType(context.Background(), m.SourceType{Id: 1, Name: "amazon"})
+
+	if len(*checked) != 2 || (*checked)[0] != 1 || (*checked)[1] != 4 {
+		t.Errorf("want sources [1 4] checked, got %v", *checked)
+	}
+}
+
+// TestCheckSourceTypeOncePerInterval tests that a source type doesn't get checked again while the lease is held,
+// which is what happens when another replica has already checked it in the current interval.
+func TestCheckSourceTypeOncePerInterval(t *testing.T) {
+	scheduler, checked := setUpScheduler(t, []m.Source{{ID: 1, SourceTypeID: 1}})
+	sourceType := m.SourceType{Id: 1, Name: "amazon"}
+
+	scheduler.checkSourceType(context.Background(), sourceType)
+	scheduler.checkSourceType(context.Background(), sourceType)
+
+	if len(*checked) != 1 {
+		t.Errorf("want the source checked once, got %d checks", len(*checked))
+	}
+
+	// once the lease expires the source type can be checked again.
+	miniredis.FastForward(scheduler.leaseDuration())
+	scheduler.checkSourceType(context.Background(), sourceType)
+
+	if len(*checked) != 2 {
+		t.Errorf("want the source checked again after the lease expired, got %d checks", len(*checked))
+	}
+}
+
+// TestCheckSourceTypeStopsWhenContextIsCancelled tests that no more checks are requested once the scheduler is
+// stopped.
+func TestCheckSourceTypeStopsWhenContextIsCancelled(t *testing.T) {
+	scheduler, checked := setUpScheduler(t, []m.Source{{ID: 1, SourceTypeID: 1}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scheduler.checkSourceType(ctx, m.SourceType{Id: 1, Name: "amazon"})
+
+	if len(*checked) != 0 {
+		t.Errorf("want no checks after cancelling the scheduler, got %v", *checked)
+	}
+}
+
+// TestNextDelay tests that the delays stay within the jitter bounds, and that the lease always expires before the
+// next check.
+func TestNextDelay(t *testing.T) {
+	scheduler, _ := setUpScheduler(t, nil)
+
+	min := scheduler.Interval - scheduler.Interval/10
+	max := scheduler.Interval + scheduler.Interval/10
+
+	for i := 0; i < 1000; i++ {
+		delay := scheduler.nextDelay()
+		if delay < min || delay > max {
+			t.Fatalf("want a delay between %s and %s, got %s", min, max, delay)
+		}
+
+		if scheduler.leaseDuration() > delay {
+			t.Fatalf("want the lease to expire before the next check, lease %s, delay %s", scheduler.leaseDuration(), delay)
+		}
+	}
+
+	scheduler.Jitter = 0
+	if scheduler.nextDelay() != scheduler.Interval {
+		t.Errorf("want no jitter, got %s", scheduler.nextDelay())
+	}
+}
+
+// TestScheduleNewSourceTypes tests that the source types which get seeded after the scheduler started get scheduled
+// too, and that the ones already scheduled don't get scheduled twice.
+func TestScheduleNewSourceTypes(t *testing.T) {
+	scheduler, _ := setUpScheduler(t, nil)
+	sourceTypeDao := &dao.MockSourceTypeDao{SourceTypes: []m.SourceType{{Id: 1, Name: "amazon"}}}
+	scheduler.SourceTypeDao = sourceTypeDao
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	scheduled := make(map[int64]bool)
+
+	scheduler.scheduleNewSourceTypes(ctx, &wg, scheduled)
+	if len(scheduled) != 1 || !scheduled[1] {
+		t.Errorf("want source type 1 scheduled, got %v", scheduled)
+	}
+
+	sourceTypeDao.SourceTypes = append(sourceTypeDao.SourceTypes, m.SourceType{Id: 2, Name: "azure"})
+	scheduler.scheduleNewSourceTypes(ctx, &wg, scheduled)
+	if len(scheduled) != 2 || !scheduled[2] {
+		t.Errorf("want source type 2 scheduled, got %v", scheduled)
+	}
+
+	cancel()
+	wg.Wait()
+}
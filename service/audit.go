@@ -0,0 +1,123 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// auditQueueSize is how many audit log entries may wait to be mirrored to the audit topic. The entries that don't fit
+// are dropped, so that a slow broker doesn't pile up the entries in memory.
+const auditQueueSize = 1000
+
+var (
+	// auditMirror mirrors the audit log to the audit topic. It is created on first use, and only when the topic is
+	// configured.
+	auditMirror       *auditLogMirror
+	auditMirrorClosed bool
+	auditMirrorMutex  sync.Mutex
+)
+
+// auditLogMirror produces the queued audit log entries to the audit topic, one after the other, from a single worker.
+type auditLogMirror struct {
+	producer *kafka.Manager
+	queue    chan *m.AuditLog
+	done     chan struct{}
+}
+
+// newAuditLogMirror starts the worker which produces the queued entries with the given producer.
+func newAuditLogMirror(producer *kafka.Manager, size int) *auditLogMirror {
+	mirror := &auditLogMirror{
+		producer: producer,
+		queue:    make(chan *m.AuditLog, size),
+		done:     make(chan struct{}),
+	}
+
+	go mirror.run()
+
+	return mirror
+}
+
+// run produces the queued entries until the queue gets closed and drained.
+func (a *auditLogMirror) run() {
+	defer close(a.done)
+
+	for entry := range a.queue {
+		msg := &kafka.Message{}
+		err := msg.AddValueAsJSON(entry.ToResponse())
+		if err != nil {
+			l.WithRequestId(entry.RequestID).Errorf("Unable to marshal the audit log entry %d: %s", entry.ID, err)
+			continue
+		}
+
+		err = a.producer.Produce(msg)
+		if err != nil {
+			l.WithRequestId(entry.RequestID).Errorf("Unable to mirror the audit log entry %d to the audit topic: %s", entry.ID, err)
+		}
+	}
+}
+
+// close stops accepting entries, waits for the queued ones to be produced and closes the producer.
+func (a *auditLogMirror) close() error {
+	close(a.queue)
+	<-a.done
+
+	return a.producer.Close()
+}
+
+// RecordAudit stores the entry in the audit log and, when the audit topic is configured, queues it to be mirrored to
+// the topic in the background so that the request doesn't wait for the brokers. The failures are logged, since the
+// request they audit has already been answered.
+func RecordAudit(entry *m.AuditLog) {
+	err := dao.GetAuditLogDao().Record(entry)
+	if err != nil {
+		l.WithRequestId(entry.RequestID).Errorf("Unable to record the audit log entry of %s %s: %s", entry.Method, entry.Route, err)
+		return
+	}
+
+	topic := config.Get().AuditLogTopic
+	if topic == "" {
+		return
+	}
+
+	// the entries are queued while holding the lock, so that the queue can't get closed in the meantime.
+	auditMirrorMutex.Lock()
+	defer auditMirrorMutex.Unlock()
+
+	if auditMirrorClosed {
+		return
+	}
+
+	if auditMirror == nil {
+		auditMirror = newAuditLogMirror(&kafka.Manager{Config: kafka.Config{
+			KafkaBrokers:   config.Get().KafkaBrokers,
+			ProducerConfig: kafka.ProducerConfig{Topic: config.Get().KafkaTopic(topic)},
+		}}, auditQueueSize)
+	}
+
+	select {
+	case auditMirror.queue <- entry:
+	default:
+		l.WithRequestId(entry.RequestID).Errorf("Unable to mirror the audit log entry %d to the audit topic: the queue is full", entry.ID)
+	}
+}
+
+// CloseAuditProducer flushes the queued audit log entries to the audit topic and closes its producer. The entries
+// recorded afterwards are no longer mirrored.
+func CloseAuditProducer() error {
+	auditMirrorMutex.Lock()
+	mirror := auditMirror
+	auditMirror = nil
+	auditMirrorClosed = true
+	auditMirrorMutex.Unlock()
+
+	if mirror == nil {
+		return nil
+	}
+
+	return mirror.close()
+}
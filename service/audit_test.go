@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// TestAuditLogMirrorCloseDrainsQueue tests that closing the mirror waits for the worker to go through every queued
+// entry.
+func TestAuditLogMirrorCloseDrainsQueue(t *testing.T) {
+	// without brokers the entries fail to be produced right away, which still drains them from the queue.
+	mirror := newAuditLogMirror(&kafka.Manager{}, 10)
+	for i := 0; i < cap(mirror.queue); i++ {
+		mirror.queue <- &m.AuditLog{ID: int64(i)}
+	}
+
+	err := mirror.close()
+	if err != nil {
+		t.Fatalf(`want nil error, got "%s"`, err)
+	}
+
+	select {
+	case <-mirror.done:
+	default:
+		t.Errorf(`want the worker to be done`)
+	}
+
+	if len(mirror.queue) != 0 {
+		t.Errorf(`want the queue to be drained, got "%d" entries left`, len(mirror.queue))
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/RedHatInsights/sources-api-go/dao"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// sourceAuthentications returns every authentication of the source, going through all the pages of the listing.
+func sourceAuthentications(tenantId, sourceId int64) ([]m.Authentication, error) {
+	authDao := dao.GetAuthenticationDao(&tenantId)
+
+	var authentications []m.Authentication
+	for {
+		page, count, err := authDao.ListForSource(sourceId, dao.DEFAULT_LIMIT, len(authentications), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		authentications = append(authentications, page...)
+
+		if len(page) == 0 || int64(len(authentications)) >= count {
+			return authentications, nil
+		}
+	}
+}
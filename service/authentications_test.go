@@ -0,0 +1,48 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// pagedAuthenticationDao lists the source's authentications a page at a time.
+type pagedAuthenticationDao struct {
+	dao.AuthenticationDao
+	authentications []m.Authentication
+}
+
+func (p *pagedAuthenticationDao) ListForSource(_ int64, limit, offset int, _ []util.Filter) ([]m.Authentication, int64, error) {
+	end := offset + limit
+	if end > len(p.authentications) {
+		end = len(p.authentications)
+	}
+
+	return p.authentications[offset:end], int64(len(p.authentications)), nil
+}
+
+// TestSourceAuthenticationsPages tests that the authentications of every page get listed.
+func TestSourceAuthenticationsPages(t *testing.T) {
+	authentications := make([]m.Authentication, dao.DEFAULT_LIMIT*2+1)
+	for i := range authentications {
+		authentications[i].ID = strconv.Itoa(i)
+	}
+
+	previous := dao.GetAuthenticationDao
+	dao.GetAuthenticationDao = func(_ *int64) dao.AuthenticationDao {
+		return &pagedAuthenticationDao{authentications: authentications}
+	}
+	defer func() { dao.GetAuthenticationDao = previous }()
+
+	got, err := sourceAuthentications(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(authentications) || got[len(got)-1].ID != strconv.Itoa(len(authentications)-1) {
+		t.Errorf("want the %d authentications listed, got %d", len(authentications), len(got))
+	}
+}
@@ -1,155 +1,202 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"net/http"
-	"net/url"
+	"errors"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/RedHatInsights/sources-api-go/config"
-	"github.com/RedHatInsights/sources-api-go/kafka"
-	l "github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/RedHatInsights/sources-api-go/dao"
 	m "github.com/RedHatInsights/sources-api-go/model"
-	"github.com/RedHatInsights/sources-api-go/util"
 )
 
 type availabilityCheckRequester struct{}
 
 type availabilityChecker interface {
-	ApplicationAvailabilityCheck(source *m.Source)
-	EndpointAvailabilityCheck(source *m.Source)
+	ApplicationAvailabilityCheck(source *m.Source, check *m.AvailabilityCheck)
+	EndpointAvailabilityCheck(source *m.Source, check *m.AvailabilityCheck)
 }
 
-var (
-	// storing the satellite topic here since it doesn't change after initial
-	// startup.
-	satelliteTopic = config.Get().KafkaTopic("platform.topological-inventory.operations-satellite")
-	// default availability checker instance
-	ac availabilityChecker = &availabilityCheckRequester{}
-)
+// default availability checker instance
+var ac availabilityChecker = &availabilityCheckRequester{}
+
+// backgroundChecks tracks the availability checks requested in the background, so that they get dispatched before
+// shutting down.
+var backgroundChecks sync.WaitGroup
+
+// RequestAvailabilityCheckInBackground requests the availability check without blocking the caller. The checks still
+// being dispatched are awaited on shutdown through WaitForBackgroundChecks.
+func RequestAvailabilityCheckInBackground(source *m.Source, check *m.AvailabilityCheck) {
+	backgroundChecks.Add(1)
+	go func() {
+		defer backgroundChecks.Done()
+		RequestAvailabilityCheck(source, check)
+	}()
+}
+
+// WaitForBackgroundChecks waits for the availability checks requested in the background to be dispatched, or for
+// the context to be done, in which case the context's error is returned.
+func WaitForBackgroundChecks(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		backgroundChecks.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-// requests both types of availability checks for a source
-func RequestAvailabilityCheck(source *m.Source) {
-	l.Log.Infof("Requesting Availability Check for Source [%v]", source.ID)
+// NewAvailabilityCheck persists a pending availability check for the source, with one target for each of its
+// unpaused applications and endpoints. The paused ones are removed from the source. The request id is forwarded with
+// the availability check requests.
+func NewAvailabilityCheck(source *m.Source, requestId string) (*m.AvailabilityCheck, error) {
+	dropPausedResources(source)
 
-	if len(source.Applications) != 0 {
-		ac.ApplicationAvailabilityCheck(source)
+	check := &m.AvailabilityCheck{SourceID: source.ID, RequestID: requestId}
+
+	for _, app := range source.Applications {
+		check.Targets = append(check.Targets, m.AvailabilityCheckTarget{
+			ResourceType:   "Application",
+			ResourceID:     strconv.FormatInt(app.ID, 10),
+			DispatchStatus: m.DispatchPending,
+		})
 	}
 
-	if len(source.Endpoints) != 0 {
-		ac.EndpointAvailabilityCheck(source)
+	for _, endpoint := range source.Endpoints {
+		check.Targets = append(check.Targets, m.AvailabilityCheckTarget{
+			ResourceType:   "Endpoint",
+			ResourceID:     strconv.FormatInt(endpoint.ID, 10),
+			DispatchStatus: m.DispatchPending,
+		})
+	}
+
+	err := dao.GetAvailabilityCheckDao(&source.TenantID).Create(check)
+	if err != nil {
+		return nil, err
 	}
 
-	l.Log.Infof("Finished Publishing Availability Messages for Source %v", source.ID)
+	return check, nil
 }
 
-// sends off an availability check http request for each of the source's
-// applications
-func (acr availabilityCheckRequester) ApplicationAvailabilityCheck(source *m.Source) {
-	for _, app := range source.Applications {
-		l.Log.Infof("Requesting Availability Check for Application %v", app.ID)
+// requests both types of availability checks for a source, recording the results of the requests in the given
+// availability check. The paused applications and endpoints aren't checked.
+func RequestAvailabilityCheck(source *m.Source, check *m.AvailabilityCheck) {
+	log := checkLogger(check)
+	log.Infof("Requesting Availability Check for Source [%v]", source.ID)
 
-		uri := app.ApplicationType.AvailabilityCheckURL()
-		if uri == nil {
-			l.Log.Warnf("Failed to fetch availability check url for [%v] - continuing", app.ApplicationType.Name)
-			continue
-		}
+	dropPausedResources(source)
 
-		httpAvailabilityRequest(source, &app, uri)
+	if len(source.Applications) != 0 {
+		ac.ApplicationAvailabilityCheck(source, check)
 	}
-}
 
-func httpAvailabilityRequest(source *m.Source, app *m.Application, uri *url.URL) {
-	body := map[string]string{"source_id": strconv.FormatInt(app.SourceID, 10)}
-	raw, err := json.Marshal(body)
-	if err != nil {
-		l.Log.Warnf("Failed to marshal source body for [%v] - continuing", app.SourceID)
-		return
+	if len(source.Endpoints) != 0 {
+		ac.EndpointAvailabilityCheck(source, check)
 	}
 
-	// spin up a 10 second context to limit the time spent waiting on a response
-	ctx, done := context.WithTimeout(context.Background(), 10*time.Second)
-	defer done()
+	if config.Get().AvailabilityVerifiers {
+		verifySource(getVerifiers(), source, check)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri.String(), bytes.NewBuffer(raw))
-	if err != nil {
-		l.Log.Warnf("Failed to make request for application [%v], uri [%v]", app.ID, uri.String())
-		return
+	// the checks which couldn't be dispatched at all are already completed.
+	if check != nil {
+		err := dao.GetAvailabilityCheckDao(&source.TenantID).WithContext(loggerContext(log)).CompleteIfAnswered(check.ID)
+		if err != nil {
+			log.Warnf("Failed to complete availability check [%v]: %v", check.ID, err)
+		}
 	}
 
-	req.Header.Add("x-rh-sources-account-number", source.Tenant.ExternalTenant)
-	req.Header.Add("x-rh-identity", util.XRhIdentityWithAccountNumber(source.Tenant.ExternalTenant))
-	req.Header.Add("Content-Type", "application/json;charset=utf-8")
+	log.Infof("Finished Publishing Availability Messages for Source %v", source.ID)
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		l.Log.Warnf("Error requesting availability status for application [%v], error: %v", app.ID, err)
+// recordDispatch stores the outcome of the availability check request for the given resource. A zero response code
+// means that the request wasn't an HTTP one.
+func recordDispatch(source *m.Source, check *m.AvailabilityCheck, resourceType string, resourceId int64, responseCode int, err error, skipped bool) {
+	if check == nil {
 		return
 	}
-	defer resp.Body.Close()
 
-	// anything greater than 299 is bad, right??? right????
-	if resp.StatusCode%100 > 2 {
-		l.Log.Warnf("Bad response from client: %v", resp.StatusCode)
+	id := strconv.FormatInt(resourceId, 10)
+	for i := range check.Targets {
+		target := &check.Targets[i]
+		if target.ResourceType != resourceType || target.ResourceID != id {
+			continue
+		}
+
+		now := time.Now()
+		target.ResponseCode = responseCode
+		target.DispatchedAt = &now
+
+		switch {
+		case skipped:
+			target.DispatchStatus = m.DispatchSkipped
+		case err != nil:
+			target.DispatchStatus = m.DispatchFailed
+			target.DispatchError = err.Error()
+		default:
+			target.DispatchStatus = m.DispatchSent
+		}
+
+		log := checkLogger(check)
+		updateErr := dao.GetAvailabilityCheckDao(&source.TenantID).WithContext(loggerContext(log)).UpdateTarget(target)
+		if updateErr != nil {
+			log.Warnf("Failed to record the dispatch of availability check [%v] for %s [%v]: %v", check.ID, resourceType, resourceId, updateErr)
+		}
+
+		return
 	}
 }
 
-// codified version of what we were sending over kafka. The satellite operations
-// worker picks this message up and makes the proper requests to the
-// platform-receptor-controller.
-type satelliteAvailabilityMessage struct {
-	SourceID       string  `json:"source_id"`
-	SourceUID      *string `json:"source_uid"`
-	SourceRef      *string `json:"source_ref"`
-	ExternalTenant string  `json:"external_tenant"`
-}
+// sends off an availability check request for each of the source's
+// applications, through the dispatcher of their application type. The
+// applications are checked concurrently, so that a slow one doesn't delay the
+// rest.
+func (acr availabilityCheckRequester) ApplicationAvailabilityCheck(source *m.Source, check *m.AvailabilityCheck) {
+	var wg sync.WaitGroup
 
-// sends off an availability check kafka message for each of the source's
-// endpoints but only if the source is of type satellite - we do not support any
-// other operations currently (legacy behavior)
-func (acr availabilityCheckRequester) EndpointAvailabilityCheck(source *m.Source) {
-	if source.SourceType.Name != "satellite" {
-		l.Log.Infof("Skipping Endpoint availability check for non-satellite source type")
-		return
+	for i := range source.Applications {
+		app := &source.Applications[i]
+		checkLogger(check).Infof("Requesting Availability Check for Application %v", app.ID)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			dispatcher := getDispatchers().forApplicationType(&app.ApplicationType)
+			dispatch(dispatcher, &dispatchTarget{source: source, check: check, resourceType: "Application", resourceId: app.ID})
+		}()
 	}
 
-	// instantiate a producer for this source
-	mgr := &kafka.Manager{Config: kafka.Config{
-		KafkaBrokers:   config.Get().KafkaBrokers,
-		ProducerConfig: kafka.ProducerConfig{Topic: satelliteTopic},
-	}}
+	wg.Wait()
+}
+
+// sends off an availability check request for each of the source's
+// endpoints, through the dispatcher of the source's type.
+func (acr availabilityCheckRequester) EndpointAvailabilityCheck(source *m.Source, check *m.AvailabilityCheck) {
+	dispatcher := getDispatchers().forSourceType(source.SourceType.Name)
 
-	l.Log.Infof("Publishing message for Source [%v] topic [%v] ", source.ID, mgr.ProducerConfig.Topic)
 	for _, endpoint := range source.Endpoints {
-		publishSatelliteMessage(mgr, source, &endpoint)
+		checkLogger(check).Infof("Requesting Availability Check for Endpoint %v", endpoint.ID)
+
+		dispatch(dispatcher, &dispatchTarget{source: source, check: check, resourceType: "Endpoint", resourceId: endpoint.ID})
 	}
 }
 
-func publishSatelliteMessage(mgr *kafka.Manager, source *m.Source, endpoint *m.Endpoint) {
-	l.Log.Infof("Requesting Availability Check for Endpoint %v", endpoint.ID)
-
-	msg := &kafka.Message{}
-	err := msg.AddValueAsJSON(&satelliteAvailabilityMessage{
-		SourceID:       strconv.FormatInt(source.ID, 10),
-		SourceUID:      source.Uid,
-		SourceRef:      source.SourceRef,
-		ExternalTenant: source.Tenant.ExternalTenant,
-	})
-	if err != nil {
-		l.Log.Warnf("Failed to add struct value as json to kafka message")
+// dispatch sends the availability check request for the target, and records its outcome.
+func dispatch(dispatcher availabilityDispatcher, target *dispatchTarget) {
+	code, err := dispatcher.Dispatch(target)
+	if errors.Is(err, errDispatchSkipped) {
+		checkLogger(target.check).Infof("Skipping the availability check for %s [%v]: %s", target.resourceType, target.resourceId, err)
+		recordDispatch(target.source, target.check, target.resourceType, target.resourceId, 0, nil, true)
 		return
 	}
 
-	msg.AddHeaders([]kafka.Header{
-		{Key: "x-rh-identity", Value: []byte(util.XRhIdentityWithAccountNumber(endpoint.Tenant.ExternalTenant))},
-		{Key: "x-rh-sources-account-number", Value: []byte(endpoint.Tenant.ExternalTenant)},
-	})
-
-	err = mgr.Produce(msg)
-	if err != nil {
-		l.Log.Warnf("Failed to produce kafka message for Source %v, error: %v", source.ID, err)
-	}
+	recordDispatch(target.source, target.check, target.resourceType, target.resourceId, code, err, false)
 }
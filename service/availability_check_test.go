@@ -1,8 +1,12 @@
 package service
 
 import (
+	"context"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/RedHatInsights/sources-api-go/dao"
 	m "github.com/RedHatInsights/sources-api-go/model"
 )
 
@@ -11,13 +15,13 @@ type dummyChecker struct {
 	EndpointCounter    int
 }
 
-func (c *dummyChecker) ApplicationAvailabilityCheck(source *m.Source) {
+func (c *dummyChecker) ApplicationAvailabilityCheck(source *m.Source, _ *m.AvailabilityCheck) {
 	for i := 0; i < len(source.Applications); i++ {
 		c.ApplicationCounter++
 	}
 }
 
-func (c *dummyChecker) EndpointAvailabilityCheck(source *m.Source) {
+func (c *dummyChecker) EndpointAvailabilityCheck(source *m.Source, _ *m.AvailabilityCheck) {
 	for i := 0; i < len(source.Endpoints); i++ {
 		c.EndpointCounter++
 	}
@@ -30,7 +34,7 @@ func TestApplicationAvailability(t *testing.T) {
 	RequestAvailabilityCheck(&m.Source{
 		// 2 applications on this source.
 		Applications: []m.Application{{}, {}},
-	})
+	}, nil)
 
 	if d.ApplicationCounter != 2 {
 		t.Errorf("availability check not called for both applications, got %v expected %v", d.ApplicationCounter, 2)
@@ -44,13 +48,51 @@ func TestEndpointAvailability(t *testing.T) {
 	RequestAvailabilityCheck(&m.Source{
 		// 3 endpoints on this source.
 		Endpoints: []m.Endpoint{{}, {}, {}},
-	})
+	}, nil)
 
 	if d.EndpointCounter != 3 {
 		t.Errorf("availability check not called for all endpoints, got %v expected %v", d.EndpointCounter, 3)
 	}
 }
 
+// blockingChecker holds the application checks until it gets released.
+type blockingChecker struct {
+	release chan struct{}
+}
+
+func (c *blockingChecker) ApplicationAvailabilityCheck(_ *m.Source, _ *m.AvailabilityCheck) {
+	<-c.release
+}
+
+func (c *blockingChecker) EndpointAvailabilityCheck(_ *m.Source, _ *m.AvailabilityCheck) {}
+
+// TestWaitForBackgroundChecks tests that the checks requested in the background are awaited, up to the context's
+// deadline.
+func TestWaitForBackgroundChecks(t *testing.T) {
+	previous := ac
+	defer func() { ac = previous }()
+
+	checker := &blockingChecker{release: make(chan struct{})}
+	ac = checker
+
+	RequestAvailabilityCheckInBackground(&m.Source{Applications: []m.Application{{}}}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := WaitForBackgroundChecks(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("want the deadline exceeded while the check is being dispatched, got %v", err)
+	}
+
+	close(checker.release)
+
+	err = WaitForBackgroundChecks(context.Background())
+	if err != nil {
+		t.Errorf("want the dispatched check awaited, got %s", err)
+	}
+}
+
 func TestBothAvailability(t *testing.T) {
 	d := &dummyChecker{}
 	ac = d
@@ -60,7 +102,7 @@ func TestBothAvailability(t *testing.T) {
 		Applications: []m.Application{{}, {}, {}},
 		// 3 endpoints on this source.
 		Endpoints: []m.Endpoint{{}, {}, {}, {}},
-	})
+	}, nil)
 
 	if d.ApplicationCounter != 3 {
 		t.Errorf("availability check not called for both applications, got %v expected %v", d.ApplicationCounter, 3)
@@ -70,3 +112,80 @@ func TestBothAvailability(t *testing.T) {
 		t.Errorf("availability check not called for all endpoints, got %v expected %v", d.EndpointCounter, 4)
 	}
 }
+
+// TestPausedResourcesAvailability tests that the paused applications and endpoints don't get checked.
+func TestPausedResourcesAvailability(t *testing.T) {
+	d := &dummyChecker{}
+	ac = d
+
+	paused := m.Pause{PausedAt: time.Now()}
+	RequestAvailabilityCheck(&m.Source{
+		Applications: []m.Application{{ID: 1}, {ID: 2, Pause: paused}},
+		Endpoints:    []m.Endpoint{{ID: 1, Pause: paused}},
+	}, nil)
+
+	if d.ApplicationCounter != 1 {
+		t.Errorf("want only the unpaused application checked, got %d checks", d.ApplicationCounter)
+	}
+
+	if d.EndpointCounter != 0 {
+		t.Errorf("want the paused endpoint not checked, got %d checks", d.EndpointCounter)
+	}
+}
+
+// TestRecordDispatch tests that the outcome of the requests gets recorded in the right target.
+func TestRecordDispatch(t *testing.T) {
+	previous := dao.GetAvailabilityCheckDao
+	dao.GetAvailabilityCheckDao = func(_ *int64) dao.AvailabilityCheckDao { return &dao.MockAvailabilityCheckDao{} }
+	defer func() { dao.GetAvailabilityCheckDao = previous }()
+
+	source := &m.Source{ID: 1}
+	check, err := NewAvailabilityCheck(&m.Source{
+		ID:           1,
+		Applications: []m.Application{{ID: 1}, {ID: 2}},
+		Endpoints:    []m.Endpoint{{ID: 1}},
+	}, "request-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if check.RequestID != "request-id" {
+		t.Errorf(`want the request id "request-id" in the check, got %q`, check.RequestID)
+	}
+
+	recordDispatch(source, check, "Application", 1, 202, nil, false)
+	recordDispatch(source, check, "Application", 2, 500, http.ErrHandlerTimeout, false)
+	recordDispatch(source, check, "Endpoint", 1, 0, nil, true)
+
+	want := []struct {
+		resourceType   string
+		dispatchStatus string
+		responseCode   int
+	}{
+		{"Application", m.DispatchSent, 202},
+		{"Application", m.DispatchFailed, 500},
+		{"Endpoint", m.DispatchSkipped, 0},
+	}
+
+	if len(check.Targets) != len(want) {
+		t.Fatalf("want %d targets, got %d", len(want), len(check.Targets))
+	}
+
+	for i, target := range check.Targets {
+		if target.ResourceType != want[i].resourceType || target.DispatchStatus != want[i].dispatchStatus || target.ResponseCode != want[i].responseCode {
+			t.Errorf("want target %+v, got %+v", want[i], target)
+		}
+
+		if target.DispatchedAt == nil {
+			t.Errorf("want the dispatch time recorded for target %d", i)
+		}
+	}
+
+	if check.Targets[1].DispatchError == "" {
+		t.Error("want the dispatch error recorded")
+	}
+
+	if check.Targets[0].Answered() || !check.Targets[1].Answered() || !check.Targets[2].Answered() {
+		t.Error("want only the dispatched target awaiting an answer")
+	}
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// defaultDispatchTimeout limits the HTTP requests of the dispatchers without a configured timeout.
+const defaultDispatchTimeout = 10 * time.Second
+
+// errDispatchSkipped is returned by the dispatchers which don't send the availability check request anywhere.
+var errDispatchSkipped = errors.New("no availability check dispatcher configured")
+
+var (
+	dispatchers     *dispatcherRegistry
+	dispatchersOnce sync.Once
+)
+
+// availabilityDispatcher sends the availability check request for one of the source's resources.
+type availabilityDispatcher interface {
+	// Dispatch sends the request, and returns the response's status code for the HTTP requests. errDispatchSkipped
+	// is returned when the request wasn't sent anywhere.
+	Dispatch(target *dispatchTarget) (int, error)
+}
+
+// dispatchTarget is the resource whose availability check is requested.
+type dispatchTarget struct {
+	source       *m.Source
+	check        *m.AvailabilityCheck
+	resourceType string
+	resourceId   int64
+}
+
+// headers returns the headers of the availability check request: the ones of the source's tenant, and the id of the
+// request which asked for the check.
+func (target *dispatchTarget) headers() []kafka.Header {
+	return checkHeaders(target.source, target.check)
+}
+
+// checkHeaders returns the headers of the source's tenant, along with the id of the request which asked for the
+// availability check.
+func checkHeaders(source *m.Source, check *m.AvailabilityCheck) []kafka.Header {
+	headers := TenantHeaders(&source.Tenant)
+	if check != nil && check.RequestID != "" {
+		headers = append(headers, kafka.Header{Key: util.RequestIdHeader, Value: []byte(check.RequestID)})
+	}
+
+	return headers
+}
+
+// dispatcherRegistry holds the dispatchers of the application types and the source types. The applications' checks
+// are dispatched by their application type, and the endpoints' ones by their source type.
+type dispatcherRegistry struct {
+	applicationTypes map[string]availabilityDispatcher
+	sourceTypes      map[string]availabilityDispatcher
+}
+
+// getDispatchers returns the registry built from the seeds, overridden by the configuration.
+func getDispatchers() *dispatcherRegistry {
+	dispatchersOnce.Do(func() {
+		configs := make([]*m.AvailabilityCheckDispatchers, 0, 2)
+
+		seeds, err := dao.AvailabilityCheckDispatchSeeds()
+		if err != nil {
+			l.Log.Errorf("Unable to load the availability check dispatchers from the seeds: %s", err)
+		} else {
+			configs = append(configs, seeds)
+		}
+
+		if raw := config.Get().AvailabilityDispatchers; raw != "" {
+			overrides := &m.AvailabilityCheckDispatchers{}
+			err = json.Unmarshal([]byte(raw), overrides)
+			if err != nil {
+				l.Log.Errorf("Unable to parse the availability check dispatchers from the configuration: %s", err)
+			} else {
+				configs = append(configs, overrides)
+			}
+		}
+
+		dispatchers = newDispatcherRegistry(configs...)
+	})
+
+	return dispatchers
+}
+
+// newDispatcherRegistry builds the dispatchers from the given configurations. The later configurations override the
+// earlier ones, and the invalid entries are left out.
+func newDispatcherRegistry(configs ...*m.AvailabilityCheckDispatchers) *dispatcherRegistry {
+	registry := &dispatcherRegistry{
+		applicationTypes: make(map[string]availabilityDispatcher),
+		sourceTypes:      make(map[string]availabilityDispatcher),
+	}
+
+	for _, cfg := range configs {
+		for name, dispatch := range cfg.ApplicationTypes {
+			dispatcher, err := newDispatcher(dispatch)
+			if err != nil {
+				l.Log.Warnf("Invalid availability check dispatcher for application type %q: %s", name, err)
+				continue
+			}
+
+			registry.applicationTypes[name] = dispatcher
+		}
+
+		for name, dispatch := range cfg.SourceTypes {
+			dispatcher, err := newDispatcher(dispatch)
+			if err != nil {
+				l.Log.Warnf("Invalid availability check dispatcher for source type %q: %s", name, err)
+				continue
+			}
+
+			registry.sourceTypes[name] = dispatcher
+		}
+	}
+
+	return registry
+}
+
+// newDispatcher returns the dispatcher of the configured strategy.
+func newDispatcher(dispatch m.AvailabilityCheckDispatch) (availabilityDispatcher, error) {
+	switch dispatch.Strategy {
+	case m.DispatchStrategyHttp:
+		rawUrl := dispatch.URL
+		if dispatch.URLEnv != "" {
+			if value, ok := os.LookupEnv(dispatch.URLEnv); ok {
+				rawUrl = value
+			}
+		}
+
+		// an application without a URL in the current environment simply doesn't get its availability checked.
+		if rawUrl == "" {
+			return &noopDispatcher{}, nil
+		}
+
+		uri, err := url.Parse(rawUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		timeout := defaultDispatchTimeout
+		if dispatch.TimeoutSeconds > 0 {
+			timeout = time.Duration(dispatch.TimeoutSeconds) * time.Second
+		}
+
+		headers := make(map[string]string, len(dispatch.HeadersFromEnv))
+		for header, env := range dispatch.HeadersFromEnv {
+			headers[header] = os.Getenv(env)
+		}
+
+		return newHttpDispatcher(uri, timeout, dispatch.Retries, headers), nil
+	case m.DispatchStrategyKafka:
+		if dispatch.Topic == "" {
+			return nil, errors.New("the kafka strategy requires a topic")
+		}
+
+		return &kafkaDispatcher{topic: config.Get().KafkaTopic(dispatch.Topic)}, nil
+	case m.DispatchStrategyNoop:
+		return &noopDispatcher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", dispatch.Strategy)
+	}
+}
+
+// forApplicationType returns the dispatcher of the application type. The application types without one fall back to
+// the "<NAME>_AVAILABILITY_CHECK_URL" environment variable.
+func (dr *dispatcherRegistry) forApplicationType(appType *m.ApplicationType) availabilityDispatcher {
+	if dispatcher, ok := dr.applicationTypes[appType.Name]; ok {
+		return dispatcher
+	}
+
+	if uri := appType.AvailabilityCheckURL(); uri != nil {
+		return newHttpDispatcher(uri, defaultDispatchTimeout, defaultDispatchRetries, nil)
+	}
+
+	return &noopDispatcher{}
+}
+
+// forSourceType returns the dispatcher of the source type.
+func (dr *dispatcherRegistry) forSourceType(name string) availabilityDispatcher {
+	if dispatcher, ok := dr.sourceTypes[name]; ok {
+		return dispatcher
+	}
+
+	return &noopDispatcher{}
+}
+
+// noopDispatcher skips the availability check requests.
+type noopDispatcher struct{}
+
+func (nd *noopDispatcher) Dispatch(_ *dispatchTarget) (int, error) {
+	return 0, errDispatchSkipped
+}
+
+// httpDispatcher posts the availability check requests to a URL, retrying the failed ones with backoff. The requests
+// are rejected right away while the URL's circuit is open.
+type httpDispatcher struct {
+	url     *url.URL
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	breaker *circuitBreaker
+	// headers are sent along with the identity headers.
+	headers map[string]string
+}
+
+// newHttpDispatcher returns a dispatcher that shares the circuit breaker with the rest of the dispatchers of the URL.
+func newHttpDispatcher(uri *url.URL, timeout time.Duration, retries int, headers map[string]string) *httpDispatcher {
+	return &httpDispatcher{
+		url:     uri,
+		timeout: timeout,
+		retries: retries,
+		backoff: defaultRetryBackoff,
+		breaker: breakerFor(uri.String()),
+		headers: headers,
+	}
+}
+
+func (hd *httpDispatcher) Dispatch(target *dispatchTarget) (int, error) {
+	if !hd.breaker.Allow() {
+		return 0, errCircuitOpen
+	}
+
+	body := map[string]string{"source_id": strconv.FormatInt(target.source.ID, 10)}
+	if target.check != nil {
+		body["availability_check_id"] = strconv.FormatInt(target.check.ID, 10)
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	var code int
+	for attempt := 0; ; attempt++ {
+		code, err = hd.post(target, raw)
+		if !isRetryable(code, err) || attempt >= hd.retries {
+			break
+		}
+
+		delay := retryBackoff(hd.backoff, attempt)
+		checkLogger(target.check).Infof("Retrying the availability check request for %s [%v] in %s: %v", target.resourceType, target.resourceId, delay, err)
+		time.Sleep(delay)
+	}
+
+	// the client errors still mean that the application is up.
+	if isRetryable(code, err) {
+		hd.breaker.Failure()
+	} else {
+		hd.breaker.Success()
+	}
+
+	return code, err
+}
+
+// post sends a single availability check request.
+func (hd *httpDispatcher) post(target *dispatchTarget, body []byte) (int, error) {
+	ctx, done := context.WithTimeout(context.Background(), hd.timeout)
+	defer done()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hd.url.String(), bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, header := range target.headers() {
+		req.Header.Add(header.Key, string(header.Value))
+	}
+	req.Header.Add("Content-Type", "application/json;charset=utf-8")
+	for header, value := range hd.headers {
+		req.Header.Set(header, value)
+	}
+
+	resp, err := availabilityHttpClient.Do(req)
+	if err != nil {
+		checkLogger(target.check).Warnf("Error requesting availability status for %s [%v], error: %v", target.resourceType, target.resourceId, err)
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// anything outside of the 2xx range is bad.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		checkLogger(target.check).Warnf("Bad response from client: %v", resp.StatusCode)
+		return resp.StatusCode, fmt.Errorf("bad response from the application: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// availabilityCheckMessage is the availability check request published to Kafka. The satellite operations worker
+// picks it up and makes the proper requests to the platform-receptor-controller.
+type availabilityCheckMessage struct {
+	SourceID       string  `json:"source_id"`
+	SourceUID      *string `json:"source_uid"`
+	SourceRef      *string `json:"source_ref"`
+	ExternalTenant string  `json:"external_tenant"`
+	OrgID          string  `json:"org_id,omitempty"`
+	// ApplicationID is only set when the request targets an application.
+	ApplicationID string `json:"application_id,omitempty"`
+	// AvailabilityCheckID is sent back in the status messages, to be able to correlate them with the check.
+	AvailabilityCheckID string `json:"availability_check_id,omitempty"`
+}
+
+// kafkaDispatcher publishes the availability check requests to a topic.
+type kafkaDispatcher struct {
+	topic string
+}
+
+func (kd *kafkaDispatcher) Dispatch(target *dispatchTarget) (int, error) {
+	mgr := &kafka.Manager{Config: kafka.Config{
+		KafkaBrokers:   config.Get().KafkaBrokers,
+		ProducerConfig: kafka.ProducerConfig{Topic: kd.topic},
+	}}
+	defer func() {
+		if err := mgr.Close(); err != nil {
+			l.Log.Warnf("Failed to close the producer for topic [%v]: %v", kd.topic, err)
+		}
+	}()
+
+	checkLogger(target.check).Infof("Publishing availability check message for %s [%v] to topic [%v]", target.resourceType, target.resourceId, kd.topic)
+
+	msg := &kafka.Message{}
+	err := msg.AddValueAsJSON(kd.message(target))
+	if err != nil {
+		checkLogger(target.check).Warnf("Failed to add struct value as json to kafka message")
+		return 0, err
+	}
+
+	msg.AddHeaders(target.headers())
+
+	err = mgr.Produce(msg)
+	if err != nil {
+		checkLogger(target.check).Warnf("Failed to produce kafka message for Source %v, error: %v", target.source.ID, err)
+	}
+
+	return 0, err
+}
+
+// message builds the availability check request for the target.
+func (kd *kafkaDispatcher) message(target *dispatchTarget) *availabilityCheckMessage {
+	message := &availabilityCheckMessage{
+		SourceID:       strconv.FormatInt(target.source.ID, 10),
+		SourceUID:      target.source.Uid,
+		SourceRef:      target.source.SourceRef,
+		ExternalTenant: target.source.Tenant.ExternalTenant,
+		OrgID:          target.source.Tenant.OrgID,
+	}
+
+	if target.resourceType == "Application" {
+		message.ApplicationID = strconv.FormatInt(target.resourceId, 10)
+	}
+
+	if target.check != nil {
+		message.AvailabilityCheckID = strconv.FormatInt(target.check.ID, 10)
+	}
+
+	return message
+}
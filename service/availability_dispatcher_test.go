@@ -0,0 +1,201 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// TestHttpDispatcher tests that the availability check's id is sent to the application, along with the configured
+// headers and the id of the request which asked for the check, and that the responses outside of the 2xx range are
+// reported as errors.
+func TestHttpDispatcher(t *testing.T) {
+	var body map[string]string
+	var psk, requestId string
+	responseCode := http.StatusAccepted
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		psk = r.Header.Get("x-rh-sources-psk")
+		requestId = r.Header.Get("x-rh-insights-request-id")
+		w.WriteHeader(responseCode)
+	}))
+	defer server.Close()
+
+	uri, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := newHttpDispatcher(uri, defaultDispatchTimeout, 0, map[string]string{"x-rh-sources-psk": "secret"})
+	target := &dispatchTarget{
+		source:       &m.Source{ID: 1, Tenant: m.Tenant{ExternalTenant: "12345"}},
+		check:        &m.AvailabilityCheck{ID: 3, RequestID: "request-id"},
+		resourceType: "Application",
+		resourceId:   2,
+	}
+
+	code, err := dispatcher.Dispatch(target)
+	if err != nil {
+		t.Errorf("want no error, got %s", err)
+	}
+
+	if code != http.StatusAccepted {
+		t.Errorf("want %d response code, got %d", http.StatusAccepted, code)
+	}
+
+	if body["source_id"] != "1" || body["availability_check_id"] != "3" {
+		t.Errorf("want the source and the availability check ids sent, got %v", body)
+	}
+
+	if psk != "secret" {
+		t.Errorf("want the configured header sent, got %q", psk)
+	}
+
+	if requestId != "request-id" {
+		t.Errorf(`want the request id "request-id" sent, got %q`, requestId)
+	}
+
+	// a 300 response used to be taken as a successful one.
+	for _, responseCode = range []int{http.StatusMultipleChoices, http.StatusNotFound, http.StatusInternalServerError} {
+		code, err = dispatcher.Dispatch(target)
+		if err == nil {
+			t.Errorf("want error for a %d response, got none", responseCode)
+		}
+
+		if code != responseCode {
+			t.Errorf("want %d response code, got %d", responseCode, code)
+		}
+	}
+}
+
+// TestHttpDispatcherRetries tests that only the server errors get retried.
+func TestHttpDispatcherRetries(t *testing.T) {
+	var calls int
+	responseCodes := []int{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(responseCodes[calls])
+		calls++
+	}))
+	defer server.Close()
+
+	uri, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := newHttpDispatcher(uri, defaultDispatchTimeout, 2, nil)
+	dispatcher.backoff = time.Millisecond
+	target := &dispatchTarget{source: &m.Source{ID: 1}, resourceType: "Application", resourceId: 1}
+
+	responseCodes = []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusAccepted}
+	code, err := dispatcher.Dispatch(target)
+	if err != nil || code != http.StatusAccepted || calls != 3 {
+		t.Errorf("want the request to succeed on the third attempt, got code %d after %d calls: %v", code, calls, err)
+	}
+
+	calls = 0
+	responseCodes = []int{http.StatusNotFound}
+	code, err = dispatcher.Dispatch(target)
+	if err == nil || code != http.StatusNotFound || calls != 1 {
+		t.Errorf("want the client error not retried, got code %d after %d calls: %v", code, calls, err)
+	}
+}
+
+// TestDispatcherRegistry tests that the dispatchers get built from the configurations, with the later ones taking
+// precedence, and that the types without a dispatcher are skipped.
+func TestDispatcherRegistry(t *testing.T) {
+	os.Setenv("TEST_DISPATCHER_URL", "http://from.the/env")
+	defer os.Unsetenv("TEST_DISPATCHER_URL")
+
+	seeds := &m.AvailabilityCheckDispatchers{
+		ApplicationTypes: map[string]m.AvailabilityCheckDispatch{
+			"/insights/platform/http":       {Strategy: m.DispatchStrategyHttp, URL: "http://from.the/seeds", URLEnv: "TEST_DISPATCHER_URL", Retries: 1},
+			"/insights/platform/overridden": {Strategy: m.DispatchStrategyHttp, URL: "http://from.the/seeds"},
+			"/insights/platform/no-url":     {Strategy: m.DispatchStrategyHttp, URLEnv: "TEST_DISPATCHER_MISSING_URL"},
+			"/insights/platform/invalid":    {Strategy: "carrier-pigeon"},
+		},
+		SourceTypes: map[string]m.AvailabilityCheckDispatch{
+			"satellite": {Strategy: m.DispatchStrategyKafka, Topic: "platform.topological-inventory.operations-satellite"},
+			"invalid":   {Strategy: m.DispatchStrategyKafka},
+		},
+	}
+
+	overrides := &m.AvailabilityCheckDispatchers{
+		ApplicationTypes: map[string]m.AvailabilityCheckDispatch{
+			"/insights/platform/overridden": {Strategy: m.DispatchStrategyNoop},
+		},
+	}
+
+	registry := newDispatcherRegistry(seeds, overrides)
+
+	dispatcher, ok := registry.forApplicationType(&m.ApplicationType{Name: "/insights/platform/http"}).(*httpDispatcher)
+	if !ok {
+		t.Fatal("want an HTTP dispatcher")
+	}
+
+	if dispatcher.url.String() != "http://from.the/env" || dispatcher.retries != 1 || dispatcher.timeout != defaultDispatchTimeout {
+		t.Errorf("unexpected HTTP dispatcher: %+v", dispatcher)
+	}
+
+	for _, name := range []string{"/insights/platform/overridden", "/insights/platform/no-url", "/insights/platform/invalid", "/insights/platform/unknown"} {
+		if _, ok := registry.forApplicationType(&m.ApplicationType{Name: name}).(*noopDispatcher); !ok {
+			t.Errorf("want a no-op dispatcher for %q", name)
+		}
+	}
+
+	if _, ok := registry.forSourceType("satellite").(*kafkaDispatcher); !ok {
+		t.Error("want a Kafka dispatcher for satellite")
+	}
+
+	for _, name := range []string{"invalid", "amazon"} {
+		if _, ok := registry.forSourceType(name).(*noopDispatcher); !ok {
+			t.Errorf("want a no-op dispatcher for %q", name)
+		}
+	}
+}
+
+// TestDispatcherRegistryLegacyUrl tests that the application types without a dispatcher still pick up the URL from
+// the "<NAME>_AVAILABILITY_CHECK_URL" environment variable.
+func TestDispatcherRegistryLegacyUrl(t *testing.T) {
+	os.Setenv("LEGACY_APP_AVAILABILITY_CHECK_URL", "http://legacy.app/check")
+	defer os.Unsetenv("LEGACY_APP_AVAILABILITY_CHECK_URL")
+
+	registry := newDispatcherRegistry()
+
+	dispatcher, ok := registry.forApplicationType(&m.ApplicationType{Name: "/insights/platform/legacy-app"}).(*httpDispatcher)
+	if !ok {
+		t.Fatal("want an HTTP dispatcher")
+	}
+
+	if dispatcher.url.String() != "http://legacy.app/check" || dispatcher.retries != defaultDispatchRetries {
+		t.Errorf("want the legacy URL with the default retries, got %+v", dispatcher)
+	}
+}
+
+// TestKafkaDispatcherMessage tests that the application's id is only sent for the applications' checks.
+func TestKafkaDispatcherMessage(t *testing.T) {
+	dispatcher := &kafkaDispatcher{topic: "topic"}
+	source := &m.Source{ID: 1, Tenant: m.Tenant{ExternalTenant: "12345"}}
+
+	message := dispatcher.message(&dispatchTarget{source: source, check: &m.AvailabilityCheck{ID: 3}, resourceType: "Application", resourceId: 2})
+	if message.SourceID != "1" || message.ApplicationID != "2" || message.AvailabilityCheckID != "3" || message.ExternalTenant != "12345" {
+		t.Errorf("unexpected application message: %+v", message)
+	}
+
+	message = dispatcher.message(&dispatchTarget{source: source, resourceType: "Endpoint", resourceId: 4})
+	if message.ApplicationID != "" || message.AvailabilityCheckID != "" {
+		t.Errorf("unexpected endpoint message: %+v", message)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultDispatchRetries is the number of retries of the application types without a dispatcher of their own.
+	defaultDispatchRetries = 2
+	// defaultRetryBackoff is the delay before the first retry, which doubles on every following one.
+	defaultRetryBackoff = 500 * time.Millisecond
+	// maxRetryBackoff caps the delay between two retries.
+	maxRetryBackoff = 5 * time.Second
+
+	// breakerThreshold is the number of consecutive failed requests that open a target's circuit.
+	breakerThreshold = 5
+	// breakerCooldown is for how long an open circuit rejects the requests before letting a trial one through.
+	breakerCooldown = time.Minute
+)
+
+// errCircuitOpen is returned instead of sending the request when the target has been failing.
+var errCircuitOpen = errors.New("the availability check target is failing, circuit open")
+
+// availabilityHttpClient is shared by the HTTP dispatchers so that the connections to the applications get reused.
+// The timeouts are set per request by the dispatchers.
+var availabilityHttpClient = &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+
+var (
+	breakers      = make(map[string]*circuitBreaker)
+	breakersMutex sync.Mutex
+)
+
+// breakerFor returns the circuit breaker of the given target, creating it if needed.
+func breakerFor(target string) *circuitBreaker {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	breaker, ok := breakers[target]
+	if !ok {
+		breaker = &circuitBreaker{threshold: breakerThreshold, cooldown: breakerCooldown, now: time.Now}
+		breakers[target] = breaker
+	}
+
+	return breaker
+}
+
+// circuitBreaker stops sending requests to a target after too many consecutive failures, so that a dead application
+// doesn't slow every availability check down. Once the cooldown is over, a single trial request is let through: its
+// success closes the circuit again, and its failure restarts the cooldown.
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// Allow returns whether a request can be sent to the target.
+func (cb *circuitBreaker) Allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.failures < cb.threshold {
+		return true
+	}
+
+	if cb.trial || cb.now().Sub(cb.openedAt) < cb.cooldown {
+		return false
+	}
+
+	cb.trial = true
+	return true
+}
+
+// Success closes the circuit.
+func (cb *circuitBreaker) Success() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.failures = 0
+	cb.trial = false
+}
+
+// Failure counts the failed request, and opens the circuit once the threshold is reached.
+func (cb *circuitBreaker) Failure() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.failures++
+	cb.trial = false
+	if cb.failures >= cb.threshold {
+		cb.openedAt = cb.now()
+	}
+}
+
+// isRetryable returns whether the outcome of an availability check request is worth retrying: the server errors, the
+// throttled requests, the timeouts and the network errors are. The rest of the errors mean the application is up,
+// and it rejected the request.
+func isRetryable(code int, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if code != 0 {
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
+// retryBackoff returns the delay before the given retry, doubling on every attempt and with up to a half of jitter
+// so that the retries of the concurrent checks don't hit the application at once.
+func retryBackoff(base time.Duration, retry int) time.Duration {
+	backoff := base << retry
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// TestCircuitBreaker tests that the circuit opens after the consecutive failures, lets a single trial request through
+// after the cooldown, and closes again when the trial succeeds.
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := &circuitBreaker{threshold: 2, cooldown: time.Minute, now: func() time.Time { return now }}
+
+	breaker.Failure()
+	if !breaker.Allow() {
+		t.Error("want the circuit closed before reaching the threshold")
+	}
+
+	breaker.Failure()
+	if breaker.Allow() {
+		t.Error("want the circuit open after reaching the threshold")
+	}
+
+	now = now.Add(time.Minute)
+	if !breaker.Allow() {
+		t.Error("want a trial request allowed after the cooldown")
+	}
+
+	if breaker.Allow() {
+		t.Error("want a single trial request allowed at once")
+	}
+
+	// a failed trial restarts the cooldown.
+	breaker.Failure()
+	if breaker.Allow() {
+		t.Error("want the circuit open after a failed trial")
+	}
+
+	now = now.Add(time.Minute)
+	if !breaker.Allow() {
+		t.Error("want a trial request allowed after the cooldown")
+	}
+
+	breaker.Success()
+	if !breaker.Allow() || !breaker.Allow() {
+		t.Error("want the circuit closed after a successful trial")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		code int
+		err  error
+		want bool
+	}{
+		{code: http.StatusAccepted, err: nil, want: false},
+		{code: http.StatusNotFound, err: errors.New("bad response"), want: false},
+		{code: http.StatusMultipleChoices, err: errors.New("bad response"), want: false},
+		{code: http.StatusTooManyRequests, err: errors.New("bad response"), want: true},
+		{code: http.StatusInternalServerError, err: errors.New("bad response"), want: true},
+		{code: http.StatusBadGateway, err: errors.New("bad response"), want: true},
+		{code: 0, err: context.DeadlineExceeded, want: true},
+		{code: 0, err: &url.Error{Op: "Post", URL: "http://example.com", Err: context.DeadlineExceeded}, want: true},
+		{code: 0, err: errors.New("unable to marshal the body"), want: false},
+	}
+
+	for _, tc := range testCases {
+		if got := isRetryable(tc.code, tc.err); got != tc.want {
+			t.Errorf("want %t for code %d and error %v, got %t", tc.want, tc.code, tc.err, got)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for retry := 0; retry < 10; retry++ {
+		want := defaultRetryBackoff << retry
+		if want > maxRetryBackoff {
+			want = maxRetryBackoff
+		}
+
+		got := retryBackoff(defaultRetryBackoff, retry)
+		if got < want/2 || got > want {
+			t.Errorf("want the backoff of retry %d between %s and %s, got %s", retry, want/2, want, got)
+		}
+	}
+}
+
+// TestHttpDispatcherCircuitOpen tests that a failing application stops receiving requests.
+func TestHttpDispatcherCircuitOpen(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	uri, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := newHttpDispatcher(uri, defaultDispatchTimeout, 0, nil)
+	target := &dispatchTarget{source: &m.Source{ID: 1}, resourceType: "Application", resourceId: 1}
+
+	for i := 0; i < breakerThreshold; i++ {
+		_, err = dispatcher.Dispatch(target)
+		if err == nil {
+			t.Error("want an error from the failing application")
+		}
+	}
+
+	_, err = dispatcher.Dispatch(target)
+	if !errors.Is(err, errCircuitOpen) {
+		t.Errorf("want the circuit open, got %v", err)
+	}
+
+	if calls != breakerThreshold {
+		t.Errorf("want %d requests sent, got %d", breakerThreshold, calls)
+	}
+
+	// the rest of the dispatchers of the same URL share the circuit.
+	_, err = newHttpDispatcher(uri, defaultDispatchTimeout, 0, nil).Dispatch(target)
+	if !errors.Is(err, errCircuitOpen) {
+		t.Errorf("want the circuit shared between the dispatchers, got %v", err)
+	}
+}
+
+// TestApplicationAvailabilityCheckConcurrent tests that the applications of a source are checked concurrently.
+func TestApplicationAvailabilityCheckConcurrent(t *testing.T) {
+	const delay = 200 * time.Millisecond
+
+	var mutex sync.Mutex
+	requested := make(map[string]bool)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+
+		mutex.Lock()
+		requested[r.URL.Path] = true
+		mutex.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	registry := newDispatcherRegistry(&m.AvailabilityCheckDispatchers{
+		ApplicationTypes: map[string]m.AvailabilityCheckDispatch{
+			"/insights/platform/one":   {Strategy: m.DispatchStrategyHttp, URL: server.URL + "/one"},
+			"/insights/platform/two":   {Strategy: m.DispatchStrategyHttp, URL: server.URL + "/two"},
+			"/insights/platform/three": {Strategy: m.DispatchStrategyHttp, URL: server.URL + "/three"},
+		},
+	})
+
+	dispatchersOnce.Do(func() {})
+	previous := dispatchers
+	dispatchers = registry
+	defer func() { dispatchers = previous }()
+
+	source := &m.Source{
+		ID: 1,
+		Applications: []m.Application{
+			{ID: 1, ApplicationType: m.ApplicationType{Name: "/insights/platform/one"}},
+			{ID: 2, ApplicationType: m.ApplicationType{Name: "/insights/platform/two"}},
+			{ID: 3, ApplicationType: m.ApplicationType{Name: "/insights/platform/three"}},
+		},
+	}
+
+	start := time.Now()
+	availabilityCheckRequester{}.ApplicationAvailabilityCheck(source, nil)
+	elapsed := time.Since(start)
+
+	if len(requested) != 3 {
+		t.Errorf("want the 3 applications requested, got %v", requested)
+	}
+
+	if elapsed >= 3*delay {
+		t.Errorf("want the applications checked concurrently, took %s", elapsed)
+	}
+}
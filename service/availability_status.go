@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/internal/events"
+	"github.com/RedHatInsights/sources-api-go/internal/types"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// ProcessAvailabilityStatus applies the availability status carried by the status message to its resource: it
+// updates the resource, records the status in the availability check that requested it and in the resource's
+// history, raises the update events through the given producer and recomputes the source's derived availability
+// status. The tenant is taken from the headers, and the origin is the one recorded in the history.
+func ProcessAvailabilityStatus(producer *events.EventStreamProducer, statusMessage types.StatusMessage, headers []kafka.Header, origin string) error {
+	log := headersLogger(headers)
+	ctx := loggerContext(log)
+
+	resource := &util.Resource{}
+	resource, err := util.ParseStatusMessageToResource(resource, statusMessage)
+	if err != nil {
+		return fmt.Errorf("invalid status message for %s(%s): %w", statusMessage.ResourceType, statusMessage.ResourceID, err)
+	}
+
+	if !util.SliceContainsString(m.AvailabilityStatuses, statusMessage.Status) {
+		return fmt.Errorf("invalid status: %s", statusMessage.Status)
+	}
+
+	updateAttributes, err := attributesForUpdate(statusMessage)
+	if err != nil {
+		return err
+	}
+
+	modelEventDao, err := dao.GetFromResourceType(statusMessage.ResourceType)
+	if err != nil {
+		return err
+	}
+
+	accountNumber, orgId, err := util.TenantFromHeaders(headers)
+	if err != nil {
+		return err
+	}
+
+	tenant, err := dao.GetTenantDao().TenantByIdentifiers(accountNumber, orgId)
+	if err != nil {
+		return err
+	}
+
+	resource.TenantID = tenant.Id
+	resource.AccountNumber = tenant.ExternalTenant
+	resource.OrgID = tenant.OrgID
+
+	paused, err := statusTargetPaused(resource)
+	if err != nil {
+		return fmt.Errorf("unable to tell whether %s(%s) is paused: %w", statusMessage.ResourceType, statusMessage.ResourceID, err)
+	}
+
+	if paused {
+		log.Infof("Discarding the availability status of %s(%s): it is paused", statusMessage.ResourceType, statusMessage.ResourceID)
+		return nil
+	}
+
+	err = (*modelEventDao).FetchAndUpdateBy(*resource, updateAttributes)
+	if err != nil {
+		return fmt.Errorf("update error in status availability: %w", err)
+	}
+
+	recordAvailabilityCheckStatus(ctx, statusMessage, resource)
+	RecordAvailabilityStatus(ctx, resource.TenantID, resource.ResourceType, statusMessage.ResourceID, statusMessage.Status, statusMessage.Error, origin)
+
+	updateAttributeKeys := make([]string, 0, len(updateAttributes))
+	for k := range updateAttributes {
+		updateAttributeKeys = append(updateAttributeKeys, k)
+	}
+	sort.Strings(updateAttributeKeys)
+
+	err = producer.RaiseEventForUpdate(*resource, updateAttributeKeys, headers)
+	if err != nil {
+		log.Errorf("Error in raising event for update: %s, resource: %s(%s)", err.Error(), statusMessage.ResourceType, statusMessage.ResourceID)
+	}
+
+	updateDerivedSourceAvailability(ctx, producer, resource, headers)
+
+	return nil
+}
+
+// statusTargetPaused tells whether the resource the status message is about is paused, either by itself or through
+// the resource it belongs to.
+func statusTargetPaused(resource *util.Resource) (bool, error) {
+	if resource.ResourceType == "Authentication" {
+		return authenticationPaused(resource.TenantID, resource.ResourceUID)
+	}
+
+	pausedType, _, err := pausedBy(resource.TenantID, resource.ResourceType, resource.ResourceID)
+
+	return pausedType != "", err
+}
+
+// updateDerivedSourceAvailability recomputes the availability status of the source the updated resource belongs to,
+// and raises the "Source.update" event only when the status changes.
+func updateDerivedSourceAvailability(ctx context.Context, producer *events.EventStreamProducer, resource *util.Resource, headers []kafka.Header) {
+	sourceResource, changed, err := UpdateDerivedSourceAvailability(ctx, *resource)
+	if err != nil {
+		l.FromContext(ctx).Errorf("Unable to derive the source's availability status from %s(%s): %s", resource.ResourceType, resource.ResourceUID, err)
+		return
+	}
+
+	if !changed {
+		return
+	}
+
+	err = producer.RaiseEventForUpdate(*sourceResource, []string{"availability_status"}, headers)
+	if err != nil {
+		l.FromContext(ctx).Errorf("Error in raising event for update: %s, resource: Source(%d)", err, sourceResource.ResourceID)
+	}
+}
+
+// recordAvailabilityCheckStatus correlates the status message with the availability check that requested it, so that
+// the check can be marked as completed once every target has answered.
+func recordAvailabilityCheckStatus(ctx context.Context, statusMessage types.StatusMessage, resource *util.Resource) {
+	log := l.FromContext(ctx)
+
+	var checkId int64
+	if statusMessage.AvailabilityCheckID != "" {
+		id, err := strconv.ParseInt(statusMessage.AvailabilityCheckID, 10, 64)
+		if err != nil {
+			log.Warnf("Invalid availability check id %q: %s", statusMessage.AvailabilityCheckID, err)
+		}
+		checkId = id
+	}
+
+	target, err := dao.GetAvailabilityCheckDao(&resource.TenantID).WithContext(ctx).RecordStatus(checkId, resource.ResourceType, statusMessage.ResourceID, statusMessage.Status, statusMessage.Error)
+	if err != nil {
+		// the status messages aren't always answers to an availability check requested by us.
+		log.Debugf("No availability check awaiting the status of %s(%s): %s", resource.ResourceType, statusMessage.ResourceID, err)
+		return
+	}
+
+	log.Debugf("Recorded the status of %s(%s) in availability check %d", resource.ResourceType, statusMessage.ResourceID, target.AvailabilityCheckID)
+}
+
+// attributesForUpdate returns the columns the status message updates. The messages about the resources without an
+// availability status, such as the application authentications, are rejected.
+func attributesForUpdate(statusMessage types.StatusMessage) (map[string]interface{}, error) {
+	statusModels := []string{"source", "application", "authentication", "endpoint", "rhcconnection"}
+	if !util.SliceContainsString(statusModels, strings.ToLower(statusMessage.ResourceType)) {
+		return nil, fmt.Errorf("%s(%s) has no availability status to update", statusMessage.ResourceType, statusMessage.ResourceID)
+	}
+
+	updateAttributes := make(map[string]interface{})
+
+	// TODO: const this? are we using it elsewhere?
+	updateAttributes["last_checked_at"] = time.Now().Format("2006-01-02T15:04:05.999Z")
+	updateAttributes["availability_status"] = statusMessage.Status
+
+	statusErrorModels := []string{"application", "authentication", "endpoint", "rhcconnection"}
+	if util.SliceContainsString(statusErrorModels, strings.ToLower(statusMessage.ResourceType)) {
+		updateAttributes["availability_status_error"] = statusMessage.Error
+	}
+
+	if statusMessage.Status == "available" {
+		updateAttributes["last_available_at"] = updateAttributes["last_checked_at"]
+	}
+
+	return updateAttributes, nil
+}
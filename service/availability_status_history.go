@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// RecordAvailabilityStatus appends the resource's availability status to its history, as long as it is a transition
+// from the previously recorded one. Failures are only logged, since the history must never block the status updates
+// themselves. The context carries the logger of the request the status comes from.
+func RecordAvailabilityStatus(ctx context.Context, tenantId int64, resourceType, resourceId, status, statusError, origin string) {
+	if status == "" {
+		return
+	}
+
+	entry := &m.AvailabilityStatusHistory{
+		ResourceType: resourceType,
+		ResourceID:   resourceId,
+		Status:       status,
+		StatusError:  statusError,
+		Origin:       origin,
+	}
+
+	recorded, err := dao.GetAvailabilityStatusHistoryDao(&tenantId).WithContext(ctx).Record(entry)
+	if err != nil {
+		l.FromContext(ctx).Errorf("Unable to record the availability status %q of %s(%s): %s", status, resourceType, resourceId, err)
+		return
+	}
+
+	if recorded {
+		l.FromContext(ctx).Debugf("Recorded the availability status transition of %s(%s) from %q to %q", resourceType, resourceId, entry.PreviousStatus, status)
+	}
+}
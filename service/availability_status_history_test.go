@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// TestRecordAvailabilityStatus tests that only the transitions get recorded, along with the status they come from.
+func TestRecordAvailabilityStatus(t *testing.T) {
+	historyDao := &dao.MockAvailabilityStatusHistoryDao{}
+
+	previous := dao.GetAvailabilityStatusHistoryDao
+	dao.GetAvailabilityStatusHistoryDao = func(_ *int64) dao.AvailabilityStatusHistoryDao { return historyDao }
+	defer func() { dao.GetAvailabilityStatusHistoryDao = previous }()
+
+	RecordAvailabilityStatus(context.Background(), 1, "Application", "1", m.Available, "", m.AvailabilityStatusOriginStatusMessage)
+	RecordAvailabilityStatus(context.Background(), 1, "Application", "1", m.Available, "", m.AvailabilityStatusOriginStatusMessage)
+	RecordAvailabilityStatus(context.Background(), 1, "Application", "1", "", "", m.AvailabilityStatusOriginApi)
+	RecordAvailabilityStatus(context.Background(), 1, "Endpoint", "1", m.Unavailable, "timeout", m.AvailabilityStatusOriginStatusMessage)
+	RecordAvailabilityStatus(context.Background(), 1, "Application", "1", m.Unavailable, "invalid ARN", m.AvailabilityStatusOriginApi)
+	RecordAvailabilityStatus(context.Background(), 1, "Application", "1", m.Unavailable, "expired ARN", m.AvailabilityStatusOriginApi)
+
+	history, count, err := historyDao.ListForResource("Application", "1", 100, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 3 {
+		t.Fatalf("want 3 transitions recorded, got %d: %v", count, history)
+	}
+
+	if history[0].StatusError != "expired ARN" || history[0].PreviousStatus != m.Unavailable {
+		t.Errorf("want the error change recorded, got %+v", history[0])
+	}
+
+	if history[1].Status != m.Unavailable || history[1].PreviousStatus != m.Available || history[1].Origin != m.AvailabilityStatusOriginApi {
+		t.Errorf("want the transition from available to unavailable recorded, got %+v", history[1])
+	}
+
+	if history[2].PreviousStatus != "" {
+		t.Errorf("want no previous status for the first transition, got %q", history[2].PreviousStatus)
+	}
+}
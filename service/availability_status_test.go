@@ -0,0 +1,60 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/types"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// TestAttributesForUpdate tests that the status messages only update the availability columns their resources have.
+func TestAttributesForUpdate(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		want         []string
+	}{
+		{"Source", []string{"availability_status", "last_checked_at", "last_available_at"}},
+		{"Application", []string{"availability_status", "availability_status_error", "last_checked_at", "last_available_at"}},
+		{"Authentication", []string{"availability_status", "availability_status_error", "last_checked_at", "last_available_at"}},
+		{"Endpoint", []string{"availability_status", "availability_status_error", "last_checked_at", "last_available_at"}},
+		{"RhcConnection", []string{"availability_status", "availability_status_error", "last_checked_at", "last_available_at"}},
+	}
+
+	for _, tt := range tests {
+		attributes, err := attributesForUpdate(types.StatusMessage{ResourceType: tt.resourceType, ResourceID: "1", Status: m.Available})
+		if err != nil {
+			t.Errorf("%s: want no error, got %s", tt.resourceType, err)
+			continue
+		}
+
+		if len(attributes) != len(tt.want) {
+			t.Errorf("%s: want the attributes %v, got %v", tt.resourceType, tt.want, attributes)
+		}
+
+		for _, column := range tt.want {
+			if _, ok := attributes[column]; !ok {
+				t.Errorf("%s: want the %q attribute, got %v", tt.resourceType, column, attributes)
+			}
+		}
+	}
+}
+
+// TestProcessAvailabilityStatusApplicationAuthentication tests that the status messages about an application
+// authentication, which has no availability status, are rejected without raising any events.
+func TestProcessAvailabilityStatusApplicationAuthentication(t *testing.T) {
+	sender := useRecordingSender(t)
+
+	statusMessage := types.StatusMessage{ResourceType: "ApplicationAuthentication", ResourceID: "1", Status: m.Available}
+	headers := []kafka.Header{{Key: "x-rh-sources-org-id", Value: []byte("12345")}}
+
+	err := ProcessAvailabilityStatus(&Producer, statusMessage, headers, m.AvailabilityStatusOriginStatusMessage)
+	if err == nil || !strings.Contains(err.Error(), "has no availability status") {
+		t.Errorf("want the status message rejected, got %v", err)
+	}
+
+	if len(sender.eventTypes) != 0 {
+		t.Errorf("want no events raised, got %v", sender.eventTypes)
+	}
+}
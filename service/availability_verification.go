@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/internal/types"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/verifiers"
+)
+
+var (
+	builtinVerifiers     *verifiers.Verifiers
+	builtinVerifiersOnce sync.Once
+)
+
+// getVerifiers returns the built-in verifiers, built from the configuration on first use.
+func getVerifiers() *verifiers.Verifiers {
+	builtinVerifiersOnce.Do(func() {
+		builtinVerifiers = verifiers.New(verifiers.ConfigFromEnv())
+	})
+
+	return builtinVerifiers
+}
+
+// verifySource checks the source's credentials, and the endpoints which no dispatcher checks, with the built-in
+// verifiers. The results are written back just like the status messages of the applications.
+func verifySource(v *verifiers.Verifiers, source *m.Source, check *m.AvailabilityCheck) {
+	log := checkLogger(check)
+	if source.Tenant.Id == 0 {
+		log.Warnf("Skipping the verification of source %d: its tenant wasn't loaded", source.ID)
+		return
+	}
+
+	headers := checkHeaders(source, check)
+
+	ctx := loggerContext(log)
+
+	auths, _, err := dao.GetAuthenticationDao(&source.TenantID).WithContext(ctx).ListForSource(source.ID, dao.DEFAULT_LIMIT, 0, nil)
+	if err != nil {
+		log.Errorf("Unable to list the authentications of source %d for their verification: %s", source.ID, err)
+	}
+
+	for i := range auths {
+		if authenticationOfPausedResource(source, &auths[i]) {
+			continue
+		}
+
+		result, ok := v.VerifyAuthentication(ctx, &auths[i])
+		if !ok {
+			continue
+		}
+
+		writeVerification(check, "Authentication", auths[i].ID, result, headers)
+	}
+
+	// the endpoints of the source types with a dispatcher are checked by whoever listens to it.
+	if _, ok := getDispatchers().forSourceType(source.SourceType.Name).(*noopDispatcher); !ok {
+		return
+	}
+
+	for i := range source.Endpoints {
+		endpoint := &source.Endpoints[i]
+
+		// the verification answers the endpoint's target of the check, which was skipped when dispatching.
+		recordDispatch(source, check, "Endpoint", endpoint.ID, 0, nil, false)
+
+		result := v.VerifyEndpoint(ctx, endpoint)
+		if result.Certificate != nil {
+			saveCertificate(ctx, source.TenantID, endpoint.ID, result.Certificate)
+		}
+
+		writeVerification(check, "Endpoint", strconv.FormatInt(endpoint.ID, 10), result, headers)
+	}
+}
+
+// ProbeEndpoint checks the endpoint with the built-in verifier right away, regardless of the "AVAILABILITY_VERIFIERS"
+// setting and of the dispatchers of its source type. The presented certificate and the availability status are
+// recorded just like in the availability checks.
+func ProbeEndpoint(ctx context.Context, endpoint *m.Endpoint, headers []kafka.Header) {
+	result := getVerifiers().VerifyEndpoint(ctx, endpoint)
+	if result.Certificate != nil {
+		saveCertificate(ctx, endpoint.TenantID, endpoint.ID, result.Certificate)
+	}
+
+	writeVerification(nil, "Endpoint", strconv.FormatInt(endpoint.ID, 10), result, headers)
+}
+
+// authenticationOfPausedResource tells whether the authentication belongs to one of the source's paused applications
+// or endpoints, which were removed from the source before the check.
+func authenticationOfPausedResource(source *m.Source, auth *m.Authentication) bool {
+	switch auth.ResourceType {
+	case "Application":
+		for _, app := range source.Applications {
+			if app.ID == auth.ResourceID {
+				return false
+			}
+		}
+
+		return true
+	case "Endpoint":
+		for _, endpoint := range source.Endpoints {
+			if endpoint.ID == auth.ResourceID {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+// saveCertificate records the certificate the endpoint presented.
+func saveCertificate(ctx context.Context, tenantId, endpointId int64, certificate *verifiers.Certificate) {
+	err := dao.GetEndpointDao(&tenantId).WithContext(ctx).SaveCertificate(&m.EndpointCertificate{
+		EndpointID: endpointId,
+		Subject:    certificate.Subject,
+		Issuer:     certificate.Issuer,
+		NotAfter:   certificate.NotAfter,
+	})
+	if err != nil {
+		l.FromContext(ctx).Errorf("Unable to save the certificate of endpoint [%d]: %s", endpointId, err)
+	}
+}
+
+// writeVerification applies the verifier's result to the resource through the same path as the status messages.
+func writeVerification(check *m.AvailabilityCheck, resourceType, resourceId string, result verifiers.Result, headers []kafka.Header) {
+	statusMessage := types.StatusMessage{
+		ResourceType: resourceType,
+		ResourceID:   resourceId,
+		Status:       result.Status,
+		Error:        result.Error,
+	}
+
+	if check != nil {
+		statusMessage.AvailabilityCheckID = strconv.FormatInt(check.ID, 10)
+	}
+
+	log := headersLogger(headers)
+	log.Infof("Verified %s [%s]: %s", resourceType, resourceId, result.Status)
+
+	err := ProcessAvailabilityStatus(&Producer, statusMessage, headers, m.AvailabilityStatusOriginVerifier)
+	if err != nil {
+		log.Errorf("Unable to write the verification of %s [%s]: %s", resourceType, resourceId, err)
+	}
+}
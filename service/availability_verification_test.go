@@ -0,0 +1,58 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/verifiers"
+)
+
+// TestVerifySourceWritesEndpointStatus tests that the endpoint's verification is written back to the endpoint, as if
+// it came in a status message.
+func TestVerifySourceWritesEndpointStatus(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	sender := useRecordingSender(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	serverUrl, _ := url.Parse(server.URL)
+	host, rawPort, _ := net.SplitHostPort(serverUrl.Host)
+	port, _ := strconv.Atoi(rawPort)
+	scheme := "http"
+
+	endpoint := fixtures.TestEndpointData[0]
+	endpoint.Scheme = &scheme
+	endpoint.Host = &host
+	endpoint.Port = &port
+
+	source := &m.Source{
+		ID:         endpoint.SourceID,
+		TenantID:   fixtures.TestTenantData[0].Id,
+		Tenant:     fixtures.TestTenantData[0],
+		SourceType: m.SourceType{Name: "unknown"},
+		Endpoints:  []m.Endpoint{endpoint},
+	}
+
+	verifySource(verifiers.New(verifiers.Config{AllowPrivateEndpoints: true}), source, nil)
+
+	updated, err := endpointDao.GetById(&endpoint.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.AvailabilityStatus.AvailabilityStatus != m.Available {
+		t.Errorf("want the endpoint to be available, got %q", updated.AvailabilityStatus.AvailabilityStatus)
+	}
+
+	if len(sender.eventTypes) == 0 || sender.eventTypes[0] != "Endpoint.update" {
+		t.Errorf("want an Endpoint.update event, got %v", sender.eventTypes)
+	}
+}
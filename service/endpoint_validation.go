@@ -1,6 +1,9 @@
 package service
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"regexp"
 	"strings"
@@ -63,19 +66,9 @@ func ValidateEndpointCreateRequest(dao dao.EndpointDao, ecr *model.EndpointCreat
 	}
 
 	if ecr.Host != "" {
-		if utf8.RuneCountInString(ecr.Host) > maxFqdnLength {
-			return fmt.Errorf("the provided host is longer than %d characters", maxFqdnLength)
-		}
-
-		if !fqdnRegexp.MatchString(ecr.Host) {
-			return fmt.Errorf("the provided host is invalid")
-		}
-
-		labels := strings.Split(ecr.Host, ".")
-		for _, label := range labels {
-			if utf8.RuneCountInString(ecr.Host) > maxLabelLength {
-				return fmt.Errorf("the label '%s' is greater than %d characters", label, maxLabelLength)
-			}
+		err := validateHost(ecr.Host)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -97,9 +90,123 @@ func ValidateEndpointCreateRequest(dao dao.EndpointDao, ecr *model.EndpointCreat
 		return fmt.Errorf("the certificate authority cannot be empty")
 	}
 
+	if ecr.CertificateAuthority != nil && *ecr.CertificateAuthority != "" {
+		err := validateCertificateAuthority(*ecr.CertificateAuthority)
+		if err != nil {
+			return err
+		}
+	}
+
 	if !util.SliceContainsString(validAvailabilityStatuses, ecr.AvailabilityStatus) {
 		return fmt.Errorf("invalid availability status")
 	}
 
 	return nil
 }
+
+// ValidateEndpointEditRequest validates the changes of the endpoint's update against the endpoint they are applied to.
+func ValidateEndpointEditRequest(dao dao.EndpointDao, endpoint *model.Endpoint, eer *model.EndpointEditRequest) error {
+	if eer.Default != nil && *eer.Default && (endpoint.Default == nil || !*endpoint.Default) && !dao.CanEndpointBeSetAsDefaultForSource(endpoint.SourceID) {
+		return fmt.Errorf("a default endpoint already exists for the provided source")
+	}
+
+	if eer.Role != nil && (endpoint.Role == nil || *eer.Role != *endpoint.Role) && !dao.IsRoleUniqueForSource(*eer.Role, endpoint.SourceID) {
+		return fmt.Errorf("the role already exists for the given source")
+	}
+
+	if eer.Scheme != nil && !schemeRegexp.MatchString(*eer.Scheme) {
+		return fmt.Errorf("the provided scheme is invalid")
+	}
+
+	if eer.Host != nil && *eer.Host != "" {
+		err := validateHost(*eer.Host)
+		if err != nil {
+			return err
+		}
+	}
+
+	if eer.Port != nil && (*eer.Port <= 0 || *eer.Port > maxPort) {
+		return fmt.Errorf("invalid port number")
+	}
+
+	// the combination of both settings is only checked when any of them changes, so that the existing endpoints can
+	// still be edited.
+	if eer.VerifySsl != nil || eer.CertificateAuthority != nil {
+		verifySsl := endpoint.VerifySsl == nil || *endpoint.VerifySsl
+		if eer.VerifySsl != nil {
+			verifySsl = *eer.VerifySsl
+		}
+
+		certificateAuthority := endpoint.CertificateAuthority
+		if eer.CertificateAuthority != nil {
+			certificateAuthority = eer.CertificateAuthority
+		}
+
+		if verifySsl && (certificateAuthority == nil || *certificateAuthority == "") {
+			return fmt.Errorf("the certificate authority cannot be empty")
+		}
+	}
+
+	if eer.CertificateAuthority != nil && *eer.CertificateAuthority != "" {
+		err := validateCertificateAuthority(*eer.CertificateAuthority)
+		if err != nil {
+			return err
+		}
+	}
+
+	if eer.AvailabilityStatus != nil && !util.SliceContainsString(validAvailabilityStatuses, *eer.AvailabilityStatus) {
+		return fmt.Errorf("invalid availability status")
+	}
+
+	return nil
+}
+
+func validateHost(host string) error {
+	if utf8.RuneCountInString(host) > maxFqdnLength {
+		return fmt.Errorf("the provided host is longer than %d characters", maxFqdnLength)
+	}
+
+	if !fqdnRegexp.MatchString(host) {
+		return fmt.Errorf("the provided host is invalid")
+	}
+
+	labels := strings.Split(host, ".")
+	for _, label := range labels {
+		if utf8.RuneCountInString(label) > maxLabelLength {
+			return fmt.Errorf("the label '%s' is greater than %d characters", label, maxLabelLength)
+		}
+	}
+
+	return nil
+}
+
+// validateCertificateAuthority checks that the certificate authority is a bundle of one or more PEM encoded
+// certificates, and nothing else.
+func validateCertificateAuthority(bundle string) error {
+	rest := []byte(bundle)
+	certificates := 0
+
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("the certificate authority contains a %q block instead of a certificate", block.Type)
+		}
+
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("the certificate authority contains an invalid certificate: %s", err)
+		}
+
+		certificates++
+	}
+
+	if certificates == 0 || len(bytes.TrimSpace(rest)) != 0 {
+		return fmt.Errorf("the certificate authority must be a PEM encoded certificate bundle")
+	}
+
+	return nil
+}
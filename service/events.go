@@ -5,7 +5,6 @@ import (
 
 	"github.com/RedHatInsights/sources-api-go/internal/events"
 	"github.com/RedHatInsights/sources-api-go/kafka"
-	l "github.com/RedHatInsights/sources-api-go/logger"
 	"github.com/RedHatInsights/sources-api-go/model"
 	"github.com/RedHatInsights/sources-api-go/util"
 	"github.com/labstack/echo/v4"
@@ -26,7 +25,7 @@ func RaiseEvent(eventType string, resource model.Event, headers []kafka.Header)
 	headers = append(headers, kafka.Header{Key: "event_type", Value: []byte(eventType)})
 	err = Producer.RaiseEvent(eventType, msg, headers)
 	if err != nil {
-		l.Log.Warnf("failed to raise event to kafka: %v", err)
+		headersLogger(headers).Warnf("failed to raise event to kafka: %v", err)
 		return nil
 	}
 
@@ -35,15 +34,19 @@ func RaiseEvent(eventType string, resource model.Event, headers []kafka.Header)
 
 // ForwadableHeaders fetches the required identity headers from the request that are needed to forward along:
 // 	1. x-rh-identity -- a generated one if it wasn't passed along (e.g. psk)
-//	2. x-rh-sources-psk -- always passed if present, and used for generation.
+//	2. x-rh-sources-account-number and x-rh-sources-org-id -- always passed if present, and used for generation.
+//	3. x-rh-insights-request-id -- the request's id, to follow it through the services which consume the events.
 func ForwadableHeaders(c echo.Context) []kafka.Header {
 	headers := make([]kafka.Header, 0)
 
-	if c.Get("psk-account") != nil {
-		psk, ok := c.Get("psk-account").(string)
-		if ok {
-			headers = append(headers, kafka.Header{Key: "x-rh-sources-account-number", Value: []byte(psk)})
-		}
+	pskAccount, accountOk := c.Get("psk-account").(string)
+	if accountOk {
+		headers = append(headers, kafka.Header{Key: "x-rh-sources-account-number", Value: []byte(pskAccount)})
+	}
+
+	pskOrgId, orgIdOk := c.Get("psk-org-id").(string)
+	if orgIdOk {
+		headers = append(headers, kafka.Header{Key: "x-rh-sources-org-id", Value: []byte(pskOrgId)})
 	}
 
 	if c.Get("x-rh-identity") != nil {
@@ -51,17 +54,38 @@ func ForwadableHeaders(c echo.Context) []kafka.Header {
 		if ok {
 			headers = append(headers, kafka.Header{Key: "x-rh-identity", Value: []byte(xrhid)})
 		}
-	} else {
-		psk, ok := c.Get("psk-account").(string)
-		if ok {
-			// the only way this would be nil is if psk auth was used - so lets
-			// generate a dummy header for services that still rely on it.
-			headers = append(headers, kafka.Header{
-				Key:   "x-rh-identity",
-				Value: []byte(util.XRhIdentityWithAccountNumber(psk)),
-			})
-		}
+	} else if accountOk || orgIdOk {
+		// the only way this would be nil is if psk auth was used - so lets
+		// generate a dummy header for services that still rely on it.
+		headers = append(headers, kafka.Header{
+			Key:   "x-rh-identity",
+			Value: []byte(util.GeneratedXRhIdentity(pskAccount, pskOrgId)),
+		})
+	}
+
+	requestId, ok := c.Get("request-id").(string)
+	if ok && requestId != "" {
+		headers = append(headers, kafka.Header{Key: util.RequestIdHeader, Value: []byte(requestId)})
 	}
 
 	return headers
 }
+
+// TenantHeaders returns the headers that identify the tenant in the messages which aren't sent on behalf of a
+// request: its account number, its organization id, and an identity generated out of them.
+func TenantHeaders(tenant *model.Tenant) []kafka.Header {
+	headers := make([]kafka.Header, 0, 3)
+
+	if tenant.ExternalTenant != "" {
+		headers = append(headers, kafka.Header{Key: "x-rh-sources-account-number", Value: []byte(tenant.ExternalTenant)})
+	}
+
+	if tenant.OrgID != "" {
+		headers = append(headers, kafka.Header{Key: "x-rh-sources-org-id", Value: []byte(tenant.OrgID)})
+	}
+
+	return append(headers, kafka.Header{
+		Key:   "x-rh-identity",
+		Value: []byte(util.GeneratedXRhIdentity(tenant.ExternalTenant, tenant.OrgID)),
+	})
+}
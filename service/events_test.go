@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/request"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+func TestTenantHeaders(t *testing.T) {
+	headers := TenantHeaders(&m.Tenant{ExternalTenant: "12345", OrgID: "67890"})
+
+	accountNumber, orgId, err := util.TenantFromHeaders(headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if accountNumber != "12345" || orgId != "67890" {
+		t.Errorf(`want "12345" and "67890", got %q and %q`, accountNumber, orgId)
+	}
+
+	// the identity alone identifies the tenant as well.
+	accountNumber, orgId, _ = util.TenantFromHeaders([]kafka.Header{headers[2]})
+	if accountNumber != "12345" || orgId != "67890" {
+		t.Errorf(`want "12345" and "67890" in the identity, got %q and %q`, accountNumber, orgId)
+	}
+
+	// the tenants without account number don't get an empty account number header.
+	headers = TenantHeaders(&m.Tenant{OrgID: "67890"})
+	if len(headers) != 2 || headers[0].Key != "x-rh-sources-org-id" {
+		t.Errorf("unexpected headers: %+v", headers)
+	}
+}
+
+// TestForwadableHeadersRequestId tests that the request id gets forwarded along with the identity headers.
+func TestForwadableHeadersRequestId(t *testing.T) {
+	c, _ := request.CreateTestContext("GET", "/", nil, map[string]interface{}{
+		"x-rh-identity": "identity",
+		"request-id":    "request-id",
+	})
+
+	headers := ForwadableHeaders(c)
+	if got := util.RequestIdFromHeaders(headers); got != "request-id" {
+		t.Errorf(`want the request id "request-id" forwarded, got %q in %+v`, got, headers)
+	}
+
+	c, _ = request.CreateTestContext("GET", "/", nil, map[string]interface{}{"x-rh-identity": "identity"})
+	if headers = ForwadableHeaders(c); len(headers) != 1 {
+		t.Errorf("want only the identity header without a request id, got %+v", headers)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+)
+
+// orgIdBackfillBatchSize is the number of account numbers that get translated at once.
+const orgIdBackfillBatchSize = 100
+
+// OrgIdTranslator maps the account numbers to the org ids of the organizations they belong to.
+type OrgIdTranslator interface {
+	// OrgIds returns the org ids of the given account numbers. The account numbers it doesn't know about are left out.
+	OrgIds(ctx context.Context, accountNumbers []string) (map[string]string, error)
+}
+
+// TenantTranslator asks the platform's tenant translator service for the org ids.
+type TenantTranslator struct {
+	Url    string
+	Client *http.Client
+}
+
+// NewTenantTranslator returns a translator which talks to the tenant translator service at the given URL.
+func NewTenantTranslator(url string) *TenantTranslator {
+	return &TenantTranslator{Url: strings.TrimSuffix(url, "/"), Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// OrgIds posts the account numbers to the translator, which answers with the account numbers mapped to their org ids.
+func (tt *TenantTranslator) OrgIds(ctx context.Context, accountNumbers []string) (map[string]string, error) {
+	body, err := json.Marshal(accountNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tt.Url+"/internal/orgIds", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := tt.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("the tenant translator answered with status %d: %s", resp.StatusCode, raw)
+	}
+
+	orgIds := make(map[string]string)
+	err = json.NewDecoder(resp.Body).Decode(&orgIds)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the tenant translator's response: %w", err)
+	}
+
+	return orgIds, nil
+}
+
+// OrgIdBackfillResult holds how many tenants got their org id, and how many couldn't be mapped to one.
+type OrgIdBackfillResult struct {
+	Updated  int
+	Unmapped int
+}
+
+// BackfillOrgIds sets the org id of the tenants which only have an account number, by translating their account
+// numbers in batches. The tenants whose account numbers can't be translated, or whose org id can't be set, are left
+// untouched and counted as unmapped, and the backfill stops when the context gets cancelled.
+func BackfillOrgIds(ctx context.Context, translator OrgIdTranslator) (*OrgIdBackfillResult, error) {
+	result := &OrgIdBackfillResult{}
+	tenantDao := dao.GetTenantDao()
+
+	var lastId int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		tenants, err := tenantDao.ListWithoutOrgId(lastId, orgIdBackfillBatchSize)
+		if err != nil {
+			return result, fmt.Errorf("unable to list the tenants without org id: %w", err)
+		}
+
+		if len(tenants) == 0 {
+			return result, nil
+		}
+
+		accountNumbers := make([]string, len(tenants))
+		for i, tenant := range tenants {
+			accountNumbers[i] = tenant.ExternalTenant
+		}
+
+		orgIds, err := translator.OrgIds(ctx, accountNumbers)
+		if err != nil {
+			return result, fmt.Errorf("unable to translate the account numbers: %w", err)
+		}
+
+		for _, tenant := range tenants {
+			orgId := orgIds[tenant.ExternalTenant]
+			if orgId == "" {
+				l.Log.Warnf("Unable to backfill the org id of tenant %d: account number %q has no org id", tenant.Id, tenant.ExternalTenant)
+				result.Unmapped++
+				continue
+			}
+
+			// another tenant might already have the org id, which has to be sorted out by hand.
+			err = tenantDao.SetOrgId(tenant.Id, orgId)
+			if err != nil {
+				l.Log.Warnf("Unable to backfill the org id of tenant %d: unable to set org id %q for account number %q: %s", tenant.Id, orgId, tenant.ExternalTenant, err)
+				result.Unmapped++
+				continue
+			}
+
+			result.Updated++
+		}
+
+		lastId = tenants[len(tenants)-1].Id
+	}
+}
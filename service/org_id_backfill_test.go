@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// backfillTenantDao holds the tenants in memory, to be able to run the backfill without a database.
+type backfillTenantDao struct {
+	dao.TenantDao
+	tenants []m.Tenant
+	// conflicting are the ids of the tenants whose org id is already taken by another tenant.
+	conflicting map[int64]bool
+}
+
+func (b *backfillTenantDao) ListWithoutOrgId(afterId int64, limit int) ([]m.Tenant, error) {
+	tenants := make([]m.Tenant, 0)
+	for _, tenant := range b.tenants {
+		if tenant.Id > afterId && tenant.OrgID == "" && tenant.ExternalTenant != "" && len(tenants) < limit {
+			tenants = append(tenants, tenant)
+		}
+	}
+
+	return tenants, nil
+}
+
+func (b *backfillTenantDao) SetOrgId(id int64, orgId string) error {
+	if b.conflicting[id] {
+		return errors.New(`duplicate key value violates unique constraint "index_tenants_on_org_id_unique"`)
+	}
+
+	for i := range b.tenants {
+		if b.tenants[i].Id == id {
+			b.tenants[i].OrgID = orgId
+		}
+	}
+
+	return nil
+}
+
+func TestBackfillOrgIds(t *testing.T) {
+	tenantDao := &backfillTenantDao{}
+	for i := int64(1); i <= orgIdBackfillBatchSize+10; i++ {
+		tenantDao.tenants = append(tenantDao.tenants, m.Tenant{Id: i, ExternalTenant: "account"})
+	}
+	tenantDao.tenants[0].ExternalTenant = "unknown"
+	tenantDao.tenants[1].OrgID = "already set"
+
+	original := dao.GetTenantDao
+	dao.GetTenantDao = func() dao.TenantDao { return tenantDao }
+	defer func() { dao.GetTenantDao = original }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Method != http.MethodPost || r.URL.Path != "/internal/orgIds" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var accountNumbers []string
+		if err := json.NewDecoder(r.Body).Decode(&accountNumbers); err != nil {
+			t.Error(err)
+		}
+
+		orgIds := make(map[string]string)
+		for _, accountNumber := range accountNumbers {
+			if accountNumber == "account" {
+				orgIds[accountNumber] = "org"
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(orgIds)
+	}))
+	defer server.Close()
+
+	result, err := BackfillOrgIds(context.Background(), NewTenantTranslator(server.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Updated != orgIdBackfillBatchSize+8 || result.Unmapped != 1 {
+		t.Errorf("want %d updated and 1 unmapped tenants, got %+v", orgIdBackfillBatchSize+8, result)
+	}
+
+	if requests != 2 {
+		t.Errorf("want the account numbers translated in 2 batches, got %d", requests)
+	}
+
+	if tenantDao.tenants[0].OrgID != "" || tenantDao.tenants[1].OrgID != "already set" || tenantDao.tenants[2].OrgID != "org" {
+		t.Errorf("unexpected org ids: %+v", tenantDao.tenants[:3])
+	}
+}
+
+func TestBackfillOrgIdsTranslatorError(t *testing.T) {
+	tenantDao := &backfillTenantDao{tenants: []m.Tenant{{Id: 1, ExternalTenant: "account"}}}
+
+	original := dao.GetTenantDao
+	dao.GetTenantDao = func() dao.TenantDao { return tenantDao }
+	defer func() { dao.GetTenantDao = original }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := BackfillOrgIds(context.Background(), NewTenantTranslator(server.URL))
+	if err == nil {
+		t.Errorf("want an error, got none")
+	}
+
+	if tenantDao.tenants[0].OrgID != "" {
+		t.Errorf("want the org id untouched, got %q", tenantDao.tenants[0].OrgID)
+	}
+}
+
+// TestBackfillOrgIdsConflict tests that the tenants whose org id can't be set are counted as unmapped, and that the
+// backfill goes on with the rest of them.
+func TestBackfillOrgIdsConflict(t *testing.T) {
+	tenantDao := &backfillTenantDao{
+		tenants:     []m.Tenant{{Id: 1, ExternalTenant: "account"}, {Id: 2, ExternalTenant: "account"}, {Id: 3, ExternalTenant: "account"}},
+		conflicting: map[int64]bool{2: true},
+	}
+
+	original := dao.GetTenantDao
+	dao.GetTenantDao = func() dao.TenantDao { return tenantDao }
+	defer func() { dao.GetTenantDao = original }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"account": "org"})
+	}))
+	defer server.Close()
+
+	result, err := BackfillOrgIds(context.Background(), NewTenantTranslator(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Updated != 2 || result.Unmapped != 1 {
+		t.Errorf("want 2 updated and 1 unmapped tenants, got %+v", result)
+	}
+
+	if tenantDao.tenants[1].OrgID != "" || tenantDao.tenants[2].OrgID != "org" {
+		t.Errorf("unexpected org ids: %+v", tenantDao.tenants)
+	}
+}
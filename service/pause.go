@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// The types of the resources which can be paused and resumed on their own.
+const (
+	PausedSource                    = "Source"
+	PausedApplication               = "Application"
+	PausedEndpoint                  = "Endpoint"
+	PausedApplicationAuthentication = "ApplicationAuthentication"
+)
+
+// A paused resource stays as it is until it gets unpaused: the API rejects any change to it, or to what belongs to it,
+// its availability doesn't get checked, and the status messages about it are discarded. Deleting it is still
+// allowed, since that doesn't require touching the resource's state. The resources belonging to a paused source are
+// paused along with it, and so are the application authentications of a paused application.
+
+// pausedNames are the names the paused resources are referred to by in the error messages.
+var pausedNames = map[string]string{
+	PausedSource:                    "source",
+	PausedApplication:               "application",
+	PausedEndpoint:                  "endpoint",
+	PausedApplicationAuthentication: "application authentication",
+}
+
+// CheckNotPaused returns a conflict error when the given resource is paused, either by itself or through the
+// resource it belongs to. The resources which can't be paused never are.
+func CheckNotPaused(tenantId int64, resourceType string, resourceId int64) error {
+	pausedType, pausedId, err := pausedBy(tenantId, resourceType, resourceId)
+	if err != nil {
+		return err
+	}
+
+	if pausedType == "" {
+		return nil
+	}
+
+	return util.NewErrConflict(fmt.Sprintf("%s %d is paused, it must be unpaused first", pausedNames[pausedType], pausedId))
+}
+
+// pausedBy returns the paused resource that keeps the given resource from changing, which is either the resource
+// itself or the one it belongs to. An empty type is returned when neither of them is paused.
+func pausedBy(tenantId int64, resourceType string, resourceId int64) (string, int64, error) {
+	var pausedAt time.Time
+	var parentType string
+	var parentId int64
+
+	switch resourceType {
+	case PausedSource:
+		source, err := dao.GetSourceDao(&tenantId).GetById(&resourceId)
+		if err != nil {
+			return "", 0, err
+		}
+
+		pausedAt = source.PausedAt
+	case PausedApplication:
+		application, err := dao.GetApplicationDao(&tenantId).GetById(&resourceId)
+		if err != nil {
+			return "", 0, err
+		}
+
+		pausedAt, parentType, parentId = application.PausedAt, PausedSource, application.SourceID
+	case PausedEndpoint:
+		endpoint, err := dao.GetEndpointDao(&tenantId).GetById(&resourceId)
+		if err != nil {
+			return "", 0, err
+		}
+
+		pausedAt, parentType, parentId = endpoint.PausedAt, PausedSource, endpoint.SourceID
+	case PausedApplicationAuthentication:
+		appAuth, err := dao.GetApplicationAuthenticationDao(&tenantId).GetById(&resourceId)
+		if err != nil {
+			return "", 0, err
+		}
+
+		pausedAt, parentType, parentId = appAuth.PausedAt, PausedApplication, appAuth.ApplicationID
+	default:
+		return "", 0, nil
+	}
+
+	if !pausedAt.IsZero() {
+		return resourceType, resourceId, nil
+	}
+
+	if parentType == "" {
+		return "", 0, nil
+	}
+
+	return pausedBy(tenantId, parentType, parentId)
+}
+
+// authenticationPaused tells whether the resource the authentication belongs to is paused.
+func authenticationPaused(tenantId int64, uid string) (bool, error) {
+	auth, err := dao.GetAuthenticationDao(&tenantId).GetById(uid)
+	if err != nil {
+		return false, err
+	}
+
+	pausedType, _, err := pausedBy(tenantId, auth.ResourceType, auth.ResourceID)
+
+	return pausedType != "", err
+}
+
+// dropPausedResources removes the paused applications and endpoints from the source, so that their availability
+// doesn't get checked.
+func dropPausedResources(source *m.Source) {
+	applications := source.Applications[:0]
+	for _, app := range source.Applications {
+		if app.PausedAt.IsZero() {
+			applications = append(applications, app)
+		}
+	}
+	source.Applications = applications
+
+	endpoints := source.Endpoints[:0]
+	for _, endpoint := range source.Endpoints {
+		if endpoint.PausedAt.IsZero() {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	source.Endpoints = endpoints
+}
+
+// ValidatePauseRequest checks that the resource isn't asked to be resumed in the past.
+func ValidatePauseRequest(req *m.PauseRequest) error {
+	if req.ResumeAt != nil && !req.ResumeAt.After(time.Now()) {
+		return errors.New("resume_at must be in the future")
+	}
+
+	return nil
+}
+
+// ScheduleResume schedules the resumption of the paused resource at the given time. When no time is given, the
+// resource's schedule is cancelled instead, since it was either paused indefinitely or resumed by hand.
+func ScheduleResume(tenantId int64, resourceType string, resourceId int64, resumeAt *time.Time) error {
+	scheduledResumeDao := dao.GetScheduledResumeDao(&tenantId)
+
+	if resumeAt == nil {
+		return scheduledResumeDao.Cancel(resourceType, resourceId)
+	}
+
+	return scheduledResumeDao.Schedule(resourceType, resourceId, *resumeAt)
+}
+
+// ResumeScheduled resumes the resource of the given schedule, raises its "Unpause" events and removes the schedule.
+// The schedule must have its tenant loaded, since the events carry the tenant's headers.
+func ResumeScheduled(entry *m.ScheduledResume) error {
+	headers := TenantHeaders(&entry.Tenant)
+
+	var err error
+	switch entry.ResourceType {
+	case PausedSource:
+		err = resumeSource(entry.TenantID, entry.ResourceID, headers)
+	case PausedApplication:
+		applicationDao := dao.GetApplicationDao(&entry.TenantID)
+		if err = applicationDao.Resume(entry.ResourceID); err != nil {
+			break
+		}
+
+		var application *m.Application
+		if application, err = applicationDao.GetById(&entry.ResourceID); err != nil {
+			break
+		}
+
+		err = RaiseEvent("Application.Unpause", application, headers)
+	case PausedEndpoint:
+		endpointDao := dao.GetEndpointDao(&entry.TenantID)
+		if err = endpointDao.Resume(entry.ResourceID); err != nil {
+			break
+		}
+
+		var endpoint *m.Endpoint
+		if endpoint, err = endpointDao.GetById(&entry.ResourceID); err != nil {
+			break
+		}
+
+		err = RaiseEvent("Endpoint.Unpause", endpoint, headers)
+	case PausedApplicationAuthentication:
+		appAuthDao := dao.GetApplicationAuthenticationDao(&entry.TenantID)
+		if err = appAuthDao.Resume(entry.ResourceID); err != nil {
+			break
+		}
+
+		var appAuth *m.ApplicationAuthentication
+		if appAuth, err = appAuthDao.GetById(&entry.ResourceID); err != nil {
+			break
+		}
+
+		err = RaiseEvent("ApplicationAuthentication.Unpause", appAuth, headers)
+	default:
+		err = fmt.Errorf("unable to resume resources of type %q", entry.ResourceType)
+	}
+
+	// a resource which no longer exists has nothing left to resume.
+	if err != nil && !errors.Is(err, util.ErrNotFoundEmpty) {
+		return err
+	}
+
+	return dao.GetScheduledResumeDao(&entry.TenantID).Done(entry)
+}
+
+// resumeSource resumes the source along with its applications, and raises their "Unpause" events.
+func resumeSource(tenantId, sourceId int64, headers []kafka.Header) error {
+	sourceDao := dao.GetSourceDao(&tenantId)
+
+	err := sourceDao.Resume(sourceId)
+	if err != nil {
+		return err
+	}
+
+	source, err := sourceDao.GetByIdWithPreload(&sourceId, "Applications")
+	if err != nil {
+		return err
+	}
+
+	err = RaiseEvent("Source.Unpause", source, headers)
+	if err != nil {
+		return err
+	}
+
+	for i := range source.Applications {
+		err = RaiseEvent("Application.Unpause", &source.Applications[i], headers)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
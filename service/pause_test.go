@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	"github.com/RedHatInsights/sources-api-go/internal/types"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+)
+
+// TestCheckNotPaused tests that the resources are paused by themselves or through the resources they belong to.
+func TestCheckNotPaused(t *testing.T) {
+	paused := m.Pause{PausedAt: time.Now()}
+
+	sourceDao := &dao.MockSourceDao{Sources: []m.Source{{ID: 1}, {ID: 2, Pause: paused}}}
+	applicationDao := &dao.MockApplicationDao{Applications: []m.Application{
+		{ID: 1, SourceID: 1},
+		{ID: 2, SourceID: 1, Pause: paused},
+		{ID: 3, SourceID: 2},
+	}}
+	appAuthDao := &dao.MockApplicationAuthenticationDao{ApplicationAuthentications: []m.ApplicationAuthentication{
+		{ID: 1, ApplicationID: 1},
+		{ID: 2, ApplicationID: 2},
+	}}
+
+	previousSourceDao, previousApplicationDao, previousAppAuthDao := dao.GetSourceDao, dao.GetApplicationDao, dao.GetApplicationAuthenticationDao
+	dao.GetSourceDao = func(_ *int64) dao.SourceDao { return sourceDao }
+	dao.GetApplicationDao = func(_ *int64) dao.ApplicationDao { return applicationDao }
+	dao.GetApplicationAuthenticationDao = func(_ *int64) dao.ApplicationAuthenticationDao { return appAuthDao }
+	defer func() {
+		dao.GetSourceDao, dao.GetApplicationDao, dao.GetApplicationAuthenticationDao = previousSourceDao, previousApplicationDao, previousAppAuthDao
+	}()
+
+	testCases := []struct {
+		resourceType string
+		resourceId   int64
+		pausedBy     string
+	}{
+		{resourceType: PausedSource, resourceId: 1},
+		{resourceType: PausedSource, resourceId: 2, pausedBy: "source 2"},
+		{resourceType: PausedApplication, resourceId: 1},
+		{resourceType: PausedApplication, resourceId: 2, pausedBy: "application 2"},
+		{resourceType: PausedApplication, resourceId: 3, pausedBy: "source 2"},
+		{resourceType: PausedApplicationAuthentication, resourceId: 1},
+		{resourceType: PausedApplicationAuthentication, resourceId: 2, pausedBy: "application 2"},
+		{resourceType: "RhcConnection", resourceId: 1},
+	}
+
+	for _, tc := range testCases {
+		err := CheckNotPaused(1, tc.resourceType, tc.resourceId)
+
+		switch {
+		case tc.pausedBy == "" && err != nil:
+			t.Errorf("%s %d: want no error, got %s", tc.resourceType, tc.resourceId, err)
+		case tc.pausedBy != "" && !errors.Is(err, util.ErrConflictEmpty):
+			t.Errorf("%s %d: want a conflict error, got %v", tc.resourceType, tc.resourceId, err)
+		case tc.pausedBy != "" && !strings.Contains(err.Error(), tc.pausedBy+" is paused"):
+			t.Errorf("%s %d: want %q paused, got %s", tc.resourceType, tc.resourceId, tc.pausedBy, err)
+		}
+	}
+}
+
+func TestValidatePauseRequest(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		resumeAt *time.Time
+		valid    bool
+	}{
+		{resumeAt: nil, valid: true},
+		{resumeAt: &future, valid: true},
+		{resumeAt: &past, valid: false},
+	}
+
+	for _, tc := range testCases {
+		err := ValidatePauseRequest(&m.PauseRequest{ResumeAt: tc.resumeAt})
+		if (err == nil) != tc.valid {
+			t.Errorf("resume_at %v: want valid %t, got error %v", tc.resumeAt, tc.valid, err)
+		}
+	}
+}
+
+// TestResumeScheduledEndpoint tests that a due endpoint gets resumed, that its event gets raised and that its
+// schedule gets removed.
+func TestResumeScheduledEndpoint(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	sender := useRecordingSender(t)
+
+	endpointId := fixtures.TestEndpointData[0].ID
+	tenantId := fixtures.TestTenantData[0].Id
+
+	err := endpointDao.Pause(endpointId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumeAt := time.Now().Add(-time.Second)
+	err = ScheduleResume(tenantId, PausedEndpoint, endpointId, &resumeAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := dao.GetScheduledResumeDao(nil).ListDue(time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(due) != 1 || due[0].ResourceID != endpointId || due[0].Tenant.ExternalTenant != fixtures.TestTenantData[0].ExternalTenant {
+		t.Fatalf("want the endpoint's schedule along with its tenant, got %+v", due)
+	}
+
+	err = ResumeScheduled(&due[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint, err := endpointDao.GetById(&endpointId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !endpoint.PausedAt.IsZero() {
+		t.Errorf("want the endpoint resumed, got paused at %s", endpoint.PausedAt)
+	}
+
+	if len(sender.eventTypes) != 1 || sender.eventTypes[0] != "Endpoint.Unpause" {
+		t.Errorf("want an Endpoint.Unpause event, got %v", sender.eventTypes)
+	}
+
+	due, err = dao.GetScheduledResumeDao(nil).ListDue(time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(due) != 0 {
+		t.Errorf("want the schedule removed, got %+v", due)
+	}
+}
+
+// TestDiscardPausedStatus tests that the status messages about a paused resource don't change it.
+func TestDiscardPausedStatus(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	sender := useRecordingSender(t)
+
+	endpointId := fixtures.TestEndpointData[0].ID
+	err := endpointDao.Pause(endpointId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = endpointDao.Resume(endpointId) }()
+
+	before, err := endpointDao.GetById(&endpointId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusMessage := types.StatusMessage{ResourceType: "Endpoint", ResourceID: strconv.FormatInt(endpointId, 10), Status: m.Unavailable}
+	headers := []kafka.Header{{Key: "x-rh-sources-account-number", Value: []byte(fixtures.TestTenantData[0].ExternalTenant)}}
+
+	err = ProcessAvailabilityStatus(&Producer, statusMessage, headers, m.AvailabilityStatusOriginStatusMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := endpointDao.GetById(&endpointId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after.AvailabilityStatus.AvailabilityStatus != before.AvailabilityStatus.AvailabilityStatus {
+		t.Errorf("want the paused endpoint's status unchanged, got %q", after.AvailabilityStatus.AvailabilityStatus)
+	}
+
+	if len(sender.eventTypes) != 0 {
+		t.Errorf("want no events for the paused endpoint, got %v", sender.eventTypes)
+	}
+}
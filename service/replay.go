@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"golang.org/x/time/rate"
+)
+
+// ReplayHeader is the header that marks the events as replayed, so that the consumers can tell them apart from the
+// events that are raised when the resources actually change.
+const ReplayHeader = "replay"
+
+// backgroundReplays tracks the replays requested through the API, which get cancelled on shutdown.
+var (
+	backgroundReplays       sync.WaitGroup
+	backgroundReplaysCtx    context.Context
+	cancelBackgroundReplays context.CancelFunc
+)
+
+func init() {
+	backgroundReplaysCtx, cancelBackgroundReplays = context.WithCancel(context.Background())
+}
+
+// ReplayOptions holds the settings of a replay.
+type ReplayOptions struct {
+	// ApplicationTypeId limits the replay to the sources which have an application of the given type, and to the
+	// applications of that type, along with their authentications. Zero means no filtering.
+	ApplicationTypeId int64
+	// EventsPerSecond is the maximum rate at which the events are sent. Zero or less means no limit.
+	EventsPerSecond int
+}
+
+// ReplayResult holds how many events were sent for each resource type.
+type ReplayResult struct {
+	Sources         int `json:"sources"`
+	Endpoints       int `json:"endpoints"`
+	Applications    int `json:"applications"`
+	Authentications int `json:"authentications"`
+	Records         int `json:"records"`
+}
+
+// ApplicationTypeIdFrom returns the application type's id from either its id or its name. The type cache must be
+// populated to be able to look up the names.
+func ApplicationTypeIdFrom(value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		return id, nil
+	}
+
+	id = dao.Static.GetApplicationTypeId(value)
+	if id == 0 {
+		return 0, util.NewErrNotFound("application type")
+	}
+
+	return id, nil
+}
+
+// ReplayTenant re-emits the current state of the given tenant to the event stream: a "create" event for every
+// source, endpoint, application and authentication, followed by a "Records.create" bulk message for every source.
+// The events are marked with the "replay" header, and are sent at the rate set in the options. The replay stops when
+// the context gets cancelled.
+func ReplayTenant(ctx context.Context, tenant *m.Tenant, opts ReplayOptions) (*ReplayResult, error) {
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if opts.EventsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.EventsPerSecond), 1)
+	}
+
+	replayer := &tenantReplayer{
+		ctx:     ctx,
+		limiter: limiter,
+		result:  &ReplayResult{},
+		headers: append(TenantHeaders(tenant), kafka.Header{Key: ReplayHeader, Value: []byte("true")}),
+	}
+
+	l.Log.Infof("Replaying the state of tenant %d...", tenant.Id)
+
+	sourceDao := dao.GetSourceDao(&tenant.Id)
+
+	var lastId int64
+	for {
+		sources, err := sourceDao.ListForReplay(lastId, dao.DEFAULT_LIMIT)
+		if err != nil {
+			return replayer.result, err
+		}
+
+		for i := range sources {
+			err = replayer.replaySource(&sources[i], opts.ApplicationTypeId)
+			if err != nil {
+				return replayer.result, err
+			}
+		}
+
+		if len(sources) < dao.DEFAULT_LIMIT {
+			break
+		}
+
+		lastId = sources[len(sources)-1].ID
+	}
+
+	l.Log.Infof("Replaying the state of tenant %d...Complete: %+v", tenant.Id, *replayer.result)
+
+	return replayer.result, nil
+}
+
+// ReplayTenantInBackground replays the tenant without blocking the caller. The result is logged once the replay
+// finishes, and the replays still running get cancelled by StopBackgroundReplays.
+func ReplayTenantInBackground(tenant *m.Tenant, opts ReplayOptions) {
+	backgroundReplays.Add(1)
+	go func() {
+		defer backgroundReplays.Done()
+
+		_, err := ReplayTenant(backgroundReplaysCtx, tenant, opts)
+		if err != nil {
+			l.Log.Errorf("Unable to replay the state of tenant %d: %s", tenant.Id, err)
+		}
+	}()
+}
+
+// StopBackgroundReplays cancels the replays running in the background, and waits for them to return.
+func StopBackgroundReplays() {
+	cancelBackgroundReplays()
+	backgroundReplays.Wait()
+}
+
+// ReplayOptionsFromConfig returns the replay options with the rate limit taken from the configuration.
+func ReplayOptionsFromConfig(applicationTypeId int64) ReplayOptions {
+	return ReplayOptions{
+		ApplicationTypeId: applicationTypeId,
+		EventsPerSecond:   config.Get().ReplayEventsPerSecond,
+	}
+}
+
+// tenantReplayer sends the events of a single replay.
+type tenantReplayer struct {
+	ctx     context.Context
+	limiter *rate.Limiter
+	headers []kafka.Header
+	result  *ReplayResult
+}
+
+// replaySource sends the events for the source, its subresources and its bulk message. Sources without applications of
+// the given type are skipped, and the applications of other types are left out of the events and the bulk message,
+// along with their authentications.
+func (tr *tenantReplayer) replaySource(source *m.Source, applicationTypeId int64) error {
+	// the bulk message preloads the tenant, the endpoints and the applications of the source, which are also needed to
+	// raise the rest of the events.
+	bulkMessage, err := dao.BulkMessageFromSource(source, &m.Authentication{ResourceType: "Source", ResourceID: source.ID})
+	if err != nil {
+		return err
+	}
+
+	applications := make([]m.Application, 0, len(source.Applications))
+	replayed := make(map[int64]bool)
+	for _, app := range source.Applications {
+		if applicationTypeId == 0 || app.ApplicationTypeID == applicationTypeId {
+			applications = append(applications, app)
+			replayed[app.ID] = true
+		}
+	}
+
+	if applicationTypeId != 0 {
+		if len(applications) == 0 {
+			return nil
+		}
+
+		filterBulkMessage(bulkMessage, replayed)
+	}
+
+	err = tr.send("Source.create", source.ToEvent())
+	if err != nil {
+		return err
+	}
+	tr.result.Sources++
+
+	for i := range source.Endpoints {
+		err = tr.send("Endpoint.create", source.Endpoints[i].ToEvent())
+		if err != nil {
+			return err
+		}
+		tr.result.Endpoints++
+	}
+
+	for i := range applications {
+		err = tr.send("Application.create", applications[i].ToEvent())
+		if err != nil {
+			return err
+		}
+		tr.result.Applications++
+	}
+
+	authentications, err := sourceAuthentications(source.TenantID, source.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range authentications {
+		if !isReplayedAuthentication(authentications[i].ResourceType, authentications[i].ResourceID, replayed) {
+			continue
+		}
+
+		authentications[i].Tenant = source.Tenant
+
+		err = tr.send("Authentication.create", authentications[i].ToEvent())
+		if err != nil {
+			return err
+		}
+		tr.result.Authentications++
+	}
+
+	err = tr.send("Records.create", bulkMessage)
+	if err != nil {
+		return err
+	}
+	tr.result.Records++
+
+	return nil
+}
+
+// isReplayedAuthentication tells whether the authentication of the given resource gets replayed, which is the case
+// unless it belongs to an application which isn't replayed.
+func isReplayedAuthentication(resourceType string, resourceId int64, replayedApplications map[int64]bool) bool {
+	return resourceType != "Application" || replayedApplications[resourceId]
+}
+
+// filterBulkMessage leaves the applications which aren't replayed out of the bulk message, along with their
+// authentications.
+func filterBulkMessage(bulkMessage map[string]interface{}, replayedApplications map[int64]bool) {
+	filter := func(key string, keep func(event interface{}) bool) {
+		events, ok := bulkMessage[key].([]interface{})
+		if !ok {
+			return
+		}
+
+		kept := make([]interface{}, 0, len(events))
+		for _, event := range events {
+			if keep(event) {
+				kept = append(kept, event)
+			}
+		}
+
+		bulkMessage[key] = kept
+	}
+
+	filter("applications", func(event interface{}) bool {
+		app, ok := event.(*m.ApplicationEvent)
+		return !ok || replayedApplications[app.ID]
+	})
+
+	filter("application_authentications", func(event interface{}) bool {
+		appAuth, ok := event.(*m.ApplicationAuthenticationEvent)
+		return !ok || replayedApplications[appAuth.ApplicationID]
+	})
+
+	filter("authentications", func(event interface{}) bool {
+		auth, ok := event.(*m.AuthenticationEvent)
+		return !ok || isReplayedAuthentication(auth.ResourceType, auth.ResourceID, replayedApplications)
+	})
+}
+
+// send waits for the rate limiter and sends the event with the replay headers.
+func (tr *tenantReplayer) send(eventType string, payload interface{}) error {
+	err := tr.limiter.Wait(tr.ctx)
+	if err != nil {
+		return err
+	}
+
+	msg, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	err = Producer.RaiseEvent(eventType, msg, tr.headers)
+	if err != nil {
+		return fmt.Errorf("failed to replay %q event: %w", eventType, err)
+	}
+
+	return nil
+}
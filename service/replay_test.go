@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	"github.com/RedHatInsights/sources-api-go/internal/events"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/fixtures"
+	"github.com/RedHatInsights/sources-api-go/internal/testutils/mocks"
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"golang.org/x/time/rate"
+)
+
+// recordingSender records the events instead of sending them to Kafka.
+type recordingSender struct {
+	eventTypes []string
+	payloads   [][]byte
+	headers    [][]kafka.Header
+}
+
+func (rs *recordingSender) RaiseEvent(eventType string, payload []byte, headers []kafka.Header) error {
+	rs.eventTypes = append(rs.eventTypes, eventType)
+	rs.payloads = append(rs.payloads, payload)
+	rs.headers = append(rs.headers, headers)
+
+	return nil
+}
+
+// useRecordingSender replaces the producer's sender for the duration of the test.
+func useRecordingSender(t *testing.T) *recordingSender {
+	sender := &recordingSender{}
+
+	previous := Producer
+	Producer = events.EventStreamProducer{Sender: sender}
+	t.Cleanup(func() { Producer = previous })
+
+	return sender
+}
+
+func TestApplicationTypeIdFrom(t *testing.T) {
+	id, err := ApplicationTypeIdFrom("12")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if id != 12 {
+		t.Errorf("want application type id 12, got %d", id)
+	}
+
+	_, err = ApplicationTypeIdFrom("/insights/platform/unknown")
+	if err == nil {
+		t.Error("want error for an unknown application type, got none")
+	}
+}
+
+// TestReplaySendIsRateLimited tests that the events get sent with the replay header, and at the given rate.
+func TestReplaySendIsRateLimited(t *testing.T) {
+	sender := useRecordingSender(t)
+
+	replayer := &tenantReplayer{
+		ctx:     context.Background(),
+		limiter: rate.NewLimiter(rate.Limit(20), 1),
+		headers: []kafka.Header{{Key: ReplayHeader, Value: []byte("true")}},
+		result:  &ReplayResult{},
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		err := replayer.send("Source.create", map[string]interface{}{"id": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the first event goes through straight away, the other two have to wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("want the events to be rate limited, but they were sent in %s", elapsed)
+	}
+
+	if len(sender.eventTypes) != 3 {
+		t.Fatalf("want 3 events sent, got %d", len(sender.eventTypes))
+	}
+
+	for _, headers := range sender.headers {
+		if len(headers) != 1 || headers[0].Key != ReplayHeader {
+			t.Errorf("want the replay header to be sent, got %v", headers)
+		}
+	}
+}
+
+// TestReplaySendStopsWhenContextIsCancelled tests that no events are sent once the replay gets cancelled.
+func TestReplaySendStopsWhenContextIsCancelled(t *testing.T) {
+	sender := useRecordingSender(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	replayer := &tenantReplayer{
+		ctx:     ctx,
+		limiter: rate.NewLimiter(rate.Limit(1), 1),
+		result:  &ReplayResult{},
+	}
+
+	err := replayer.send("Source.create", map[string]interface{}{})
+	if err == nil {
+		t.Error("want error for a cancelled replay, got none")
+	}
+
+	if len(sender.eventTypes) != 0 {
+		t.Errorf("want no events sent, got %v", sender.eventTypes)
+	}
+}
+
+func TestReplayTenant(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	sender := useRecordingSender(t)
+	dao.Vault = &mocks.MockVault{}
+
+	tenant := fixtures.TestTenantData[0]
+	result, err := ReplayTenant(context.Background(), &tenant, ReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources, endpoints, applications int
+	for _, source := range fixtures.TestSourceData {
+		if source.TenantID != tenant.Id {
+			continue
+		}
+		sources++
+
+		for _, endpoint := range fixtures.TestEndpointData {
+			if endpoint.SourceID == source.ID {
+				endpoints++
+			}
+		}
+
+		for _, app := range fixtures.TestApplicationData {
+			if app.SourceID == source.ID {
+				applications++
+			}
+		}
+	}
+
+	if result.Sources != sources || result.Records != sources {
+		t.Errorf("want %d sources and bulk messages replayed, got %d and %d", sources, result.Sources, result.Records)
+	}
+
+	if result.Endpoints != endpoints {
+		t.Errorf("want %d endpoints replayed, got %d", endpoints, result.Endpoints)
+	}
+
+	if result.Applications != applications {
+		t.Errorf("want %d applications replayed, got %d", applications, result.Applications)
+	}
+
+	want := result.Sources + result.Endpoints + result.Applications + result.Authentications + result.Records
+	if len(sender.eventTypes) != want {
+		t.Errorf("want %d events sent, got %d", want, len(sender.eventTypes))
+	}
+
+	// filtering by an application type which no source has must not send anything.
+	sender.eventTypes = nil
+	result, err = ReplayTenant(context.Background(), &tenant, ReplayOptions{ApplicationTypeId: 12345})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Sources != 0 || len(sender.eventTypes) != 0 {
+		t.Errorf("want no events sent when filtering by an unknown application type, got %v", sender.eventTypes)
+	}
+}
+
+// TestReplayTenantPagesThroughEverySource tests that a tenant with more sources than fit in a page gets each of them
+// replayed exactly once.
+func TestReplayTenantPagesThroughEverySource(t *testing.T) {
+	testutils.SkipIfNotRunningIntegrationTests(t)
+	sender := useRecordingSender(t)
+	dao.Vault = &mocks.MockVault{}
+
+	tenant := m.Tenant{ExternalTenant: "replay paging"}
+	dao.DB.Create(&tenant)
+
+	sources := make([]m.Source, dao.DEFAULT_LIMIT*2+1)
+	for i := range sources {
+		sources[i] = m.Source{
+			Name:         fmt.Sprintf("replay paging %d", i),
+			SourceTypeID: fixtures.TestSourceTypeData[0].Id,
+			TenantID:     tenant.Id,
+		}
+	}
+	dao.DB.Create(&sources)
+
+	t.Cleanup(func() {
+		dao.DB.Where("tenant_id = ?", tenant.Id).Delete(&m.Source{})
+		dao.DB.Delete(&tenant)
+	})
+
+	_, err := ReplayTenant(context.Background(), &tenant, ReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := make(map[int64]int)
+	for i, eventType := range sender.eventTypes {
+		if eventType != "Source.create" {
+			continue
+		}
+
+		var event m.SourceEvent
+		err = json.Unmarshal(sender.payloads[i], &event)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		replayed[*event.ID]++
+	}
+
+	if len(replayed) != len(sources) {
+		t.Errorf("want %d sources replayed, got %d", len(sources), len(replayed))
+	}
+
+	for _, source := range sources {
+		if replayed[source.ID] != 1 {
+			t.Errorf("want source %d replayed once, got %d times", source.ID, replayed[source.ID])
+		}
+	}
+}
+
+// TestFilterBulkMessage tests that the applications which aren't replayed are left out of the bulk message, along with
+// their authentications.
+func TestFilterBulkMessage(t *testing.T) {
+	bulkMessage := map[string]interface{}{
+		"source":       &m.SourceEvent{},
+		"applications": []interface{}{&m.ApplicationEvent{ID: 1}, &m.ApplicationEvent{ID: 2}},
+		"application_authentications": []interface{}{
+			&m.ApplicationAuthenticationEvent{ID: 1, ApplicationID: 1},
+			&m.ApplicationAuthenticationEvent{ID: 2, ApplicationID: 2},
+		},
+		"authentications": []interface{}{
+			&m.AuthenticationEvent{ID: "source", ResourceType: "Source", ResourceID: 1},
+			&m.AuthenticationEvent{ID: "replayed", ResourceType: "Application", ResourceID: 1},
+			&m.AuthenticationEvent{ID: "filtered", ResourceType: "Application", ResourceID: 2},
+		},
+	}
+
+	filterBulkMessage(bulkMessage, map[int64]bool{1: true})
+
+	apps := bulkMessage["applications"].([]interface{})
+	if len(apps) != 1 || apps[0].(*m.ApplicationEvent).ID != 1 {
+		t.Errorf("want only the replayed application, got %v", apps)
+	}
+
+	appAuths := bulkMessage["application_authentications"].([]interface{})
+	if len(appAuths) != 1 || appAuths[0].(*m.ApplicationAuthenticationEvent).ApplicationID != 1 {
+		t.Errorf("want only the replayed application's application authentication, got %v", appAuths)
+	}
+
+	auths := bulkMessage["authentications"].([]interface{})
+	if len(auths) != 2 || auths[0].(*m.AuthenticationEvent).ID != "source" || auths[1].(*m.AuthenticationEvent).ID != "replayed" {
+		t.Errorf("want the source's and the replayed application's authentications, got %v", auths)
+	}
+
+	if bulkMessage["source"] == nil {
+		t.Error("want the source kept in the bulk message")
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"github.com/sirupsen/logrus"
+)
+
+// headersLogger returns the logger of the request the message with the given headers follows from, which adds the
+// request's id to every line.
+func headersLogger(headers []kafka.Header) logrus.FieldLogger {
+	return l.WithRequestId(util.RequestIdFromHeaders(headers))
+}
+
+// checkLogger returns the logger of the request which asked for the availability check. The scheduled checks, and
+// the work outside of a check, get the application's logger.
+func checkLogger(check *m.AvailabilityCheck) logrus.FieldLogger {
+	if check == nil {
+		return l.Log
+	}
+
+	return l.WithRequestId(check.RequestID)
+}
+
+// loggerContext returns a context carrying the given logger, for the DAO calls made outside of a request's context.
+func loggerContext(logger logrus.FieldLogger) context.Context {
+	return l.ContextWithLogger(context.Background(), logger)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/RedHatInsights/sources-api-go/config"
+	"github.com/RedHatInsights/sources-api-go/dao"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/util"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultSourceAvailabilityRules is the key of the rules that apply to the source types without their own rules.
+const defaultSourceAvailabilityRules = "default"
+
+//go:embed source_availability_rules.yml
+var sourceAvailabilityRulesFile []byte
+
+var (
+	sourceAvailabilityRules     map[string]SourceAvailabilityRules
+	sourceAvailabilityRulesOnce sync.Once
+)
+
+// SourceAvailabilityRules define how the availability status of a source is derived from the statuses of its
+// applications, endpoints and authentications.
+type SourceAvailabilityRules struct {
+	// Resources lists the resource types which are taken into account.
+	Resources []string `json:"resources"`
+	// Required lists the resource types that make the whole source unavailable when any of them is unavailable.
+	Required []string `json:"required"`
+}
+
+// SourceAvailabilityRulesFor returns the rules of the given source type, or the default rules if the source type
+// doesn't have its own.
+func SourceAvailabilityRulesFor(sourceTypeName string) SourceAvailabilityRules {
+	sourceAvailabilityRulesOnce.Do(func() {
+		sourceAvailabilityRules = loadSourceAvailabilityRules(config.Get().SourceAvailabilityRules)
+	})
+
+	rules, ok := sourceAvailabilityRules[sourceTypeName]
+	if !ok {
+		rules, ok = sourceAvailabilityRules[defaultSourceAvailabilityRules]
+	}
+
+	if !ok {
+		rules = SourceAvailabilityRules{Resources: []string{"Application", "Endpoint", "Authentication"}}
+	}
+
+	return rules
+}
+
+// loadSourceAvailabilityRules parses the built-in rules, and then the ones of the given file, if any. The rules of
+// the file replace the built-in ones of the same source types.
+func loadSourceAvailabilityRules(overridesFile string) map[string]SourceAvailabilityRules {
+	rules := make(map[string]SourceAvailabilityRules)
+
+	err := yaml.Unmarshal(sourceAvailabilityRulesFile, &rules)
+	if err != nil {
+		l.Log.Errorf("Unable to parse the source availability rules, using the defaults: %s", err)
+	}
+
+	if overridesFile == "" {
+		return rules
+	}
+
+	raw, err := os.ReadFile(overridesFile)
+	if err != nil {
+		l.Log.Errorf("Unable to read the source availability rules from %q, using the built-in ones: %s", overridesFile, err)
+		return rules
+	}
+
+	overrides := make(map[string]SourceAvailabilityRules)
+	err = yaml.Unmarshal(raw, &overrides)
+	if err != nil {
+		l.Log.Errorf("Unable to parse the source availability rules from %q, using the built-in ones: %s", overridesFile, err)
+		return rules
+	}
+
+	for sourceType, override := range overrides {
+		rules[sourceType] = override
+	}
+
+	return rules
+}
+
+// DeriveSourceAvailability computes the availability status of a source from the statuses of its children:
+//
+// - "available" when every one of them is available.
+// - "unavailable" when every one of them is unavailable, or when one of the required resource types is.
+// - "partially_available" otherwise.
+//
+// The paused children and the children which haven't reported a final status yet are ignored. An empty string is
+// returned when no status can be derived.
+func DeriveSourceAvailability(rules SourceAvailabilityRules, applications []m.Application, endpoints []m.Endpoint, authentications []m.Authentication) string {
+	var available, unavailable int
+	var requiredUnavailable bool
+
+	count := func(resourceType, status string) {
+		switch status {
+		case m.Available:
+			available++
+		case m.PartiallyAvailable:
+			// a partially available child makes the source partially available at best.
+			available++
+			unavailable++
+		case m.Unavailable:
+			unavailable++
+			if util.SliceContainsString(rules.Required, resourceType) {
+				requiredUnavailable = true
+			}
+		}
+	}
+
+	if util.SliceContainsString(rules.Resources, "Application") {
+		for _, app := range applications {
+			if app.PausedAt.IsZero() {
+				count("Application", app.AvailabilityStatus.AvailabilityStatus)
+			}
+		}
+	}
+
+	if util.SliceContainsString(rules.Resources, "Endpoint") {
+		for _, endpoint := range endpoints {
+			if endpoint.PausedAt.IsZero() {
+				count("Endpoint", endpoint.AvailabilityStatus.AvailabilityStatus)
+			}
+		}
+	}
+
+	if util.SliceContainsString(rules.Resources, "Authentication") {
+		for _, auth := range authentications {
+			count("Authentication", auth.AvailabilityStatus.AvailabilityStatus)
+		}
+	}
+
+	switch {
+	case available == 0 && unavailable == 0:
+		return ""
+	case requiredUnavailable || available == 0:
+		return m.Unavailable
+	case unavailable == 0:
+		return m.Available
+	default:
+		return m.PartiallyAvailable
+	}
+}
+
+// UpdateDerivedSourceAvailability recomputes the availability status of the source the given application, endpoint
+// or authentication belongs to, and persists it. It returns the source's resource and whether its status changed, so
+// that the caller knows when to raise the "Source.update" event. Other resource types are ignored. The context carries
+// the logger of the request the update follows from.
+func UpdateDerivedSourceAvailability(ctx context.Context, resource util.Resource) (*util.Resource, bool, error) {
+	sourceId, err := sourceIdFor(resource)
+	if err != nil || sourceId == 0 {
+		return nil, false, err
+	}
+
+	source, err := dao.GetSourceDao(&resource.TenantID).WithContext(ctx).GetByIdWithPreload(&sourceId, "SourceType", "Applications", "Endpoints")
+	if err != nil {
+		return nil, false, err
+	}
+
+	rules := SourceAvailabilityRulesFor(source.SourceType.Name)
+
+	var authentications []m.Authentication
+	if util.SliceContainsString(rules.Resources, "Authentication") {
+		authentications, err = sourceAuthentications(resource.TenantID, source.ID)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	sourceResource := &util.Resource{
+		ResourceType:  "Source",
+		ResourceID:    source.ID,
+		TenantID:      resource.TenantID,
+		AccountNumber: resource.AccountNumber,
+		OrgID:         resource.OrgID,
+	}
+
+	status := DeriveSourceAvailability(rules, source.Applications, source.Endpoints, authentications)
+	if status == "" || status == source.AvailabilityStatus.AvailabilityStatus {
+		return sourceResource, false, nil
+	}
+
+	err = dao.GetSourceDao(&resource.TenantID).WithContext(ctx).FetchAndUpdateBy(*sourceResource, map[string]interface{}{"availability_status": status})
+	if err != nil {
+		return nil, false, err
+	}
+
+	l.FromContext(ctx).Infof("Derived availability status of source %d changed from %q to %q", source.ID, source.AvailabilityStatus.AvailabilityStatus, status)
+
+	RecordAvailabilityStatus(ctx, resource.TenantID, "Source", strconv.FormatInt(source.ID, 10), status, "", m.AvailabilityStatusOriginDerived)
+
+	return sourceResource, true, nil
+}
+
+// sourceIdFor returns the id of the source the resource belongs to, or zero for the resource types which don't count
+// towards the availability status of their source.
+func sourceIdFor(resource util.Resource) (int64, error) {
+	switch resource.ResourceType {
+	case "Application":
+		app, err := dao.GetApplicationDao(&resource.TenantID).GetById(&resource.ResourceID)
+		if err != nil {
+			return 0, err
+		}
+
+		return app.SourceID, nil
+	case "Endpoint":
+		endpoint, err := dao.GetEndpointDao(&resource.TenantID).GetById(&resource.ResourceID)
+		if err != nil {
+			return 0, err
+		}
+
+		return endpoint.SourceID, nil
+	case "Authentication":
+		auth, err := dao.GetAuthenticationDao(&resource.TenantID).GetById(resource.ResourceUID)
+		if err != nil {
+			return 0, err
+		}
+
+		if auth.SourceID == 0 {
+			return 0, fmt.Errorf("authentication %s has no source", resource.ResourceUID)
+		}
+
+		return auth.SourceID, nil
+	default:
+		return 0, nil
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+func TestDeriveSourceAvailability(t *testing.T) {
+	rules := SourceAvailabilityRules{
+		Resources: []string{"Application", "Endpoint", "Authentication"},
+		Required:  []string{"Endpoint"},
+	}
+
+	app := func(status string) m.Application {
+		return m.Application{AvailabilityStatus: m.AvailabilityStatus{AvailabilityStatus: status}}
+	}
+	endpoint := func(status string) m.Endpoint {
+		return m.Endpoint{AvailabilityStatus: m.AvailabilityStatus{AvailabilityStatus: status}}
+	}
+	auth := func(status string) m.Authentication {
+		return m.Authentication{AvailabilityStatus: m.AvailabilityStatus{AvailabilityStatus: status}}
+	}
+
+	pausedApp := app(m.Unavailable)
+	pausedApp.PausedAt = time.Now()
+
+	testCases := []struct {
+		name            string
+		applications    []m.Application
+		endpoints       []m.Endpoint
+		authentications []m.Authentication
+		want            string
+	}{
+		{name: "no children", want: ""},
+		{name: "no final statuses", applications: []m.Application{app(""), app(m.InProgress)}, want: ""},
+		{name: "all available", applications: []m.Application{app(m.Available)}, endpoints: []m.Endpoint{endpoint(m.Available)}, authentications: []m.Authentication{auth(m.Available)}, want: m.Available},
+		{name: "all unavailable", applications: []m.Application{app(m.Unavailable)}, authentications: []m.Authentication{auth(m.Unavailable)}, want: m.Unavailable},
+		{name: "mixed", applications: []m.Application{app(m.Available), app(m.Unavailable)}, want: m.PartiallyAvailable},
+		{name: "partially available child", applications: []m.Application{app(m.PartiallyAvailable)}, want: m.PartiallyAvailable},
+		{name: "required unavailable", applications: []m.Application{app(m.Available)}, endpoints: []m.Endpoint{endpoint(m.Unavailable)}, want: m.Unavailable},
+		{name: "paused children ignored", applications: []m.Application{app(m.Available), pausedApp}, want: m.Available},
+		{name: "in progress ignored", applications: []m.Application{app(m.Available), app(m.InProgress)}, want: m.Available},
+	}
+
+	for _, tc := range testCases {
+		got := DeriveSourceAvailability(rules, tc.applications, tc.endpoints, tc.authentications)
+		if got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestDeriveSourceAvailabilityIgnoresResourceTypes(t *testing.T) {
+	rules := SourceAvailabilityRules{Resources: []string{"Application"}}
+
+	got := DeriveSourceAvailability(
+		rules,
+		[]m.Application{{AvailabilityStatus: m.AvailabilityStatus{AvailabilityStatus: m.Available}}},
+		[]m.Endpoint{{AvailabilityStatus: m.AvailabilityStatus{AvailabilityStatus: m.Unavailable}}},
+		[]m.Authentication{{AvailabilityStatus: m.AvailabilityStatus{AvailabilityStatus: m.Unavailable}}},
+	)
+
+	if got != m.Available {
+		t.Errorf("want %q, got %q", m.Available, got)
+	}
+}
+
+func TestSourceAvailabilityRulesFor(t *testing.T) {
+	amazon := SourceAvailabilityRulesFor("amazon")
+	if len(amazon.Required) != 0 || len(amazon.Resources) != 2 {
+		t.Errorf("unexpected rules for amazon: %+v", amazon)
+	}
+
+	// the source types without their own rules get the default ones.
+	unknown := SourceAvailabilityRulesFor("unknown")
+	if len(unknown.Resources) != 3 || len(unknown.Required) != 1 || unknown.Required[0] != "Endpoint" {
+		t.Errorf("unexpected default rules: %+v", unknown)
+	}
+}
+
+func TestLoadSourceAvailabilityRulesOverrides(t *testing.T) {
+	overridesFile := filepath.Join(t.TempDir(), "rules.yml")
+	err := os.WriteFile(overridesFile, []byte("amazon:\n  resources: [Application]\n  required: [Application]\nvsphere:\n  resources: [Endpoint]\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := loadSourceAvailabilityRules(overridesFile)
+
+	// the overridden source types get the rules of the file...
+	amazon := rules["amazon"]
+	if len(amazon.Resources) != 1 || len(amazon.Required) != 1 || amazon.Required[0] != "Application" {
+		t.Errorf("unexpected rules for amazon: %+v", amazon)
+	}
+
+	if vsphere := rules["vsphere"]; len(vsphere.Resources) != 1 || vsphere.Resources[0] != "Endpoint" {
+		t.Errorf("unexpected rules for vsphere: %+v", vsphere)
+	}
+
+	// ... and the rest keep the built-in ones.
+	if azure := rules["azure"]; len(azure.Resources) != 2 {
+		t.Errorf("unexpected rules for azure: %+v", azure)
+	}
+
+	if def := rules[defaultSourceAvailabilityRules]; len(def.Resources) != 3 {
+		t.Errorf("unexpected default rules: %+v", def)
+	}
+}
+
+func TestLoadSourceAvailabilityRulesMissingFile(t *testing.T) {
+	rules := loadSourceAvailabilityRules(filepath.Join(t.TempDir(), "missing.yml"))
+
+	if len(rules["amazon"].Resources) != 2 {
+		t.Errorf("want the built-in rules, got %+v", rules)
+	}
+}
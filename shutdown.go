@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RedHatInsights/sources-api-go/dao"
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/RedHatInsights/sources-api-go/redis"
+	"github.com/RedHatInsights/sources-api-go/service"
+	"github.com/labstack/echo/v4"
+)
+
+// serve starts the server on the given address and blocks until the context gets cancelled. Once that happens, the
+// server stops accepting new connections and waits for the in-flight requests to finish, and then for the
+// availability checks they requested to be dispatched, up to the configured shutdown timeout. The replays running in
+// the background get cancelled on every way out, since the connections get closed right after.
+func serve(ctx context.Context, e *echo.Echo, address string) error {
+	defer func() {
+		l.Log.Info("Stopping the replays...")
+		service.StopBackgroundReplays()
+		l.Log.Info("Stopping the replays...Complete")
+	}()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- e.Start(address)
+	}()
+
+	select {
+	case err := <-serverErrors:
+		// the server couldn't even start, so there is nothing to drain.
+		return err
+	case <-ctx.Done():
+	}
+
+	l.Log.Info("Shutting down the server, draining in-flight requests...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	err := e.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	l.Log.Info("Shutting down the server, draining in-flight requests...Complete")
+
+	l.Log.Info("Waiting for the availability checks to be dispatched...")
+	err = service.WaitForBackgroundChecks(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("unable to dispatch the pending availability checks: %w", err)
+	}
+
+	l.Log.Info("Waiting for the availability checks to be dispatched...Complete")
+
+	return nil
+}
+
+// closeConnections closes the database, Redis and Vault clients. Any errors are logged, since there is nothing else
+// we can do about them at that point.
+func closeConnections() {
+	l.Log.Info("Closing the database and Vault connections...")
+	if err := dao.Close(); err != nil {
+		l.Log.Errorf("Unable to close the database connection: %s", err)
+	} else {
+		l.Log.Info("Closing the database and Vault connections...Complete")
+	}
+
+	l.Log.Info("Flushing the audit log topic...")
+	if err := service.CloseAuditProducer(); err != nil {
+		l.Log.Errorf("Unable to flush the audit log topic: %s", err)
+	} else {
+		l.Log.Info("Flushing the audit log topic...Complete")
+	}
+
+	l.Log.Info("Closing the Redis connection...")
+	if err := redis.Close(); err != nil {
+		l.Log.Errorf("Unable to close the Redis connection: %s", err)
+	} else {
+		l.Log.Info("Closing the Redis connection...Complete")
+	}
+}
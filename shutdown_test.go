@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestServeDrainsInFlightRequests tests that when the context gets cancelled while a request is being processed, the
+// server waits for the request to finish before shutting down.
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	requestStarted := make(chan struct{})
+	e.GET("/slow", func(c echo.Context) error {
+		close(requestStarted)
+		time.Sleep(200 * time.Millisecond)
+
+		return c.String(http.StatusOK, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, e, "127.0.0.1:0")
+	}()
+
+	// wait for the server to be listening.
+	deadline := time.Now().Add(5 * time.Second)
+	for e.ListenerAddr() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the server did not start in time")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	type response struct {
+		status int
+		body   string
+		err    error
+	}
+
+	responses := make(chan response, 1)
+	go func() {
+		resp, err := http.Get("http://" + e.ListenerAddr().String() + "/slow")
+		if err != nil {
+			responses <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		responses <- response{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	<-requestStarted
+	cancel()
+
+	resp := <-responses
+	if resp.err != nil {
+		t.Fatalf("the in-flight request failed: %s", resp.err)
+	}
+
+	if resp.status != http.StatusOK || resp.body != "done" {
+		t.Errorf("want status %d and body %q, got %d and %q", http.StatusOK, "done", resp.status, resp.body)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("want no error when shutting down the server, got %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not shut down in time")
+	}
+}
+
+// TestServeReturnsStartupErrors tests that the errors that prevent the server from starting are returned right away.
+func TestServeReturnsStartupErrors(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	err := serve(context.Background(), e, "invalid-address")
+	if err == nil {
+		t.Error("want error, got none")
+	}
+}
@@ -23,7 +23,7 @@ func getSourceDaoWithTenant(c echo.Context) (dao.SourceDao, error) {
 		return nil, err
 	}
 
-	return dao.GetSourceDao(&tenantId), nil
+	return dao.GetSourceDao(&tenantId).WithContext(c.Request().Context()), nil
 }
 
 func SourceList(c echo.Context) error {
@@ -47,14 +47,13 @@ func SourceList(c echo.Context) error {
 		count   int64
 	)
 
-	// When listing sources via cert-auth we want to lock them down to only the
-	// satellite source type.
-	if c.Get("cert-auth") != nil {
-		satelliteId := strconv.Itoa(int(dao.Static.GetSourceTypeId("satellite")))
-		filters = append(filters, util.Filter{Name: "source_type_id", Value: []string{satelliteId}})
+	// When listing sources as a system we want to lock them down to only the
+	// sources the system owns.
+	if owner, ok := c.Get("source-owner").(*m.SourceOwner); ok {
+		sources, count, err = sourcesDB.ListOwned(owner, limit, offset, filters)
+	} else {
+		sources, count, err = sourcesDB.List(limit, offset, filters)
 	}
-
-	sources, count, err = sourcesDB.List(limit, offset, filters)
 	if err != nil {
 		return err
 	}
@@ -120,6 +119,17 @@ func SourceCreate(c echo.Context) error {
 		SourceTypeID: *input.SourceTypeID,
 	}
 
+	// the systems can only create sources of the types their policy allows,
+	// and they are the owners of the sources they create.
+	if owner, ok := c.Get("source-owner").(*m.SourceOwner); ok {
+		if !ownerMayCreate(owner, source.SourceTypeID) {
+			return util.NewErrBadRequest(fmt.Sprintf("the %q systems can only create sources of types %v", owner.Key, owner.SourceTypes))
+		}
+
+		ownerString := owner.String()
+		source.Owner = &ownerString
+	}
+
 	err = sourcesDB.Create(source)
 	if err != nil {
 		return err
@@ -129,6 +139,17 @@ func SourceCreate(c echo.Context) error {
 	return c.JSON(http.StatusCreated, source.ToResponse())
 }
 
+// ownerMayCreate tells whether the system may create a source of the given source type.
+func ownerMayCreate(owner *m.SourceOwner, sourceTypeId int64) bool {
+	for _, name := range owner.SourceTypes {
+		if dao.Static.GetSourceTypeId(name) == sourceTypeId {
+			return true
+		}
+	}
+
+	return false
+}
+
 func SourceEdit(c echo.Context) error {
 	sourcesDB, err := getSourceDao(c)
 	if err != nil {
@@ -150,12 +171,21 @@ func SourceEdit(c echo.Context) error {
 		return err
 	}
 
+	err = checkNotPaused(c, service.PausedSource, s.ID)
+	if err != nil {
+		return err
+	}
+
 	s.UpdateFromRequest(input)
 	err = sourcesDB.Update(s)
 	if err != nil {
 		return err
 	}
 
+	if input.AvailabilityStatus != nil {
+		service.RecordAvailabilityStatus(c.Request().Context(), *sourcesDB.Tenant(), "Source", strconv.FormatInt(s.ID, 10), s.AvailabilityStatus.AvailabilityStatus, "", m.AvailabilityStatusOriginApi)
+	}
+
 	setEventStreamResource(c, s)
 	return c.JSON(http.StatusOK, s.ToResponse())
 }
@@ -195,7 +225,7 @@ func SourceListAuthentications(c echo.Context) error {
 
 	auths, count, err := authDao.ListForSource(sourceID, 100, 0, nil)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, util.ErrorDoc(err.Error(), "404"))
+		return c.JSON(http.StatusNotFound, util.ErrorDoc(c.Request().Context(), err.Error(), "404"))
 	}
 
 	out := make([]interface{}, count)
@@ -301,6 +331,11 @@ func SourceCheckAvailability(c echo.Context) error {
 		return util.NewErrBadRequest(err)
 	}
 
+	err = checkNotPaused(c, service.PausedSource, sourceID)
+	if err != nil {
+		return err
+	}
+
 	src, err := sourceDao.GetByIdWithPreload(&sourceID,
 		"SourceType",
 		"Applications",
@@ -312,10 +347,17 @@ func SourceCheckAvailability(c echo.Context) error {
 		return err
 	}
 
-	// do it async!
-	go func() { service.RequestAvailabilityCheck(src) }()
+	requestId, _ := c.Get("request-id").(string)
+
+	check, err := service.NewAvailabilityCheck(src, requestId)
+	if err != nil {
+		return err
+	}
 
-	return c.JSON(http.StatusAccepted, map[string]interface{}{})
+	// do it async! The check gets dispatched before shutting down.
+	service.RequestAvailabilityCheckInBackground(src, check)
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{"id": strconv.FormatInt(check.ID, 10)})
 }
 
 // SourcesRhcConnectionList returns all the connections related to a source.
@@ -324,17 +366,17 @@ func SourcesRhcConnectionList(c echo.Context) error {
 
 	sourceId, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc("invalid id provided ", "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), "invalid id provided ", "400"))
 	}
 
 	filters, err := getFilters(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	limit, offset, err := getLimitAndOffset(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	// Check if the given source exists.
@@ -348,7 +390,7 @@ func SourcesRhcConnectionList(c echo.Context) error {
 		if errors.Is(err, util.ErrNotFoundEmpty) {
 			return err
 		}
-		return c.JSON(http.StatusBadRequest, util.ErrorDoc(err.Error(), "400"))
+		return c.JSON(http.StatusBadRequest, util.ErrorDoc(c.Request().Context(), err.Error(), "400"))
 	}
 
 	rhcConnectionDao, err := getRhcConnectionDao(c)
@@ -371,13 +413,18 @@ func SourcesRhcConnectionList(c echo.Context) error {
 }
 
 // SourcePause pauses a source and all its dependant applications, by setting the former's and the latter's "paused_at"
-// columns to "now()".
+// columns to "now()". When a "resume_at" is given, the source gets resumed on its own at that time.
 func SourcePause(c echo.Context) error {
 	sourceId, err := strconv.ParseInt(c.Param("source_id"), 10, 64)
 	if err != nil {
 		return util.NewErrBadRequest(err)
 	}
 
+	pauseRequest, err := getPauseRequest(c)
+	if err != nil {
+		return err
+	}
+
 	sourceDao, err := getSourceDao(c)
 	if err != nil {
 		return err
@@ -388,6 +435,11 @@ func SourcePause(c echo.Context) error {
 		return util.NewErrBadRequest(err)
 	}
 
+	err = scheduleResume(c, service.PausedSource, sourceId, pauseRequest.ResumeAt)
+	if err != nil {
+		return err
+	}
+
 	source, err := sourceDao.GetByIdWithPreload(&sourceId, "Applications")
 	if err != nil {
 		return err
@@ -431,6 +483,11 @@ func SourceResume(c echo.Context) error {
 		return util.NewErrBadRequest(err)
 	}
 
+	err = scheduleResume(c, service.PausedSource, sourceId, nil)
+	if err != nil {
+		return err
+	}
+
 	source, err := sourceDao.GetByIdWithPreload(&sourceId, "Applications")
 	if err != nil {
 		return err
@@ -348,6 +348,7 @@ func TestSourceList(t *testing.T) {
 	AssertLinks(t, c.Request().RequestURI, out.Links, 100, 0)
 }
 
+// TestSourceListSatellite tests that the systems only list the sources they own.
 func TestSourceListSatellite(t *testing.T) {
 	testutils.SkipIfNotRunningIntegrationTests(t)
 
@@ -360,8 +361,8 @@ func TestSourceListSatellite(t *testing.T) {
 			"offset":   0,
 			"filters":  []util.Filter{},
 			"tenantID": int64(1),
-			// this gets set during the parse middleware
-			"cert-auth": true,
+			// this gets set during the permission check
+			"source-owner": &m.SourceOwner{Key: "cn", ID: "satellite", SourceTypes: []string{"satellite"}},
 		})
 
 	err := SourceList(c)
@@ -526,6 +527,42 @@ func TestSourceCreateBadRequest(t *testing.T) {
 	testutils.BadRequestTest(t, rec)
 }
 
+// TestSourceCreateSystemSourceType tests that the systems can't create sources of the types their policy doesn't
+// allow.
+func TestSourceCreateSystemSourceType(t *testing.T) {
+	name := "TestRequest"
+	var sourceTypeId int64 = 1
+
+	requestBody := m.SourceCreateRequest{
+		Name:            &name,
+		SourceTypeIDRaw: &sourceTypeId,
+	}
+
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		t.Error("Could not marshal JSON")
+	}
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/sources",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID":     int64(1),
+			"source-owner": &m.SourceOwner{Key: "cn", ID: "satellite", SourceTypes: []string{"satellite"}},
+		},
+	)
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	badRequestSourceCreate := ErrorHandlingContext(SourceCreate)
+	err = badRequestSourceCreate(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.BadRequestTest(t, rec)
+}
+
 // TestSourceCreate tests that a 201 is received when a proper JSON message is received
 func TestSourceCreate(t *testing.T) {
 	// Test with a proper JSON
@@ -634,6 +671,59 @@ func TestSourceEdit(t *testing.T) {
 	}
 }
 
+// TestSourceEditPaused tests that a paused source can't be edited.
+func TestSourceEditPaused(t *testing.T) {
+	usePausedSource(t, fixtures.TestSourceData[0])
+
+	body, _ := json.Marshal(m.SourceEditRequest{Name: request.PointerToString("New source name")})
+
+	c, rec := request.CreateTestContext(
+		http.MethodPatch,
+		"/api/sources/v3.1/sources/1",
+		bytes.NewReader(body),
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Request().Header.Add("Content-Type", "application/json;charset=utf-8")
+
+	conflictSourceEdit := ErrorHandlingContext(SourceEdit)
+	err := conflictSourceEdit(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.ConflictTest(t, rec)
+}
+
+// TestSourceCheckAvailabilityPaused tests that the availability of a paused source can't be checked.
+func TestSourceCheckAvailabilityPaused(t *testing.T) {
+	usePausedSource(t, fixtures.TestSourceData[0])
+
+	c, rec := request.CreateTestContext(
+		http.MethodPost,
+		"/api/sources/v3.1/sources/1/check_availability",
+		nil,
+		map[string]interface{}{
+			"tenantID": int64(1),
+		},
+	)
+
+	c.SetParamNames("source_id")
+	c.SetParamValues("1")
+
+	conflictSourceCheckAvailability := ErrorHandlingContext(SourceCheckAvailability)
+	err := conflictSourceCheckAvailability(c)
+	if err != nil {
+		t.Error(err)
+	}
+
+	testutils.ConflictTest(t, rec)
+}
+
 func TestSourceEditNotFound(t *testing.T) {
 	newSourceName := "New source name"
 	req := m.SourceEditRequest{
@@ -761,6 +851,16 @@ func TestAvailabilityStatusCheck(t *testing.T) {
 	if rec.Code != 202 {
 		t.Errorf("Wrong code, got %v, expected %v", rec.Code, 202)
 	}
+
+	var out map[string]string
+	err = json.Unmarshal(rec.Body.Bytes(), &out)
+	if err != nil {
+		t.Error("Failed unmarshaling output")
+	}
+
+	if out["id"] == "" {
+		t.Error("Expected the id of the availability check in the response")
+	}
 }
 
 func TestAvailabilityStatusCheckNotFound(t *testing.T) {
@@ -1,17 +1,15 @@
 package statuslistener
 
 import (
-	"sort"
-	"strings"
-	"time"
+	"context"
 
 	c "github.com/RedHatInsights/sources-api-go/config"
-	"github.com/RedHatInsights/sources-api-go/dao"
 	"github.com/RedHatInsights/sources-api-go/internal/events"
 	"github.com/RedHatInsights/sources-api-go/internal/types"
 	"github.com/RedHatInsights/sources-api-go/kafka"
 	l "github.com/RedHatInsights/sources-api-go/logger"
 	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/RedHatInsights/sources-api-go/service"
 	"github.com/RedHatInsights/sources-api-go/util"
 )
 
@@ -27,9 +25,11 @@ type AvailabilityStatusListener struct {
 	*events.EventStreamProducer
 }
 
-func Run() {
+// Run consumes the availability status messages until the given context is cancelled. Once that happens, it waits
+// for the in-flight messages to be processed and commits their offsets before returning.
+func Run(ctx context.Context) {
 	avs := AvailabilityStatusListener{EventStreamProducer: NewEventStreamProducer()}
-	avs.subscribeToAvailabilityStatus()
+	avs.subscribeToAvailabilityStatus(ctx)
 }
 
 func NewEventStreamProducer() *events.EventStreamProducer {
@@ -37,7 +37,7 @@ func NewEventStreamProducer() *events.EventStreamProducer {
 	return &events.EventStreamProducer{Sender: sender}
 }
 
-func (avs *AvailabilityStatusListener) subscribeToAvailabilityStatus() {
+func (avs *AvailabilityStatusListener) subscribeToAvailabilityStatus(ctx context.Context) {
 	if l.Log == nil {
 		panic("logging is not initialized")
 	}
@@ -50,117 +50,73 @@ func (avs *AvailabilityStatusListener) subscribeToAvailabilityStatus() {
 	}
 
 	kf := &kafka.Manager{Config: kafkaConfig}
-	err := kf.Consume(avs.ConsumeStatusMessage)
+	err := kf.Consume(ctx, avs.ConsumeStatusMessage)
 
 	if err != nil {
 		l.Log.Errorf("Consumer kafka message error: %s", err.Error())
 	}
+
+	l.Log.Info("Closing the availability status consumer...")
+	err = kf.Close()
+	if err != nil {
+		l.Log.Errorf("Unable to close the availability status consumer: %s", err)
+		return
+	}
+	l.Log.Info("Closing the availability status consumer...Complete")
 }
 
 func (avs *AvailabilityStatusListener) ConsumeStatusMessage(message kafka.Message) {
+	headers := avs.headersFrom(message)
+	log := l.Log.WithField("request_id", util.RequestIdFromHeaders(headers))
+
 	var statusMessage types.StatusMessage
 	err := message.ParseTo(&statusMessage)
 	if err != nil {
-		l.Log.Errorf("Error in parsing status message %v", err)
+		log.Errorf("Error in parsing status message %v", err)
 		return
 	}
 
 	if message.GetHeader("event_type") != eventAvailabilityStatus {
-		l.Log.Warnf("Skipping invalid event_type %q", message.GetHeader("event_type"))
+		log.Warnf("Skipping invalid event_type %q", message.GetHeader("event_type"))
 		return
 	}
 
-	l.Log.Infof("Kafka message %s, %s received with payload: %s", message.Headers, message.Key, message.Value)
-
-	headers := avs.headersFrom(message)
+	log.Infof("Kafka message %s, %s received with payload: %s", message.Headers, message.Key, message.Value)
 
 	avs.processEvent(statusMessage, headers)
 }
 
+// headersFrom copies the message's headers, which get forwarded with the events raised for the status update. The
+// messages without a request id get a new one, so that the events can still be correlated with the logs.
 func (avs *AvailabilityStatusListener) headersFrom(message kafka.Message) []kafka.Header {
-	if len(message.Headers) < 1 {
-		return []kafka.Header{}
-	}
-
 	headers := make([]kafka.Header, len(message.Headers))
 	for index, header := range message.Headers {
 		headers[index] = kafka.Header{Key: header.Key, Value: header.Value}
 	}
 
-	return headers
-}
-
-func (avs *AvailabilityStatusListener) processEvent(statusMessage types.StatusMessage, headers []kafka.Header) {
-	resource := &util.Resource{}
-	resource, err := util.ParseStatusMessageToResource(resource, statusMessage)
-	if err != nil {
-		l.Log.Errorf("Invalid Status: %s", statusMessage.Status)
-		return
-	}
-
-	if !util.SliceContainsString(m.AvailabilityStatuses, statusMessage.Status) {
-		l.Log.Errorf("Invalid Status: %s", statusMessage.Status)
-		return
-	}
-
-	updateAttributes := avs.attributesForUpdate(statusMessage)
-	modelEventDao, err := dao.GetFromResourceType(statusMessage.ResourceType)
-	if err != nil {
-		l.Log.Error(err)
-		return
-	}
-
-	accountNumber, err := util.AccountNumberFromHeaders(headers)
-	if err != nil {
-		l.Log.Error(err)
-		return
-	}
-
-	tenantDao := dao.GetTenantDao()
-	tenant, err := tenantDao.TenantByAccountNumber(accountNumber)
-	if err != nil {
-		l.Log.Error(err)
-		return
-	}
-
-	resource.TenantID = tenant.Id
-	resource.AccountNumber = tenant.ExternalTenant
-	err = (*modelEventDao).FetchAndUpdateBy(*resource, updateAttributes)
-
-	if err != nil {
-		l.Log.Errorf("Update error in status availability: %s", err)
-		return
+	if !util.IsValidRequestId(util.RequestIdFromHeaders(headers)) {
+		headers = withoutHeader(headers, util.RequestIdHeader)
+		headers = append(headers, kafka.Header{Key: util.RequestIdHeader, Value: []byte(util.NewRequestId())})
 	}
 
-	updateAttributeKeys := make([]string, 0)
-	for k := range updateAttributes {
-		updateAttributeKeys = append(updateAttributeKeys, k)
+	return headers
+}
 
+// withoutHeader removes the headers with the given key.
+func withoutHeader(headers []kafka.Header, key string) []kafka.Header {
+	kept := headers[:0]
+	for _, header := range headers {
+		if header.Key != key {
+			kept = append(kept, header)
+		}
 	}
-	sort.Strings(updateAttributeKeys)
-
-	err = avs.EventStreamProducer.RaiseEventForUpdate(*resource, updateAttributeKeys, headers)
 
-	if err != nil {
-		l.Log.Errorf("Error in raising event for update: %s, resource: %s(%s)", err.Error(), statusMessage.ResourceType, statusMessage.ResourceID)
-	}
+	return kept
 }
 
-func (avs *AvailabilityStatusListener) attributesForUpdate(statusMessage types.StatusMessage) map[string]interface{} {
-	updateAttributes := make(map[string]interface{})
-
-	// TODO: const this? are we using it elsewhere?
-	updateAttributes["last_checked_at"] = time.Now().Format("2006-01-02T15:04:05.999Z")
-	updateAttributes["availability_status"] = statusMessage.Status
-
-	statusErrorModels := []string{"application", "authentication", "endpoint"}
-	if util.SliceContainsString(statusErrorModels, strings.ToLower(statusMessage.ResourceType)) {
-		updateAttributes["availability_status_error"] = statusMessage.Error
-	}
-
-	if statusMessage.Status == "available" {
-		updateAttributes["last_available_at"] = updateAttributes["last_checked_at"]
+func (avs *AvailabilityStatusListener) processEvent(statusMessage types.StatusMessage, headers []kafka.Header) {
+	err := service.ProcessAvailabilityStatus(avs.EventStreamProducer, statusMessage, headers, m.AvailabilityStatusOriginStatusMessage)
+	if err != nil {
+		l.Log.WithField("request_id", util.RequestIdFromHeaders(headers)).Error(err)
 	}
-
-	return updateAttributes
 }
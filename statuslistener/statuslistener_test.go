@@ -39,6 +39,9 @@ type MockEventStreamSender struct {
 	types.StatusMessage
 
 	RaiseEventCalled bool
+	// derivedSourceUpdate is set once the events for the source's derived availability status start, since they don't
+	// belong to the resource under test.
+	derivedSourceUpdate bool
 }
 
 func LoadJSONContentFrom(resourceType string, resourceID string, prefix string) []byte {
@@ -344,6 +347,14 @@ func (streamProducerSender *MockEventStreamSender) RaiseEvent(eventType string,
 	streamProducerSender.RaiseEventCalled = true
 	var err error
 
+	if eventType == "Source.update" && streamProducerSender.ResourceType != "Source" {
+		streamProducerSender.derivedSourceUpdate = true
+	}
+
+	if streamProducerSender.derivedSourceUpdate {
+		return nil
+	}
+
 	for _, data := range testData {
 		if streamProducerSender.ResourceType == data.ResourceType && streamProducerSender.ResourceID == data.ResourceID {
 			var isResult bool
@@ -450,3 +461,27 @@ func TestConsumeStatusMessage(t *testing.T) {
 		}
 	}
 }
+
+// TestHeadersFromRequestId tests that the request id of the status messages gets forwarded, and that the messages
+// without a valid one get a new one.
+func TestHeadersFromRequestId(t *testing.T) {
+	avs := AvailabilityStatusListener{}
+	identity := kafkaGo.Header{Key: "x-rh-identity", Value: []byte("identity")}
+
+	headers := avs.headersFrom(kafka.Message{Headers: []kafkaGo.Header{identity, {Key: "x-rh-insights-request-id", Value: []byte("request-id")}}})
+	if len(headers) != 2 || util.RequestIdFromHeaders(headers) != "request-id" {
+		t.Errorf(`want the request id "request-id" forwarded, got %+v`, headers)
+	}
+
+	for _, messageHeaders := range [][]kafkaGo.Header{
+		{identity},
+		{identity, {Key: "x-rh-insights-request-id", Value: []byte("id\nwith a new line")}},
+	} {
+		headers = avs.headersFrom(kafka.Message{Headers: messageHeaders})
+
+		requestId := util.RequestIdFromHeaders(headers)
+		if len(headers) != 2 || !util.IsValidRequestId(requestId) || requestId == string(messageHeaders[len(messageHeaders)-1].Value) {
+			t.Errorf("want a new request id, got %+v", headers)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -9,6 +10,7 @@ import (
 
 var ErrNotFoundEmpty = NewErrNotFound("")
 var ErrBadRequestEmpty = NewErrBadRequest("")
+var ErrConflictEmpty = NewErrConflict("")
 
 type Error struct {
 	Detail string `json:"detail"`
@@ -18,8 +20,11 @@ type ErrorDocument struct {
 	Errors []Error `json:"errors"`
 }
 
-func ErrorDoc(message, status string) *ErrorDocument {
-	l.Log.Error(message)
+// ErrorDoc logs the error message with the logger of the request the context belongs to, and returns it as an error
+// document. The secrets the message might include, such as the ones of the requests' payloads, are redacted from both.
+func ErrorDoc(ctx context.Context, message, status string) *ErrorDocument {
+	message = l.Redact(message)
+	l.FromContext(ctx).Error(message)
 
 	return &ErrorDocument{
 		[]Error{{
@@ -67,3 +72,21 @@ func NewErrBadRequest(t interface{}) error {
 		panic("bad interface type for bad request: " + reflect.ValueOf(t).String())
 	}
 }
+
+// ErrConflict is returned when the request can't be fulfilled due to the current state of the resource, such as it
+// being paused.
+type ErrConflict struct {
+	Message string
+}
+
+func (e ErrConflict) Error() string {
+	return fmt.Sprintf("conflict: %s", e.Message)
+}
+
+func (e ErrConflict) Is(err error) bool {
+	return reflect.TypeOf(err) == reflect.TypeOf(e)
+}
+
+func NewErrConflict(message string) error {
+	return ErrConflict{Message: message}
+}
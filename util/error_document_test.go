@@ -0,0 +1,32 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	l "github.com/RedHatInsights/sources-api-go/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// TestErrorDocRedactsSecrets tests that the secrets in the error messages reach neither the logs nor the clients.
+func TestErrorDocRedactsSecrets(t *testing.T) {
+	original := l.Log
+	t.Cleanup(func() { l.Log = original })
+
+	out := &bytes.Buffer{}
+	l.Log = &logrus.Logger{Out: out, Level: logrus.ErrorLevel, Formatter: &logrus.JSONFormatter{}, Hooks: make(logrus.LevelHooks)}
+
+	secret := "vault-password-7c1e"
+	doc := ErrorDoc(context.Background(), `Validation failed: {"username":"admin","password":"`+secret+`"}`, "400")
+
+	detail := doc.Errors[0].Detail
+	if strings.Contains(detail, secret) || !strings.Contains(detail, `"username":"admin"`) {
+		t.Errorf(`want the password redacted from the detail, got %q`, detail)
+	}
+
+	if strings.Contains(out.String(), secret) {
+		t.Errorf(`want the password redacted from the logs, got %q`, out.String())
+	}
+}
@@ -1,11 +1,65 @@
 package util
 
-import "regexp"
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 var FilterRegex = regexp.MustCompile(`^filter\[(\w+)](\[\w*]|$)`)
 
+// SourceScopeOperation is the operation of the filters which restrict the lists to the sources granted by the RBAC
+// resource definitions. The query parameters can't set it, since their operations always come in brackets.
+const SourceScopeOperation = "source_scope"
+
+// the prefixes which tell apart the source ids from the source type names in the values of the source scope filters.
+const (
+	sourceScopeIdPrefix   = "id:"
+	sourceScopeTypePrefix = "source_type:"
+)
+
 type Filter struct {
 	Name      string
 	Operation string
 	Value     []string
 }
+
+// SourceScope holds the source ids and the source type names that the RBAC resource definitions grant access to.
+type SourceScope struct {
+	SourceIds   []int64
+	SourceTypes []string
+}
+
+// Filter returns the filter which restricts a list to the sources of the scope, through the given column of the
+// listed resources, which holds their source ids.
+func (s SourceScope) Filter(column string) Filter {
+	values := make([]string, 0, len(s.SourceIds)+len(s.SourceTypes))
+	for _, id := range s.SourceIds {
+		values = append(values, sourceScopeIdPrefix+strconv.FormatInt(id, 10))
+	}
+
+	for _, sourceType := range s.SourceTypes {
+		values = append(values, sourceScopeTypePrefix+sourceType)
+	}
+
+	return Filter{Name: column, Operation: SourceScopeOperation, Value: values}
+}
+
+// SourceScopeFrom returns the scope that the given source scope filter restricts the lists to.
+func SourceScopeFrom(filter Filter) SourceScope {
+	scope := SourceScope{SourceIds: make([]int64, 0), SourceTypes: make([]string, 0)}
+
+	for _, value := range filter.Value {
+		switch {
+		case strings.HasPrefix(value, sourceScopeIdPrefix):
+			id, err := strconv.ParseInt(strings.TrimPrefix(value, sourceScopeIdPrefix), 10, 64)
+			if err == nil {
+				scope.SourceIds = append(scope.SourceIds, id)
+			}
+		case strings.HasPrefix(value, sourceScopeTypePrefix):
+			scope.SourceTypes = append(scope.SourceTypes, strings.TrimPrefix(value, sourceScopeTypePrefix))
+		}
+	}
+
+	return scope
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSourceScopeFilter tests that the source scopes survive the filters which carry them.
+func TestSourceScopeFilter(t *testing.T) {
+	scope := SourceScope{SourceIds: []int64{1, 2}, SourceTypes: []string{"amazon", "source_type:azure"}}
+
+	filter := scope.Filter("source_id")
+	if filter.Name != "source_id" || filter.Operation != SourceScopeOperation {
+		t.Errorf(`want a source scope filter on "source_id", got "%v"`, filter)
+	}
+
+	if got := SourceScopeFrom(filter); !reflect.DeepEqual(got, scope) {
+		t.Errorf(`want the scope "%v", got "%v"`, scope, got)
+	}
+
+	empty := SourceScopeFrom(SourceScope{}.Filter("id"))
+	if len(empty.SourceIds) != 0 || len(empty.SourceTypes) != 0 {
+		t.Errorf(`want an empty scope, got "%v"`, empty)
+	}
+}
@@ -11,19 +11,43 @@ type minimalIdentityHeader struct {
 
 type id struct {
 	Account string `json:"account_number"`
+	OrgID   string `json:"org_id,omitempty"`
+	// Internal holds the organization id where the services which haven't moved to the top level "org_id" yet look for
+	// it.
+	Internal internal `json:"internal"`
 }
 
-func newMinimalIdentity(account string) *minimalIdentityHeader {
-	return &minimalIdentityHeader{Identity: id{Account: account}}
+type internal struct {
+	OrgID string `json:"org_id,omitempty"`
+}
+
+func newMinimalIdentity(account, orgId string) *minimalIdentityHeader {
+	return &minimalIdentityHeader{Identity: id{Account: account, OrgID: orgId, Internal: internal{OrgID: orgId}}}
 }
 
 // returns a base64 encoded header to use as x-rh-identity when one is not
 // provided
-func XRhIdentityWithAccountNumber(account string) string {
-	bytes, err := json.Marshal(newMinimalIdentity(account))
+func GeneratedXRhIdentity(account, orgId string) string {
+	bytes, err := json.Marshal(newMinimalIdentity(account, orgId))
 	if err != nil {
 		return ""
 	}
 
 	return base64.StdEncoding.EncodeToString(bytes)
 }
+
+// OrgIdFromIdentity returns the organization id of the decoded identity, either from its top level "org_id" or from
+// the legacy "internal.org_id". The identity library only knows about the latter, which is why it is parsed on its
+// own.
+func OrgIdFromIdentity(decoded []byte) string {
+	var identity minimalIdentityHeader
+	if err := json.Unmarshal(decoded, &identity); err != nil {
+		return ""
+	}
+
+	if identity.Identity.OrgID != "" {
+		return identity.Identity.OrgID
+	}
+
+	return identity.Identity.Internal.OrgID
+}
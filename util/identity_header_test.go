@@ -9,7 +9,7 @@ import (
 )
 
 func TestCreateIdentityHeader(t *testing.T) {
-	out := XRhIdentityWithAccountNumber("1234")
+	out := GeneratedXRhIdentity("1234", "5678")
 
 	bytes, err := base64.StdEncoding.DecodeString(out)
 	if err != nil {
@@ -25,4 +25,31 @@ func TestCreateIdentityHeader(t *testing.T) {
 	if identity.Identity.AccountNumber != "1234" {
 		t.Errorf("did not marshal correctly, got %v wanted %v", identity.Identity.AccountNumber, "1234")
 	}
+
+	if identity.Identity.Internal.OrgID != "5678" {
+		t.Errorf("did not marshal correctly, got %v wanted %v", identity.Identity.Internal.OrgID, "5678")
+	}
+
+	if OrgIdFromIdentity(bytes) != "5678" {
+		t.Errorf("did not marshal correctly, got %v wanted %v", OrgIdFromIdentity(bytes), "5678")
+	}
+}
+
+func TestOrgIdFromIdentity(t *testing.T) {
+	tests := []struct {
+		identity string
+		want     string
+	}{
+		{`{"identity": {"account_number": "1234", "org_id": "5678"}}`, "5678"},
+		{`{"identity": {"org_id": "5678", "internal": {"org_id": "9999"}}}`, "5678"},
+		{`{"identity": {"internal": {"org_id": "9999"}}}`, "9999"},
+		{`{"identity": {"account_number": "1234"}}`, ""},
+		{`not json`, ""},
+	}
+
+	for _, test := range tests {
+		if got := OrgIdFromIdentity([]byte(test.identity)); got != test.want {
+			t.Errorf("want %q for %s, got %q", test.want, test.identity, got)
+		}
+	}
 }
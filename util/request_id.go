@@ -0,0 +1,37 @@
+package util
+
+import (
+	"regexp"
+
+	"github.com/RedHatInsights/sources-api-go/kafka"
+	"github.com/google/uuid"
+)
+
+// RequestIdHeader carries the id which correlates the logs of a request with the events, the outbound requests and
+// the status messages that follow from it.
+const RequestIdHeader = "x-rh-insights-request-id"
+
+// validRequestId limits the ids accepted from the callers, since they end up in every log line.
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewRequestId generates an id for the requests and the messages which don't come with one.
+func NewRequestId() string {
+	return uuid.New().String()
+}
+
+// IsValidRequestId tells whether the id received from a caller can be used as is.
+func IsValidRequestId(id string) bool {
+	return validRequestId.MatchString(id)
+}
+
+// RequestIdFromHeaders returns the request id of the message with the given headers, or an empty string when it has
+// none.
+func RequestIdFromHeaders(headers []kafka.Header) string {
+	for _, header := range headers {
+		if header.Key == RequestIdHeader {
+			return string(header.Value)
+		}
+	}
+
+	return ""
+}
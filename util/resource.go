@@ -14,6 +14,7 @@ type Resource struct {
 	ResourceUID   string
 	TenantID      int64
 	AccountNumber string
+	OrgID         string
 }
 
 func ParseStatusMessageToResource(resource *Resource, statusMessage types.StatusMessage) (*Resource, error) {
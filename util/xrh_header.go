@@ -11,6 +11,7 @@ import (
 
 const (
 	xrhAccountNumberKey string = "x-rh-sources-account-number"
+	xrhOrgIdKey         string = "x-rh-sources-org-id"
 	xrhIdentityKey      string = "x-rh-identity"
 )
 
@@ -29,21 +30,40 @@ func ParseXRHIDHeader(inputIdentity string) (*identity.XRHID, error) {
 	return XRHIdentity, nil
 }
 
-func AccountNumberFromHeaders(headers []kafka.Header) (string, error) {
+// TenantFromHeaders returns the account number and the organization id of the tenant the headers refer to. Both of
+// them are taken from the same place, so that a tenant can't be made out of one tenant's account number and another
+// one's organization id: the "x-rh-sources-account-number" and "x-rh-sources-org-id" headers are used when any of them
+// is present, and the identity's ones otherwise.
+func TenantFromHeaders(headers []kafka.Header) (string, string, error) {
+	var accountNumber, orgId string
+	var xrhid []byte
+
 	for _, header := range headers {
-		if header.Key == xrhAccountNumberKey {
-			return string(header.Value), nil
+		switch header.Key {
+		case xrhAccountNumberKey:
+			accountNumber = string(header.Value)
+		case xrhOrgIdKey:
+			orgId = string(header.Value)
+		case xrhIdentityKey:
+			xrhid = header.Value
 		}
+	}
 
-		if header.Key == xrhIdentityKey {
-			XRHIdentity, err := ParseXRHIDHeader(string(header.Value))
-			if err != nil {
-				return "", err
-			}
-
-			return XRHIdentity.Identity.AccountNumber, nil
+	if accountNumber == "" && orgId == "" && xrhid != nil {
+		XRHIdentity, err := ParseXRHIDHeader(string(xrhid))
+		if err != nil {
+			return "", "", err
 		}
+
+		decoded, _ := base64.StdEncoding.DecodeString(string(xrhid))
+
+		accountNumber = XRHIdentity.Identity.AccountNumber
+		orgId = OrgIdFromIdentity(decoded)
+	}
+
+	if accountNumber == "" && orgId == "" {
+		return "", "", fmt.Errorf("unable to get the tenant from headers, %s, %s and %s are missing", xrhAccountNumberKey, xrhOrgIdKey, xrhIdentityKey)
 	}
 
-	return "", fmt.Errorf("unable to get account number from headers, %s and %s are missing", xrhAccountNumberKey, xrhIdentityKey)
+	return accountNumber, orgId, nil
 }
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/sources-api-go/kafka"
+)
+
+func TestTenantFromHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		headers       []kafka.Header
+		accountNumber string
+		orgId         string
+	}{
+		{
+			name: "psk headers",
+			headers: []kafka.Header{
+				{Key: "x-rh-sources-account-number", Value: []byte("1234")},
+				{Key: "x-rh-sources-org-id", Value: []byte("5678")},
+			},
+			accountNumber: "1234",
+			orgId:         "5678",
+		},
+		{
+			name:          "identity only",
+			headers:       []kafka.Header{{Key: "x-rh-identity", Value: []byte(GeneratedXRhIdentity("1234", "5678"))}},
+			accountNumber: "1234",
+			orgId:         "5678",
+		},
+		{
+			name:    "org id only",
+			headers: []kafka.Header{{Key: "x-rh-identity", Value: []byte(GeneratedXRhIdentity("", "5678"))}},
+			orgId:   "5678",
+		},
+		{
+			// the identifiers never get mixed up, since they could belong to different tenants.
+			name: "psk headers take precedence",
+			headers: []kafka.Header{
+				{Key: "x-rh-identity", Value: []byte(GeneratedXRhIdentity("1111", "2222"))},
+				{Key: "x-rh-sources-account-number", Value: []byte("1234")},
+			},
+			accountNumber: "1234",
+		},
+		{
+			name: "psk org id takes precedence",
+			headers: []kafka.Header{
+				{Key: "x-rh-identity", Value: []byte(GeneratedXRhIdentity("1111", "2222"))},
+				{Key: "x-rh-sources-org-id", Value: []byte("5678")},
+			},
+			orgId: "5678",
+		},
+	}
+
+	for _, test := range tests {
+		accountNumber, orgId, err := TenantFromHeaders(test.headers)
+		if err != nil {
+			t.Errorf("%s: want no error, got %s", test.name, err)
+			continue
+		}
+
+		if accountNumber != test.accountNumber || orgId != test.orgId {
+			t.Errorf("%s: want %q and %q, got %q and %q", test.name, test.accountNumber, test.orgId, accountNumber, orgId)
+		}
+	}
+
+	_, _, err := TenantFromHeaders([]kafka.Header{{Key: "event_type", Value: []byte("Source.create")}})
+	if err == nil {
+		t.Errorf("want an error when the tenant is missing, got none")
+	}
+}
@@ -0,0 +1,82 @@
+package verifiers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const defaultAwsRegion = "us-east-1"
+
+// awsAccessKeyVerifier checks the access key and the secret key by fetching the caller's identity from STS, which
+// doesn't require any permission.
+type awsAccessKeyVerifier struct {
+	endpoint string
+	region   string
+	client   *http.Client
+}
+
+func (v *awsAccessKeyVerifier) Verify(ctx context.Context, auth *m.Authentication) error {
+	if auth.Username == "" || auth.Password == "" {
+		return errors.New("missing access key or secret key")
+	}
+
+	region := v.region
+	if region == "" {
+		region = defaultAwsRegion
+	}
+
+	awsConfig := aws.NewConfig().
+		WithRegion(region).
+		WithCredentials(credentials.NewStaticCredentials(auth.Username, auth.Password, "")).
+		WithHTTPClient(v.client).
+		WithMaxRetries(0)
+
+	if v.endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(v.endpoint)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = sts.New(sess).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) {
+			return fmt.Errorf("invalid credentials: %s", awsErr.Message())
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// awsArnVerifier checks that the authentication holds a well formed IAM role ARN. Assuming the role requires
+// credentials of our own which the role trusts, so that is left to the applications.
+type awsArnVerifier struct{}
+
+func (v *awsArnVerifier) Verify(_ context.Context, auth *m.Authentication) error {
+	roleArn, err := arn.Parse(auth.Username)
+	if err != nil {
+		return fmt.Errorf("invalid ARN: %w", err)
+	}
+
+	if roleArn.Service != "iam" || !strings.HasPrefix(roleArn.Resource, "role/") {
+		return fmt.Errorf("invalid ARN: %q is not an IAM role", auth.Username)
+	}
+
+	return nil
+}
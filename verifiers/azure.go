@@ -0,0 +1,85 @@
+package verifiers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+const (
+	defaultAzureLoginEndpoint = "https://login.microsoftonline.com"
+	azureManagementScope      = "https://management.azure.com/.default"
+)
+
+// azureClientSecretVerifier checks the service principal's credentials by requesting a token for the management API
+// with the client credentials grant.
+type azureClientSecretVerifier struct {
+	endpoint string
+	client   *http.Client
+}
+
+// azureTokenError is the error body of the token endpoint.
+type azureTokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (v *azureClientSecretVerifier) Verify(ctx context.Context, auth *m.Authentication) error {
+	tenantId := azureTenantId(auth)
+	if tenantId == "" || auth.Username == "" || auth.Password == "" {
+		return errors.New("missing tenant id, client id or client secret")
+	}
+
+	endpoint := v.endpoint
+	if endpoint == "" {
+		endpoint = defaultAzureLoginEndpoint
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {auth.Username},
+		"client_secret": {auth.Password},
+		"scope":         {azureManagementScope},
+	}
+
+	tokenUrl := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(endpoint, "/"), url.PathEscape(tenantId))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var tokenErr azureTokenError
+	if json.NewDecoder(resp.Body).Decode(&tokenErr) == nil && tokenErr.Error != "" {
+		return fmt.Errorf("invalid credentials: %s", tokenErr.Error)
+	}
+
+	return fmt.Errorf("invalid credentials: token request returned %d", resp.StatusCode)
+}
+
+// azureTenantId returns the tenant id, which is stored in the authentication's "extra.azure.tenant_id".
+func azureTenantId(auth *m.Authentication) string {
+	azure, ok := auth.Extra["azure"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	tenantId, _ := azure["tenant_id"].(string)
+	return tenantId
+}
@@ -0,0 +1,60 @@
+package verifiers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+func TestVerifyAzureClientSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tenant/oauth2/v2.0/token" {
+			t.Errorf("want the tenant's token path, got %q", r.URL.Path)
+		}
+
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("client_id") != "client" || r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "invalid_client", "error_description": "AADSTS7000215: Invalid client secret provided."}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"token_type": "Bearer", "expires_in": 3599, "access_token": "token"}`))
+	}))
+	defer server.Close()
+
+	v := New(Config{AzureLoginEndpoint: server.URL})
+	auth := func(clientId, secret string) *m.Authentication {
+		return &m.Authentication{
+			AuthType: "tenant_id_client_id_client_secret",
+			Username: clientId,
+			Password: secret,
+			Extra:    map[string]interface{}{"azure": map[string]interface{}{"tenant_id": "tenant"}},
+		}
+	}
+
+	result, _ := v.VerifyAuthentication(context.Background(), auth("client", "secret"))
+	if result.Status != statusAvailable {
+		t.Errorf("want available, got %+v", result)
+	}
+
+	result, _ = v.VerifyAuthentication(context.Background(), auth("client", "wrong"))
+	if result.Status != statusUnavailable || !strings.Contains(result.Error, "invalid_client") {
+		t.Errorf("want unavailable with the token error, got %+v", result)
+	}
+
+	missingTenant := auth("client", "secret")
+	missingTenant.Extra = nil
+	result, _ = v.VerifyAuthentication(context.Background(), missingTenant)
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable without the tenant id, got %+v", result)
+	}
+}
@@ -0,0 +1,257 @@
+package verifiers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// errUnreachable is returned for every endpoint which couldn't be connected to, so that the stored errors don't tell
+// apart the refused connections, the timeouts and the forbidden addresses.
+var errUnreachable = errors.New("the endpoint is unreachable")
+
+// forbiddenNetworks are the loopback, private, link-local —which includes the cloud providers' metadata services— and
+// otherwise special networks that the endpoints must not point at, since the requests are made from inside the
+// cluster.
+var forbiddenNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// endpointVerifier checks that the endpoint answers. For the HTTP endpoints any response counts, since the point is
+// reaching the endpoint and trusting its certificate, and not being authorized by it. The rest of the endpoints only
+// need to accept a TCP connection.
+type endpointVerifier struct {
+	timeout time.Duration
+	// allowPrivateAddresses lets the endpoints resolve to the forbidden networks.
+	allowPrivateAddresses bool
+}
+
+// Certificate is the certificate an endpoint presented.
+type Certificate struct {
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+}
+
+// Verify checks the endpoint, and returns the certificate it presented when it is a TLS one. The certificate is
+// returned even when it isn't trusted.
+func (v *endpointVerifier) Verify(ctx context.Context, endpoint *m.Endpoint) (*Certificate, error) {
+	endpointUrl, err := endpointURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: v.timeout}
+	if !v.allowPrivateAddresses {
+		// the check is made on the resolved address right before connecting, which covers the redirections and the
+		// host names which resolve to a different address than the one that might have been checked beforehand.
+		dialer.Control = rejectForbiddenAddress
+	}
+
+	if endpointUrl.Scheme != "http" && endpointUrl.Scheme != "https" {
+		conn, err := dialer.DialContext(ctx, "tcp", endpointUrl.Host)
+		if err != nil {
+			return nil, errUnreachable
+		}
+
+		return nil, conn.Close()
+	}
+
+	var presented *Certificate
+	var certificateErr error
+	tlsConfig, err := endpointTLSConfig(endpoint, func(certificate *x509.Certificate) {
+		presented = &Certificate{
+			Subject:  certificate.Subject.String(),
+			Issuer:   certificate.Issuer.String(),
+			NotAfter: certificate.NotAfter,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	verifyConnection := tlsConfig.VerifyConnection
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		certificateErr = verifyConnection(state)
+		return certificateErr
+	}
+
+	client := &http.Client{
+		Timeout:   v.timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, DialContext: dialer.DialContext},
+		// the redirections might point anywhere, so the endpoint answering is enough.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpointUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// the certificate errors are the only ones worth telling, since the endpoint was reached.
+		if certificateErr != nil {
+			return presented, certificateErr
+		}
+
+		return presented, errUnreachable
+	}
+
+	return presented, resp.Body.Close()
+}
+
+// rejectForbiddenAddress is a dialer's control function which refuses connecting to the forbidden networks.
+func rejectForbiddenAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %q", host)
+	}
+
+	if isForbiddenAddress(ip) {
+		return fmt.Errorf("the address %s is not allowed", ip)
+	}
+
+	return nil
+}
+
+// isForbiddenAddress returns true when the address belongs to any of the forbidden networks.
+func isForbiddenAddress(ip net.IP) bool {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+	}
+
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseNetworks parses the given CIDRs, panicking on the invalid ones.
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = network
+	}
+
+	return networks
+}
+
+// endpointURL builds the endpoint's URL from its scheme, host, port and path.
+func endpointURL(endpoint *m.Endpoint) (*url.URL, error) {
+	if endpoint.Host == nil || *endpoint.Host == "" {
+		return nil, errors.New("the endpoint has no host")
+	}
+
+	scheme := "https"
+	if endpoint.Scheme != nil && *endpoint.Scheme != "" {
+		scheme = *endpoint.Scheme
+	}
+
+	host := *endpoint.Host
+	switch {
+	case endpoint.Port != nil:
+		host = net.JoinHostPort(host, strconv.Itoa(*endpoint.Port))
+	case scheme == "https":
+		host = net.JoinHostPort(host, "443")
+	case scheme == "http":
+		host = net.JoinHostPort(host, "80")
+	default:
+		return nil, fmt.Errorf("the endpoint has no port for the %q scheme", scheme)
+	}
+
+	endpointUrl := &url.URL{Scheme: scheme, Host: host}
+	if endpoint.Path != nil {
+		endpointUrl.Path = *endpoint.Path
+	}
+
+	return endpointUrl, nil
+}
+
+// endpointTLSConfig trusts the endpoint's certificate authority when it has one, and skips the verification of the
+// certificate when the endpoint says so. The verification is done by hand, so that the presented certificate reaches
+// the given callback even when it isn't trusted.
+func endpointTLSConfig(endpoint *m.Endpoint, presented func(*x509.Certificate)) (*tls.Config, error) {
+	verify := endpoint.VerifySsl == nil || *endpoint.VerifySsl
+
+	var roots *x509.CertPool
+	if verify && endpoint.CertificateAuthority != nil && *endpoint.CertificateAuthority != "" {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(*endpoint.CertificateAuthority)) {
+			return nil, errors.New("invalid certificate authority")
+		}
+	}
+
+	var serverName string
+	if endpoint.Host != nil {
+		serverName = *endpoint.Host
+	}
+
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // #nosec G402 -- the certificate is verified in VerifyConnection.
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("the endpoint presented no certificate")
+			}
+
+			leaf := state.PeerCertificates[0]
+			presented(leaf)
+
+			if !verify {
+				return nil
+			}
+
+			intermediates := x509.NewCertPool()
+			for _, certificate := range state.PeerCertificates[1:] {
+				intermediates.AddCert(certificate)
+			}
+
+			_, err := leaf.Verify(x509.VerifyOptions{DNSName: serverName, Roots: roots, Intermediates: intermediates})
+			if err != nil {
+				return fmt.Errorf("certificate verification failed: %w", err)
+			}
+
+			return nil
+		},
+	}, nil
+}
@@ -0,0 +1,192 @@
+package verifiers
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// endpointFor returns an endpoint pointing at the given server.
+func endpointFor(t *testing.T, serverUrl string) *m.Endpoint {
+	parsed, err := url.Parse(serverUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host, rawPort, err := net.SplitHostPort(parsed.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port, _ := strconv.Atoi(rawPort)
+	path := "/api"
+
+	return &m.Endpoint{Scheme: &parsed.Scheme, Host: &host, Port: &port, Path: &path}
+}
+
+func TestVerifyEndpoint(t *testing.T) {
+	// any answer means that the endpoint is reachable.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api" {
+			t.Errorf("want the endpoint's path, got %q", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	v := New(Config{AllowPrivateEndpoints: true})
+	endpoint := endpointFor(t, server.URL)
+
+	result := v.VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusAvailable {
+		t.Errorf("want available, got %+v", result)
+	}
+
+	server.Close()
+
+	result = v.VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable once the server is gone, got %+v", result)
+	}
+
+	result = v.VerifyEndpoint(context.Background(), &m.Endpoint{})
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable without a host, got %+v", result)
+	}
+}
+
+func TestVerifyEndpointTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	v := New(Config{AllowPrivateEndpoints: true})
+
+	// the server's certificate isn't trusted by default.
+	endpoint := endpointFor(t, server.URL)
+	result := v.VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable for an untrusted certificate, got %+v", result)
+	}
+
+	// the untrusted certificate gets recorded nonetheless.
+	if result.Certificate == nil || result.Certificate.Issuer != server.Certificate().Issuer.String() || !result.Certificate.NotAfter.Equal(server.Certificate().NotAfter) {
+		t.Errorf("want the server's certificate, got %+v", result.Certificate)
+	}
+
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	endpoint.CertificateAuthority = &ca
+	result = v.VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusAvailable {
+		t.Errorf("want available with the endpoint's certificate authority, got %+v", result)
+	}
+
+	verifySsl := false
+	endpoint = endpointFor(t, server.URL)
+	endpoint.VerifySsl = &verifySsl
+	result = v.VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusAvailable {
+		t.Errorf("want available without verifying the certificate, got %+v", result)
+	}
+
+	invalidCa := "not a certificate"
+	endpoint = endpointFor(t, server.URL)
+	endpoint.CertificateAuthority = &invalidCa
+	result = v.VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable for an invalid certificate authority, got %+v", result)
+	}
+}
+
+func TestVerifyEndpointCertificateExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	verifySsl := false
+	endpoint := endpointFor(t, server.URL)
+	endpoint.VerifySsl = &verifySsl
+
+	result := New(Config{CertificateExpiryWindow: time.Hour, AllowPrivateEndpoints: true}).VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusAvailable || result.Error != "" {
+		t.Errorf("want available without errors, got %+v", result)
+	}
+
+	// a window which reaches the certificate's expiry warns about it, but keeps the endpoint available.
+	window := time.Until(server.Certificate().NotAfter) + time.Hour
+	result = New(Config{CertificateExpiryWindow: window, AllowPrivateEndpoints: true}).VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusAvailable || !strings.Contains(result.Error, "the certificate expires on") {
+		t.Errorf("want available with an expiry warning, got %+v", result)
+	}
+}
+
+func TestVerifyEndpointTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host, rawPort, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(rawPort)
+	scheme := "amqp"
+	endpoint := &m.Endpoint{Scheme: &scheme, Host: &host, Port: &port}
+
+	result := New(Config{AllowPrivateEndpoints: true}).VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusAvailable {
+		t.Errorf("want available, got %+v", result)
+	}
+
+	listener.Close()
+
+	result = New(Config{AllowPrivateEndpoints: true}).VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable once the listener is closed, got %+v", result)
+	}
+}
+
+func TestVerifyEndpointForbiddenAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the endpoint must not be reached")
+	}))
+	defer server.Close()
+
+	v := New(Config{})
+
+	// the loopback addresses are refused unless they are explicitly allowed, and the error doesn't tell why.
+	result := v.VerifyEndpoint(context.Background(), endpointFor(t, server.URL))
+	if result.Status != statusUnavailable || result.Error != errUnreachable.Error() {
+		t.Errorf("want unavailable with a generic error, got %+v", result)
+	}
+
+	// the host names get checked once resolved.
+	host := "localhost"
+	endpoint := endpointFor(t, server.URL)
+	endpoint.Host = &host
+
+	result = v.VerifyEndpoint(context.Background(), endpoint)
+	if result.Status != statusUnavailable || result.Error != errUnreachable.Error() {
+		t.Errorf("want unavailable for a host name resolving to a loopback address, got %+v", result)
+	}
+}
+
+func TestIsForbiddenAddress(t *testing.T) {
+	forbidden := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254"}
+	for _, address := range forbidden {
+		if !isForbiddenAddress(net.ParseIP(address)) {
+			t.Errorf("want %s to be forbidden", address)
+		}
+	}
+
+	allowed := []string{"8.8.8.8", "172.32.0.1", "2001:4860:4860::8888"}
+	for _, address := range allowed {
+		if isForbiddenAddress(net.ParseIP(address)) {
+			t.Errorf("want %s to be allowed", address)
+		}
+	}
+}
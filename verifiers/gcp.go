@@ -0,0 +1,153 @@
+package verifiers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+const (
+	defaultGcpTokenEndpoint = "https://oauth2.googleapis.com/token"
+	gcpReadOnlyScope        = "https://www.googleapis.com/auth/cloud-platform.read-only"
+	gcpJwtBearerGrant       = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+// gcpServiceAccountVerifier checks the service account key by exchanging a signed assertion for an access token. The
+// assertion is always sent to the configured token endpoint, and never to the "token_uri" of the key.
+type gcpServiceAccountVerifier struct {
+	endpoint string
+	client   *http.Client
+}
+
+// gcpServiceAccount holds the fields of the service account key the verifier needs.
+type gcpServiceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+}
+
+func (v *gcpServiceAccountVerifier) Verify(ctx context.Context, auth *m.Authentication) error {
+	var account gcpServiceAccount
+	err := json.Unmarshal([]byte(auth.Password), &account)
+	if err != nil {
+		return fmt.Errorf("invalid service account JSON: %w", err)
+	}
+
+	if account.Type != "service_account" || account.ClientEmail == "" {
+		return errors.New("invalid service account JSON: not a service account key")
+	}
+
+	if auth.Username != "" && account.ProjectID != "" && auth.Username != account.ProjectID {
+		return fmt.Errorf("the service account belongs to project %q instead of %q", account.ProjectID, auth.Username)
+	}
+
+	key, err := parseRsaPrivateKey(account.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("invalid service account JSON: %w", err)
+	}
+
+	endpoint := v.endpoint
+	if endpoint == "" {
+		endpoint = defaultGcpTokenEndpoint
+	}
+
+	assertion, err := signJwt(key, account.PrivateKeyID, map[string]interface{}{
+		"iss":   account.ClientEmail,
+		"scope": gcpReadOnlyScope,
+		"aud":   endpoint,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{"grant_type": {gcpJwtBearerGrant}, "assertion": {assertion}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var tokenErr struct {
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&tokenErr) == nil && tokenErr.Error != "" {
+		return fmt.Errorf("invalid credentials: %s", tokenErr.Error)
+	}
+
+	return fmt.Errorf("invalid credentials: token request returned %d", resp.StatusCode)
+}
+
+// parseRsaPrivateKey parses the PEM encoded PKCS #8 or PKCS #1 RSA private key of the service account.
+func parseRsaPrivateKey(rawKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(rawKey))
+	if block == nil {
+		return nil, errors.New("the private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the private key is not an RSA key")
+	}
+
+	return key, nil
+}
+
+// signJwt returns the RS256 signed JWT with the given claims.
+func signJwt(key *rsa.PrivateKey, keyId string, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyId})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
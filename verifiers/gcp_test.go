@@ -0,0 +1,104 @@
+package verifiers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+// serviceAccountJSON returns a service account key with a freshly generated private key.
+func serviceAccountJSON(t *testing.T, projectId string) (string, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     projectId,
+		"private_key_id": "key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "sources@" + projectId + ".iam.gserviceaccount.com",
+		"token_uri":      "https://attacker.example.com/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(account), key
+}
+
+func TestVerifyGcpServiceAccount(t *testing.T) {
+	account, key := serviceAccountJSON(t, "project")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != gcpJwtBearerGrant {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+
+		// the assertion must be signed with the service account's key.
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("want a JWT assertion, got %q", r.Form.Get("assertion"))
+		}
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": 3599, "token_type": "Bearer"}`))
+	}))
+	defer server.Close()
+
+	v := New(Config{GcpTokenEndpoint: server.URL})
+
+	result, _ := v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "project_id_service_account_json", Username: "project", Password: account})
+	if result.Status != statusAvailable {
+		t.Errorf("want available, got %+v", result)
+	}
+
+	// a key which doesn't belong to the service account is rejected by the token endpoint.
+	_, otherKey := serviceAccountJSON(t, "project")
+	otherDer, _ := x509.MarshalPKCS8PrivateKey(otherKey)
+
+	var mismatched map[string]string
+	_ = json.Unmarshal([]byte(account), &mismatched)
+	mismatched["private_key"] = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: otherDer}))
+	mismatchedAccount, _ := json.Marshal(mismatched)
+
+	result, _ = v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "project_id_service_account_json", Username: "project", Password: string(mismatchedAccount)})
+	if result.Status != statusUnavailable || !strings.Contains(result.Error, "invalid_grant") {
+		t.Errorf("want unavailable with the token error, got %+v", result)
+	}
+
+	result, _ = v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "project_id_service_account_json", Username: "another-project", Password: account})
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable for a mismatched project, got %+v", result)
+	}
+
+	result, _ = v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "project_id_service_account_json", Username: "project", Password: "{not json"})
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable for an invalid JSON, got %+v", result)
+	}
+}
@@ -0,0 +1,128 @@
+package verifiers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	c "github.com/RedHatInsights/sources-api-go/config"
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+const (
+	statusAvailable   = "available"
+	statusUnavailable = "unavailable"
+
+	// defaultTimeout limits the requests of the verifiers without a configured timeout.
+	defaultTimeout = 10 * time.Second
+)
+
+// Result is the availability status a verifier came up with, in the same terms as the status messages.
+type Result struct {
+	Status string
+	Error  string
+	// Certificate is the certificate the endpoint presented, for the TLS endpoints.
+	Certificate *Certificate
+}
+
+func resultFrom(err error) Result {
+	if err != nil {
+		return Result{Status: statusUnavailable, Error: err.Error()}
+	}
+
+	return Result{Status: statusAvailable}
+}
+
+// Config holds the endpoints the verifiers talk to. They are configurable so that the verifiers can be pointed at
+// stub servers.
+type Config struct {
+	// AwsStsEndpoint is the STS endpoint. Empty uses the SDK's default one for the region.
+	AwsStsEndpoint string
+	AwsRegion      string
+	// AzureLoginEndpoint is the Microsoft identity platform's base URL.
+	AzureLoginEndpoint string
+	// GcpTokenEndpoint is the Google OAuth 2.0 token endpoint.
+	GcpTokenEndpoint string
+	Timeout          time.Duration
+	// CertificateExpiryWindow is how long before their expiry the endpoints' certificates get reported.
+	CertificateExpiryWindow time.Duration
+	// AllowPrivateEndpoints lets the endpoints point at loopback, private and link-local addresses, which are
+	// refused otherwise.
+	AllowPrivateEndpoints bool
+}
+
+// ConfigFromEnv returns the verifiers' configuration from the runtime configuration.
+func ConfigFromEnv() Config {
+	cfg := c.Get()
+
+	return Config{
+		AwsStsEndpoint:     cfg.VerifierAwsStsUrl,
+		AwsRegion:          cfg.AwsRegion,
+		AzureLoginEndpoint: cfg.VerifierAzureLoginUrl,
+		GcpTokenEndpoint:   cfg.VerifierGcpTokenUrl,
+		Timeout:            time.Duration(cfg.VerifierTimeout) * time.Second,
+
+		CertificateExpiryWindow: time.Duration(cfg.CertificateExpiryDays) * 24 * time.Hour,
+	}
+}
+
+// authenticationVerifier checks that the credentials of an authentication are accepted by their provider.
+type authenticationVerifier interface {
+	Verify(ctx context.Context, auth *m.Authentication) error
+}
+
+// Verifiers checks the credentials and the endpoints of the sources with the built-in verifiers.
+type Verifiers struct {
+	authTypes    map[string]authenticationVerifier
+	endpoints    *endpointVerifier
+	expiryWindow time.Duration
+}
+
+// New returns the built-in verifiers, talking to the endpoints of the given configuration.
+func New(cfg Config) *Verifiers {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	return &Verifiers{
+		authTypes: map[string]authenticationVerifier{
+			"access_key_secret_key":             &awsAccessKeyVerifier{endpoint: cfg.AwsStsEndpoint, region: cfg.AwsRegion, client: client},
+			"arn":                               &awsArnVerifier{},
+			"cloud-meter-arn":                   &awsArnVerifier{},
+			"tenant_id_client_id_client_secret": &azureClientSecretVerifier{endpoint: cfg.AzureLoginEndpoint, client: client},
+			"project_id_service_account_json":   &gcpServiceAccountVerifier{endpoint: cfg.GcpTokenEndpoint, client: client},
+		},
+		endpoints:    &endpointVerifier{timeout: cfg.Timeout, allowPrivateAddresses: cfg.AllowPrivateEndpoints},
+		expiryWindow: cfg.CertificateExpiryWindow,
+	}
+}
+
+// VerifyAuthentication verifies the authentication's credentials. The returned boolean is false when there is no
+// built-in verifier for the authentication's type.
+func (v *Verifiers) VerifyAuthentication(ctx context.Context, auth *m.Authentication) (Result, bool) {
+	verifier, ok := v.authTypes[auth.AuthType]
+	if !ok {
+		return Result{}, false
+	}
+
+	return resultFrom(verifier.Verify(ctx, auth)), true
+}
+
+// VerifyEndpoint checks that the endpoint is reachable, honoring its certificate authority and its "verify_ssl"
+// setting. A certificate which expires within the expiry window leaves the endpoint available, with an error that
+// warns about it.
+func (v *Verifiers) VerifyEndpoint(ctx context.Context, endpoint *m.Endpoint) Result {
+	certificate, err := v.endpoints.Verify(ctx, endpoint)
+
+	result := resultFrom(err)
+	result.Certificate = certificate
+
+	if err == nil && certificate != nil && time.Until(certificate.NotAfter) < v.expiryWindow {
+		result.Error = fmt.Sprintf("the certificate expires on %s", certificate.NotAfter.Format(time.RFC3339))
+	}
+
+	return result
+}
@@ -0,0 +1,98 @@
+package verifiers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	m "github.com/RedHatInsights/sources-api-go/model"
+)
+
+const stsIdentityResponse = `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult>
+    <Arn>arn:aws:iam::123456789012:user/sources</Arn>
+    <UserId>AIDAEXAMPLE</UserId>
+    <Account>123456789012</Account>
+  </GetCallerIdentityResult>
+  <ResponseMetadata><RequestId>1</RequestId></ResponseMetadata>
+</GetCallerIdentityResponse>`
+
+const stsErrorResponse = `<ErrorResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>InvalidClientTokenId</Code>
+    <Message>The security token included in the request is invalid.</Message>
+  </Error>
+  <RequestId>1</RequestId>
+</ErrorResponse>`
+
+// stubSts answers the GetCallerIdentity requests, accepting only the "valid" access key.
+func stubSts(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "GetCallerIdentity" {
+			t.Errorf("unexpected STS request: %v", r.Form)
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential=valid/") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(stsErrorResponse))
+			return
+		}
+
+		_, _ = w.Write([]byte(stsIdentityResponse))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestVerifyAwsAccessKey(t *testing.T) {
+	server := stubSts(t)
+	v := New(Config{AwsStsEndpoint: server.URL})
+
+	result, ok := v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "access_key_secret_key", Username: "valid", Password: "secret"})
+	if !ok || result.Status != statusAvailable {
+		t.Errorf("want available, got %+v", result)
+	}
+
+	result, _ = v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "access_key_secret_key", Username: "invalid", Password: "secret"})
+	if result.Status != statusUnavailable || !strings.Contains(result.Error, "security token included in the request is invalid") {
+		t.Errorf("want unavailable with the STS error, got %+v", result)
+	}
+
+	result, _ = v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "access_key_secret_key"})
+	if result.Status != statusUnavailable {
+		t.Errorf("want unavailable for missing keys, got %+v", result)
+	}
+}
+
+func TestVerifyAwsArn(t *testing.T) {
+	v := New(Config{})
+
+	testCases := []struct {
+		arn  string
+		want string
+	}{
+		{arn: "arn:aws:iam::123456789012:role/cost-management", want: statusAvailable},
+		{arn: "arn:aws:iam::123456789012:user/someone", want: statusUnavailable},
+		{arn: "arn:aws:s3:::bucket", want: statusUnavailable},
+		{arn: "not an arn", want: statusUnavailable},
+	}
+
+	for _, tc := range testCases {
+		result, ok := v.VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "arn", Username: tc.arn})
+		if !ok || result.Status != tc.want {
+			t.Errorf("%q: want %q, got %+v", tc.arn, tc.want, result)
+		}
+	}
+}
+
+func TestVerifyUnknownAuthType(t *testing.T) {
+	_, ok := New(Config{}).VerifyAuthentication(context.Background(), &m.Authentication{AuthType: "username_password"})
+	if ok {
+		t.Error("want no verifier for the authentication type, got one")
+	}
+}